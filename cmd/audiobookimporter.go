@@ -6,12 +6,12 @@ import (
 	"log/slog"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks"
-	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/audible"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/providers"
 	"github.com/bobbyrward/stronghold/internal/models"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 	"github.com/cappuccinotm/slogx"
 	"github.com/spf13/cobra"
 )
@@ -38,19 +38,20 @@ func runAudiobookImporter(cmd *cobra.Command, args []string) error {
 
 	eventlog.Cleanup(ctx, db, 90)
 
-	qbitClient, err := qbit.CreateClient()
+	downloadClient, err := downloadclient.CreateClient()
 	if err != nil {
-		slog.ErrorContext(ctx, "failed to create qBittorrent client", slogx.Error(err))
-		return fmt.Errorf("failed to create qBittorrent client: %w", err)
+		slog.ErrorContext(ctx, "failed to create download client", slogx.Error(err))
+		return fmt.Errorf("failed to create download client: %w", err)
 	}
 
-	audibleApiClient := audible.NewAudibleApiClient()
+	metadataSources := providers.NewDefaultRegistry(config.Config.Hardcover.ApiToken)
 
 	abookImporterSystem, err := audiobooks.NewAudiobookImporterSystem(
-		qbitClient,
+		downloadClient,
 		config.Config.Importers,
 		metadata.NewFFProbeMetadataProvider(),
-		audibleApiClient,
+		metadata.NewFFProbeMediaProbe(),
+		metadataSources,
 		db,
 	)
 	if err != nil {
@@ -9,14 +9,15 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks"
-	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/audible"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/providers"
 	"github.com/bobbyrward/stronghold/internal/importers/authorsubscriptions"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
 	"github.com/bobbyrward/stronghold/internal/importers/ebooks"
 	"github.com/bobbyrward/stronghold/internal/models"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 )
 
 func createAuthorSubscriptionImporterCmd() *cobra.Command {
@@ -44,20 +45,21 @@ func runAuthorSubscriptionImporter(cmd *cobra.Command, args []string) error {
 
 	eventlog.Cleanup(ctx, db, 90)
 
-	// Create qBittorrent client
-	qbitClient, err := qbit.CreateClient()
+	// Create download client
+	downloadClient, err := downloadclient.CreateClient()
 	if err != nil {
-		slog.ErrorContext(ctx, "failed to create qBittorrent client", slogx.Error(err))
-		return fmt.Errorf("failed to create qBittorrent client: %w", err)
+		slog.ErrorContext(ctx, "failed to create download client", slogx.Error(err))
+		return fmt.Errorf("failed to create download client: %w", err)
 	}
 
 	// Create audiobook importer system (for audiobook imports)
-	audibleApiClient := audible.NewAudibleApiClient()
+	metadataSources := providers.NewDefaultRegistry(config.Config.Hardcover.ApiToken)
 	audiobookSystem, err := audiobooks.NewAudiobookImporterSystem(
-		qbitClient,
+		downloadClient,
 		config.Config.Importers,
 		metadata.NewFFProbeMetadataProvider(),
-		audibleApiClient,
+		metadata.NewFFProbeMediaProbe(),
+		metadataSources,
 		db,
 	)
 	if err != nil {
@@ -66,14 +68,15 @@ func runAuthorSubscriptionImporter(cmd *cobra.Command, args []string) error {
 	}
 
 	// Create ebook importer system (for ebook imports)
-	ebookSystem := ebooks.NewBookImporterSystem(qbitClient, db)
+	ebookSystem := ebooks.NewBookImporterSystem(downloadClient, db)
 
 	// Create and run the author subscription importer
+	router := common.NewRouter(db, audiobookSystem, ebookSystem)
+
 	importer := authorsubscriptions.NewAuthorSubscriptionImporter(
 		db,
-		qbitClient,
-		audiobookSystem,
-		ebookSystem,
+		downloadClient,
+		router,
 	)
 
 	err = importer.Run(ctx)
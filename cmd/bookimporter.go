@@ -8,10 +8,10 @@ import (
 	"github.com/cappuccinotm/slogx"
 	"github.com/spf13/cobra"
 
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/importers/ebooks"
 	"github.com/bobbyrward/stronghold/internal/models"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 )
 
 func createBookImportCmd() *cobra.Command {
@@ -36,13 +36,13 @@ func runBookImport(cmd *cobra.Command, args []string) error {
 
 	eventlog.Cleanup(ctx, db, 90)
 
-	qbitClient, err := qbit.CreateClient()
+	downloadClient, err := downloadclient.CreateClient()
 	if err != nil {
-		slog.ErrorContext(ctx, "failed to create qBittorrent client", slogx.Error(err))
-		return fmt.Errorf("failed to create qBittorrent client: %w", err)
+		slog.ErrorContext(ctx, "failed to create download client", slogx.Error(err))
+		return fmt.Errorf("failed to create download client: %w", err)
 	}
 
-	bookImporterSystem := ebooks.NewBookImporterSystem(qbitClient, db)
+	bookImporterSystem := ebooks.NewBookImporterSystem(downloadClient, db)
 
 	err = bookImporterSystem.Run(ctx)
 	if err != nil {
@@ -95,6 +95,22 @@ func displaySearchResultsTable(searchParams *booksearch.SearchParameters, result
 
 	fmt.Println(tableWriter.Render())
 
+	if result.TotalFound > 0 {
+		last := result.TotalFound
+		if result.HasMore() {
+			last = result.NextOffset
+		}
+		fmt.Printf("Showing results %d-%d of %d\n", result.Offset+1, last, result.TotalFound)
+	}
+
+	if result.Cached {
+		fmt.Println("Results served from cache, use --no-cache to refresh")
+	}
+
+	if result.HasMore() && searchParams.MaxResults > 0 {
+		fmt.Printf("Use --page %d for more\n", result.NextOffset/searchParams.MaxResults+1)
+	}
+
 	return nil
 }
 
@@ -3,6 +3,10 @@ package booksearch
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"os"
+	"os/user"
+	"strings"
 
 	"github.com/bobbyrward/stronghold/internal/booksearch"
 	"github.com/bobbyrward/stronghold/internal/models"
@@ -11,8 +15,18 @@ import (
 
 func createSearchCommand() *cobra.Command {
 	var (
-		format string
-		limit  int
+		format         string
+		limit          int
+		page           int
+		languages      []int
+		mainCategories []string
+		categories     []int
+		fileTypes      []string
+		startDate      string
+		endDate        string
+		searchType     string
+		sortType       string
+		noCache        bool
 	)
 
 	searchCmd := &cobra.Command{
@@ -20,19 +34,53 @@ func createSearchCommand() *cobra.Command {
 		Short: "Search for books using external APIs",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			query := args[0]
+			if page < 1 {
+				return fmt.Errorf("page must be at least 1")
+			}
 
-			return runSearchCommand(cmd, query, format, limit)
+			params := booksearch.SearchParameters{
+				Query:      args[0],
+				MaxResults: limit,
+				Offset:     (page - 1) * limit,
+				Languages:  languages,
+				Categories: categories,
+				Formats:    fileTypes,
+				StartDate:  startDate,
+				EndDate:    endDate,
+				SearchType: searchType,
+				SortType:   sortType,
+				SkipCache:  noCache,
+			}
+
+			for _, value := range mainCategories {
+				mainCategory, err := booksearch.ParseMainCategory(value)
+				if err != nil {
+					return err
+				}
+				params.MainCategories = append(params.MainCategories, mainCategory)
+			}
+
+			return runSearchCommand(cmd, &params, format)
 		},
 	}
 
 	searchCmd.Flags().StringVarP(&format, "format", "f", "table", "Output format (table, json)")
-	searchCmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of results")
+	searchCmd.Flags().IntVar(&limit, "limit", 10, "Maximum number of results per page")
+	searchCmd.Flags().IntVar(&page, "page", 1, "Page of results to show")
+	searchCmd.Flags().IntSliceVar(&languages, "language", nil, "Language IDs to search (default English)")
+	searchCmd.Flags().StringSliceVar(&mainCategories, "type", nil, "Main categories to search: ebooks, audiobooks or IDs (default both)")
+	searchCmd.Flags().IntSliceVar(&categories, "category", nil, "Category IDs to search")
+	searchCmd.Flags().StringSliceVar(&fileTypes, "filetype", nil, "Only show results with one of these file types, e.g. m4b,epub")
+	searchCmd.Flags().StringVar(&startDate, "from", "", "Only show torrents added on or after this date (YYYY-MM-DD)")
+	searchCmd.Flags().StringVar(&endDate, "to", "", "Only show torrents added on or before this date (YYYY-MM-DD)")
+	searchCmd.Flags().StringVar(&searchType, "search-type", booksearch.SearchTypeActive, "Torrents to search: "+strings.Join(booksearch.SearchTypes, ", "))
+	searchCmd.Flags().StringVar(&sortType, "sort", booksearch.SortDateDesc, "Sort order: "+strings.Join(booksearch.SortTypes, ", "))
+	searchCmd.Flags().BoolVar(&noCache, "no-cache", false, "Bypass the search cache and query the site directly")
 
 	return searchCmd
 }
 
-func runSearchCommand(cmd *cobra.Command, query, format string, limit int) error {
+func runSearchCommand(cmd *cobra.Command, params *booksearch.SearchParameters, format string) error {
 	ctx := context.Background()
 
 	// Connect to database
@@ -43,17 +91,32 @@ func runSearchCommand(cmd *cobra.Command, query, format string, limit int) error
 
 	searchService := booksearch.NewBookSearchService()
 
-	params := booksearch.SearchParameters{MaxResults: limit, Query: query}
-
-	results, err := searchService.Search(ctx, db, &params)
+	results, err := searchService.Search(ctx, db, params)
 	if err != nil {
 		return fmt.Errorf("failed to search for books: %w", err)
 	}
 
-	err = displaySearchResults(&params, results, format)
+	_, err = booksearch.RecordSearch(db, booksearch.Requester{
+		Source:      booksearch.HistorySourceCLI,
+		RequestedBy: currentUsername(),
+	}, params, results)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to record search history", slog.Any("error", err))
+	}
+
+	err = displaySearchResults(params, results, format)
 	if err != nil {
 		return fmt.Errorf("failed to display results: %w", err)
 	}
 
 	return nil
 }
+
+// currentUsername returns the local user running the command, for search history.
+func currentUsername() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+
+	return os.Getenv("USER")
+}
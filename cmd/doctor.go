@@ -5,10 +5,15 @@ import (
 	"fmt"
 	"log/slog"
 	"strconv"
+	"strings"
 
 	"github.com/bobbyrward/stronghold/internal/catalog"
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/hardcover"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/library"
 	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/spf13/cobra"
 	"gorm.io/gorm"
@@ -24,6 +29,9 @@ func createDoctorCmd() *cobra.Command {
 	doctorCmd.AddCommand(createDoctorInitBookSearchCmd())
 	doctorCmd.AddCommand(createDoctorBackfillHardcoverRefsCmd())
 	doctorCmd.AddCommand(createDoctorSyncBibliographyCmd())
+	doctorCmd.AddCommand(createDoctorVerifyLibrariesCmd())
+	doctorCmd.AddCommand(createDoctorScanLibrariesCmd())
+	doctorCmd.AddCommand(createDoctorMigrateImportConfigCmd())
 
 	return doctorCmd
 }
@@ -60,6 +68,175 @@ func runDoctorSyncBibliographyCmd(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func createDoctorVerifyLibrariesCmd() *cobra.Command {
+	var opts library.VerifyOptions
+
+	verifyLibrariesCmd := &cobra.Command{
+		Use:   "verify-libraries",
+		Short: "Check each library on disk against the recorded imports",
+		Long: `Walk every library path and cross-reference the books found with the imports
+recorded in the database. Reports recorded books that are missing from disk,
+audiobook directories without a metadata.opf, books imported more than once, and
+books on disk that were never recorded. Nothing is changed unless a repair flag
+is given. Libraries imported before import tracking existed report every book as
+an orphan until --adopt-orphans is run once.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctorVerifyLibrariesCmd(cmd, args, opts)
+		},
+	}
+
+	verifyLibrariesCmd.Flags().BoolVar(&opts.RegenerateOpf, "regenerate-opf", false, "Rewrite missing metadata.opf files from the metadata recorded at import")
+	verifyLibrariesCmd.Flags().BoolVar(&opts.ForgetMissing, "forget-missing", false, "Delete the import records of books no longer on disk")
+	verifyLibrariesCmd.Flags().BoolVar(&opts.AdoptOrphans, "adopt-orphans", false, "Record books found on disk that have no import record")
+
+	return verifyLibrariesCmd
+}
+
+func runDoctorVerifyLibrariesCmd(cmd *cobra.Command, args []string, opts library.VerifyOptions) error {
+	ctx := context.Background()
+
+	slog.InfoContext(ctx, "Verifying libraries")
+
+	db, err := models.ConnectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	reports, err := library.VerifyLibraries(ctx, db, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		logLibraryReport(db, report)
+
+		fmt.Printf("%s (%s): %d books, %d issues\n", report.Library, report.Path, report.Books, len(report.Issues))
+
+		for _, issue := range report.Issues {
+			status := ""
+			if issue.Repaired {
+				status = " [repaired]"
+			}
+
+			if issue.Detail != "" {
+				fmt.Printf("  %-12s %s: %s%s\n", strings.ToUpper(string(issue.Kind)), issue.Path, issue.Detail, status)
+			} else {
+				fmt.Printf("  %-12s %s%s\n", strings.ToUpper(string(issue.Kind)), issue.Path, status)
+			}
+		}
+	}
+
+	return nil
+}
+
+// logLibraryReport records a verification report in the event log, counting issues by kind.
+func logLibraryReport(db *gorm.DB, report library.Report) {
+	counts := make(map[string]int)
+	repaired := 0
+
+	for _, issue := range report.Issues {
+		counts[string(issue.Kind)]++
+		if issue.Repaired {
+			repaired++
+		}
+	}
+
+	eventlog.Log(db, eventlog.CategoryLibrary, eventlog.EventLibraryVerified, eventlog.SourceDoctor,
+		eventlog.EntityLibrary, fmt.Sprintf("%d", report.LibraryID),
+		fmt.Sprintf("Verified library %s: %d books, %d issues", report.Library, report.Books, len(report.Issues)),
+		map[string]any{"path": report.Path, "books": report.Books, "issues": counts, "repaired": repaired})
+}
+
+func createDoctorScanLibrariesCmd() *cobra.Command {
+	var full bool
+
+	scanLibrariesCmd := &cobra.Command{
+		Use:   "scan-libraries",
+		Short: "Match the books already in each library to the catalog",
+		Long: `Walk every library path and read each book's metadata.opf, EPUB metadata or
+audio tags. Books are matched to catalog Books by author (or alias) and title,
+their location is recorded, and matching acquisition targets are marked
+satisfied. Re-scans only read books that are new or changed since the last scan;
+use --full to read everything again.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctorScanLibrariesCmd(cmd, args, full)
+		},
+	}
+
+	scanLibrariesCmd.Flags().BoolVar(&full, "full", false, "Read every book, not only new and changed ones")
+
+	return scanLibrariesCmd
+}
+
+func runDoctorScanLibrariesCmd(cmd *cobra.Command, args []string, full bool) error {
+	ctx := context.Background()
+
+	slog.InfoContext(ctx, "Scanning libraries")
+
+	db, err := models.ConnectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	scanner := library.NewScanner(db, metadata.NewFFProbeMetadataProvider())
+	scanner.Full = full
+
+	results, err := scanner.ScanLibraries(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		eventlog.Log(db, eventlog.CategoryLibrary, eventlog.EventLibraryScanned, eventlog.SourceDoctor,
+			eventlog.EntityLibrary, fmt.Sprintf("%d", result.LibraryID),
+			fmt.Sprintf("Scanned library %s: %d books, %d matched", result.Library, result.Found, result.Matched),
+			map[string]any{"found": result.Found, "read": result.Read, "matched": result.Matched, "satisfied": result.Satisfied, "removed": result.Removed})
+
+		fmt.Printf("%s: %d books, %d read, %d matched, %d targets satisfied, %d removed\n",
+			result.Library, result.Found, result.Read, result.Matched, result.Satisfied, result.Removed)
+	}
+
+	return nil
+}
+
+func createDoctorMigrateImportConfigCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate-import-config",
+		Short: "Copy libraries and import types from the YAML config into the database",
+		Long: `Create a database library for every library under importers.ebooks and
+importers.audiobooks, and a database import type for every import type there.
+Libraries and categories that already exist in the database are left alone, so
+this is safe to run again. Import types whose category or library cannot be
+found are skipped with a warning; once migrated, the importers only read the
+database and the YAML sections can be removed.`,
+		RunE: runDoctorMigrateImportConfigCmd,
+	}
+}
+
+func runDoctorMigrateImportConfigCmd(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	slog.InfoContext(ctx, "Migrating import configuration to the database")
+
+	db, err := models.ConnectDB()
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	result, err := common.MigrateImportConfig(ctx, db, config.Config.Importers)
+	if err != nil {
+		return err
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Printf("warning: %s\n", warning)
+	}
+
+	fmt.Printf("Import config migration complete: %d libraries and %d import types created\n",
+		result.LibrariesCreated, result.ImportTypesCreated)
+	return nil
+}
+
 func createDoctorBackfillHardcoverRefsCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "backfill-hardcover-refs",
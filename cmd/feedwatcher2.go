@@ -8,10 +8,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/feedwatcher2"
 	"github.com/bobbyrward/stronghold/internal/models"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 )
 
 func createFeedWatcher2Cmd() *cobra.Command {
@@ -19,7 +19,7 @@ func createFeedWatcher2Cmd() *cobra.Command {
 		Use:   "feedwatcher2",
 		Short: "Run feedwatcher2 to monitor feeds for subscribed authors",
 		Long: `Monitors RSS feeds from the database, matches items against author
-subscriptions (including aliases), and sends matching torrents to qBittorrent.
+subscriptions (including aliases), and sends matching torrents to the download client.
 
 This is the database-driven successor to feed-watcher, using Author and
 AuthorSubscription models instead of config-based filters.`,
@@ -40,17 +40,18 @@ func runFeedWatcher2(cmd *cobra.Command, args []string) error {
 	// Clean up old event logs
 	eventlog.Cleanup(ctx, db, 90)
 
-	qbitClient, err := qbit.CreateClient()
+	downloadClient, err := downloadclient.CreateClient()
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to create qBittorrent client", slog.Any("error", err))
-		return fmt.Errorf("failed to create qBittorrent client: %w", err)
+		slog.ErrorContext(ctx, "Failed to create download client", slog.Any("error", err))
+		return fmt.Errorf("failed to create download client: %w", err)
 	}
 
 	fw := feedwatcher2.NewFeedWatcher2(
 		db,
-		qbitClient,
+		downloadClient,
 		config.Config.BookSearch.HttpProxy,
 		config.Config.BookSearch.HttpsProxy,
+		config.Config.FeedWatcher2,
 	)
 
 	if err := fw.Run(ctx); err != nil {
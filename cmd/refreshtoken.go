@@ -3,26 +3,35 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/bobbyrward/stronghold/internal/booksearch"
+	"github.com/bobbyrward/stronghold/internal/config"
 	"github.com/bobbyrward/stronghold/internal/models"
 )
 
 func createRefreshTokenCmd() *cobra.Command {
+	var ifDue bool
+
 	refreshTokenCmd := &cobra.Command{
 		Use:   "refresh-token",
 		Short: "Refresh the book search API token",
+		Long: `Refreshes the book search token cookie and records the IP address and ASN
+the site saw. With --if-due the token is only refreshed once it is older than
+bookSearch.tokenRefresh.intervalHours, so cron can run it hourly.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRefreshToken(cmd, args)
+			return runRefreshToken(cmd, args, ifDue)
 		},
 	}
 
+	refreshTokenCmd.Flags().BoolVar(&ifDue, "if-due", false, "Only refresh if the token is older than bookSearch.tokenRefresh.intervalHours")
+
 	return refreshTokenCmd
 }
 
-func runRefreshToken(cmd *cobra.Command, args []string) error {
+func runRefreshToken(cmd *cobra.Command, args []string, ifDue bool) error {
 	ctx := context.Background()
 
 	// Connect to database
@@ -33,9 +42,29 @@ func runRefreshToken(cmd *cobra.Command, args []string) error {
 
 	searchService := booksearch.NewBookSearchService()
 
-	err = searchService.RefreshToken(ctx, db)
-	if err != nil {
-		return fmt.Errorf("failed to refresh token: %w", err)
+	if ifDue {
+		intervalHours := config.Config.BookSearch.TokenRefresh.IntervalHours
+		if intervalHours <= 0 {
+			fmt.Println("Scheduled token refreshes are disabled")
+			return nil
+		}
+
+		interval := time.Duration(intervalHours) * time.Hour
+
+		refreshed, err := searchService.RefreshTokenIfDue(ctx, db, interval)
+		if err != nil {
+			return fmt.Errorf("failed to refresh token: %w", err)
+		}
+
+		if !refreshed {
+			fmt.Println("Token refresh not due yet")
+			return nil
+		}
+	} else {
+		err = searchService.RefreshToken(ctx, db)
+		if err != nil {
+			return fmt.Errorf("failed to refresh token: %w", err)
+		}
 	}
 
 	fmt.Println("Token refresh completed successfully")
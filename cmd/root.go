@@ -52,6 +52,9 @@ func init() {
 	rootCmd.AddCommand(createFeedWatcher2Cmd())
 	rootCmd.AddCommand(createSubscribeCmd())
 	rootCmd.AddCommand(createAuthorSubscriptionImporterCmd())
+	rootCmd.AddCommand(createTorrentCleanupCmd())
+	rootCmd.AddCommand(createSubscriptionReconcileCmd())
+	rootCmd.AddCommand(createTorrentHealthCmd())
 }
 
 func internalCobraInit() error {
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/spf13/cobra"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/feedwatcher2"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func createSubscriptionReconcileCmd() *cobra.Command {
+	var dryRun bool
+	var action string
+
+	subscriptionReconcileCmd := &cobra.Command{
+		Use:   "subscription-reconcile",
+		Short: "Check author subscription downloads against the download client",
+		Long: `Compares the torrents feedwatcher2 downloaded for author subscriptions
+against the download client and marks items whose torrents are missing,
+errored, stalled or recategorized.
+
+With the "readd" action, missing torrents are added again and recategorized
+torrents are moved back. With the "release" action, drifted items are deleted
+so the next feed hit downloads them again.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			reconcileConfig := config.Config.FeedWatcher2.Reconcile
+			if cmd.Flags().Changed("action") {
+				reconcileConfig.Action = action
+			}
+
+			return runSubscriptionReconcile(cmd, args, reconcileConfig, dryRun)
+		},
+	}
+
+	subscriptionReconcileCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Log the changes that would be made without making them")
+	subscriptionReconcileCmd.Flags().StringVar(&action, "action", "", `Override the configured action: "", "readd" or "release"`)
+
+	return subscriptionReconcileCmd
+}
+
+func runSubscriptionReconcile(cmd *cobra.Command, args []string, reconcileConfig config.ReconcileConfig, dryRun bool) error {
+	ctx := context.Background()
+
+	slog.InfoContext(ctx, "Starting subscription reconcile command")
+
+	db, err := models.ConnectAndMigrate(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to connect to database", slogx.Error(err))
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	downloadClient, err := downloadclient.CreateClient()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create download client", slogx.Error(err))
+		return fmt.Errorf("failed to create download client: %w", err)
+	}
+
+	reconciler := feedwatcher2.NewReconciler(
+		db,
+		downloadClient,
+		config.Config.BookSearch.HttpProxy,
+		config.Config.BookSearch.HttpsProxy,
+		reconcileConfig,
+	)
+	reconciler.DryRun = dryRun
+
+	drifts, err := reconciler.Run(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to reconcile subscription items", slogx.Error(err))
+		return fmt.Errorf("failed to reconcile subscription items: %w", err)
+	}
+
+	for _, drift := range drifts {
+		fmt.Printf("%s\t%s\t%s\t%s\n", drift.Item.TorrentHash, drift.Status, drift.Outcome, drift.Item.Title)
+	}
+
+	slog.InfoContext(ctx, "Subscription reconcile completed successfully", slog.Int("drifted", len(drifts)))
+
+	return nil
+}
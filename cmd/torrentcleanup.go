@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/spf13/cobra"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/importers/cleanup"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func createTorrentCleanupCmd() *cobra.Command {
+	var dryRun bool
+
+	torrentCleanupCmd := &cobra.Command{
+		Use:   "torrent-cleanup",
+		Short: "Remove imported torrents from the download client according to each import type's retention policy",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runTorrentCleanup(cmd, args, dryRun)
+		},
+	}
+
+	torrentCleanupCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Log the torrents that would be removed without removing them")
+
+	return torrentCleanupCmd
+}
+
+func runTorrentCleanup(cmd *cobra.Command, args []string, dryRun bool) error {
+	ctx := context.Background()
+
+	slog.InfoContext(ctx, "Starting torrent cleanup command")
+
+	db, err := models.ConnectAndMigrate(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to connect to database", slogx.Error(err))
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	downloadClient, err := downloadclient.CreateClient()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create download client", slogx.Error(err))
+		return fmt.Errorf("failed to create download client: %w", err)
+	}
+
+	cleanupSystem := cleanup.NewCleanupSystem(downloadClient, config.Config.Importers, db)
+	cleanupSystem.DryRun = dryRun
+
+	removals, err := cleanupSystem.Run(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to run torrent cleanup", slogx.Error(err))
+		return fmt.Errorf("failed to run torrent cleanup: %w", err)
+	}
+
+	for _, removal := range removals {
+		fmt.Printf("%s\t%s\t%s\n", removal.Torrent.Hash, removal.Reason, removal.Torrent.Name)
+	}
+
+	slog.InfoContext(ctx, "Torrent cleanup completed successfully", slog.Int("removed", len(removals)))
+
+	return nil
+}
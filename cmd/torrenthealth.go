@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/spf13/cobra"
+
+	"github.com/bobbyrward/stronghold/internal/booksearch"
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/torrenthealth"
+)
+
+func createTorrentHealthCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "torrent-health",
+		Short: "Flag incomplete torrents that stopped downloading",
+		Long: `Checks the state, speed and availability of incomplete torrents and
+flags the ones that made no progress for longer than torrentHealth.stalledAfterHours.
+Stalls are timed across runs, so run this periodically.
+
+Flagged torrents are reported through their author subscription's notifier.
+With removeStalled they are removed, and with searchAlternative another
+release of the book is downloaded in their place.`,
+		RunE: runTorrentHealth,
+	}
+}
+
+func runTorrentHealth(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	slog.InfoContext(ctx, "Starting torrent health command")
+
+	db, err := models.ConnectAndMigrate(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to connect to database", slogx.Error(err))
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	downloadClient, err := downloadclient.CreateClient()
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create download client", slogx.Error(err))
+		return fmt.Errorf("failed to create download client: %w", err)
+	}
+
+	monitor := torrenthealth.NewMonitor(
+		db,
+		downloadClient,
+		booksearch.NewBookSearchService(),
+		config.Config.BookSearch.HttpProxy,
+		config.Config.BookSearch.HttpsProxy,
+		config.Config.TorrentHealth,
+	)
+
+	flags, err := monitor.Run(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to check torrent health", slogx.Error(err))
+		return fmt.Errorf("failed to check torrent health: %w", err)
+	}
+
+	for _, flag := range flags {
+		fmt.Printf("%s\t%s\t%s\t%s\n", flag.Torrent.Hash, flag.Reason, flag.Action, flag.Torrent.Name)
+	}
+
+	slog.InfoContext(ctx, "Torrent health check completed successfully", slog.Int("flagged", len(flags)))
+
+	return nil
+}
@@ -0,0 +1,193 @@
+// Package audiobookshelf asks an Audiobookshelf server to pick up newly imported
+// audiobooks and finds the library items it creates for them.
+package audiobookshelf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+const (
+	defaultLookupAttempts = 10
+	defaultLookupInterval = 3 * time.Second
+	requestTimeout        = 30 * time.Second
+)
+
+// ErrItemNotFound is returned by WaitForItem when the scan has not produced an
+// item for the folder after every lookup attempt.
+var ErrItemNotFound = errors.New("audiobookshelf item not found")
+
+// Client talks to the Audiobookshelf API with an API token. The token's user
+// must be an admin to trigger scans.
+type Client struct {
+	BaseURL string
+	Token   string
+
+	// LookupAttempts and LookupInterval control how long WaitForItem polls
+	// for the item after a scan.
+	LookupAttempts int
+	LookupInterval time.Duration
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// NewClient returns the client for cfg, or nil when no URL is configured.
+func NewClient(cfg config.AudiobookshelfConfig) *Client {
+	if cfg.URL == "" {
+		return nil
+	}
+
+	client := &Client{
+		BaseURL:        strings.TrimSuffix(cfg.URL, "/"),
+		Token:          cfg.APIToken,
+		LookupAttempts: cfg.LookupAttempts,
+		LookupInterval: time.Duration(cfg.LookupIntervalSeconds) * time.Second,
+	}
+
+	if client.LookupAttempts <= 0 {
+		client.LookupAttempts = defaultLookupAttempts
+	}
+	if client.LookupInterval <= 0 {
+		client.LookupInterval = defaultLookupInterval
+	}
+
+	return client
+}
+
+// ItemURL links to a library item in the Audiobookshelf web UI.
+func (c *Client) ItemURL(itemID string) string {
+	return c.BaseURL + "/item/" + url.PathEscape(itemID)
+}
+
+// ScanFolder tells Audiobookshelf a file was added under a library folder, the
+// way its file watcher does, so only that book's folder is scanned. filePath
+// is as Audiobookshelf sees it.
+func (c *Client) ScanFolder(ctx context.Context, libraryID string, filePath string) error {
+	body := map[string]string{
+		"libraryId": libraryID,
+		"path":      filePath,
+		"type":      "add",
+	}
+
+	return c.do(ctx, http.MethodPost, "/api/watcher/update", body, nil)
+}
+
+// searchResponse is the part of a library search reply that holds books.
+type searchResponse struct {
+	Book []struct {
+		LibraryItem libraryItem `json:"libraryItem"`
+	} `json:"book"`
+}
+
+type libraryItem struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+// FindItem searches a library for query and returns the ID of the item whose
+// folder is itemPath. ok is false when there is none yet.
+func (c *Client) FindItem(ctx context.Context, libraryID string, itemPath string, query string) (string, bool, error) {
+	endpoint := fmt.Sprintf("/api/libraries/%s/search?q=%s&limit=25", url.PathEscape(libraryID), url.QueryEscape(query))
+
+	var result searchResponse
+	if err := c.do(ctx, http.MethodGet, endpoint, nil, &result); err != nil {
+		return "", false, err
+	}
+
+	for _, book := range result.Book {
+		if path.Clean(book.LibraryItem.Path) == path.Clean(itemPath) {
+			return book.LibraryItem.ID, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// WaitForItem polls FindItem until the scan has created the item, returning
+// ErrItemNotFound when it never shows up.
+func (c *Client) WaitForItem(ctx context.Context, libraryID string, itemPath string, query string) (string, error) {
+	for attempt := 1; ; attempt++ {
+		itemID, ok, err := c.FindItem(ctx, libraryID, itemPath, query)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return itemID, nil
+		}
+
+		if attempt >= c.LookupAttempts {
+			return "", ErrItemNotFound
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(c.LookupInterval):
+		}
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, endpoint string, body any, result any) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("audiobookshelf request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read audiobookshelf response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audiobookshelf %s %s returned %s: %s", method, strings.SplitN(endpoint, "?", 2)[0], resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if result == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("failed to decode audiobookshelf response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package audiobookshelf
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+func TestNewClient(t *testing.T) {
+	assert.Nil(t, NewClient(config.AudiobookshelfConfig{}))
+
+	client := NewClient(config.AudiobookshelfConfig{URL: "http://abs:13378/", APIToken: "token"})
+	require.NotNil(t, client)
+	assert.Equal(t, "http://abs:13378", client.BaseURL)
+	assert.Equal(t, defaultLookupAttempts, client.LookupAttempts)
+	assert.Equal(t, defaultLookupInterval, client.LookupInterval)
+	assert.Equal(t, "http://abs:13378/item/li_123", client.ItemURL("li_123"))
+}
+
+func TestScanFolder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/watcher/update", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]string{"libraryId": "lib1", "path": "/audiobooks/Dune/metadata.opf", "type": "add"}, body)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(config.AudiobookshelfConfig{URL: server.URL, APIToken: "token"})
+	require.NoError(t, client.ScanFolder(context.Background(), "lib1", "/audiobooks/Dune/metadata.opf"))
+}
+
+func TestScanFolder_Forbidden(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(config.AudiobookshelfConfig{URL: server.URL, APIToken: "token"})
+	err := client.ScanFolder(context.Background(), "lib1", "/audiobooks/Dune/metadata.opf")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
+
+func TestWaitForItem(t *testing.T) {
+	searches := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/libraries/lib1/search", r.URL.Path)
+		assert.Equal(t, "Dune", r.URL.Query().Get("q"))
+		searches++
+
+		// The scan finishes on the third lookup; another book with the same
+		// title is in the library all along
+		books := []map[string]any{
+			{"libraryItem": map[string]any{"id": "li_other", "path": "/audiobooks/Dune (Abridged)"}},
+		}
+		if searches >= 3 {
+			books = append(books, map[string]any{"libraryItem": map[string]any{"id": "li_dune", "path": "/audiobooks/Dune/"}})
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]any{"book": books, "authors": []any{}})
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Token: "token", LookupAttempts: 5, LookupInterval: time.Millisecond}
+
+	itemID, err := client.WaitForItem(context.Background(), "lib1", "/audiobooks/Dune", "Dune")
+	require.NoError(t, err)
+	assert.Equal(t, "li_dune", itemID)
+	assert.Equal(t, 3, searches)
+
+	searches = -10
+	client.LookupAttempts = 2
+	_, err = client.WaitForItem(context.Background(), "lib1", "/audiobooks/Dune", "Dune")
+	assert.ErrorIs(t, err, ErrItemNotFound)
+}
@@ -0,0 +1,142 @@
+package booksearch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// storedItem is a SearchResponseItem without the decoding of the site's
+// format, so stored results are read back as they were written
+type storedItem SearchResponseItem
+
+type storedResponse struct {
+	Total      int          `json:"total"`
+	TotalFound int          `json:"total_found"`
+	Data       []storedItem `json:"data"`
+}
+
+func encodeItems(items []SearchResponseItem) []storedItem {
+	stored := make([]storedItem, len(items))
+	for i, item := range items {
+		stored[i] = storedItem(item)
+	}
+	return stored
+}
+
+func decodeItems(stored []storedItem) []SearchResponseItem {
+	items := make([]SearchResponseItem, len(stored))
+	for i, item := range stored {
+		items[i] = SearchResponseItem(item)
+	}
+	return items
+}
+
+// cacheKey hashes the request with its query normalised, so searches that
+// differ only in case, spacing or filter order share an entry.
+func cacheKey(request SearchRequest) string {
+	request.Tor.Query = strings.Join(strings.Fields(strings.ToLower(request.Tor.Query)), " ")
+	request.Tor.Hash = strings.ToLower(request.Tor.Hash)
+	request.Tor.BrowseLang = slices.Sorted(slices.Values(request.Tor.BrowseLang))
+	request.Tor.MainCategories = slices.Sorted(slices.Values(request.Tor.MainCategories))
+	request.Tor.Categories = slices.Sorted(slices.Values(request.Tor.Categories))
+
+	data, _ := json.Marshal(request)
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// cachedResponse returns the unexpired response cached under key, or nil.
+func cachedResponse(ctx context.Context, db *gorm.DB, key string) *SearchResponse {
+	var entry models.SearchCacheEntry
+
+	err := db.Where("cache_key = ? AND expires_at > ?", key, time.Now()).First(&entry).Error
+	if err != nil {
+		return nil
+	}
+
+	var stored storedResponse
+	if err := json.Unmarshal([]byte(entry.Response), &stored); err != nil {
+		slog.WarnContext(ctx, "Ignoring unreadable search cache entry", slog.Uint64("id", uint64(entry.ID)), slog.Any("err", err))
+		return nil
+	}
+
+	slog.InfoContext(ctx, "Using cached search response", slog.Time("expiresAt", entry.ExpiresAt))
+
+	return &SearchResponse{
+		Total:      stored.Total,
+		TotalFound: stored.TotalFound,
+		Data:       decodeItems(stored.Data),
+		Cached:     true,
+	}
+}
+
+// cacheResponse stores response under key for ttl. Failures are only logged.
+func cacheResponse(ctx context.Context, db *gorm.DB, key string, response *SearchResponse, ttl time.Duration) {
+	data, err := json.Marshal(storedResponse{
+		Total:      response.Total,
+		TotalFound: response.TotalFound,
+		Data:       encodeItems(response.Data),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to encode search response for the cache", slog.Any("err", err))
+		return
+	}
+
+	entry := models.SearchCacheEntry{
+		CacheKey:  key,
+		Response:  string(data),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	err = db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"created_at", "response", "expires_at"}),
+	}).Create(&entry).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to cache search response", slog.Any("err", err))
+	}
+}
+
+// Cleanup deletes expired search cache entries and search history older than
+// retentionDays. A retentionDays of zero keeps the history.
+// It is fire-and-forget: errors are logged but never returned.
+func Cleanup(ctx context.Context, db *gorm.DB, retentionDays int) {
+	result := db.Where("expires_at < ?", time.Now()).Delete(&models.SearchCacheEntry{})
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "Failed to cleanup search cache", slog.Any("error", result.Error))
+	} else if result.RowsAffected > 0 {
+		slog.InfoContext(ctx, "Cleaned up expired search cache entries", slog.Int64("deleted", result.RowsAffected))
+	}
+
+	if retentionDays <= 0 {
+		return
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+
+	result = db.Where("created_at < ?", cutoff).Delete(&models.SearchHistory{})
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "Failed to cleanup search history",
+			slog.Int("retention_days", retentionDays),
+			slog.Any("error", result.Error))
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		slog.InfoContext(ctx, "Cleaned up old search history",
+			slog.Int64("deleted", result.RowsAffected),
+			slog.Int("retention_days", retentionDays))
+	}
+}
@@ -0,0 +1,163 @@
+package booksearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func TestCacheKeyNormalisesQuery(t *testing.T) {
+	first := SearchParameters{Query: "  Dune   Messiah ", Categories: []int{2, 1}}
+	second := SearchParameters{Query: "dune messiah", Categories: []int{1, 2}}
+	other := SearchParameters{Query: "dune messiah", Offset: 25}
+
+	assert.Equal(t, cacheKey(first.request()), cacheKey(second.request()))
+	assert.NotEqual(t, cacheKey(first.request()), cacheKey(other.request()))
+}
+
+func TestSearchUsesCache(t *testing.T) {
+	ctx := context.Background()
+	db, site := setupFakeSite(t)
+	require.NoError(t, models.UpsertBookSearchCredential(db, "current", "192.0.2.1", "64500"))
+	config.Config.BookSearch.Cache.TTLMinutes = 30
+
+	service := NewBookSearchService()
+
+	response, err := service.Search(ctx, db, &SearchParameters{Query: "Dune"})
+	require.NoError(t, err)
+	assert.False(t, response.Cached)
+	assert.Equal(t, 1, site.searches)
+
+	response, err = service.Search(ctx, db, &SearchParameters{Query: " dune "})
+	require.NoError(t, err)
+	assert.True(t, response.Cached)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, "Dune", response.Data[0].Title)
+	assert.Equal(t, 1, site.searches)
+
+	// SkipCache goes to the site and refreshes the entry
+	response, err = service.Search(ctx, db, &SearchParameters{Query: "Dune", SkipCache: true})
+	require.NoError(t, err)
+	assert.False(t, response.Cached)
+	assert.Equal(t, 2, site.searches)
+
+	var count int64
+	require.NoError(t, db.Model(&models.SearchCacheEntry{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// Expired entries are ignored
+	require.NoError(t, db.Model(&models.SearchCacheEntry{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	response, err = service.Search(ctx, db, &SearchParameters{Query: "Dune"})
+	require.NoError(t, err)
+	assert.False(t, response.Cached)
+	assert.Equal(t, 3, site.searches)
+}
+
+func TestSearchCacheDisabled(t *testing.T) {
+	ctx := context.Background()
+	db, site := setupFakeSite(t)
+	require.NoError(t, models.UpsertBookSearchCredential(db, "current", "192.0.2.1", "64500"))
+
+	service := NewBookSearchService()
+
+	for range 2 {
+		response, err := service.Search(ctx, db, &SearchParameters{Query: "Dune"})
+		require.NoError(t, err)
+		assert.False(t, response.Cached)
+	}
+
+	assert.Equal(t, 2, site.searches)
+
+	var count int64
+	require.NoError(t, db.Model(&models.SearchCacheEntry{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestRecordSearch(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	response := &SearchResponse{
+		Total:      1,
+		TotalFound: 3,
+		Cached:     true,
+		Data: []SearchResponseItem{{
+			ID:           42,
+			Title:        "Dune",
+			MainCategory: MainCategoryEbooks,
+			Authors:      map[string]string{"1": "Frank Herbert"},
+			Series:       map[string]SeriesEntry{"7": {Name: "Dune", Index: 1}},
+			FileTypes:    "epub",
+		}},
+	}
+
+	history, err := RecordSearch(db, Requester{
+		Source:      HistorySourceDiscordBot,
+		RequestedBy: "user-1",
+		Location:    "channel-1",
+	}, &SearchParameters{Query: "dune"}, response)
+	require.NoError(t, err)
+
+	var stored models.SearchHistory
+	require.NoError(t, db.First(&stored, history.ID).Error)
+	assert.Equal(t, HistorySourceDiscordBot, stored.Source)
+	assert.Equal(t, "user-1", stored.RequestedBy)
+	assert.Equal(t, "channel-1", stored.Location)
+	assert.Equal(t, "dune", stored.Query)
+	assert.Equal(t, 1, stored.ResultCount)
+	assert.Equal(t, 3, stored.TotalFound)
+	assert.True(t, stored.Cached)
+
+	results, err := HistoryResults(&stored)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, response.Data[0], results[0])
+	assert.Equal(t, "Frank Herbert", results[0].AuthorNames())
+	assert.Equal(t, "Dune(1)", results[0].SeriesNames())
+
+	history, err = RecordSearch(db, Requester{Source: HistorySourceCLI}, &SearchParameters{Hash: "abc"}, &SearchResponse{})
+	require.NoError(t, err)
+	assert.Equal(t, "hash:abc", history.Query)
+	assert.Zero(t, history.ResultCount)
+}
+
+func TestCleanup(t *testing.T) {
+	ctx := context.Background()
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	require.NoError(t, db.Create(&[]models.SearchCacheEntry{
+		{CacheKey: "expired", Response: "{}", ExpiresAt: time.Now().Add(-time.Minute)},
+		{CacheKey: "current", Response: "{}", ExpiresAt: time.Now().Add(time.Hour)},
+	}).Error)
+
+	old := models.SearchHistory{Source: HistorySourceAPI, Query: "old"}
+	recent := models.SearchHistory{Source: HistorySourceAPI, Query: "recent"}
+	require.NoError(t, db.Create(&old).Error)
+	require.NoError(t, db.Create(&recent).Error)
+	require.NoError(t, db.Model(&old).Update("created_at", time.Now().AddDate(0, 0, -100)).Error)
+
+	// A retention of zero only removes expired cache entries
+	Cleanup(ctx, db, 0)
+
+	var keys []string
+	require.NoError(t, db.Model(&models.SearchCacheEntry{}).Pluck("cache_key", &keys).Error)
+	assert.Equal(t, []string{"current"}, keys)
+
+	var count int64
+	require.NoError(t, db.Model(&models.SearchHistory{}).Count(&count).Error)
+	assert.Equal(t, int64(2), count)
+
+	Cleanup(ctx, db, 90)
+
+	var queries []string
+	require.NoError(t, db.Model(&models.SearchHistory{}).Pluck("query", &queries).Error)
+	assert.Equal(t, []string{"recent"}, queries)
+}
@@ -0,0 +1,81 @@
+package booksearch
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// Search history sources
+const (
+	HistorySourceDiscordBot = "discord-bot"
+	HistorySourceAPI        = "api"
+	HistorySourceCLI        = "cli"
+)
+
+// Requester is who ran a search and from where, for the search history
+type Requester struct {
+	Source string
+
+	// RequestedBy is a Discord user ID, client IP or local user
+	RequestedBy string
+
+	// Location is where the search came from, e.g. a Discord channel ID
+	Location string
+}
+
+// RecordSearch adds a search and its results to the search history.
+func RecordSearch(db *gorm.DB, requester Requester, params *SearchParameters, response *SearchResponse) (*models.SearchHistory, error) {
+	parameters, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search parameters: %w", err)
+	}
+
+	results, err := json.Marshal(encodeItems(response.Data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search results: %w", err)
+	}
+
+	query := params.Query
+	switch {
+	case params.Hash != "":
+		query = "hash:" + params.Hash
+	case params.ID != nil:
+		query = fmt.Sprintf("id:%d", *params.ID)
+	}
+
+	history := models.SearchHistory{
+		Source:      requester.Source,
+		RequestedBy: requester.RequestedBy,
+		Location:    requester.Location,
+		Query:       query,
+		Parameters:  string(parameters),
+		ResultCount: len(response.Data),
+		TotalFound:  response.TotalFound,
+		Cached:      response.Cached,
+		Results:     string(results),
+	}
+
+	if err := db.Create(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to record search: %w", err)
+	}
+
+	return &history, nil
+}
+
+// HistoryResults returns the results recorded for a search.
+func HistoryResults(history *models.SearchHistory) ([]SearchResponseItem, error) {
+	if history.Results == "" {
+		return nil, nil
+	}
+
+	var stored []storedItem
+	if err := json.Unmarshal([]byte(history.Results), &stored); err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+
+	return decodeItems(stored), nil
+}
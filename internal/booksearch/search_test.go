@@ -0,0 +1,113 @@
+package booksearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func TestSearchParametersRequestDefaults(t *testing.T) {
+	params := SearchParameters{Query: "Dune", MaxResults: 5}
+	request := params.request()
+
+	assert.Equal(t, 5, request.PerPage)
+	assert.Equal(t, []int{LanguageEnglish}, request.Tor.BrowseLang)
+	assert.Equal(t, []int{MainCategoryAudiobooks, MainCategoryEbooks}, request.Tor.MainCategories)
+	assert.Equal(t, []int{}, request.Tor.Categories)
+	assert.Equal(t, SearchTypeActive, request.Tor.SearchType)
+	assert.Equal(t, SortDateDesc, request.Tor.SortType)
+	assert.Zero(t, request.Tor.StartNumber)
+}
+
+func TestSearchParametersRequestFilters(t *testing.T) {
+	params := SearchParameters{
+		Query:          "Dune",
+		Offset:         40,
+		Languages:      []int{1, 36},
+		MainCategories: []int{MainCategoryEbooks},
+		Categories:     []int{61},
+		StartDate:      "2024-01-01",
+		EndDate:        "2024-06-30",
+		SearchType:     SearchTypeFreeleech,
+		SortType:       "seedersDesc",
+	}
+	request := params.request()
+
+	assert.Equal(t, []int{1, 36}, request.Tor.BrowseLang)
+	assert.Equal(t, []int{MainCategoryEbooks}, request.Tor.MainCategories)
+	assert.Equal(t, []int{61}, request.Tor.Categories)
+	assert.Equal(t, SearchTypeFreeleech, request.Tor.SearchType)
+	assert.Equal(t, "seedersDesc", request.Tor.SortType)
+	assert.Equal(t, 40, request.Tor.StartNumber)
+	assert.Equal(t, "2024-01-01", request.Tor.StartDate)
+	assert.Equal(t, "2024-06-30", request.Tor.EndDate)
+}
+
+func TestParseMainCategory(t *testing.T) {
+	for value, want := range map[string]int{"ebooks": MainCategoryEbooks, "Audiobook": MainCategoryAudiobooks, " 14 ": 14} {
+		got, err := ParseMainCategory(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	_, err := ParseMainCategory("comics")
+	assert.Error(t, err)
+}
+
+func TestSearchPaging(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+	require.NoError(t, models.UpsertBookSearchCredential(db, "token", "", ""))
+
+	var received SearchRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("mam_id")
+		if err != nil || cookie.Value != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_, _ = w.Write([]byte(`{"total": 3, "total_found": 5, "data": [
+			{"id": 1, "title": "Dune", "filetype": "m4b"},
+			{"id": 2, "title": "Dune Messiah", "filetype": "epub mobi"},
+			{"id": 3, "title": "Children of Dune", "filetype": "mp3"}
+		]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	previous := config.Config.BookSearch
+	t.Cleanup(func() { config.Config.BookSearch = previous })
+	config.Config.BookSearch = config.BookSearchConfig{
+		BaseURL:         server.URL,
+		SearchEndpoint:  "/search",
+		TokenCookieName: "mam_id",
+	}
+
+	service := NewBookSearchService()
+
+	response, err := service.Search(context.Background(), db, &SearchParameters{Query: "Dune", MaxResults: 3})
+	require.NoError(t, err)
+	assert.Len(t, response.Data, 3)
+	assert.Equal(t, 0, response.Offset)
+	assert.Equal(t, 3, response.NextOffset)
+	assert.True(t, response.HasMore())
+
+	response, err = service.Search(context.Background(), db, &SearchParameters{Query: "Dune", MaxResults: 3, Offset: 2, Formats: []string{"M4B", ".epub"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, received.Tor.StartNumber)
+	assert.Equal(t, 2, response.Offset)
+	assert.False(t, response.HasMore())
+
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, "Dune", response.Data[0].Title)
+	assert.Equal(t, "Dune Messiah", response.Data[1].Title)
+}
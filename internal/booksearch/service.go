@@ -9,16 +9,22 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
 	"time"
 
 	"github.com/bobbyrward/stronghold/internal/config"
 	"github.com/bobbyrward/stronghold/internal/cookies"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/notifications"
 	"github.com/cappuccinotm/slogx/logger"
 	"github.com/carlmjohnson/requests"
 	"gorm.io/gorm"
 )
 
+// ErrTokenRejected is returned when the site rejects the token cookie
+var ErrTokenRejected = errors.New("book search token rejected")
+
 type BookSearchService struct{}
 
 func NewBookSearchService() *BookSearchService {
@@ -52,9 +58,99 @@ func (params *SearchParameters) Validate() error {
 		return fmt.Errorf("only one of Query or Hash or ID can be set")
 	}
 
+	if params.Offset < 0 {
+		return fmt.Errorf("offset cannot be negative")
+	}
+
+	if params.MaxResults < 0 {
+		return fmt.Errorf("max results cannot be negative")
+	}
+
+	if params.SearchType != "" && !slices.Contains(SearchTypes, params.SearchType) {
+		return fmt.Errorf("unknown search type %q", params.SearchType)
+	}
+
+	if params.SortType != "" && !slices.Contains(SortTypes, params.SortType) {
+		return fmt.Errorf("unknown sort type %q", params.SortType)
+	}
+
+	var startDate, endDate time.Time
+	var err error
+
+	if params.StartDate != "" {
+		startDate, err = time.Parse(SearchDateLayout, params.StartDate)
+		if err != nil {
+			return fmt.Errorf("invalid start date %q, expected YYYY-MM-DD", params.StartDate)
+		}
+	}
+
+	if params.EndDate != "" {
+		endDate, err = time.Parse(SearchDateLayout, params.EndDate)
+		if err != nil {
+			return fmt.Errorf("invalid end date %q, expected YYYY-MM-DD", params.EndDate)
+		}
+	}
+
+	if !startDate.IsZero() && !endDate.IsZero() && endDate.Before(startDate) {
+		return fmt.Errorf("end date %s is before start date %s", params.EndDate, params.StartDate)
+	}
+
 	return nil
 }
 
+// request builds the API request for the parameters, filling in the defaults.
+func (params *SearchParameters) request() SearchRequest {
+	languages := params.Languages
+	if len(languages) == 0 {
+		languages = []int{LanguageEnglish}
+	}
+
+	mainCategories := params.MainCategories
+	if len(mainCategories) == 0 {
+		mainCategories = []int{MainCategoryAudiobooks, MainCategoryEbooks}
+	}
+
+	categories := params.Categories
+	if categories == nil {
+		categories = []int{}
+	}
+
+	searchType := params.SearchType
+	if searchType == "" {
+		searchType = SearchTypeActive
+	}
+
+	sortType := params.SortType
+	if sortType == "" {
+		sortType = SortDateDesc
+	}
+
+	return SearchRequest{
+		IncludeDlLink:      true,
+		IncludeDescription: true,
+		IncludeISBN:        true,
+		PerPage:            params.MaxResults,
+		Tor: SearchRequestTor{
+			ID:             params.ID,
+			BrowseLang:     languages,
+			MainCategories: mainCategories,
+			Categories:     categories,
+			SearchType:     searchType,
+			SortType:       sortType,
+			SearchIn: SearchRequestSrchIn{
+				Author: true,
+				Series: true,
+				Title:  true,
+			},
+			Query:       params.Query,
+			Hash:        params.Hash,
+			StartNumber: params.Offset,
+			StartDate:   params.StartDate,
+			EndDate:     params.EndDate,
+		},
+	}
+}
+
 func createHTTPClient(enableLogging bool) *http.Client {
 	searchConfig := &config.Config.BookSearch
 
@@ -100,6 +196,45 @@ func (s *BookSearchService) Search(ctx context.Context, db *gorm.DB, params *Sea
 		return nil, err
 	}
 
+	request := params.request()
+	key := cacheKey(request)
+	ttl := time.Duration(searchConfig.Cache.TTLMinutes) * time.Minute
+
+	var response *SearchResponse
+	if ttl > 0 && !params.SkipCache {
+		response = cachedResponse(ctx, db, key)
+	}
+
+	if response == nil {
+		response, err = s.fetchWithRefresh(ctx, db, &request)
+		if err != nil {
+			return nil, err
+		}
+
+		if ttl > 0 {
+			cacheResponse(ctx, db, key, response, ttl)
+		}
+	}
+
+	response.Offset = params.Offset
+	if next := params.Offset + len(response.Data); len(response.Data) > 0 && next < response.TotalFound {
+		response.NextOffset = next
+	}
+
+	if len(params.Formats) > 0 {
+		response.Data = slices.DeleteFunc(response.Data, func(item SearchResponseItem) bool {
+			return !item.hasFormat(params.Formats)
+		})
+	}
+
+	return response, nil
+}
+
+// fetchWithRefresh makes the search request, refreshing the token and trying
+// once more if the site rejects it.
+func (s *BookSearchService) fetchWithRefresh(ctx context.Context, db *gorm.DB, request *SearchRequest) (*SearchResponse, error) {
+	searchConfig := &config.Config.BookSearch
+
 	// Get API key from database
 	credential, err := models.GetBookSearchCredential(db)
 	if err != nil {
@@ -112,28 +247,6 @@ func (s *BookSearchService) Search(ctx context.Context, db *gorm.DB, params *Sea
 
 	url := fmt.Sprintf("%s%s", baseURL, searchEndpoint)
 
-	request := SearchRequest{
-		IncludeDlLink:      true,
-		IncludeDescription: true,
-		IncludeISBN:        true,
-		PerPage:            params.MaxResults,
-		Tor: SearchRequestTor{
-			ID:             params.ID,
-			BrowseLang:     []int{1},
-			MainCategories: []int{13, 14},
-			Categories:     []int{},
-			SearchType:     "active",
-			SortType:       "dateDesc",
-			SearchIn: SearchRequestSrchIn{
-				Author: true,
-				Series: true,
-				Title:  true,
-			},
-			Query: params.Query,
-			Hash:  params.Hash,
-		},
-	}
-
 	bytes, err := json.Marshal(request)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to marshal search request", slog.Any("err", err))
@@ -141,26 +254,59 @@ func (s *BookSearchService) Search(ctx context.Context, db *gorm.DB, params *Sea
 	}
 	slog.InfoContext(ctx, "Marshalled request", slog.String("request", string(bytes)))
 
-	var response SearchResponse
-
 	slog.InfoContext(ctx, "Making request", slog.String("url", url), slog.Any("request", request))
 
-	err = requests.
+	response, err := s.fetch(ctx, url, credential.APIKey, request)
+	if errors.Is(err, ErrTokenRejected) {
+		// The token has expired, so refresh it and try once more
+		slog.WarnContext(ctx, "Book search token rejected, refreshing it", slog.Any("err", err))
+
+		if refreshErr := s.RefreshToken(ctx, db); refreshErr != nil {
+			return nil, fmt.Errorf("unable to search: %w", errors.Join(err, refreshErr))
+		}
+
+		credential, err = models.GetBookSearchCredential(db)
+		if err != nil {
+			return nil, fmt.Errorf("book search credential not found in database")
+		}
+
+		response, err = s.fetch(ctx, url, credential.APIKey, request)
+		if errors.Is(err, ErrTokenRejected) {
+			if _, recordErr := models.RecordBookSearchCredentialFailure(db, err.Error()); recordErr != nil {
+				slog.ErrorContext(ctx, "Failed to record book search credential failure", slog.Any("err", recordErr))
+			}
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to search", slog.Any("err", err))
+		return nil, fmt.Errorf("unable to search: %w", err)
+	}
+
+	slog.InfoContext(ctx, "made request", slog.Any("response", response))
+
+	return response, nil
+}
+
+// fetch posts a search request with the token cookie.
+func (s *BookSearchService) fetch(ctx context.Context, url, token string, request *SearchRequest) (*SearchResponse, error) {
+	var response SearchResponse
+
+	err := requests.
 		URL(url).
 		Client(createHTTPClient(false)).
-		Cookie(searchConfig.TokenCookieName, credential.APIKey).
+		Cookie(config.Config.BookSearch.TokenCookieName, token).
 		Method("POST").
 		CheckStatus(200).
-		BodyJSON(&request).
+		BodyJSON(request).
 		ToJSON(&response).
 		Fetch(ctx)
+	if requests.HasStatusErr(err, http.StatusUnauthorized, http.StatusForbidden) {
+		return nil, fmt.Errorf("%w: %w", ErrTokenRejected, err)
+	}
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to search", slog.Any("err", err))
-		return nil, fmt.Errorf("unable to search: %w", err)
+		return nil, err
 	}
 
-	slog.InfoContext(ctx, "made request", slog.Any("response", response))
-
 	return &response, nil
 }
 
@@ -209,7 +355,7 @@ func (s *BookSearchService) RefreshToken(ctx context.Context, db *gorm.DB) error
 		Fetch(ctx)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to refresh token", slog.Any("err", err))
-		return fmt.Errorf("unable to refresh token: %w", err)
+		return s.refreshFailed(ctx, db, credential, fmt.Errorf("unable to refresh token: %w", err))
 	}
 
 	responseCookies := client.Jar.Cookies(&url.URL{
@@ -220,14 +366,109 @@ func (s *BookSearchService) RefreshToken(ctx context.Context, db *gorm.DB) error
 	tokenCookie, found := cookies.FindCookieByName(responseCookies, searchConfig.TokenCookieName)
 	if !found {
 		slog.ErrorContext(ctx, "Token cookie not found in response", slog.String("cookieName", searchConfig.TokenCookieName))
-		return fmt.Errorf("token cookie '%s' not found in response", searchConfig.TokenCookieName)
+		return s.refreshFailed(ctx, db, credential, fmt.Errorf("token cookie '%s' not found in response", searchConfig.TokenCookieName))
 	}
 
-	err = models.UpsertBookSearchCredential(db, tokenCookie.Value, response.IPAddress, fmt.Sprintf("%d", response.ASN))
+	asn := fmt.Sprintf("%d", response.ASN)
+
+	err = models.UpsertBookSearchCredential(db, tokenCookie.Value, response.IPAddress, asn)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to upsert book search credential", slog.Any("err", err))
 		return fmt.Errorf("failed to upsert book search credential: %w", err)
 	}
 
+	ipChanged := credential.IPAddress != "" && (credential.IPAddress != response.IPAddress || credential.ASN != asn)
+	if ipChanged {
+		slog.WarnContext(ctx, "Book search IP address changed",
+			slog.String("previousIp", credential.IPAddress),
+			slog.String("ip", response.IPAddress),
+			slog.String("previousAsn", credential.ASN),
+			slog.String("asn", asn))
+	}
+
+	summary := "Book search token refreshed"
+	if ipChanged {
+		summary = fmt.Sprintf("Book search token refreshed, IP changed from %s to %s", credential.IPAddress, response.IPAddress)
+	}
+
+	eventlog.Log(db, eventlog.CategoryCredential, eventlog.EventCredentialRefreshed, eventlog.SourceBookSearch,
+		eventlog.EntityCredential, "", summary,
+		map[string]any{
+			"ip_address":  response.IPAddress,
+			"asn":         asn,
+			"as":          response.AS,
+			"ip_changed":  ipChanged,
+			"previous_ip": credential.IPAddress,
+		})
+
 	return nil
 }
+
+// refreshFailed records a failed refresh and returns err. Only the first of a
+// run of failures is sent to the notifier, so scheduled retries don't repeat it.
+func (s *BookSearchService) refreshFailed(ctx context.Context, db *gorm.DB, credential *models.BookSearchCredential, err error) error {
+	failures, recordErr := models.RecordBookSearchCredentialFailure(db, err.Error())
+	if recordErr != nil {
+		slog.ErrorContext(ctx, "Failed to record book search credential failure", slog.Any("err", recordErr))
+	}
+
+	eventlog.Log(db, eventlog.CategoryCredential, eventlog.EventCredentialRefreshFailed, eventlog.SourceBookSearch,
+		eventlog.EntityCredential, "",
+		fmt.Sprintf("Book search token refresh failed: %s", err),
+		map[string]any{"error": err.Error(), "consecutive_failures": failures})
+
+	if failures <= 1 {
+		message := createRefreshFailedNotificationPayload(credential, err)
+		if notifyErr := notifications.SendNotification(ctx, config.Config.BookSearch.TokenRefresh.Notifier, message); notifyErr != nil {
+			slog.ErrorContext(ctx, "Failed to send token refresh notification", slog.Any("err", notifyErr))
+		}
+	}
+
+	return err
+}
+
+func createRefreshFailedNotificationPayload(credential *models.BookSearchCredential, err error) notifications.DiscordWebhookMessage {
+	var embed notifications.DiscordEmbed
+
+	embed.Author.Name = "Book Search"
+	embed.Title = "Token Refresh Failed"
+	embed.Description = err.Error()
+	embed.Color = 15158332
+	embed.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	if !credential.LastRefresh.IsZero() {
+		embed.Fields = append(embed.Fields, notifications.DiscordEmbedField{
+			Name:   "Last Refresh",
+			Value:  credential.LastRefresh.UTC().Format(time.RFC3339),
+			Inline: true,
+		})
+	}
+
+	if credential.IPAddress != "" {
+		embed.Fields = append(embed.Fields, notifications.DiscordEmbedField{
+			Name:   "IP Address",
+			Value:  credential.IPAddress,
+			Inline: true,
+		})
+	}
+
+	return notifications.DiscordWebhookMessage{
+		Username: "Stronghold",
+		Embeds:   []notifications.DiscordEmbed{embed},
+	}
+}
+
+// RefreshTokenIfDue refreshes the token if it was last refreshed more than
+// interval ago, and reports whether it tried.
+func (s *BookSearchService) RefreshTokenIfDue(ctx context.Context, db *gorm.DB, interval time.Duration) (bool, error) {
+	credential, err := models.GetBookSearchCredential(db)
+	if err != nil {
+		return false, fmt.Errorf("book search credential not found in database")
+	}
+
+	if time.Since(credential.LastRefresh) < interval {
+		return false, nil
+	}
+
+	return true, s.RefreshToken(ctx, db)
+}
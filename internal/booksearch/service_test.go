@@ -28,6 +28,36 @@ func TestSearchParametersValidate(t *testing.T) {
             params:  SearchParameters{Query: "Dune", Hash: "abcdef123456"},
             wantErr: true,
         },
+        {
+            name:    "negative offset",
+            params:  SearchParameters{Query: "Dune", Offset: -1},
+            wantErr: true,
+        },
+        {
+            name:    "unknown search type",
+            params:  SearchParameters{Query: "Dune", SearchType: "everything"},
+            wantErr: true,
+        },
+        {
+            name:    "unknown sort type",
+            params:  SearchParameters{Query: "Dune", SortType: "newest"},
+            wantErr: true,
+        },
+        {
+            name:    "invalid start date",
+            params:  SearchParameters{Query: "Dune", StartDate: "01/02/2024"},
+            wantErr: true,
+        },
+        {
+            name:    "end date before start date",
+            params:  SearchParameters{Query: "Dune", StartDate: "2024-02-01", EndDate: "2024-01-01"},
+            wantErr: true,
+        },
+        {
+            name:    "all filters",
+            params:  SearchParameters{Query: "Dune", Offset: 20, Languages: []int{1, 2}, MainCategories: []int{MainCategoryAudiobooks}, StartDate: "2024-01-01", EndDate: "2024-12-31", SearchType: SearchTypeAll, SortType: "seedersDesc"},
+            wantErr: false,
+        },
     }
 
     for _, tt := range tests {
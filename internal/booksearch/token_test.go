@@ -0,0 +1,195 @@
+package booksearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// fakeSite issues tokens from /refresh and only accepts the latest one on
+// /search.
+type fakeSite struct {
+	mu          sync.Mutex
+	token       string
+	ipAddress   string
+	failRefresh bool
+	refreshes   int
+	searches    int
+}
+
+func (f *fakeSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/refresh":
+		f.refreshes++
+		if f.failRefresh {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		f.token = "token-" + time.Now().Format(time.RFC3339Nano)
+		http.SetCookie(w, &http.Cookie{Name: "mam_id", Value: f.token, Path: "/"})
+		_ = json.NewEncoder(w).Encode(map[string]any{"Success": true, "ip": f.ipAddress, "ASN": 64500, "AS": "Example"})
+	case "/search":
+		f.searches++
+		if cookie, err := r.Cookie("mam_id"); err != nil || cookie.Value != f.token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"total": 1, "total_found": 1, "data": [{"id": 1, "title": "Dune"}]}`))
+	}
+}
+
+func setupFakeSite(t *testing.T) (*gorm.DB, *fakeSite) {
+	t.Helper()
+
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	site := &fakeSite{token: "current", ipAddress: "192.0.2.1"}
+	server := httptest.NewServer(site)
+	t.Cleanup(server.Close)
+
+	previous := config.Config.BookSearch
+	t.Cleanup(func() { config.Config.BookSearch = previous })
+	config.Config.BookSearch = config.BookSearchConfig{
+		BaseURL:         server.URL,
+		SearchEndpoint:  "/search",
+		TokenRefreshURL: server.URL + "/refresh",
+		CookieDomain:    "127.0.0.1",
+		TokenCookieName: "mam_id",
+	}
+
+	return db, site
+}
+
+func TestRefreshToken(t *testing.T) {
+	ctx := context.Background()
+	db, site := setupFakeSite(t)
+	require.NoError(t, models.UpsertBookSearchCredential(db, "current", "192.0.2.1", "64500"))
+
+	service := NewBookSearchService()
+
+	require.NoError(t, service.RefreshToken(ctx, db))
+
+	credential, err := models.GetBookSearchCredential(db)
+	require.NoError(t, err)
+	assert.Equal(t, site.token, credential.APIKey)
+	assert.Empty(t, credential.PreviousIPAddress)
+	assert.Nil(t, credential.IPChangedAt)
+
+	// The site now sees a different address
+	site.ipAddress = "198.51.100.7"
+	require.NoError(t, service.RefreshToken(ctx, db))
+
+	credential, err = models.GetBookSearchCredential(db)
+	require.NoError(t, err)
+	assert.Equal(t, "198.51.100.7", credential.IPAddress)
+	assert.Equal(t, "192.0.2.1", credential.PreviousIPAddress)
+	assert.Equal(t, "64500", credential.PreviousASN)
+	assert.NotNil(t, credential.IPChangedAt)
+
+	var events []models.EventLog
+	require.NoError(t, db.Where("event_type = ?", eventlog.EventCredentialRefreshed).Order("id").Find(&events).Error)
+	require.Len(t, events, 2)
+	assert.Contains(t, events[1].Summary, "IP changed from 192.0.2.1 to 198.51.100.7")
+}
+
+func TestRefreshTokenFailure(t *testing.T) {
+	ctx := context.Background()
+	db, site := setupFakeSite(t)
+	require.NoError(t, models.UpsertBookSearchCredential(db, "current", "192.0.2.1", "64500"))
+	site.failRefresh = true
+
+	service := NewBookSearchService()
+
+	require.Error(t, service.RefreshToken(ctx, db))
+	require.Error(t, service.RefreshToken(ctx, db))
+
+	credential, err := models.GetBookSearchCredential(db)
+	require.NoError(t, err)
+	assert.Equal(t, "current", credential.APIKey)
+	assert.Equal(t, 2, credential.ConsecutiveFailures)
+	assert.Contains(t, credential.LastError, "unable to refresh token")
+	assert.NotNil(t, credential.LastFailureAt)
+
+	var failures int64
+	require.NoError(t, db.Model(&models.EventLog{}).Where("event_type = ?", eventlog.EventCredentialRefreshFailed).Count(&failures).Error)
+	assert.Equal(t, int64(2), failures)
+
+	// A successful refresh clears the failures
+	site.failRefresh = false
+	require.NoError(t, service.RefreshToken(ctx, db))
+
+	credential, err = models.GetBookSearchCredential(db)
+	require.NoError(t, err)
+	assert.Zero(t, credential.ConsecutiveFailures)
+	assert.Empty(t, credential.LastError)
+}
+
+func TestRefreshTokenIfDue(t *testing.T) {
+	ctx := context.Background()
+	db, site := setupFakeSite(t)
+	require.NoError(t, models.UpsertBookSearchCredential(db, "current", "192.0.2.1", "64500"))
+
+	service := NewBookSearchService()
+
+	refreshed, err := service.RefreshTokenIfDue(ctx, db, time.Hour)
+	require.NoError(t, err)
+	assert.False(t, refreshed)
+	assert.Zero(t, site.refreshes)
+
+	require.NoError(t, db.Model(&models.BookSearchCredential{}).Where("1 = 1").Update("last_refresh", time.Now().Add(-2*time.Hour)).Error)
+
+	refreshed, err = service.RefreshTokenIfDue(ctx, db, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, refreshed)
+	assert.Equal(t, 1, site.refreshes)
+}
+
+func TestSearchRefreshesRejectedToken(t *testing.T) {
+	ctx := context.Background()
+	db, site := setupFakeSite(t)
+	require.NoError(t, models.UpsertBookSearchCredential(db, "expired", "192.0.2.1", "64500"))
+
+	service := NewBookSearchService()
+
+	response, err := service.Search(ctx, db, &SearchParameters{Query: "Dune"})
+	require.NoError(t, err)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, 1, site.refreshes)
+	assert.Equal(t, 2, site.searches)
+
+	credential, err := models.GetBookSearchCredential(db)
+	require.NoError(t, err)
+	assert.Equal(t, site.token, credential.APIKey)
+}
+
+func TestSearchRetriesOnlyOnce(t *testing.T) {
+	ctx := context.Background()
+	db, site := setupFakeSite(t)
+	require.NoError(t, models.UpsertBookSearchCredential(db, "expired", "192.0.2.1", "64500"))
+	site.failRefresh = true
+
+	service := NewBookSearchService()
+
+	_, err := service.Search(ctx, db, &SearchParameters{Query: "Dune"})
+	require.ErrorIs(t, err, ErrTokenRejected)
+	assert.Equal(t, 1, site.refreshes)
+	assert.Equal(t, 1, site.searches)
+}
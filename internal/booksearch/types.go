@@ -11,7 +11,6 @@ import (
 	"strings"
 
 	"github.com/bobbyrward/stronghold/internal/config"
-	"github.com/bobbyrward/stronghold/internal/models"
 )
 
 const (
@@ -19,6 +18,59 @@ const (
 	MainCategoryAudiobooks = 13
 )
 
+// LanguageEnglish is the default language filter
+const LanguageEnglish = 1
+
+// Search types for SearchParameters.SearchType
+const (
+	SearchTypeAll            = "all"
+	SearchTypeActive         = "active"
+	SearchTypeInactive       = "inactive"
+	SearchTypeFreeleech      = "fl"
+	SearchTypeFreeleechOrVIP = "fl-VIP"
+	SearchTypeVIP            = "VIP"
+	SearchTypeNotVIP         = "nVIP"
+	SearchTypeNoMeta         = "nMeta"
+)
+
+// SearchTypes are the values SearchParameters.SearchType accepts
+var SearchTypes = []string{
+	SearchTypeAll, SearchTypeActive, SearchTypeInactive, SearchTypeFreeleech,
+	SearchTypeFreeleechOrVIP, SearchTypeVIP, SearchTypeNotVIP, SearchTypeNoMeta,
+}
+
+// SortDateDesc is the default sort, newest first
+const SortDateDesc = "dateDesc"
+
+// SortTypes are the values SearchParameters.SortType accepts
+var SortTypes = []string{
+	"default", "random",
+	"titleAsc", "titleDesc", "fileAsc", "fileDesc", "sizeAsc", "sizeDesc",
+	"seedersAsc", "seedersDesc", "leechersAsc", "leechersDesc", "snatchedAsc", "snatchedDesc",
+	"dateAsc", SortDateDesc, "bmkaAsc", "bmkaDesc", "reseedAsc", "reseedDesc",
+	"categoryAsc", "categoryDesc",
+}
+
+// SearchDateLayout is the format of SearchParameters.StartDate and EndDate
+const SearchDateLayout = "2006-01-02"
+
+// ParseMainCategory parses a main category name (ebooks or audiobooks) or ID.
+func ParseMainCategory(value string) (int, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "ebook", "ebooks", "book", "books":
+		return MainCategoryEbooks, nil
+	case "audiobook", "audiobooks":
+		return MainCategoryAudiobooks, nil
+	}
+
+	id, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("unknown main category %q", value)
+	}
+
+	return id, nil
+}
+
 type SearchRequest struct {
 	IncludeDlLink      bool             `json:"dlLink,omitempty"`
 	IncludeDescription bool             `json:"description,omitempty"`
@@ -37,6 +89,9 @@ type SearchRequestTor struct {
 	SearchIn       SearchRequestSrchIn `json:"srchIn"`
 	Query          string              `json:"text,omitempty"`
 	Hash           string              `json:"hash,omitempty"`
+	StartNumber    int                 `json:"startNumber,omitempty"`
+	StartDate      string              `json:"startDate,omitempty"`
+	EndDate        string              `json:"endDate,omitempty"`
 }
 
 type SearchRequestSrchIn struct {
@@ -52,20 +107,64 @@ type SearchParameters struct {
 
 	ID *int
 
-	// Offset             int
-	// DateOffset         string
+	// Offset is how many results to skip, for paging through TotalFound
+	Offset int
+
+	// Languages defaults to English and MainCategories to ebooks and
+	// audiobooks. Categories are the site's sub-category IDs.
+	Languages      []int
+	MainCategories []int
+	Categories     []int
+
+	// StartDate and EndDate limit results to torrents added in that range,
+	// inclusive, formatted as SearchDateLayout
+	StartDate string
+	EndDate   string
+
+	// Formats keeps only results with one of these file types, e.g. m4b.
+	// The site can't filter on file type, so this filters each page.
+	Formats []string
 
-	// Languages    []int
-	// Categories   []int
-	// EndDate      string
-	// Hash         string
-	// MainCategory []int
+	// SearchType defaults to SearchTypeActive and SortType to SortDateDesc
+	SearchType string
+	SortType   string
+
+	// SkipCache always asks the site, though the response is still cached
+	SkipCache bool
 }
 
 type SearchResponse struct {
 	Total      int                  `json:"total"`
 	TotalFound int                  `json:"total_found"`
 	Data       []SearchResponseItem `json:"data"`
+
+	// Offset is the position of the first result in TotalFound, and
+	// NextOffset the position of the next page, or 0 on the last page
+	Offset     int `json:"offset"`
+	NextOffset int `json:"next_offset,omitempty"`
+
+	// Cached is set when the response came from the search cache
+	Cached bool `json:"cached"`
+}
+
+// HasMore reports whether there is another page of results.
+func (r *SearchResponse) HasMore() bool {
+	return r.NextOffset > 0
+}
+
+// hasFormat reports whether the item has one of the file types.
+func (sri *SearchResponseItem) hasFormat(formats []string) bool {
+	fileTypes := strings.FieldsFunc(strings.ToLower(sri.FileTypes), func(r rune) bool {
+		return r == ' ' || r == ',' || r == '/' || r == '|'
+	})
+
+	for _, format := range formats {
+		if slices.Contains(fileTypes, strings.ToLower(strings.TrimPrefix(format, "."))) {
+			return true
+		}
+	}
+
+	return false
 }
 
 type SeriesEntry struct {
@@ -179,33 +278,23 @@ func (sri *SearchResponseItem) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (sri *SearchResponseItem) ToModel() models.SearchResponseItem {
-	model := models.SearchResponseItem{
-		Title:        sri.Title,
-		Category:     sri.CategoryName,
-		MainCategory: sri.MainCategory,
-		DlHash:       sri.DlHash,
-		FileTypes:    sri.FileTypes,
-		TorrentID:    uint(sri.ID),
-		Language:     sri.LanguageCode,
-		Size:         sri.Size,
-		Tags:         sri.Tags,
-		Authors:      strings.Join(slices.Collect(maps.Values(sri.Authors)), ", "),
-	}
-
-	if len(sri.Series) > 0 {
-		seriesCollected := make([]string, 0)
-
-		for _, value := range sri.Series {
-			seriesCollected = append(seriesCollected, fmt.Sprintf("%s(%d)", value.Name, value.Index))
-		}
+// AuthorNames returns the item's authors, comma separated.
+func (sri *SearchResponseItem) AuthorNames() string {
+	return strings.Join(slices.Sorted(maps.Values(sri.Authors)), ", ")
+}
 
-		model.Series = strings.Join(seriesCollected, ", ")
-	}
+// NarratorNames returns the item's narrators, comma separated.
+func (sri *SearchResponseItem) NarratorNames() string {
+	return strings.Join(slices.Sorted(maps.Values(sri.Narrators)), ", ")
+}
 
-	if len(sri.Narrators) > 0 {
-		model.Narrators = strings.Join(slices.Collect(maps.Values(sri.Narrators)), ", ")
+// SeriesNames returns the item's series with their indexes, e.g. "Dune(1)".
+func (sri *SearchResponseItem) SeriesNames() string {
+	series := make([]string, 0, len(sri.Series))
+	for _, value := range sri.Series {
+		series = append(series, fmt.Sprintf("%s(%d)", value.Name, value.Index))
 	}
+	slices.Sort(series)
 
-	return model
+	return strings.Join(series, ", ")
 }
@@ -0,0 +1,56 @@
+package calibre
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var addedBookIDs = regexp.MustCompile(`Added book ids: ([0-9, ]+)`)
+
+// CalibredbClient adds books to a local library with "calibredb add".
+type CalibredbClient struct {
+	Command string
+	Library string
+	Timeout time.Duration
+}
+
+// Compile-time check that CalibredbClient implements Client interface.
+var _ Client = (*CalibredbClient)(nil)
+
+// AddBook runs "calibredb add --with-library <library> <path>" and reads the
+// new book ID from its output. calibredb skips duplicates unless told not to,
+// and says so instead of printing an ID.
+func (c *CalibredbClient) AddBook(ctx context.Context, path string) (int, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, c.Command, "add", "--with-library", c.Library, path).CombinedOutput()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return 0, false, fmt.Errorf("%s timed out after %s", c.Command, c.Timeout)
+		}
+		return 0, false, fmt.Errorf("%s add failed: %w: %s", c.Command, err, strings.TrimSpace(string(output)))
+	}
+
+	match := addedBookIDs.FindStringSubmatch(string(output))
+	if match == nil {
+		if strings.Contains(string(output), "already exist") {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("unexpected %s output: %s", c.Command, strings.TrimSpace(string(output)))
+	}
+
+	// One file is added at a time, so the first ID is the book
+	bookID, err := strconv.Atoi(strings.TrimSpace(strings.Split(match[1], ",")[0]))
+	if err != nil {
+		return 0, false, fmt.Errorf("unexpected %s book id %q: %w", c.Command, match[1], err)
+	}
+
+	return bookID, true, nil
+}
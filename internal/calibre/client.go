@@ -0,0 +1,93 @@
+// Package calibre adds imported ebooks to a Calibre library, either with the
+// calibredb command line tool or through a Calibre content server.
+package calibre
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+const defaultTimeout = 2 * time.Minute
+
+// Client adds books to one Calibre library.
+type Client interface {
+	// AddBook adds the ebook file at path and returns its Calibre book ID.
+	// added is false when Calibre skipped the file as a duplicate of a book
+	// it already has.
+	AddBook(ctx context.Context, path string) (bookID int, added bool, err error)
+}
+
+// NewClient returns the client for a Calibre library location. An http or https
+// URL is a content server, with the library ID in the fragment like calibredb's
+// --with-library (http://calibre:8080/#Books). Anything else is the path of a
+// local library, written to with calibredb.
+func NewClient(cfg config.CalibreConfig, location string) (Client, error) {
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	if !IsServerURL(location) {
+		command := cfg.Calibredb
+		if command == "" {
+			command = "calibredb"
+		}
+
+		return &CalibredbClient{
+			Command: command,
+			Library: location,
+			Timeout: timeout,
+		}, nil
+	}
+
+	server, err := ParseServerURL(location)
+	if err != nil {
+		return nil, err
+	}
+
+	server.Username = cfg.Username
+	server.Password = cfg.Password
+	server.Timeout = timeout
+
+	return server, nil
+}
+
+// IsServerURL reports whether a library location is a content server URL.
+func IsServerURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// ParseServerURL splits a content server URL into the server address and the
+// library ID in its fragment. Without a fragment the server's default library
+// is used.
+func ParseServerURL(location string) (*ServerClient, error) {
+	u, err := url.Parse(location)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid Calibre content server URL %q", location)
+	}
+
+	libraryID := u.Fragment
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	return &ServerClient{
+		BaseURL:   strings.TrimSuffix(u.String(), "/"),
+		LibraryID: libraryID,
+	}, nil
+}
+
+// BookURL links to a book in Calibre-Web. It is empty when no Calibre-Web URL
+// is configured.
+func BookURL(calibreWebURL string, bookID int) string {
+	if calibreWebURL == "" || bookID == 0 {
+		return ""
+	}
+
+	return strings.TrimSuffix(calibreWebURL, "/") + "/book/" + strconv.Itoa(bookID)
+}
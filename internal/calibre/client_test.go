@@ -0,0 +1,161 @@
+package calibre
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+// writeStubCalibredb writes a shell script standing in for calibredb.
+func writeStubCalibredb(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "calibredb")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	return path
+}
+
+func writeBook(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "Dune - Frank Herbert.epub")
+	require.NoError(t, os.WriteFile(path, []byte("epub content"), 0644))
+	return path
+}
+
+func TestNewClient(t *testing.T) {
+	client, err := NewClient(config.CalibreConfig{}, "/calibre/library")
+	require.NoError(t, err)
+	require.IsType(t, &CalibredbClient{}, client)
+	assert.Equal(t, "calibredb", client.(*CalibredbClient).Command)
+	assert.Equal(t, "/calibre/library", client.(*CalibredbClient).Library)
+
+	client, err = NewClient(config.CalibreConfig{Username: "admin", Password: "secret"}, "http://calibre:8080/#My_Books")
+	require.NoError(t, err)
+	require.IsType(t, &ServerClient{}, client)
+	server := client.(*ServerClient)
+	assert.Equal(t, "http://calibre:8080", server.BaseURL)
+	assert.Equal(t, "My_Books", server.LibraryID)
+	assert.Equal(t, "admin", server.Username)
+
+	_, err = NewClient(config.CalibreConfig{}, "http://")
+	assert.Error(t, err)
+}
+
+func TestBookURL(t *testing.T) {
+	assert.Equal(t, "https://calibre.example.com/book/42", BookURL("https://calibre.example.com/", 42))
+	assert.Empty(t, BookURL("", 42))
+	assert.Empty(t, BookURL("https://calibre.example.com", 0))
+}
+
+func TestCalibredbClient_AddBook(t *testing.T) {
+	ctx := context.Background()
+	book := writeBook(t)
+
+	t.Run("added", func(t *testing.T) {
+		argsFile := filepath.Join(t.TempDir(), "args")
+		client := &CalibredbClient{
+			Command: writeStubCalibredb(t, `echo "$@" > "`+argsFile+`"; echo "Added book ids: 17"`),
+			Library: "/calibre/library",
+			Timeout: defaultTimeout,
+		}
+
+		bookID, added, err := client.AddBook(ctx, book)
+		require.NoError(t, err)
+		assert.True(t, added)
+		assert.Equal(t, 17, bookID)
+
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		assert.Equal(t, "add --with-library /calibre/library "+book+"\n", string(args))
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		client := &CalibredbClient{
+			Command: writeStubCalibredb(t, `echo "The following books were not added as they already exist in the database (see --duplicates option):"; echo "  Dune"`),
+			Timeout: defaultTimeout,
+		}
+
+		_, added, err := client.AddBook(ctx, book)
+		require.NoError(t, err)
+		assert.False(t, added)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		client := &CalibredbClient{
+			Command: writeStubCalibredb(t, `echo "Another calibre program is running" >&2; exit 1`),
+			Timeout: defaultTimeout,
+		}
+
+		_, _, err := client.AddBook(ctx, book)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Another calibre program is running")
+	})
+}
+
+func TestServerClient_AddBook(t *testing.T) {
+	ctx := context.Background()
+	book := writeBook(t)
+
+	t.Run("added", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "/cdb/add-book/0/n/Dune - Frank Herbert.epub/My_Books", r.URL.Path)
+
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "admin", username)
+			assert.Equal(t, "secret", password)
+
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, "epub content", string(body))
+
+			_ = json.NewEncoder(w).Encode(map[string]any{"id": 0, "book_id": 42, "title": "Dune", "filename": "Dune - Frank Herbert.epub"})
+		}))
+		defer server.Close()
+
+		client, err := NewClient(config.CalibreConfig{Username: "admin", Password: "secret"}, server.URL+"/#My_Books")
+		require.NoError(t, err)
+
+		bookID, added, err := client.AddBook(ctx, book)
+		require.NoError(t, err)
+		assert.True(t, added)
+		assert.Equal(t, 42, bookID)
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"id": 0, "title": "Dune", "duplicates": [{"title": "Dune", "authors": ["Frank Herbert"]}]}`))
+		}))
+		defer server.Close()
+
+		client := &ServerClient{BaseURL: server.URL}
+
+		_, added, err := client.AddBook(ctx, book)
+		require.NoError(t, err)
+		assert.False(t, added)
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		client := &ServerClient{BaseURL: server.URL}
+
+		_, _, err := client.AddBook(ctx, book)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "403")
+	})
+}
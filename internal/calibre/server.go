@@ -0,0 +1,108 @@
+package calibre
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ServerClient adds books through a Calibre content server's add-book endpoint.
+// The server must allow remote writes (--enable-local-write or a user with
+// write access), and authentication must use basic auth (--auth-mode=basic),
+// the only mode this client speaks.
+type ServerClient struct {
+	BaseURL   string
+	LibraryID string
+	Username  string
+	Password  string
+	Timeout   time.Duration
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Compile-time check that ServerClient implements Client interface.
+var _ Client = (*ServerClient)(nil)
+
+// addBookResponse is the reply of /cdb/add-book. book_id is missing when the
+// book was a duplicate, and duplicates lists the books it matched.
+type addBookResponse struct {
+	BookID     *int              `json:"book_id"`
+	Duplicates []json.RawMessage `json:"duplicates"`
+}
+
+// AddBook uploads the file to /cdb/add-book without adding duplicates.
+func (c *ServerClient) AddBook(ctx context.Context, path string) (int, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, false, err
+	}
+	defer func() { _ = file.Close() }()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, false, err
+	}
+
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	endpoint := fmt.Sprintf("%s/cdb/add-book/0/n/%s", c.BaseURL, url.PathEscape(filepath.Base(path)))
+	if c.LibraryID != "" {
+		endpoint += "/" + url.PathEscape(c.LibraryID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, file)
+	if err != nil {
+		return 0, false, err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to add book to Calibre: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read Calibre response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, false, fmt.Errorf("calibre content server returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var result addBookResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, false, fmt.Errorf("failed to decode Calibre response: %w", err)
+	}
+
+	if result.BookID == nil {
+		if len(result.Duplicates) > 0 {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("calibre content server did not return a book id: %s", strings.TrimSpace(string(body)))
+	}
+
+	return *result.BookID, true, nil
+}
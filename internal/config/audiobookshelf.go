@@ -0,0 +1,14 @@
+package config
+
+// AudiobookshelfConfig is the Audiobookshelf server the audiobook importer asks
+// to scan newly imported books. Which of its libraries a book goes to is set
+// per library in the database.
+type AudiobookshelfConfig struct {
+	URL      string `yaml:"url"`
+	APIToken string `yaml:"apiToken"`
+
+	// LookupAttempts and LookupIntervalSeconds control how long the importer
+	// waits for the scan to create the item it links in notifications.
+	LookupAttempts        int `yaml:"lookupAttempts"`
+	LookupIntervalSeconds int `yaml:"lookupIntervalSeconds"`
+}
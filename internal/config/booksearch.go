@@ -9,4 +9,30 @@ type BookSearchConfig struct {
 	HttpProxy        string `yaml:"httpProxy"`
 	HttpsProxy       string `yaml:"httpsProxy"`
 	TorrentUrlPrefix string `yaml:"torrentUrlPrefix"`
+
+	// TokenRefresh schedules token refreshes and reports failed ones
+	TokenRefresh TokenRefreshConfig `yaml:"tokenRefresh"`
+
+	// Cache keeps search responses and the search history
+	Cache SearchCacheConfig `yaml:"cache"`
+}
+
+// SearchCacheConfig configures the book search cache and history.
+type SearchCacheConfig struct {
+	// TTLMinutes is how long a search response is reused. Zero disables the cache.
+	TTLMinutes int `yaml:"ttlMinutes"`
+
+	// HistoryRetentionDays is how long the search history is kept. Zero keeps
+	// it forever.
+	HistoryRetentionDays int `yaml:"historyRetentionDays"`
+}
+
+// TokenRefreshConfig configures automatic refreshes of the book search token.
+type TokenRefreshConfig struct {
+	// IntervalHours between scheduled refreshes, run by the refresh-token
+	// --if-due cron job. Zero disables them; rejected tokens are still refreshed.
+	IntervalHours int `yaml:"intervalHours"`
+
+	// Notifier is told when a refresh fails. Empty sends none.
+	Notifier string `yaml:"notifier"`
 }
@@ -0,0 +1,40 @@
+package config
+
+// DownloadClientConfig selects the torrent client Stronghold talks to.
+type DownloadClientConfig struct {
+	// Type is qbittorrent (the default) or transmission
+	Type string `yaml:"type"`
+
+	// RetryAttempts is how many times a read or an idempotent change is tried
+	// when the client cannot be reached or answers with a server error. The
+	// backoff starts at RetryBackoffMillis and doubles between attempts.
+	// go-qbittorrent also retries each request up to 5 times on connection
+	// errors and expired logins, so the attempts multiply.
+	RetryAttempts      int `yaml:"retryAttempts"`
+	RetryBackoffMillis int `yaml:"retryBackoffMillis"`
+
+	// BreakerFailures consecutive failures open the circuit breaker, which
+	// then refuses calls for BreakerCooldownSeconds before trying again
+	BreakerFailures        int `yaml:"breakerFailures"`
+	BreakerCooldownSeconds int `yaml:"breakerCooldownSeconds"`
+}
+
+// TransmissionConfig configures the Transmission RPC client. URL is the RPC
+// endpoint, e.g. http://localhost:9091/transmission/rpc.
+type TransmissionConfig struct {
+	URL               string `yaml:"url"`
+	Username          string `yaml:"username"`
+	Password          string `yaml:"password"`
+	DownloadPath      string `yaml:"downloadPath"`
+	LocalDownloadPath string `yaml:"localDownloadPath"`
+}
+
+// DownloadPaths returns the selected client's download path and where that
+// path is mounted locally.
+func (c *ClusterConfig) DownloadPaths() (string, string) {
+	if c.DownloadClient.Type == "transmission" {
+		return c.Transmission.DownloadPath, c.Transmission.LocalDownloadPath
+	}
+
+	return c.Qbit.DownloadPath, c.Qbit.LocalDownloadPath
+}
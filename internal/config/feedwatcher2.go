@@ -0,0 +1,40 @@
+package config
+
+// FeedWatcher2Config holds the rules feedwatcher2 checks a matched release
+// against before adding it to the download client, and how its downloads are
+// reconciled with the client afterwards.
+type FeedWatcher2Config struct {
+	Audiobooks ReleaseRules    `yaml:"audiobooks"`
+	Ebooks     ReleaseRules    `yaml:"ebooks"`
+	Reconcile  ReconcileConfig `yaml:"reconcile"`
+	// RejectedReleaseDays is how long a rejected release is skipped before it
+	// is checked again, so changed release rules eventually apply to it.
+	// Zero uses the default of 7 days.
+	RejectedReleaseDays int `yaml:"rejectedReleaseDays"`
+}
+
+// ReleaseRules limits the total size of a release's audio or ebook files.
+// Zero disables a limit. Releases without any such files are always rejected.
+type ReleaseRules struct {
+	MinSizeMB int64 `yaml:"minSizeMB"`
+	MaxSizeMB int64 `yaml:"maxSizeMB"`
+}
+
+// Reconcile actions for subscription items that drifted from the download client
+const (
+	// ReconcileActionReadd re-adds missing torrents and restores the category
+	// of recategorized ones
+	ReconcileActionReadd = "readd"
+	// ReconcileActionRelease deletes drifted items so the next feed hit
+	// downloads them again
+	ReconcileActionRelease = "release"
+)
+
+// ReconcileConfig controls the subscription reconciliation job. An empty
+// Action only records each item's status.
+type ReconcileConfig struct {
+	// StalledAfterHours is how long the torrent health monitor must have seen
+	// a torrent stalled before it counts as stalled. Zero disables the check.
+	StalledAfterHours int    `yaml:"stalledAfterHours"`
+	Action            string `yaml:"action"`
+}
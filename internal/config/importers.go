@@ -3,24 +3,114 @@ package config
 type ImportLibrary struct {
 	Name string `yaml:"name" json:"name"`
 	Path string `yaml:"path" json:"path"`
+
+	// PreferredFormats is the ebook format order, best first, e.g. [epub, azw3].
+	PreferredFormats []string `yaml:"preferredFormats" json:"preferred_formats,omitempty"`
+
+	// AudiobookshelfLibraryID is the Audiobookshelf library to scan after an
+	// audiobook import, and AudiobookshelfPath is Path as Audiobookshelf sees it.
+	AudiobookshelfLibraryID string `yaml:"audiobookshelfLibraryID" json:"audiobookshelf_library_id,omitempty"`
+	AudiobookshelfPath      string `yaml:"audiobookshelfPath" json:"audiobookshelf_path,omitempty"`
+
+	// WriteAudioTags rewrites the tags of imported audiobook files from the
+	// selected metadata.
+	WriteAudioTags bool `yaml:"writeAudioTags" json:"write_audio_tags,omitempty"`
 }
 
+// ImportType is the settings the importers use for one torrent category. They
+// are loaded from the database import types; the YAML form is only read by
+// doctor migrate-import-config.
 type ImportType struct {
 	Category          string `yaml:"category"`
 	Library           string `yaml:"library"`
 	CalibreDesktopURL string `yaml:"calibreDesktopURL"`
 	CalibreWebURL     string `yaml:"calibreWebURL"`
 	DiscordNotifier   string `yaml:"notification"`
+
+	// CalibreLibrary is the Calibre library ebooks are added to after being
+	// copied: a local library path, or a content server URL with the library
+	// ID as its fragment (http://calibre:8080/#Books). Empty skips Calibre.
+	CalibreLibrary string `yaml:"calibreLibrary"`
+
+	// MetadataProviders is the ordered fallback chain of audiobook metadata
+	// sources (audible, hardcover, googlebooks, openlibrary). Defaults to audible.
+	MetadataProviders []string `yaml:"metadataProviders"`
+
+	// MinSeedTimeMinutes and MinSeedRatio hold completed torrents back from
+	// import until they have seeded for this long or reached this ratio,
+	// whichever comes first. Both default to 0, importing on completion.
+	MinSeedTimeMinutes int     `yaml:"minSeedTimeMinutes"`
+	MinSeedRatio       float64 `yaml:"minSeedRatio"`
+
+	// Retention controls when imported torrents are removed from qBittorrent.
+	Retention Retention `yaml:"retention"`
+}
+
+// Retention removes an imported torrent once it reaches RemoveAfterRatio or has
+// seeded for RemoveAfterSeedDays, whichever comes first. With neither set the
+// torrent is kept forever.
+type Retention struct {
+	RemoveAfterRatio    float64 `yaml:"removeAfterRatio"`
+	RemoveAfterSeedDays int     `yaml:"removeAfterSeedDays"`
+
+	// PrivateMinSeedDays keeps private-tracker torrents seeding at least this
+	// long, even after reaching the ratio.
+	PrivateMinSeedDays int `yaml:"privateMinSeedDays"`
+
+	// RequireHardlink only removes torrents whose files are all hardlinked into
+	// the library, so deleting the download cannot lose the imported copy.
+	RequireHardlink bool `yaml:"requireHardlink"`
+
+	// DeleteFiles also deletes the downloaded files along with the torrent.
+	DeleteFiles bool `yaml:"deleteFiles"`
 }
 
 type AudiobookImporter struct {
+	// Deprecated: libraries and import types live in the database. These are
+	// only read by doctor migrate-import-config.
 	Libraries   []ImportLibrary `yaml:"libraries"`
 	ImportTypes []ImportType    `yaml:"importTypes"`
+
+	// RuntimeTolerancePercent is how far the audio duration may drift from the
+	// metadata runtime before the match is treated as the wrong edition.
+	RuntimeTolerancePercent int `yaml:"runtimeTolerancePercent"`
+
+	// AutoSelectThreshold is the minimum match score (0-1) for picking one of
+	// several Audible editions without manual selection.
+	AutoSelectThreshold float64 `yaml:"autoSelectThreshold"`
+
+	// FieldPrecedence overrides the provider order for individual metadata
+	// fields when merging results, e.g. description: [googlebooks, audible].
+	FieldPrecedence map[string][]string `yaml:"fieldPrecedence"`
 }
 
 type BookImporter struct {
+	// Deprecated: libraries and import types live in the database. These are
+	// only read by doctor migrate-import-config.
 	Libraries   []ImportLibrary `yaml:"libraries"`
 	ImportTypes []ImportType    `yaml:"importTypes"`
+
+	Converter EbookConverter `yaml:"converter"`
+	Calibre   CalibreConfig  `yaml:"calibre"`
+}
+
+// CalibreConfig is how the ebook importer reaches the Calibre libraries that
+// import types add books to. Username and Password are only used for content
+// servers.
+type CalibreConfig struct {
+	Calibredb      string `yaml:"calibredb"`
+	Username       string `yaml:"username"`
+	Password       string `yaml:"password"`
+	TimeoutSeconds int    `yaml:"timeoutSeconds"`
+}
+
+// EbookConverter is an external command that converts between ebook formats,
+// run as "<command> <input> <output> <args...>" like Calibre's ebook-convert.
+// It produces a library's preferred format when a torrent is missing it.
+type EbookConverter struct {
+	Command        string   `yaml:"command"`
+	Args           []string `yaml:"args"`
+	TimeoutSeconds int      `yaml:"timeoutSeconds"`
 }
 
 type ImportersConfig struct {
@@ -28,6 +118,14 @@ type ImportersConfig struct {
 	ManualInterventionTag string            `yaml:"manualInterventionTag"`
 	BookImporter          BookImporter      `yaml:"ebooks"`
 	AudiobookImporter     AudiobookImporter `yaml:"audiobooks"`
+
+	// MaxAttempts is how many times a torrent is tried before a transient
+	// failure is sent to manual intervention.
+	MaxAttempts int `yaml:"maxAttempts"`
+
+	// RetryBackoffMinutes is the delay before the first retry. It doubles
+	// after every failed attempt.
+	RetryBackoffMinutes int `yaml:"retryBackoffMinutes"`
 }
 
 func FindLibraryByName(libraries []ImportLibrary, libraryName string) (*ImportLibrary, bool) {
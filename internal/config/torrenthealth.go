@@ -0,0 +1,25 @@
+package config
+
+// TorrentHealthConfig controls the torrent health monitor, which flags
+// incomplete torrents that stopped making progress.
+type TorrentHealthConfig struct {
+	// Categories limits the monitor to these categories. Empty monitors every
+	// incomplete torrent.
+	Categories []string `yaml:"categories"`
+
+	// StalledAfterHours is how long a torrent may go without progress before
+	// it is flagged
+	StalledAfterHours int `yaml:"stalledAfterHours"`
+
+	// Notifier is the notification used for torrents that do not belong to
+	// an author subscription, which notify through the subscription's
+	// Notifier instead. Empty sends none.
+	Notifier string `yaml:"notifier"`
+
+	// RemoveStalled removes flagged torrents and their data from the client
+	RemoveStalled bool `yaml:"removeStalled"`
+
+	// SearchAlternative searches for another release of a removed author
+	// subscription torrent and downloads the best seeded one
+	SearchAlternative bool `yaml:"searchAlternative"`
+}
@@ -1,14 +1,19 @@
 package config
 
 type ClusterConfig struct {
-	Postgres      PostgresConfig      `yaml:"postgres"`
-	Qbit          QbitConfig          `yaml:"qbit"`
-	Notifications NotificationsConfig `yaml:"notifications"`
-	FeedWatcher   FeedWatcherConfig   `yaml:"feedWatcher"`
-	DiscordBot    DiscordBotConfig    `yaml:"discordBot"`
-	BookSearch    BookSearchConfig    `yaml:"bookSearch"`
-	Logging       LoggingConfig       `yaml:"logging"`
-	APIClient     APIClientConfig     `yaml:"apiClient"`
-	Importers     ImportersConfig     `yaml:"importers"`
-	Hardcover     HarcoverConfig      `yaml:"hardcover"`
+	Postgres       PostgresConfig       `yaml:"postgres"`
+	DownloadClient DownloadClientConfig `yaml:"downloadClient"`
+	Qbit           QbitConfig           `yaml:"qbit"`
+	Transmission   TransmissionConfig   `yaml:"transmission"`
+	Notifications  NotificationsConfig  `yaml:"notifications"`
+	FeedWatcher    FeedWatcherConfig    `yaml:"feedWatcher"`
+	FeedWatcher2   FeedWatcher2Config   `yaml:"feedWatcher2"`
+	TorrentHealth  TorrentHealthConfig  `yaml:"torrentHealth"`
+	DiscordBot     DiscordBotConfig     `yaml:"discordBot"`
+	BookSearch     BookSearchConfig     `yaml:"bookSearch"`
+	Logging        LoggingConfig        `yaml:"logging"`
+	APIClient      APIClientConfig      `yaml:"apiClient"`
+	Importers      ImportersConfig      `yaml:"importers"`
+	Hardcover      HarcoverConfig       `yaml:"hardcover"`
+	Audiobookshelf AudiobookshelfConfig `yaml:"audiobookshelf"`
 }
@@ -13,17 +13,19 @@ import (
 
 	"github.com/bobbyrward/stronghold/internal/booksearch"
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/models"
-	"github.com/bobbyrward/stronghold/internal/qbit"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
 )
 
 type Bot struct {
-	session    *discordgo.Session
-	config     *config.DiscordBotConfig
-	bookSearch *booksearch.BookSearchService
-	qbitClient qbit.QbitClient
-	db         *gorm.DB
+	session           *discordgo.Session
+	config            *config.DiscordBotConfig
+	bookSearch        *booksearch.BookSearchService
+	downloadClient    downloadclient.DownloadClient
+	torrentDownloader *torrentutil.TorrentDownloader
+	db                *gorm.DB
 }
 
 func Run() error {
@@ -47,7 +49,7 @@ func NewBot(cfg *config.DiscordBotConfig) (*Bot, error) {
 		return nil, fmt.Errorf("failed to create discord session: %w", err)
 	}
 
-	qbitClient, err := qbit.CreateClient()
+	downloadClient, err := downloadclient.CreateClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create qbittorrent client: %w", err)
 	}
@@ -65,12 +67,16 @@ func NewBot(cfg *config.DiscordBotConfig) (*Bot, error) {
 	// Clean up old event logs
 	eventlog.Cleanup(context.Background(), db, 90)
 
+	// Clean up expired search cache entries and old search history
+	booksearch.Cleanup(context.Background(), db, config.Config.BookSearch.Cache.HistoryRetentionDays)
+
 	bot := &Bot{
-		session:    session,
-		config:     cfg,
-		bookSearch: booksearch.NewBookSearchService(),
-		qbitClient: qbitClient,
-		db:         db,
+		session:           session,
+		config:            cfg,
+		bookSearch:        booksearch.NewBookSearchService(),
+		downloadClient:    downloadClient,
+		torrentDownloader: torrentutil.NewTorrentDownloader(config.Config.BookSearch.HttpProxy, config.Config.BookSearch.HttpsProxy),
+		db:                db,
 	}
 
 	bot.setupHandlers()
@@ -116,10 +122,12 @@ func (b *Bot) ready(s *discordgo.Session, event *discordgo.Ready) {
 }
 
 func (b *Bot) registerCommands() error {
+	minPage := 1.0
+
 	commands := []*discordgo.ApplicationCommand{
 		{
 			Name:        "requestbook",
-			Description: "Search and request books to be added to qBittorrent",
+			Description: "Search and request books to be added to the download client",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
@@ -127,6 +135,52 @@ func (b *Bot) registerCommands() error {
 					Description: "Search query for books",
 					Required:    true,
 				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "type",
+					Description: "Only search ebooks or audiobooks",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Ebooks", Value: "ebooks"},
+						{Name: "Audiobooks", Value: "audiobooks"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "sort",
+					Description: "Result order (default newest first)",
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Newest first", Value: booksearch.SortDateDesc},
+						{Name: "Oldest first", Value: "dateAsc"},
+						{Name: "Most seeders", Value: "seedersDesc"},
+						{Name: "Title", Value: "titleAsc"},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "filetype",
+					Description: "Only show these file types, e.g. epub or m4b,mp3",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "language",
+					Description: "Language ID to search (default English)",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "from",
+					Description: "Only torrents added on or after this date (YYYY-MM-DD)",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "to",
+					Description: "Only torrents added on or before this date (YYYY-MM-DD)",
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "page",
+					Description: "Page of results to show",
+					MinValue:    &minPage,
+				},
 			},
 		},
 	}
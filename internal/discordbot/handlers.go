@@ -2,6 +2,7 @@ package discordbot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"reflect"
@@ -9,7 +10,7 @@ import (
 	"strings"
 
 	"github.com/bobbyrward/stronghold/internal/booksearch"
-	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/bwmarrin/discordgo"
@@ -37,19 +38,67 @@ func (b *Bot) handleSlashCommand(s *discordgo.Session, i *discordgo.InteractionC
 	}
 }
 
-func (b *Bot) handleRequestBookCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	options := i.ApplicationCommandData().Options
-	if len(options) == 0 {
-		b.respondWithError(s, i, "No search query provided")
-		return
+// requestBookResultsPerPage is how many results /requestbook shows at once
+const requestBookResultsPerPage = 5
+
+// requestBookParams builds the search from the /requestbook options.
+func requestBookParams(options []*discordgo.ApplicationCommandInteractionDataOption) (*booksearch.SearchParameters, int, error) {
+	params := &booksearch.SearchParameters{
+		MaxResults: requestBookResultsPerPage,
+	}
+	page := 1
+
+	for _, option := range options {
+		switch option.Name {
+		case "query":
+			params.Query = option.StringValue()
+		case "type":
+			mainCategory, err := booksearch.ParseMainCategory(option.StringValue())
+			if err != nil {
+				return nil, 0, err
+			}
+			params.MainCategories = []int{mainCategory}
+		case "sort":
+			params.SortType = option.StringValue()
+		case "filetype":
+			for _, fileType := range strings.Split(option.StringValue(), ",") {
+				if fileType = strings.TrimSpace(fileType); fileType != "" {
+					params.Formats = append(params.Formats, fileType)
+				}
+			}
+		case "language":
+			params.Languages = []int{int(option.IntValue())}
+		case "from":
+			params.StartDate = option.StringValue()
+		case "to":
+			params.EndDate = option.StringValue()
+		case "page":
+			page = max(int(option.IntValue()), 1)
+		}
+	}
+
+	if params.Query == "" {
+		return nil, 0, errors.New("search query cannot be empty")
+	}
+
+	params.Offset = (page - 1) * requestBookResultsPerPage
+
+	if err := params.Validate(); err != nil {
+		return nil, 0, err
 	}
 
-	query := options[0].StringValue()
-	if query == "" {
-		b.respondWithError(s, i, "Search query cannot be empty")
+	return params, page, nil
+}
+
+func (b *Bot) handleRequestBookCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	params, page, err := requestBookParams(i.ApplicationCommandData().Options)
+	if err != nil {
+		b.respondWithError(s, i, err.Error())
 		return
 	}
 
+	query := params.Query
+
 	ctx := context.Background()
 	slog.InfoContext(ctx, "Processing book request", slog.String("query", query), slog.String("userId", i.Member.User.ID))
 
@@ -58,7 +107,7 @@ func (b *Bot) handleRequestBookCommand(s *discordgo.Session, i *discordgo.Intera
 		fmt.Sprintf("Book search requested: %s", query),
 		map[string]string{"query": query, "user_id": i.Member.User.ID, "channel_id": i.ChannelID})
 
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
 	})
 	if err != nil {
@@ -66,11 +115,6 @@ func (b *Bot) handleRequestBookCommand(s *discordgo.Session, i *discordgo.Intera
 		return
 	}
 
-	params := &booksearch.SearchParameters{
-		Query:      query,
-		MaxResults: 5,
-	}
-
 	searchResponse, err := b.bookSearch.Search(context.Background(), b.db, params)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to search books", slog.Any("error", err), slog.String("query", query))
@@ -78,6 +122,17 @@ func (b *Bot) handleRequestBookCommand(s *discordgo.Session, i *discordgo.Intera
 		return
 	}
 
+	history, err := booksearch.RecordSearch(b.db, booksearch.Requester{
+		Source:      booksearch.HistorySourceDiscordBot,
+		RequestedBy: i.Member.User.ID,
+		Location:    i.ChannelID,
+	}, params, searchResponse)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record search history", slogx.Error(err))
+		b.editResponseWithError(s, i, "Failed to record search results")
+		return
+	}
+
 	if len(searchResponse.Data) == 0 {
 		_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
 			Content: fmt.Sprintf("No books found for query: **%s**", query),
@@ -88,38 +143,15 @@ func (b *Bot) handleRequestBookCommand(s *discordgo.Session, i *discordgo.Intera
 		return
 	}
 
-	dbResults, err := b.cacheSearchResults(searchResponse)
-	if err != nil {
-		b.editResponseWithError(s, i, "Failed to cache search results")
-		return
-	}
-
 	eventlog.Log(b.db, eventlog.CategorySearch, eventlog.EventSearchCompleted, eventlog.SourceDiscordBot,
 		eventlog.EntitySearch, "",
-		fmt.Sprintf("Search completed: %s (%d results)", query, len(dbResults)),
-		map[string]any{"query": query, "result_count": len(dbResults)})
+		fmt.Sprintf("Search completed: %s (%d results)", query, len(searchResponse.Data)),
+		map[string]any{"query": query, "result_count": len(searchResponse.Data), "cached": searchResponse.Cached})
 
-	b.sendBookSelectionMessage(s, i, dbResults, params)
+	b.sendBookSelectionMessage(s, i, history.ID, params, searchResponse, page)
 }
 
-func (b *Bot) cacheSearchResults(searchResponse *booksearch.SearchResponse) ([]models.SearchResponseItem, error) {
-	ctx := context.Background()
-	modelItems := make([]models.SearchResponseItem, len(searchResponse.Data))
-
-	for idx, book := range searchResponse.Data {
-		modelItems[idx] = book.ToModel()
-	}
-
-	result := b.db.Create(&modelItems)
-	if result.Error != nil {
-		slog.ErrorContext(ctx, "Failed to cache search results", slogx.Error(result.Error))
-		return modelItems, result.Error
-	}
-
-	return modelItems, nil
-}
-
-func displayTitle(result *models.SearchResponseItem) string {
+func displayTitle(result *booksearch.SearchResponseItem) string {
 	category := ""
 
 	switch result.MainCategory {
@@ -134,13 +166,13 @@ func displayTitle(result *models.SearchResponseItem) string {
 	return fmt.Sprintf("%s (%s)", result.Title, category)
 }
 
-func displayString(result *models.SearchResponseItem) string {
+func displayString(result *booksearch.SearchResponseItem) string {
 	parts := []string{}
 
-	parts = append(parts, fmt.Sprintf("Authors: %s", result.Authors))
+	parts = append(parts, fmt.Sprintf("Authors: %s", result.AuthorNames()))
 
 	if len(result.Series) > 0 {
-		parts = append(parts, fmt.Sprintf("Series: %s", result.Series))
+		parts = append(parts, fmt.Sprintf("Series: %s", result.SeriesNames()))
 	}
 
 	parts = append(parts, fmt.Sprintf("Type: %s", result.FileTypes))
@@ -149,16 +181,23 @@ func displayString(result *models.SearchResponseItem) string {
 	return strings.Join(parts, "\n")
 }
 
-func (b *Bot) sendBookSelectionMessage(s *discordgo.Session, i *discordgo.InteractionCreate, searchResults []models.SearchResponseItem, searchParams *booksearch.SearchParameters) {
+func (b *Bot) sendBookSelectionMessage(s *discordgo.Session, i *discordgo.InteractionCreate, historyID uint, searchParams *booksearch.SearchParameters, searchResponse *booksearch.SearchResponse, page int) {
 	ctx := context.Background()
+	searchResults := searchResponse.Data
 
 	embed := &discordgo.MessageEmbed{
 		Title:       fmt.Sprintf("Books found for: %s", searchParams.Query),
-		Description: "Select one or more books to add to qBittorrent:",
+		Description: "Select one or more books to add to the download client:",
 		Color:       0x00ff00,
 		Fields:      make([]*discordgo.MessageEmbedField, len(searchResults)),
 	}
 
+	footer := fmt.Sprintf("Page %d, %d results found", page, searchResponse.TotalFound)
+	if searchResponse.HasMore() {
+		footer += fmt.Sprintf(" - use page:%d for more", page+1)
+	}
+	embed.Footer = &discordgo.MessageEmbedFooter{Text: footer}
+
 	for idx, book := range searchResults {
 		embed.Fields[idx] = &discordgo.MessageEmbedField{
 			Name:   displayTitle(&book),
@@ -167,7 +206,7 @@ func (b *Bot) sendBookSelectionMessage(s *discordgo.Session, i *discordgo.Intera
 		}
 	}
 
-	components := b.createBookSelectionComponents(searchResults)
+	components := b.createBookSelectionComponents(historyID, searchResults)
 
 	_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
 		Embeds:     []*discordgo.MessageEmbed{embed},
@@ -179,7 +218,10 @@ func (b *Bot) sendBookSelectionMessage(s *discordgo.Session, i *discordgo.Intera
 	}
 }
 
-func (b *Bot) createBookSelectionComponents(searchResults []models.SearchResponseItem) []discordgo.MessageComponent {
+// createBookSelectionComponents builds the selection buttons. Each button
+// refers to its result by search history ID and index so the selection can be
+// resolved after the search cache has expired.
+func (b *Bot) createBookSelectionComponents(historyID uint, searchResults []booksearch.SearchResponseItem) []discordgo.MessageComponent {
 	var components []discordgo.MessageComponent
 
 	for i := 0; i < len(searchResults); i += 5 {
@@ -190,11 +232,10 @@ func (b *Bot) createBookSelectionComponents(searchResults []models.SearchRespons
 
 		var buttons []discordgo.MessageComponent
 		for j := i; j < end; j++ {
-			book := searchResults[j]
 			buttons = append(buttons, discordgo.Button{
 				Label:    fmt.Sprintf("%d", j+1),
 				Style:    discordgo.SecondaryButton,
-				CustomID: fmt.Sprintf("select_book_%d", book.ID),
+				CustomID: fmt.Sprintf("select_book_%d_%d", historyID, j),
 			})
 		}
 
@@ -346,7 +387,7 @@ func (b *Bot) handleAddSelectedBooks(s *discordgo.Session, i *discordgo.Interact
 		return
 	}
 
-	var selectedBookIDs []int
+	var selections []bookSelection
 	components := i.Message.Components
 
 	for _, component := range components {
@@ -354,35 +395,52 @@ func (b *Bot) handleAddSelectedBooks(s *discordgo.Session, i *discordgo.Interact
 			for _, comp := range actionRow.Components {
 				if button, ok := comp.(*discordgo.Button); ok {
 					if strings.HasPrefix(button.CustomID, "select_book_") && button.Style == discordgo.SuccessButton {
-						idString := strings.TrimPrefix(button.CustomID, "select_book_")
-						id, _ := strconv.Atoi(idString)
-						selectedBookIDs = append(selectedBookIDs, id)
+						selection, err := parseBookSelection(button.CustomID)
+						if err != nil {
+							slog.WarnContext(ctx, "Invalid book selection", slog.String("customID", button.CustomID), slogx.Error(err))
+							continue
+						}
+						selections = append(selections, selection)
 					}
 				}
 			}
 		}
 	}
 
-	if len(selectedBookIDs) == 0 {
+	if len(selections) == 0 {
 		slog.WarnContext(ctx, "No books selected for adding")
 		return
 	}
 
 	var successCount int
 	var failedBooks []string
+	histories := make(map[uint][]booksearch.SearchResponseItem)
+
+	for _, selection := range selections {
+		results, ok := histories[selection.historyID]
+		if !ok {
+			var history models.SearchHistory
+			if err := b.db.First(&history, selection.historyID).Error; err != nil {
+				slog.ErrorContext(ctx, "Failed to find search history",
+					slog.Uint64("historyID", uint64(selection.historyID)),
+					slogx.Error(err),
+				)
+			} else if results, err = booksearch.HistoryResults(&history); err != nil {
+				slog.ErrorContext(ctx, "Failed to decode search history results",
+					slog.Uint64("historyID", uint64(selection.historyID)),
+					slogx.Error(err),
+				)
+			}
+			histories[selection.historyID] = results
+		}
 
-	for _, bookID := range selectedBookIDs {
-		var book models.SearchResponseItem
-
-		result := b.db.First(&book, bookID)
-
-		if result.Error != nil {
-			slog.ErrorContext(ctx, "Failed to find cached search result",
-				slog.Int("id", bookID),
-			)
+		if selection.index >= len(results) {
+			failedBooks = append(failedBooks, fmt.Sprintf("result %d", selection.index+1))
+			continue
 		}
 
-		torrentURL := fmt.Sprintf("%s/tor/download.php/%s", config.Config.BookSearch.BaseURL, book.DlHash)
+		book := results[selection.index]
+		torrentURL := book.DownloadTorrentURL()
 
 		qbitCategory := ""
 		switch book.MainCategory {
@@ -402,17 +460,12 @@ func (b *Bot) handleAddSelectedBooks(s *discordgo.Session, i *discordgo.Interact
 			qbitCategory = "unknown"
 		}
 
-		_, err := b.qbitClient.AddTorrentFromUrlCtx(
-			ctx,
-			torrentURL,
-			map[string]string{
-				"autoTMM":  "true",
-				"category": qbitCategory,
-			},
-		)
+		hash, err := b.torrentDownloader.DownloadAndAdd(ctx, b.downloadClient, torrentURL, downloadclient.AddOptions{
+			Category: qbitCategory,
+		})
 
 		if err != nil {
-			slog.ErrorContext(ctx, "Failed to add torrent to qBittorrent",
+			slog.ErrorContext(ctx, "Failed to add torrent to the download client",
 				slog.Any("error", err),
 				slog.String("dlHash", book.DlHash),
 				slog.String("torrentURL", torrentURL),
@@ -420,20 +473,22 @@ func (b *Bot) handleAddSelectedBooks(s *discordgo.Session, i *discordgo.Interact
 			failedBooks = append(failedBooks, book.Title)
 		} else {
 			successCount++
-			slog.InfoContext(ctx, "Successfully added torrent to qBittorrent",
+			slog.InfoContext(ctx, "Successfully added torrent to the download client",
 				slog.String("dlHash", book.DlHash),
+				slog.String("hash", hash),
 				slog.String("torrentURL", torrentURL),
 				slog.String("channelid", i.ChannelID),
 			)
 			eventlog.Log(b.db, eventlog.CategoryDownload, eventlog.EventTorrentAdded, eventlog.SourceDiscordBot,
-				eventlog.EntityTorrent, book.DlHash,
+				eventlog.EntityTorrent, hash,
 				fmt.Sprintf("Downloaded via Discord: %s", book.Title),
 				map[string]any{
 					"title":      book.Title,
-					"authors":    book.Authors,
+					"authors":    book.AuthorNames(),
 					"category":   qbitCategory,
 					"dl_hash":    book.DlHash,
-					"torrent_id": book.TorrentID,
+					"hash":       hash,
+					"torrent_id": book.ID,
 					"channel_id": i.ChannelID,
 				})
 		}
@@ -443,7 +498,7 @@ func (b *Bot) handleAddSelectedBooks(s *discordgo.Session, i *discordgo.Interact
 	if successCount > 0 && len(failedBooks) == 0 {
 		embed = &discordgo.MessageEmbed{
 			Title:       "Books Added Successfully! 🎉",
-			Description: fmt.Sprintf("Successfully added %d book(s) to qBittorrent", successCount),
+			Description: fmt.Sprintf("Successfully added %d book(s) to the download client", successCount),
 			Color:       0x00ff00,
 		}
 	} else if successCount > 0 && len(failedBooks) > 0 {
@@ -455,7 +510,7 @@ func (b *Bot) handleAddSelectedBooks(s *discordgo.Session, i *discordgo.Interact
 	} else {
 		embed = &discordgo.MessageEmbed{
 			Title:       "Failed to Add Books ❌",
-			Description: "Failed to add any books to qBittorrent. Check logs for details.",
+			Description: "Failed to add any books to the download client. Check logs for details.",
 			Color:       0xff0000,
 		}
 	}
@@ -469,6 +524,33 @@ func (b *Bot) handleAddSelectedBooks(s *discordgo.Session, i *discordgo.Interact
 	}
 }
 
+// bookSelection identifies a search result by the search history entry it was
+// recorded in and its index within that entry.
+type bookSelection struct {
+	historyID uint
+	index     int
+}
+
+// parseBookSelection parses a "select_book_<historyID>_<index>" button ID.
+func parseBookSelection(customID string) (bookSelection, error) {
+	historyPart, indexPart, ok := strings.Cut(strings.TrimPrefix(customID, "select_book_"), "_")
+	if !ok {
+		return bookSelection{}, fmt.Errorf("malformed selection id: %s", customID)
+	}
+
+	historyID, err := strconv.ParseUint(historyPart, 10, 64)
+	if err != nil {
+		return bookSelection{}, fmt.Errorf("invalid history id: %w", err)
+	}
+
+	index, err := strconv.Atoi(indexPart)
+	if err != nil || index < 0 {
+		return bookSelection{}, fmt.Errorf("invalid result index: %s", indexPart)
+	}
+
+	return bookSelection{historyID: uint(historyID), index: index}, nil
+}
+
 func (b *Bot) handleCancelSelection(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	ctx := context.Background()
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
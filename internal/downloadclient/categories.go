@@ -0,0 +1,28 @@
+package downloadclient
+
+import (
+	"context"
+	"fmt"
+)
+
+func GetTorrentsByCategory(ctx context.Context, client DownloadClient, category string) ([]Torrent, error) {
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{
+		Category: category,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get torrents: %w", err)
+	}
+
+	return torrents, nil
+}
+
+func GetUnimportedTorrentsByCategory(ctx context.Context, client DownloadClient, category string) ([]Torrent, error) {
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{
+		Category: category,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get torrents: %w", err)
+	}
+
+	return FilterUnimportedTorrents(torrents), nil
+}
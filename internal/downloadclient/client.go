@@ -0,0 +1,107 @@
+// Package downloadclient is Stronghold's interface to torrent clients, with
+// adapters for qBittorrent and Transmission.
+package downloadclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+// Client types selectable with downloadClient.type
+const (
+	TypeQbittorrent  = "qbittorrent"
+	TypeTransmission = "transmission"
+)
+
+// ErrTorrentNotFound is returned for a hash the download client does not know.
+var ErrTorrentNotFound = errors.New("torrent not found")
+
+// DownloadClient is the set of torrent client operations Stronghold uses.
+// Clients without categories or tags emulate them; see each adapter.
+type DownloadClient interface {
+	// AddTorrentFromURL adds the .torrent file or magnet link at url
+	AddTorrentFromURL(ctx context.Context, url string, options AddOptions) error
+
+	// AddTorrentFromBytes adds a .torrent file's contents
+	AddTorrentFromBytes(ctx context.Context, data []byte, options AddOptions) error
+
+	GetTorrents(ctx context.Context, filter TorrentFilter) ([]Torrent, error)
+
+	// GetFiles returns the files of a torrent. A hash the client does not know
+	// is an error wrapping ErrTorrentNotFound.
+	GetFiles(ctx context.Context, hash string) ([]TorrentFile, error)
+
+	AddTags(ctx context.Context, hashes []string, tags ...string) error
+	RemoveTags(ctx context.Context, hashes []string, tags ...string) error
+
+	// SetTags replaces every tag of the torrents with tags
+	SetTags(ctx context.Context, hashes []string, tags ...string) error
+
+	SetCategory(ctx context.Context, hashes []string, category string) error
+	RemoveTorrents(ctx context.Context, hashes []string, deleteFiles bool) error
+}
+
+var (
+	sharedClientMu sync.Mutex
+	sharedClient   *ResilientClient
+)
+
+// CreateClient creates the client selected by downloadClient.type,
+// qBittorrent when it is not set, wrapped with retries and a circuit breaker.
+func CreateClient() (DownloadClient, error) {
+	return createResilientClient()
+}
+
+// SharedClient returns the process-wide client, creating it on first use.
+// Servers use it so the client's session and circuit breaker outlive a
+// single request.
+func SharedClient() (*ResilientClient, error) {
+	sharedClientMu.Lock()
+	defer sharedClientMu.Unlock()
+
+	if sharedClient == nil {
+		client, err := createResilientClient()
+		if err != nil {
+			return nil, err
+		}
+		sharedClient = client
+	}
+
+	return sharedClient, nil
+}
+
+func createResilientClient() (*ResilientClient, error) {
+	client, err := createAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResilientClient(client, config.Config.DownloadClient), nil
+}
+
+// createAdapter creates the bare adapter for downloadClient.type.
+func createAdapter() (DownloadClient, error) {
+	ctx := context.Background()
+
+	switch config.Config.DownloadClient.Type {
+	case "", TypeQbittorrent:
+		slog.InfoContext(ctx, "Creating qBittorrent client",
+			slog.String("host", config.Config.Qbit.URL),
+			slog.String("username", config.Config.Qbit.Username))
+
+		return NewQbittorrentClient(config.Config.Qbit), nil
+	case TypeTransmission:
+		slog.InfoContext(ctx, "Creating Transmission client",
+			slog.String("url", config.Config.Transmission.URL),
+			slog.String("username", config.Config.Transmission.Username))
+
+		return NewTransmissionClient(config.Config.Transmission), nil
+	default:
+		return nil, fmt.Errorf("unknown download client type: %s", config.Config.DownloadClient.Type)
+	}
+}
@@ -0,0 +1,113 @@
+package downloadclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Reasons a torrent is not yet ready for import
+const (
+	SkipReasonDownloading = "downloading"
+	SkipReasonChecking    = "checking"
+	SkipReasonMoving      = "moving"
+	SkipReasonErrored     = "errored"
+	SkipReasonSeeding     = "seeding"
+)
+
+// SeedGate holds back completed torrents until they have seeded long enough.
+// A torrent passes once it meets either threshold. A zero gate passes every
+// completed torrent.
+type SeedGate struct {
+	MinSeedTime time.Duration
+	MinRatio    float64
+}
+
+func (g SeedGate) enabled() bool {
+	return g.MinSeedTime > 0 || g.MinRatio > 0
+}
+
+func (g SeedGate) passes(torrent Torrent) bool {
+	if !g.enabled() {
+		return true
+	}
+
+	if g.MinSeedTime > 0 && time.Duration(torrent.SeedingTime)*time.Second >= g.MinSeedTime {
+		return true
+	}
+
+	return g.MinRatio > 0 && torrent.Ratio >= g.MinRatio
+}
+
+// SkippedTorrent is an unimported torrent that is not ready for import yet.
+type SkippedTorrent struct {
+	Torrent Torrent
+	Reason  string
+}
+
+// ImportCandidates splits unimported torrents into those ready for import and
+// those skipped for now.
+type ImportCandidates struct {
+	Ready   []Torrent
+	Skipped []SkippedTorrent
+}
+
+// ImportReadiness reports whether a torrent has finished downloading and
+// checking and passes the seed gate. When it is not ready, the reason says why.
+func ImportReadiness(torrent Torrent, gate SeedGate) (bool, string) {
+	switch torrent.State {
+	case StateChecking:
+		return false, SkipReasonChecking
+	case StateMoving:
+		return false, SkipReasonMoving
+	case StateError, StateMissingFiles, StateUnknown:
+		return false, SkipReasonErrored
+	case StateSeeding, StateStalledSeeding, StateQueuedSeeding, StatePausedSeeding:
+		// Finished downloading
+	default:
+		return false, SkipReasonDownloading
+	}
+
+	if torrent.Progress < 1 || torrent.AmountLeft > 0 {
+		return false, SkipReasonDownloading
+	}
+
+	if !gate.passes(torrent) {
+		return false, SkipReasonSeeding
+	}
+
+	return true, ""
+}
+
+// FilterImportReadyTorrents splits unimported torrents by ImportReadiness.
+// Torrents that already have tags are dropped.
+func FilterImportReadyTorrents(torrents []Torrent, gate SeedGate) ImportCandidates {
+	candidates := ImportCandidates{
+		Ready:   make([]Torrent, 0, len(torrents)),
+		Skipped: make([]SkippedTorrent, 0),
+	}
+
+	for _, torrent := range FilterUnimportedTorrents(torrents) {
+		ready, reason := ImportReadiness(torrent, gate)
+		if ready {
+			candidates.Ready = append(candidates.Ready, torrent)
+		} else {
+			candidates.Skipped = append(candidates.Skipped, SkippedTorrent{Torrent: torrent, Reason: reason})
+		}
+	}
+
+	return candidates
+}
+
+// GetImportReadyTorrentsByCategory returns the unimported torrents in a category,
+// split into those ready for import and those still downloading, checking or seeding.
+func GetImportReadyTorrentsByCategory(ctx context.Context, client DownloadClient, category string, gate SeedGate) (ImportCandidates, error) {
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{
+		Category: category,
+	})
+	if err != nil {
+		return ImportCandidates{}, fmt.Errorf("failed to get torrents: %w", err)
+	}
+
+	return FilterImportReadyTorrents(torrents, gate), nil
+}
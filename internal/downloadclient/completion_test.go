@@ -0,0 +1,102 @@
+package downloadclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func completedTorrent(hash string) Torrent {
+	return Torrent{
+		Hash:     hash,
+		Name:     hash,
+		State:    StateStalledSeeding,
+		Progress: 1,
+	}
+}
+
+func TestImportReadiness(t *testing.T) {
+	downloading := completedTorrent("downloading")
+	downloading.State = StateDownloading
+	downloading.Progress = 0.42
+	downloading.AmountLeft = 1024
+
+	paused := completedTorrent("paused")
+	paused.State = StatePausedDownloading
+	paused.Progress = 0.5
+
+	checking := completedTorrent("checking")
+	checking.State = StateChecking
+
+	moving := completedTorrent("moving")
+	moving.State = StateMoving
+
+	missing := completedTorrent("missing")
+	missing.State = StateMissingFiles
+
+	stopped := completedTorrent("stopped")
+	stopped.State = StatePausedSeeding
+
+	tests := []struct {
+		name       string
+		torrent    Torrent
+		wantReady  bool
+		wantReason string
+	}{
+		{name: "completed", torrent: completedTorrent("done"), wantReady: true},
+		{name: "stopped after completing", torrent: stopped, wantReady: true},
+		{name: "downloading", torrent: downloading, wantReason: SkipReasonDownloading},
+		{name: "paused while downloading", torrent: paused, wantReason: SkipReasonDownloading},
+		{name: "rechecking", torrent: checking, wantReason: SkipReasonChecking},
+		{name: "moving", torrent: moving, wantReason: SkipReasonMoving},
+		{name: "missing files", torrent: missing, wantReason: SkipReasonErrored},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ready, reason := ImportReadiness(tt.torrent, SeedGate{})
+			assert.Equal(t, tt.wantReady, ready)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
+
+func TestImportReadiness_SeedGate(t *testing.T) {
+	torrent := completedTorrent("seeding")
+	torrent.SeedingTime = int64((30 * time.Minute).Seconds())
+	torrent.Ratio = 0.4
+
+	ready, reason := ImportReadiness(torrent, SeedGate{MinSeedTime: time.Hour})
+	assert.False(t, ready)
+	assert.Equal(t, SkipReasonSeeding, reason)
+
+	ready, _ = ImportReadiness(torrent, SeedGate{MinSeedTime: 20 * time.Minute})
+	assert.True(t, ready)
+
+	// Either threshold is enough
+	ready, _ = ImportReadiness(torrent, SeedGate{MinSeedTime: time.Hour, MinRatio: 0.25})
+	assert.True(t, ready)
+
+	ready, reason = ImportReadiness(torrent, SeedGate{MinRatio: 1})
+	assert.False(t, ready)
+	assert.Equal(t, SkipReasonSeeding, reason)
+}
+
+func TestFilterImportReadyTorrents(t *testing.T) {
+	downloading := completedTorrent("downloading")
+	downloading.State = StateDownloading
+	downloading.Progress = 0.1
+
+	imported := completedTorrent("imported")
+	imported.Tags = []string{"imported"}
+
+	candidates := FilterImportReadyTorrents([]Torrent{completedTorrent("done"), downloading, imported}, SeedGate{})
+
+	assert.Len(t, candidates.Ready, 1)
+	assert.Equal(t, "done", candidates.Ready[0].Hash)
+
+	assert.Len(t, candidates.Skipped, 1)
+	assert.Equal(t, "downloading", candidates.Skipped[0].Torrent.Hash)
+	assert.Equal(t, SkipReasonDownloading, candidates.Skipped[0].Reason)
+}
@@ -0,0 +1,204 @@
+package downloadclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/autobrr/go-qbittorrent"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+// QbittorrentClient adapts the qBittorrent Web API. Torrents are added with
+// automatic torrent management, so they download to their category's save path.
+type QbittorrentClient struct {
+	client *qbittorrent.Client
+}
+
+// Compile-time check that QbittorrentClient implements DownloadClient interface.
+var _ DownloadClient = (*QbittorrentClient)(nil)
+
+// NewQbittorrentClient creates a client for the qBittorrent at cfg.URL. It logs
+// in on the first request.
+func NewQbittorrentClient(cfg config.QbitConfig) *QbittorrentClient {
+	return &QbittorrentClient{
+		client: qbittorrent.NewClient(qbittorrent.Config{
+			Host:     cfg.URL,
+			Username: cfg.Username,
+			Password: cfg.Password,
+		}),
+	}
+}
+
+func (q *QbittorrentClient) addOptions(options AddOptions) map[string]string {
+	opts := map[string]string{
+		"autoTMM": "true",
+	}
+
+	if options.Category != "" {
+		opts["category"] = options.Category
+	}
+	if len(options.Tags) > 0 {
+		opts["tags"] = strings.Join(options.Tags, ",")
+	}
+	if options.Paused {
+		// qBittorrent 5 renamed paused to stopped
+		opts["paused"] = "true"
+		opts["stopped"] = "true"
+	}
+
+	return opts
+}
+
+func checkAddResponse(response *qbittorrent.TorrentAddResponse) error {
+	if response != nil && response.FailureCount > 0 {
+		return fmt.Errorf("qBittorrent failed to add the torrent")
+	}
+
+	return nil
+}
+
+// AddTorrentFromURL implements DownloadClient.
+func (q *QbittorrentClient) AddTorrentFromURL(ctx context.Context, url string, options AddOptions) error {
+	response, err := q.client.AddTorrentFromUrlCtx(ctx, url, q.addOptions(options))
+	if err != nil {
+		return err
+	}
+
+	return checkAddResponse(response)
+}
+
+// AddTorrentFromBytes implements DownloadClient.
+func (q *QbittorrentClient) AddTorrentFromBytes(ctx context.Context, data []byte, options AddOptions) error {
+	response, err := q.client.AddTorrentFromMemoryCtx(ctx, data, q.addOptions(options))
+	if err != nil {
+		return err
+	}
+
+	return checkAddResponse(response)
+}
+
+// GetTorrents implements DownloadClient.
+func (q *QbittorrentClient) GetTorrents(ctx context.Context, filter TorrentFilter) ([]Torrent, error) {
+	torrents, err := q.client.GetTorrentsCtx(ctx, qbittorrent.TorrentFilterOptions{
+		Category: filter.Category,
+		Tag:      filter.Tag,
+		Hashes:   filter.Hashes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Torrent, 0, len(torrents))
+	for _, torrent := range torrents {
+		result = append(result, Torrent{
+			Hash:        torrent.Hash,
+			Name:        torrent.Name,
+			Category:    torrent.Category,
+			Tags:        ParseTagList(torrent.Tags),
+			State:       qbittorrentState(torrent.State),
+			Progress:    torrent.Progress,
+			Size:        torrent.Size,
+			AmountLeft:  torrent.AmountLeft,
+			SavePath:    torrent.SavePath,
+			ContentPath: torrent.ContentPath,
+			AddedOn:     torrent.AddedOn,
+			SeedingTime: torrent.SeedingTime,
+			Ratio:       torrent.Ratio,
+			Private:     torrent.Private,
+
+			DownloadSpeed: torrent.DlSpeed,
+			Seeds:         torrent.NumSeeds,
+			Availability:  torrent.Availability,
+		})
+	}
+
+	return result, nil
+}
+
+// GetFiles implements DownloadClient.
+func (q *QbittorrentClient) GetFiles(ctx context.Context, hash string) ([]TorrentFile, error) {
+	files, err := q.client.GetFilesInformationCtx(ctx, hash)
+	if errors.Is(err, qbittorrent.ErrTorrentNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrTorrentNotFound, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]TorrentFile, 0)
+	if files == nil {
+		return result, nil
+	}
+
+	for _, file := range *files {
+		result = append(result, TorrentFile{
+			Name:     file.Name,
+			Size:     file.Size,
+			Progress: float64(file.Progress),
+		})
+	}
+
+	return result, nil
+}
+
+// AddTags implements DownloadClient.
+func (q *QbittorrentClient) AddTags(ctx context.Context, hashes []string, tags ...string) error {
+	return q.client.AddTagsCtx(ctx, hashes, strings.Join(tags, ","))
+}
+
+// RemoveTags implements DownloadClient.
+func (q *QbittorrentClient) RemoveTags(ctx context.Context, hashes []string, tags ...string) error {
+	return q.client.RemoveTagsCtx(ctx, hashes, strings.Join(tags, ","))
+}
+
+// SetTags implements DownloadClient. It needs qBittorrent 5.1 or later.
+func (q *QbittorrentClient) SetTags(ctx context.Context, hashes []string, tags ...string) error {
+	return q.client.SetTags(ctx, hashes, strings.Join(tags, ","))
+}
+
+// SetCategory implements DownloadClient.
+func (q *QbittorrentClient) SetCategory(ctx context.Context, hashes []string, category string) error {
+	return q.client.SetCategoryCtx(ctx, hashes, category)
+}
+
+// RemoveTorrents implements DownloadClient.
+func (q *QbittorrentClient) RemoveTorrents(ctx context.Context, hashes []string, deleteFiles bool) error {
+	return q.client.DeleteTorrentsCtx(ctx, hashes, deleteFiles)
+}
+
+// qbittorrentState maps a qBittorrent state to a TorrentState.
+func qbittorrentState(state qbittorrent.TorrentState) TorrentState {
+	switch state {
+	case qbittorrent.TorrentStateMetaDl, "forcedMetaDL":
+		return StateMetadata
+	case qbittorrent.TorrentStateDownloading, qbittorrent.TorrentStateForcedDl, qbittorrent.TorrentStateAllocating:
+		return StateDownloading
+	case qbittorrent.TorrentStateStalledDl:
+		return StateStalledDownloading
+	case qbittorrent.TorrentStateQueuedDl:
+		return StateQueuedDownloading
+	case qbittorrent.TorrentStatePausedDl, qbittorrent.TorrentStateStoppedDl:
+		return StatePausedDownloading
+	case qbittorrent.TorrentStateUploading, qbittorrent.TorrentStateForcedUp:
+		return StateSeeding
+	case qbittorrent.TorrentStateStalledUp:
+		return StateStalledSeeding
+	case qbittorrent.TorrentStateQueuedUp:
+		return StateQueuedSeeding
+	case qbittorrent.TorrentStatePausedUp, qbittorrent.TorrentStateStoppedUp:
+		return StatePausedSeeding
+	case qbittorrent.TorrentStateCheckingUp, qbittorrent.TorrentStateCheckingDl, qbittorrent.TorrentStateCheckingResumeData:
+		return StateChecking
+	case qbittorrent.TorrentStateMoving:
+		return StateMoving
+	case qbittorrent.TorrentStateError:
+		return StateError
+	case qbittorrent.TorrentStateMissingFiles:
+		return StateMissingFiles
+	}
+
+	return StateUnknown
+}
@@ -0,0 +1,302 @@
+package downloadclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+// qbittorrentRequest is a Web API call received by the fake qBittorrent.
+type qbittorrentRequest struct {
+	Path string
+	Form url.Values
+	Body string
+}
+
+// fakeQbittorrent stands in for the qBittorrent Web API. It requires a login
+// and records every other call.
+type fakeQbittorrent struct {
+	mu       sync.Mutex
+	requests []qbittorrentRequest
+	logins   int
+
+	// session is bumped to expire the current login
+	session int
+
+	// failures are how many upcoming calls to a path fail with a 502
+	failures map[string]int
+}
+
+func (f *fakeQbittorrent) sessionID() string {
+	return fmt.Sprintf("session-%d", f.session)
+}
+
+// expireSession makes the fake reject the current login, as qBittorrent does
+// after its session timeout.
+func (f *fakeQbittorrent) expireSession() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.session++
+}
+
+// failNext makes the next count calls to path fail with 502 Bad Gateway.
+func (f *fakeQbittorrent) failNext(path string, count int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failures == nil {
+		f.failures = make(map[string]int)
+	}
+	f.failures[path] = count
+}
+
+func (f *fakeQbittorrent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v2/auth/login" {
+		f.mu.Lock()
+		f.logins++
+		sessionID := f.sessionID()
+		f.mu.Unlock()
+
+		_ = r.ParseForm()
+		if r.PostForm.Get("username") != "admin" || r.PostForm.Get("password") != "secret" {
+			_, _ = w.Write([]byte("Fails."))
+			return
+		}
+
+		http.SetCookie(w, &http.Cookie{Name: "SID", Value: sessionID, Path: "/"})
+		_, _ = w.Write([]byte("Ok."))
+		return
+	}
+
+	f.mu.Lock()
+	sessionID := f.sessionID()
+	failing := f.failures[r.URL.Path] > 0
+	if failing {
+		f.failures[r.URL.Path]--
+	}
+	f.mu.Unlock()
+
+	if !hasSession(r, sessionID) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	if failing {
+		w.WriteHeader(http.StatusBadGateway)
+		return
+	}
+
+	request := qbittorrentRequest{Path: r.URL.Path}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		request.Form = r.MultipartForm.Value
+		if files := r.MultipartForm.File["torrents"]; len(files) == 1 {
+			file, _ := files[0].Open()
+			data, _ := io.ReadAll(file)
+			request.Body = string(data)
+		}
+	} else {
+		_ = r.ParseForm()
+		request.Form = r.Form
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, request)
+	f.mu.Unlock()
+
+	switch r.URL.Path {
+	case "/api/v2/app/webapiVersion":
+		_, _ = w.Write([]byte("2.11.4"))
+	case "/api/v2/torrents/info":
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{
+			"hash": "abc", "name": "Dune", "category": "audiobooks", "tags": "imported, needs-manual",
+			"state": "stalledUP", "progress": 1, "size": 1024, "amount_left": 0,
+			"save_path": "/downloads/audiobooks", "content_path": "/downloads/audiobooks/Dune",
+			"added_on": 1700000000, "seeding_time": 3600, "ratio": 1.5, "private": true,
+			"dlspeed": 0, "num_seeds": 3, "availability": -1
+		}]`))
+	case "/api/v2/torrents/files":
+		if request.Form.Get("hash") != "abc" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"name": "Dune/Dune.m4b", "size": 1024, "progress": 0.5}]`))
+	case "/api/v2/torrents/add":
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("Ok."))
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// hasSession reports whether r carries the session cookie. A request retried
+// after a re-login carries both the expired and the new cookie.
+func hasSession(r *http.Request, sessionID string) bool {
+	for _, cookie := range r.CookiesNamed("SID") {
+		if cookie.Value == sessionID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lastRequest returns the last recorded call to path.
+func (f *fakeQbittorrent) lastRequest(t *testing.T, path string) qbittorrentRequest {
+	t.Helper()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i := len(f.requests) - 1; i >= 0; i-- {
+		if f.requests[i].Path == path {
+			return f.requests[i]
+		}
+	}
+
+	require.Failf(t, "request not found", "no request to %s", path)
+	return qbittorrentRequest{}
+}
+
+func newTestQbittorrent(t *testing.T) (*QbittorrentClient, *fakeQbittorrent) {
+	t.Helper()
+
+	fake := &fakeQbittorrent{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := NewQbittorrentClient(config.QbitConfig{URL: server.URL, Username: "admin", Password: "secret"})
+	return client, fake
+}
+
+func TestQbittorrentClient_GetTorrents(t *testing.T) {
+	client, fake := newTestQbittorrent(t)
+
+	torrents, err := client.GetTorrents(context.Background(), TorrentFilter{Category: "audiobooks", Tag: "imported", Hashes: []string{"abc"}})
+	require.NoError(t, err)
+	require.Len(t, torrents, 1)
+
+	assert.Equal(t, Torrent{
+		Hash:        "abc",
+		Name:        "Dune",
+		Category:    "audiobooks",
+		Tags:        []string{"imported", "needs-manual"},
+		State:       StateStalledSeeding,
+		Progress:    1,
+		Size:        1024,
+		SavePath:    "/downloads/audiobooks",
+		ContentPath: "/downloads/audiobooks/Dune",
+		AddedOn:     1700000000,
+		SeedingTime: 3600,
+		Ratio:       1.5,
+		Private:     true,
+
+		Seeds:        3,
+		Availability: -1,
+	}, torrents[0])
+
+	request := fake.lastRequest(t, "/api/v2/torrents/info")
+	assert.Equal(t, "audiobooks", request.Form.Get("category"))
+	assert.Equal(t, "imported", request.Form.Get("tag"))
+	assert.Equal(t, "abc", request.Form.Get("hashes"))
+	assert.Equal(t, 1, fake.logins)
+}
+
+func TestQbittorrentClient_RenewsExpiredSession(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTestQbittorrent(t)
+
+	_, err := client.GetTorrents(ctx, TorrentFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, fake.logins)
+
+	fake.expireSession()
+
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{})
+	require.NoError(t, err)
+	assert.Len(t, torrents, 1)
+	assert.Equal(t, 2, fake.logins)
+}
+
+func TestQbittorrentClient_GetFiles(t *testing.T) {
+	client, _ := newTestQbittorrent(t)
+
+	files, err := client.GetFiles(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, []TorrentFile{{Name: "Dune/Dune.m4b", Size: 1024, Progress: 0.5}}, files)
+
+	_, err = client.GetFiles(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrTorrentNotFound)
+}
+
+func TestQbittorrentClient_AddTorrent(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTestQbittorrent(t)
+
+	err := client.AddTorrentFromURL(ctx, "https://example.com/dune.torrent", AddOptions{Category: "audiobooks", Tags: []string{"a", "b"}})
+	require.NoError(t, err)
+
+	request := fake.lastRequest(t, "/api/v2/torrents/add")
+	assert.Equal(t, "https://example.com/dune.torrent", request.Form.Get("urls"))
+	assert.Equal(t, "audiobooks", request.Form.Get("category"))
+	assert.Equal(t, "a,b", request.Form.Get("tags"))
+	assert.Equal(t, "true", request.Form.Get("autoTMM"))
+
+	err = client.AddTorrentFromBytes(ctx, []byte("d8:announce0:e"), AddOptions{Category: "books"})
+	require.NoError(t, err)
+
+	request = fake.lastRequest(t, "/api/v2/torrents/add")
+	assert.Equal(t, "d8:announce0:e", request.Body)
+	assert.Equal(t, []string{"books"}, request.Form["category"])
+}
+
+func TestQbittorrentClient_Mutations(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTestQbittorrent(t)
+	hashes := []string{"abc", "def"}
+
+	require.NoError(t, client.AddTags(ctx, hashes, "imported", "checked"))
+	request := fake.lastRequest(t, "/api/v2/torrents/addTags")
+	assert.Equal(t, "abc|def", request.Form.Get("hashes"))
+	assert.Equal(t, "imported,checked", request.Form.Get("tags"))
+
+	require.NoError(t, client.RemoveTags(ctx, hashes, "needs-manual"))
+	assert.Equal(t, "needs-manual", fake.lastRequest(t, "/api/v2/torrents/removeTags").Form.Get("tags"))
+
+	require.NoError(t, client.SetTags(ctx, hashes, "one"))
+	assert.Equal(t, "one", fake.lastRequest(t, "/api/v2/torrents/setTags").Form.Get("tags"))
+
+	require.NoError(t, client.SetCategory(ctx, hashes, "books"))
+	assert.Equal(t, "books", fake.lastRequest(t, "/api/v2/torrents/setCategory").Form.Get("category"))
+
+	require.NoError(t, client.RemoveTorrents(ctx, hashes, true))
+	request = fake.lastRequest(t, "/api/v2/torrents/delete")
+	assert.Equal(t, "abc|def", request.Form.Get("hashes"))
+	assert.Equal(t, "true", request.Form.Get("deleteFiles"))
+}
+
+func TestQbittorrentState(t *testing.T) {
+	assert.Equal(t, StateMetadata, qbittorrentState("metaDL"))
+	assert.Equal(t, StateDownloading, qbittorrentState("forcedDL"))
+	assert.Equal(t, StatePausedDownloading, qbittorrentState("stoppedDL"))
+	assert.Equal(t, StatePausedSeeding, qbittorrentState("stoppedUP"))
+	assert.Equal(t, StateChecking, qbittorrentState("checkingResumeData"))
+	assert.Equal(t, StateUnknown, qbittorrentState("somethingNew"))
+}
@@ -0,0 +1,412 @@
+package downloadclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+// Circuit breaker states
+const (
+	// BreakerClosed lets calls through
+	BreakerClosed = "closed"
+	// BreakerOpen refuses calls until the cooldown has passed
+	BreakerOpen = "open"
+	// BreakerHalfOpen lets one trial call through to see if the client is back
+	BreakerHalfOpen = "half-open"
+)
+
+// Defaults for the zero values of config.DownloadClientConfig
+const (
+	defaultRetryAttempts   = 3
+	defaultRetryBackoff    = 500 * time.Millisecond
+	defaultBreakerFailures = 5
+	defaultBreakerCooldown = time.Minute
+	maxRetryBackoff        = 10 * time.Second
+)
+
+// probeHash is a hash no torrent has, looked up to check the client answers
+const probeHash = "0000000000000000000000000000000000000000"
+
+// ErrCircuitOpen is returned without calling the client while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("download client unavailable: circuit breaker is open")
+
+// StatusError is an unexpected HTTP status from a download client.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// qbittorrentStatusPattern finds the status go-qbittorrent puts in its error
+// messages, e.g. "unrecoverable status: 502" or "status code: 503".
+var qbittorrentStatusPattern = regexp.MustCompile(`status(?: code)?: (\d{3})`)
+
+// qbittorrentRelogin is in the errors go-qbittorrent returns once its own
+// re-login and retry on 403 Forbidden has run out of attempts.
+const qbittorrentRelogin = "qbit re-login"
+
+// isAuthError reports whether err is a failed qBittorrent login. go-qbittorrent
+// has already logged in again and retried the request, so repeating the call
+// would only repeat its retries.
+func isAuthError(err error) bool {
+	return strings.Contains(err.Error(), qbittorrentRelogin)
+}
+
+// statusCode returns the HTTP status an error reports, or 0.
+func statusCode(err error) int {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+
+	if match := qbittorrentStatusPattern.FindStringSubmatch(err.Error()); match != nil {
+		code, _ := strconv.Atoi(match[1])
+		return code
+	}
+
+	return 0
+}
+
+// IsTransient reports whether a failed call might succeed if repeated: the
+// client could not be reached, or it answered with a server error or asked
+// to slow down. qBittorrent's expired sessions are renewed by go-qbittorrent
+// itself, so login failures are never transient, even when the login answered
+// with a server error.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) || isAuthError(err) {
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	code := statusCode(err)
+	return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests
+}
+
+// CircuitBreakerHealth is a snapshot of a circuit breaker.
+type CircuitBreakerHealth struct {
+	// State is BreakerClosed, BreakerOpen or BreakerHalfOpen
+	State               string
+	ConsecutiveFailures int
+	LastError           string
+	LastFailureAt       *time.Time
+	OpenedAt            *time.Time
+
+	// RetryAt is when an open breaker lets a trial call through
+	RetryAt *time.Time
+}
+
+// CircuitBreaker stops calls to a client after consecutive transient failures
+// and lets one trial call through once the cooldown has passed.
+type CircuitBreaker struct {
+	mu          sync.Mutex
+	threshold   int
+	cooldown    time.Duration
+	state       string
+	failures    int
+	lastError   error
+	lastFailure time.Time
+	openedAt    time.Time
+	trialActive bool
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow returns ErrCircuitOpen if a call must not be made now.
+func (cb *CircuitBreaker) Allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	switch cb.state {
+	case BreakerOpen:
+		if cb.now().Sub(cb.openedAt) < cb.cooldown {
+			return ErrCircuitOpen
+		}
+		cb.state = BreakerHalfOpen
+		cb.trialActive = true
+		return nil
+	case BreakerHalfOpen:
+		// Only the trial call goes through
+		if cb.trialActive {
+			return ErrCircuitOpen
+		}
+		cb.trialActive = true
+	}
+
+	return nil
+}
+
+// Record updates the breaker with a call's outcome. Only transient errors
+// count as failures; any other answer, including a failed login, shows the
+// client is reachable.
+func (cb *CircuitBreaker) Record(ctx context.Context, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.trialActive = false
+
+	// The caller gave up, which says nothing about the client
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	if !IsTransient(err) {
+		cb.state = BreakerClosed
+		cb.failures = 0
+		return
+	}
+
+	cb.failures++
+	cb.lastError = err
+	cb.lastFailure = cb.now()
+
+	if cb.state == BreakerHalfOpen || cb.failures >= cb.threshold {
+		if cb.state != BreakerOpen {
+			slog.WarnContext(ctx, "Download client circuit breaker opened",
+				slog.Int("failures", cb.failures),
+				slog.Duration("cooldown", cb.cooldown),
+				slogx.Error(err))
+		}
+		cb.state = BreakerOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// Health returns the breaker's current state.
+func (cb *CircuitBreaker) Health() CircuitBreakerHealth {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	health := CircuitBreakerHealth{
+		State:               cb.state,
+		ConsecutiveFailures: cb.failures,
+	}
+
+	if cb.lastError != nil {
+		lastFailure := cb.lastFailure
+		health.LastError = cb.lastError.Error()
+		health.LastFailureAt = &lastFailure
+	}
+
+	if cb.state == BreakerOpen {
+		openedAt := cb.openedAt
+		retryAt := cb.openedAt.Add(cb.cooldown)
+		health.OpenedAt = &openedAt
+		health.RetryAt = &retryAt
+	}
+
+	return health
+}
+
+// ResilientClient wraps a DownloadClient with retries and a circuit breaker.
+// Idempotent calls are retried with exponential backoff on transient errors.
+// Adding a torrent is never retried, since the first attempt may have reached
+// the client.
+//
+// go-qbittorrent retries each request itself, up to 5 attempts, on connection
+// errors and on 403 Forbidden after logging in again; it does not retry server
+// errors. A qBittorrent read can therefore make up to RetryAttempts × 5 HTTP
+// requests. Login failures are left to go-qbittorrent and are neither retried
+// here nor counted by the circuit breaker.
+type ResilientClient struct {
+	client        DownloadClient
+	breaker       *CircuitBreaker
+	retryAttempts int
+	retryBackoff  time.Duration
+}
+
+// Compile-time check that ResilientClient implements DownloadClient interface.
+var _ DownloadClient = (*ResilientClient)(nil)
+
+// NewResilientClient wraps client. Zero values in cfg use the defaults.
+func NewResilientClient(client DownloadClient, cfg config.DownloadClientConfig) *ResilientClient {
+	retryAttempts := cfg.RetryAttempts
+	if retryAttempts <= 0 {
+		retryAttempts = defaultRetryAttempts
+	}
+
+	retryBackoff := time.Duration(cfg.RetryBackoffMillis) * time.Millisecond
+	if retryBackoff <= 0 {
+		retryBackoff = defaultRetryBackoff
+	}
+
+	threshold := cfg.BreakerFailures
+	if threshold <= 0 {
+		threshold = defaultBreakerFailures
+	}
+
+	cooldown := time.Duration(cfg.BreakerCooldownSeconds) * time.Second
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+
+	return &ResilientClient{
+		client:        client,
+		breaker:       NewCircuitBreaker(threshold, cooldown),
+		retryAttempts: retryAttempts,
+		retryBackoff:  retryBackoff,
+	}
+}
+
+// Health returns the state of the client's circuit breaker.
+func (rc *ResilientClient) Health() CircuitBreakerHealth {
+	return rc.breaker.Health()
+}
+
+// Ping checks that the client answers, with a single lookup of a hash no
+// torrent has. It goes through the circuit breaker like any other call.
+func (rc *ResilientClient) Ping(ctx context.Context) error {
+	return rc.once(ctx, func() error {
+		_, err := rc.client.GetTorrents(ctx, TorrentFilter{Hashes: []string{probeHash}})
+		return err
+	})
+}
+
+// once makes a single call through the circuit breaker.
+func (rc *ResilientClient) once(ctx context.Context, call func() error) error {
+	if err := rc.breaker.Allow(); err != nil {
+		return err
+	}
+
+	err := call()
+	rc.breaker.Record(ctx, err)
+
+	return err
+}
+
+// retry makes an idempotent call, repeating it with backoff while it fails
+// with a transient error.
+func (rc *ResilientClient) retry(ctx context.Context, operation string, call func() error) error {
+	backoff := rc.retryBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := rc.once(ctx, call)
+		if err == nil || !IsTransient(err) || attempt >= rc.retryAttempts {
+			return err
+		}
+
+		slog.WarnContext(ctx, "Download client call failed, retrying",
+			slog.String("operation", operation),
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", backoff),
+			slogx.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// AddTorrentFromURL implements DownloadClient.
+func (rc *ResilientClient) AddTorrentFromURL(ctx context.Context, url string, options AddOptions) error {
+	return rc.once(ctx, func() error {
+		return rc.client.AddTorrentFromURL(ctx, url, options)
+	})
+}
+
+// AddTorrentFromBytes implements DownloadClient.
+func (rc *ResilientClient) AddTorrentFromBytes(ctx context.Context, data []byte, options AddOptions) error {
+	return rc.once(ctx, func() error {
+		return rc.client.AddTorrentFromBytes(ctx, data, options)
+	})
+}
+
+// GetTorrents implements DownloadClient.
+func (rc *ResilientClient) GetTorrents(ctx context.Context, filter TorrentFilter) ([]Torrent, error) {
+	var torrents []Torrent
+	err := rc.retry(ctx, "get torrents", func() error {
+		var err error
+		torrents, err = rc.client.GetTorrents(ctx, filter)
+		return err
+	})
+
+	return torrents, err
+}
+
+// GetFiles implements DownloadClient.
+func (rc *ResilientClient) GetFiles(ctx context.Context, hash string) ([]TorrentFile, error) {
+	var files []TorrentFile
+	err := rc.retry(ctx, "get files", func() error {
+		var err error
+		files, err = rc.client.GetFiles(ctx, hash)
+		return err
+	})
+
+	return files, err
+}
+
+// AddTags implements DownloadClient.
+func (rc *ResilientClient) AddTags(ctx context.Context, hashes []string, tags ...string) error {
+	return rc.retry(ctx, "add tags", func() error {
+		return rc.client.AddTags(ctx, hashes, tags...)
+	})
+}
+
+// RemoveTags implements DownloadClient.
+func (rc *ResilientClient) RemoveTags(ctx context.Context, hashes []string, tags ...string) error {
+	return rc.retry(ctx, "remove tags", func() error {
+		return rc.client.RemoveTags(ctx, hashes, tags...)
+	})
+}
+
+// SetTags implements DownloadClient.
+func (rc *ResilientClient) SetTags(ctx context.Context, hashes []string, tags ...string) error {
+	return rc.retry(ctx, "set tags", func() error {
+		return rc.client.SetTags(ctx, hashes, tags...)
+	})
+}
+
+// SetCategory implements DownloadClient.
+func (rc *ResilientClient) SetCategory(ctx context.Context, hashes []string, category string) error {
+	return rc.retry(ctx, "set category", func() error {
+		return rc.client.SetCategory(ctx, hashes, category)
+	})
+}
+
+// RemoveTorrents implements DownloadClient. Removing a torrent that is
+// already gone is not an error, so it is retried too.
+func (rc *ResilientClient) RemoveTorrents(ctx context.Context, hashes []string, deleteFiles bool) error {
+	return rc.retry(ctx, "remove torrents", func() error {
+		return rc.client.RemoveTorrents(ctx, hashes, deleteFiles)
+	})
+}
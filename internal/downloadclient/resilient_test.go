@@ -0,0 +1,227 @@
+package downloadclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+// stubClient fails its calls with the queued errors, then succeeds.
+type stubClient struct {
+	DownloadClient
+
+	errs  []error
+	calls int
+}
+
+func (s *stubClient) next() error {
+	s.calls++
+	if len(s.errs) == 0 {
+		return nil
+	}
+
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func (s *stubClient) GetTorrents(ctx context.Context, filter TorrentFilter) ([]Torrent, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+	return []Torrent{{Hash: "abc"}}, nil
+}
+
+func (s *stubClient) AddTorrentFromURL(ctx context.Context, url string, options AddOptions) error {
+	return s.next()
+}
+
+func newTestResilientClient(stub *stubClient) *ResilientClient {
+	return NewResilientClient(stub, config.DownloadClientConfig{
+		RetryAttempts:          3,
+		RetryBackoffMillis:     1,
+		BreakerFailures:        2,
+		BreakerCooldownSeconds: 60,
+	})
+}
+
+var errBadGateway = &StatusError{StatusCode: 502, Message: "bad gateway"}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"status 502", errBadGateway, true},
+		{"status 429", &StatusError{StatusCode: 429}, true},
+		{"status 404", &StatusError{StatusCode: 404}, false},
+		{"qbittorrent 5xx", fmt.Errorf("could not get torrents: %w", errors.New("unrecoverable status: 503")), true},
+		{"qbittorrent 4xx", errors.New("unexpected status: status code: 409"), false},
+		{"connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"canceled", context.Canceled, false},
+		{"circuit open", ErrCircuitOpen, false},
+		{"other", errors.New("torrent not found"), false},
+		{"qbittorrent login", errors.New("could not get torrents: error making request: All attempts fail:\n#1: qbit re-login failed: login error; status code: 503"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsTransient(tt.err))
+		})
+	}
+}
+
+func TestResilientClient_RetriesTransientErrors(t *testing.T) {
+	stub := &stubClient{errs: []error{errBadGateway, errBadGateway}}
+	client := NewResilientClient(stub, config.DownloadClientConfig{RetryAttempts: 3, RetryBackoffMillis: 1})
+
+	torrents, err := client.GetTorrents(context.Background(), TorrentFilter{})
+	require.NoError(t, err)
+	assert.Len(t, torrents, 1)
+	assert.Equal(t, 3, stub.calls)
+	assert.Equal(t, BreakerClosed, client.Health().State)
+	assert.Zero(t, client.Health().ConsecutiveFailures)
+}
+
+func TestResilientClient_GivesUpAfterAttempts(t *testing.T) {
+	stub := &stubClient{errs: []error{errBadGateway, errBadGateway, errBadGateway, errBadGateway}}
+	client := NewResilientClient(stub, config.DownloadClientConfig{RetryAttempts: 3, RetryBackoffMillis: 1})
+
+	_, err := client.GetTorrents(context.Background(), TorrentFilter{})
+	require.ErrorIs(t, err, errBadGateway)
+	assert.Equal(t, 3, stub.calls)
+}
+
+func TestResilientClient_DoesNotRetryPermanentErrors(t *testing.T) {
+	notFound := &StatusError{StatusCode: 404, Message: "not found"}
+	stub := &stubClient{errs: []error{notFound}}
+	client := newTestResilientClient(stub)
+
+	_, err := client.GetTorrents(context.Background(), TorrentFilter{})
+	require.ErrorIs(t, err, notFound)
+	assert.Equal(t, 1, stub.calls)
+	assert.Equal(t, BreakerClosed, client.Health().State)
+}
+
+func TestResilientClient_DoesNotRetryLoginFailures(t *testing.T) {
+	// go-qbittorrent has already logged in again and retried the request
+	loginFailed := errors.New("error making request: All attempts fail:\n#1: qbit re-login failed: login error; status code: 502")
+	stub := &stubClient{errs: []error{loginFailed, loginFailed}}
+	client := newTestResilientClient(stub)
+
+	for range 2 {
+		_, err := client.GetTorrents(context.Background(), TorrentFilter{})
+		require.ErrorIs(t, err, loginFailed)
+	}
+
+	assert.Equal(t, 2, stub.calls)
+	assert.Equal(t, BreakerClosed, client.Health().State)
+	assert.Zero(t, client.Health().ConsecutiveFailures)
+}
+
+func TestResilientClient_DoesNotRetryAdds(t *testing.T) {
+	stub := &stubClient{errs: []error{errBadGateway}}
+	client := newTestResilientClient(stub)
+
+	err := client.AddTorrentFromURL(context.Background(), "https://example.com/dune.torrent", AddOptions{})
+	require.ErrorIs(t, err, errBadGateway)
+	assert.Equal(t, 1, stub.calls)
+	assert.Equal(t, 1, client.Health().ConsecutiveFailures)
+}
+
+func TestResilientClient_StopsRetryingWhenCanceled(t *testing.T) {
+	stub := &stubClient{errs: []error{errBadGateway, errBadGateway}}
+	client := NewResilientClient(stub, config.DownloadClientConfig{RetryAttempts: 3, RetryBackoffMillis: 60000})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := client.GetTorrents(ctx, TorrentFilter{})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, stub.calls)
+}
+
+func TestResilientClient_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	stub := &stubClient{errs: []error{errBadGateway, errBadGateway, errBadGateway}}
+	client := NewResilientClient(stub, config.DownloadClientConfig{
+		RetryAttempts:          1,
+		BreakerFailures:        2,
+		BreakerCooldownSeconds: 60,
+	})
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	client.breaker.now = func() time.Time { return now }
+
+	for range 2 {
+		_, err := client.GetTorrents(ctx, TorrentFilter{})
+		require.ErrorIs(t, err, errBadGateway)
+	}
+
+	health := client.Health()
+	assert.Equal(t, BreakerOpen, health.State)
+	assert.Equal(t, 2, health.ConsecutiveFailures)
+	assert.Equal(t, "bad gateway", health.LastError)
+	require.NotNil(t, health.RetryAt)
+	assert.Equal(t, now.Add(time.Minute), *health.RetryAt)
+
+	// Open: the client is not called
+	_, err := client.GetTorrents(ctx, TorrentFilter{})
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, stub.calls)
+
+	// After the cooldown a failing trial reopens it
+	now = now.Add(time.Minute)
+	_, err = client.GetTorrents(ctx, TorrentFilter{})
+	require.ErrorIs(t, err, errBadGateway)
+	assert.Equal(t, 3, stub.calls)
+	assert.Equal(t, BreakerOpen, client.Health().State)
+
+	_, err = client.GetTorrents(ctx, TorrentFilter{})
+	require.ErrorIs(t, err, ErrCircuitOpen)
+
+	// And a successful trial closes it
+	now = now.Add(time.Minute)
+	require.NoError(t, client.Ping(ctx))
+	assert.Equal(t, BreakerClosed, client.Health().State)
+	assert.Zero(t, client.Health().ConsecutiveFailures)
+}
+
+func TestCircuitBreaker_HalfOpenAllowsOneTrial(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Minute)
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Record(context.Background(), errBadGateway)
+	require.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	assert.Equal(t, BreakerHalfOpen, breaker.Health().State)
+	require.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+}
+
+func TestResilientClient_Qbittorrent(t *testing.T) {
+	ctx := context.Background()
+	qbit, fake := newTestQbittorrent(t)
+	client := NewResilientClient(qbit, config.DownloadClientConfig{RetryAttempts: 3, RetryBackoffMillis: 1})
+
+	fake.expireSession()
+	fake.failNext("/api/v2/torrents/info", 2)
+
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{})
+	require.NoError(t, err)
+	assert.Len(t, torrents, 1)
+	assert.Equal(t, BreakerClosed, client.Health().State)
+}
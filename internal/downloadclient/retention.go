@@ -0,0 +1,69 @@
+package downloadclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Reasons an imported torrent is removed or kept
+const (
+	RemoveReasonRatio    = "ratio"
+	RemoveReasonSeedTime = "seed-time"
+
+	KeepReasonNoPolicy = "no-policy"
+	KeepReasonPrivate  = "private"
+	KeepReasonSeeding  = "seeding"
+)
+
+// RetentionPolicy decides when an imported torrent may be removed. A torrent is
+// removed once it meets either threshold, but private-tracker torrents are kept
+// until they have seeded for PrivateMinSeedTime. A policy without thresholds
+// keeps every torrent.
+type RetentionPolicy struct {
+	RemoveAfterRatio    float64
+	RemoveAfterSeedTime time.Duration
+	PrivateMinSeedTime  time.Duration
+}
+
+// Enabled reports whether the policy ever removes a torrent.
+func (p RetentionPolicy) Enabled() bool {
+	return p.RemoveAfterRatio > 0 || p.RemoveAfterSeedTime > 0
+}
+
+// RetentionDecision reports whether an imported torrent should be removed and why.
+func RetentionDecision(torrent Torrent, policy RetentionPolicy) (bool, string) {
+	if !policy.Enabled() {
+		return false, KeepReasonNoPolicy
+	}
+
+	seedTime := time.Duration(torrent.SeedingTime) * time.Second
+
+	if torrent.Private && seedTime < policy.PrivateMinSeedTime {
+		return false, KeepReasonPrivate
+	}
+
+	if policy.RemoveAfterRatio > 0 && torrent.Ratio >= policy.RemoveAfterRatio {
+		return true, RemoveReasonRatio
+	}
+
+	if policy.RemoveAfterSeedTime > 0 && seedTime >= policy.RemoveAfterSeedTime {
+		return true, RemoveReasonSeedTime
+	}
+
+	return false, KeepReasonSeeding
+}
+
+// GetImportedTorrentsByCategory returns the torrents in a category that have
+// the imported tag.
+func GetImportedTorrentsByCategory(ctx context.Context, client DownloadClient, category string, importedTag string) ([]Torrent, error) {
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{
+		Category: category,
+		Tag:      importedTag,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get torrents: %w", err)
+	}
+
+	return FilterTorrentsByTag(torrents, importedTag).Filtered, nil
+}
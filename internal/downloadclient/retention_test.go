@@ -0,0 +1,47 @@
+package downloadclient
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func seededTorrent(ratio float64, seedTime time.Duration, private bool) Torrent {
+	torrent := completedTorrent("seeded")
+	torrent.Ratio = ratio
+	torrent.SeedingTime = int64(seedTime.Seconds())
+	torrent.Private = private
+	return torrent
+}
+
+func TestRetentionDecision(t *testing.T) {
+	policy := RetentionPolicy{
+		RemoveAfterRatio:    2,
+		RemoveAfterSeedTime: 14 * 24 * time.Hour,
+		PrivateMinSeedTime:  30 * 24 * time.Hour,
+	}
+
+	tests := []struct {
+		name       string
+		torrent    Torrent
+		policy     RetentionPolicy
+		wantRemove bool
+		wantReason string
+	}{
+		{name: "no policy", torrent: seededTorrent(10, 365*24*time.Hour, false), policy: RetentionPolicy{}, wantReason: KeepReasonNoPolicy},
+		{name: "still seeding", torrent: seededTorrent(1, 24*time.Hour, false), policy: policy, wantReason: KeepReasonSeeding},
+		{name: "ratio reached", torrent: seededTorrent(2.5, time.Hour, false), policy: policy, wantRemove: true, wantReason: RemoveReasonRatio},
+		{name: "seed time reached", torrent: seededTorrent(0.1, 15*24*time.Hour, false), policy: policy, wantRemove: true, wantReason: RemoveReasonSeedTime},
+		{name: "private below minimum seed time", torrent: seededTorrent(5, 20*24*time.Hour, true), policy: policy, wantReason: KeepReasonPrivate},
+		{name: "private past minimum seed time", torrent: seededTorrent(5, 31*24*time.Hour, true), policy: policy, wantRemove: true, wantReason: RemoveReasonRatio},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			remove, reason := RetentionDecision(tt.torrent, tt.policy)
+			assert.Equal(t, tt.wantRemove, remove)
+			assert.Equal(t, tt.wantReason, reason)
+		})
+	}
+}
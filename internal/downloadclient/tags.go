@@ -1,9 +1,8 @@
-package qbit
+package downloadclient
 
 import (
 	"context"
 	"fmt"
-	"strings"
 )
 
 type FilteredTorrents struct {
@@ -11,16 +10,6 @@ type FilteredTorrents struct {
 	Remaining []Torrent
 }
 
-func TagsFromTagList(tagList string) map[string]bool {
-	tags := make(map[string]bool)
-
-	for _, tag := range strings.Split(tagList, ",") {
-		tags[tag] = true
-	}
-
-	return tags
-}
-
 func FilterTorrentsByTag(torrents []Torrent, tagFilter string) FilteredTorrents {
 	result := FilteredTorrents{
 		Filtered:  make([]Torrent, 0),
@@ -28,9 +17,7 @@ func FilterTorrentsByTag(torrents []Torrent, tagFilter string) FilteredTorrents
 	}
 
 	for _, torrent := range torrents {
-		tags := TagsFromTagList(torrent.Tags)
-
-		if _, ok := tags[tagFilter]; ok {
+		if torrent.HasTag(tagFilter) {
 			result.Filtered = append(result.Filtered, torrent)
 		} else {
 			result.Remaining = append(result.Remaining, torrent)
@@ -44,7 +31,7 @@ func FilterUnimportedTorrents(torrents []Torrent) []Torrent {
 	unimported := make([]Torrent, 0, len(torrents))
 
 	for _, torrent := range torrents {
-		if torrent.Tags == "" {
+		if len(torrent.Tags) == 0 {
 			unimported = append(unimported, torrent)
 		}
 	}
@@ -52,12 +39,12 @@ func FilterUnimportedTorrents(torrents []Torrent) []Torrent {
 	return unimported
 }
 
-func TagTorrent(ctx context.Context, client QbitClient, torrent Torrent, tag string) error {
-	return client.AddTagsCtx(ctx, []string{torrent.Hash}, tag)
+func TagTorrent(ctx context.Context, client DownloadClient, torrent Torrent, tag string) error {
+	return client.AddTags(ctx, []string{torrent.Hash}, tag)
 }
 
-func GetUnimportedTorrents(ctx context.Context, qbit QbitClient) ([]Torrent, error) {
-	torrents, err := qbit.GetTorrentsCtx(ctx, TorrentFilterOptions{Tag: ""})
+func GetUnimportedTorrents(ctx context.Context, client DownloadClient) ([]Torrent, error) {
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{Tag: ""})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get torrents: %w", err)
 	}
@@ -65,8 +52,8 @@ func GetUnimportedTorrents(ctx context.Context, qbit QbitClient) ([]Torrent, err
 	return FilterUnimportedTorrents(torrents), nil
 }
 
-func GetManualInterventionTorrents(ctx context.Context, qbit QbitClient, manualInterventionTag string) ([]Torrent, error) {
-	torrents, err := qbit.GetTorrentsCtx(ctx, TorrentFilterOptions{Tag: ""})
+func GetManualInterventionTorrents(ctx context.Context, client DownloadClient, manualInterventionTag string) ([]Torrent, error) {
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{Tag: ""})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get torrents: %w", err)
 	}
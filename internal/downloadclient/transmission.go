@@ -0,0 +1,443 @@
+package downloadclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+const (
+	transmissionSessionHeader = "X-Transmission-Session-Id"
+
+	// categoryLabelPrefix marks the label that holds a torrent's category, as
+	// Transmission has labels but no categories
+	categoryLabelPrefix = "category:"
+
+	transmissionTimeout = 30 * time.Second
+)
+
+// Transmission torrent statuses
+const (
+	transmissionStopped      = 0
+	transmissionCheckWait    = 1
+	transmissionCheck        = 2
+	transmissionDownloadWait = 3
+	transmissionDownload     = 4
+	transmissionSeedWait     = 5
+	transmissionSeed         = 6
+
+	// transmissionLocalError is the error code for local errors, such as
+	// missing files; tracker errors are only warnings for Stronghold
+	transmissionLocalError = 3
+)
+
+var transmissionTorrentFields = []string{
+	"hashString", "name", "labels", "status", "error", "percentDone", "metadataPercentComplete",
+	"sizeWhenDone", "leftUntilDone", "downloadDir", "addedDate", "secondsSeeding", "uploadRatio", "isPrivate",
+	"rateDownload", "peersSendingToUs", "desiredAvailable",
+}
+
+// TransmissionClient adapts the Transmission RPC API (labels need Transmission
+// 4.0). Tags are labels, and the category is the label prefixed "category:".
+// Torrents download to Transmission's default download directory.
+type TransmissionClient struct {
+	URL      string
+	Username string
+	Password string
+
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+// Compile-time check that TransmissionClient implements DownloadClient interface.
+var _ DownloadClient = (*TransmissionClient)(nil)
+
+// NewTransmissionClient creates a client for the Transmission RPC endpoint at cfg.URL.
+func NewTransmissionClient(cfg config.TransmissionConfig) *TransmissionClient {
+	return &TransmissionClient{
+		URL:      cfg.URL,
+		Username: cfg.Username,
+		Password: cfg.Password,
+	}
+}
+
+type transmissionRequest struct {
+	Method    string `json:"method"`
+	Arguments any    `json:"arguments,omitempty"`
+}
+
+type transmissionResponse struct {
+	Result    string          `json:"result"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+type transmissionTorrent struct {
+	HashString              string   `json:"hashString"`
+	Name                    string   `json:"name"`
+	Labels                  []string `json:"labels"`
+	Status                  int      `json:"status"`
+	Error                   int      `json:"error"`
+	PercentDone             float64  `json:"percentDone"`
+	MetadataPercentComplete float64  `json:"metadataPercentComplete"`
+	SizeWhenDone            int64    `json:"sizeWhenDone"`
+	LeftUntilDone           int64    `json:"leftUntilDone"`
+	DownloadDir             string   `json:"downloadDir"`
+	AddedDate               int64    `json:"addedDate"`
+	SecondsSeeding          int64    `json:"secondsSeeding"`
+	UploadRatio             float64  `json:"uploadRatio"`
+	IsPrivate               bool     `json:"isPrivate"`
+	RateDownload            int64    `json:"rateDownload"`
+	PeersSendingToUs        int64    `json:"peersSendingToUs"`
+	DesiredAvailable        int64    `json:"desiredAvailable"`
+	Files                   []struct {
+		Name           string `json:"name"`
+		Length         int64  `json:"length"`
+		BytesCompleted int64  `json:"bytesCompleted"`
+	} `json:"files"`
+}
+
+// call runs an RPC method, fetching a new session id when Transmission asks
+// for one, and decodes the reply's arguments into result.
+func (tc *TransmissionClient) call(ctx context.Context, method string, arguments any, result any) error {
+	body, err := json.Marshal(transmissionRequest{Method: method, Arguments: arguments})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, transmissionTimeout)
+	defer cancel()
+
+	httpClient := tc.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, tc.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if tc.Username != "" {
+			req.SetBasicAuth(tc.Username, tc.Password)
+		}
+
+		tc.mu.Lock()
+		req.Header.Set(transmissionSessionHeader, tc.sessionID)
+		tc.mu.Unlock()
+
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("transmission request failed: %w", err)
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read Transmission response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusConflict && attempt == 0 {
+			tc.mu.Lock()
+			tc.sessionID = resp.Header.Get(transmissionSessionHeader)
+			tc.mu.Unlock()
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return &StatusError{
+				StatusCode: resp.StatusCode,
+				Message:    fmt.Sprintf("transmission %s returned %s: %s", method, resp.Status, strings.TrimSpace(string(data))),
+			}
+		}
+
+		var reply transmissionResponse
+		if err := json.Unmarshal(data, &reply); err != nil {
+			return fmt.Errorf("failed to decode Transmission response: %w", err)
+		}
+
+		if reply.Result != "success" {
+			return fmt.Errorf("transmission %s failed: %s", method, reply.Result)
+		}
+
+		if result == nil || len(reply.Arguments) == 0 {
+			return nil
+		}
+
+		return json.Unmarshal(reply.Arguments, result)
+	}
+}
+
+func (tc *TransmissionClient) addTorrent(ctx context.Context, arguments map[string]any, options AddOptions) error {
+	labels := slices.Clone(options.Tags)
+	if options.Category != "" {
+		labels = append(labels, categoryLabelPrefix+options.Category)
+	}
+
+	if len(labels) > 0 {
+		arguments["labels"] = labels
+	}
+	if options.Paused {
+		arguments["paused"] = true
+	}
+
+	var result struct {
+		Added     *transmissionTorrent `json:"torrent-added"`
+		Duplicate *transmissionTorrent `json:"torrent-duplicate"`
+	}
+
+	err := tc.call(ctx, "torrent-add", arguments, &result)
+	if err != nil {
+		return err
+	}
+
+	if result.Added == nil && result.Duplicate == nil {
+		return fmt.Errorf("transmission did not add the torrent")
+	}
+
+	return nil
+}
+
+// AddTorrentFromURL implements DownloadClient.
+func (tc *TransmissionClient) AddTorrentFromURL(ctx context.Context, url string, options AddOptions) error {
+	return tc.addTorrent(ctx, map[string]any{"filename": url}, options)
+}
+
+// AddTorrentFromBytes implements DownloadClient.
+func (tc *TransmissionClient) AddTorrentFromBytes(ctx context.Context, data []byte, options AddOptions) error {
+	return tc.addTorrent(ctx, map[string]any{"metainfo": base64.StdEncoding.EncodeToString(data)}, options)
+}
+
+func (tc *TransmissionClient) getTorrents(ctx context.Context, hashes []string, fields []string) ([]transmissionTorrent, error) {
+	arguments := map[string]any{"fields": fields}
+	if len(hashes) > 0 {
+		arguments["ids"] = hashes
+	}
+
+	var result struct {
+		Torrents []transmissionTorrent `json:"torrents"`
+	}
+
+	err := tc.call(ctx, "torrent-get", arguments, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Torrents, nil
+}
+
+// GetTorrents implements DownloadClient. Transmission cannot filter by label,
+// so category and tag filters are applied here.
+func (tc *TransmissionClient) GetTorrents(ctx context.Context, filter TorrentFilter) ([]Torrent, error) {
+	torrents, err := tc.getTorrents(ctx, filter.Hashes, transmissionTorrentFields)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Torrent, 0, len(torrents))
+	for _, t := range torrents {
+		torrent := transmissionToTorrent(t)
+
+		if filter.Category != "" && torrent.Category != filter.Category {
+			continue
+		}
+		if filter.Tag != "" && !torrent.HasTag(filter.Tag) {
+			continue
+		}
+
+		result = append(result, torrent)
+	}
+
+	return result, nil
+}
+
+// GetFiles implements DownloadClient.
+func (tc *TransmissionClient) GetFiles(ctx context.Context, hash string) ([]TorrentFile, error) {
+	torrents, err := tc.getTorrents(ctx, []string{hash}, []string{"hashString", "files"})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(torrents) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTorrentNotFound, hash)
+	}
+
+	files := make([]TorrentFile, 0, len(torrents[0].Files))
+	for _, file := range torrents[0].Files {
+		progress := 0.0
+		if file.Length > 0 {
+			progress = float64(file.BytesCompleted) / float64(file.Length)
+		}
+
+		files = append(files, TorrentFile{Name: file.Name, Size: file.Length, Progress: progress})
+	}
+
+	return files, nil
+}
+
+// updateLabels rewrites the labels of each torrent with update, as torrent-set
+// replaces the whole label list.
+func (tc *TransmissionClient) updateLabels(ctx context.Context, hashes []string, update func(labels []string) []string) error {
+	torrents, err := tc.getTorrents(ctx, hashes, []string{"hashString", "labels"})
+	if err != nil {
+		return err
+	}
+
+	for _, torrent := range torrents {
+		labels := update(slices.Clone(torrent.Labels))
+
+		err := tc.call(ctx, "torrent-set", map[string]any{
+			"ids":    []string{torrent.HashString},
+			"labels": labels,
+		}, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// AddTags implements DownloadClient.
+func (tc *TransmissionClient) AddTags(ctx context.Context, hashes []string, tags ...string) error {
+	return tc.updateLabels(ctx, hashes, func(labels []string) []string {
+		for _, tag := range tags {
+			if !slices.Contains(labels, tag) {
+				labels = append(labels, tag)
+			}
+		}
+		return labels
+	})
+}
+
+// RemoveTags implements DownloadClient.
+func (tc *TransmissionClient) RemoveTags(ctx context.Context, hashes []string, tags ...string) error {
+	return tc.updateLabels(ctx, hashes, func(labels []string) []string {
+		return slices.DeleteFunc(labels, func(label string) bool {
+			return slices.Contains(tags, label)
+		})
+	})
+}
+
+// SetTags implements DownloadClient. The category label is kept.
+func (tc *TransmissionClient) SetTags(ctx context.Context, hashes []string, tags ...string) error {
+	return tc.updateLabels(ctx, hashes, func(labels []string) []string {
+		result := slices.Clone(tags)
+		for _, label := range labels {
+			if strings.HasPrefix(label, categoryLabelPrefix) {
+				result = append(result, label)
+			}
+		}
+		return result
+	})
+}
+
+// SetCategory implements DownloadClient. An empty category removes it.
+func (tc *TransmissionClient) SetCategory(ctx context.Context, hashes []string, category string) error {
+	return tc.updateLabels(ctx, hashes, func(labels []string) []string {
+		labels = slices.DeleteFunc(labels, func(label string) bool {
+			return strings.HasPrefix(label, categoryLabelPrefix)
+		})
+		if category != "" {
+			labels = append(labels, categoryLabelPrefix+category)
+		}
+		return labels
+	})
+}
+
+// RemoveTorrents implements DownloadClient.
+func (tc *TransmissionClient) RemoveTorrents(ctx context.Context, hashes []string, deleteFiles bool) error {
+	return tc.call(ctx, "torrent-remove", map[string]any{
+		"ids":               hashes,
+		"delete-local-data": deleteFiles,
+	}, nil)
+}
+
+func transmissionToTorrent(t transmissionTorrent) Torrent {
+	torrent := Torrent{
+		Hash:        t.HashString,
+		Name:        t.Name,
+		Tags:        make([]string, 0, len(t.Labels)),
+		State:       transmissionState(t),
+		Progress:    t.PercentDone,
+		Size:        t.SizeWhenDone,
+		AmountLeft:  t.LeftUntilDone,
+		SavePath:    t.DownloadDir,
+		ContentPath: path.Join(t.DownloadDir, t.Name),
+		AddedOn:     t.AddedDate,
+		SeedingTime: t.SecondsSeeding,
+		Ratio:       max(t.UploadRatio, 0),
+		Private:     t.IsPrivate,
+
+		DownloadSpeed: t.RateDownload,
+		Seeds:         t.PeersSendingToUs,
+		Availability:  transmissionAvailability(t),
+	}
+
+	for _, label := range t.Labels {
+		if category, ok := strings.CutPrefix(label, categoryLabelPrefix); ok {
+			torrent.Category = category
+		} else {
+			torrent.Tags = append(torrent.Tags, label)
+		}
+	}
+
+	return torrent
+}
+
+// transmissionAvailability is the fraction of the wanted data that is either
+// downloaded or available from connected peers.
+func transmissionAvailability(t transmissionTorrent) float64 {
+	if t.SizeWhenDone <= 0 {
+		return 0
+	}
+
+	available := t.SizeWhenDone - t.LeftUntilDone + t.DesiredAvailable
+	return min(float64(available)/float64(t.SizeWhenDone), 1)
+}
+
+// transmissionState maps a Transmission status to a TorrentState.
+func transmissionState(t transmissionTorrent) TorrentState {
+	if t.Error == transmissionLocalError {
+		return StateError
+	}
+
+	complete := t.PercentDone >= 1
+
+	switch t.Status {
+	case transmissionStopped:
+		if complete {
+			return StatePausedSeeding
+		}
+		return StatePausedDownloading
+	case transmissionCheckWait, transmissionCheck:
+		return StateChecking
+	case transmissionDownloadWait:
+		return StateQueuedDownloading
+	case transmissionDownload:
+		if t.MetadataPercentComplete < 1 {
+			return StateMetadata
+		}
+		return StateDownloading
+	case transmissionSeedWait:
+		return StateQueuedSeeding
+	case transmissionSeed:
+		return StateSeeding
+	}
+
+	return StateUnknown
+}
@@ -0,0 +1,286 @@
+package downloadclient
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+)
+
+// transmissionCall is an RPC call received by the fake Transmission.
+type transmissionCall struct {
+	Method    string
+	Arguments map[string]any
+}
+
+// fakeTransmission stands in for the Transmission RPC endpoint. It holds
+// torrents by hash, requires the session id handshake and records every call.
+type fakeTransmission struct {
+	mu       sync.Mutex
+	calls    []transmissionCall
+	torrents map[string]map[string]any
+	rejected int
+}
+
+func (f *fakeTransmission) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get(transmissionSessionHeader) != "session" {
+		f.mu.Lock()
+		f.rejected++
+		f.mu.Unlock()
+
+		w.Header().Set(transmissionSessionHeader, "session")
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+
+	if user, password, _ := r.BasicAuth(); user != "admin" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var request struct {
+		Method    string         `json:"method"`
+		Arguments map[string]any `json:"arguments"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, transmissionCall{Method: request.Method, Arguments: request.Arguments})
+
+	arguments := map[string]any{}
+	switch request.Method {
+	case "torrent-get":
+		torrents := make([]map[string]any, 0)
+		for hash, torrent := range f.torrents {
+			if ids, ok := request.Arguments["ids"].([]any); ok && !slices.Contains(ids, any(hash)) {
+				continue
+			}
+			torrents = append(torrents, torrent)
+		}
+		arguments["torrents"] = torrents
+	case "torrent-set":
+		for _, id := range request.Arguments["ids"].([]any) {
+			f.torrents[id.(string)]["labels"] = request.Arguments["labels"]
+		}
+	case "torrent-add":
+		arguments["torrent-added"] = map[string]any{"hashString": "new"}
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": "success", "arguments": arguments})
+}
+
+// callsTo returns the recorded calls of method.
+func (f *fakeTransmission) callsTo(method string) []transmissionCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var calls []transmissionCall
+	for _, call := range f.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// labels returns the labels the fake holds for hash.
+func (f *fakeTransmission) labels(hash string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	labels := make([]string, 0)
+	for _, label := range f.torrents[hash]["labels"].([]any) {
+		labels = append(labels, label.(string))
+	}
+
+	return labels
+}
+
+func newTestTransmission(t *testing.T) (*TransmissionClient, *fakeTransmission) {
+	t.Helper()
+
+	fake := &fakeTransmission{
+		torrents: map[string]map[string]any{
+			"abc": {
+				"hashString": "abc", "name": "Dune", "labels": []any{"imported", "category:audiobooks"},
+				"status": transmissionSeed, "error": 0, "percentDone": 1.0, "metadataPercentComplete": 1.0,
+				"sizeWhenDone": 1024, "leftUntilDone": 0, "downloadDir": "/downloads", "addedDate": 1700000000,
+				"secondsSeeding": 3600, "uploadRatio": 1.5, "isPrivate": true,
+				"rateDownload": 0, "peersSendingToUs": 0, "desiredAvailable": 0,
+				"files": []any{map[string]any{"name": "Dune/Dune.m4b", "length": 1024, "bytesCompleted": 512}},
+			},
+			"def": {
+				"hashString": "def", "name": "Emma", "labels": []any{},
+				"status": transmissionDownload, "error": 0, "percentDone": 0.25, "metadataPercentComplete": 1.0,
+				"sizeWhenDone": 2048, "leftUntilDone": 1536, "downloadDir": "/downloads", "addedDate": 1700000100,
+				"uploadRatio": -1, "rateDownload": 4096, "peersSendingToUs": 2, "desiredAvailable": 512,
+			},
+		},
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := NewTransmissionClient(config.TransmissionConfig{URL: server.URL, Username: "admin", Password: "secret"})
+	return client, fake
+}
+
+func TestTransmissionClient_GetTorrents(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTestTransmission(t)
+
+	torrents, err := client.GetTorrents(ctx, TorrentFilter{Category: "audiobooks"})
+	require.NoError(t, err)
+	require.Len(t, torrents, 1)
+
+	assert.Equal(t, Torrent{
+		Hash:        "abc",
+		Name:        "Dune",
+		Category:    "audiobooks",
+		Tags:        []string{"imported"},
+		State:       StateSeeding,
+		Progress:    1,
+		Size:        1024,
+		SavePath:    "/downloads",
+		ContentPath: "/downloads/Dune",
+		AddedOn:     1700000000,
+		SeedingTime: 3600,
+		Ratio:       1.5,
+		Private:     true,
+
+		Availability: 1,
+	}, torrents[0])
+
+	// The session id is fetched once and reused
+	assert.Equal(t, 1, fake.rejected)
+
+	torrents, err = client.GetTorrents(ctx, TorrentFilter{Hashes: []string{"def"}})
+	require.NoError(t, err)
+	require.Len(t, torrents, 1)
+	assert.Equal(t, "Emma", torrents[0].Name)
+	assert.Equal(t, StateDownloading, torrents[0].State)
+	assert.Equal(t, 0.0, torrents[0].Ratio)
+	assert.Equal(t, int64(4096), torrents[0].DownloadSpeed)
+	assert.Equal(t, int64(2), torrents[0].Seeds)
+	assert.Equal(t, 0.5, torrents[0].Availability)
+	assert.Equal(t, 1, fake.rejected)
+
+	torrents, err = client.GetTorrents(ctx, TorrentFilter{Tag: "imported"})
+	require.NoError(t, err)
+	require.Len(t, torrents, 1)
+	assert.Equal(t, "abc", torrents[0].Hash)
+}
+
+func TestTransmissionClient_GetFiles(t *testing.T) {
+	client, _ := newTestTransmission(t)
+
+	files, err := client.GetFiles(context.Background(), "abc")
+	require.NoError(t, err)
+	assert.Equal(t, []TorrentFile{{Name: "Dune/Dune.m4b", Size: 1024, Progress: 0.5}}, files)
+
+	_, err = client.GetFiles(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrTorrentNotFound)
+}
+
+func TestTransmissionClient_AddTorrent(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTestTransmission(t)
+
+	err := client.AddTorrentFromURL(ctx, "https://example.com/dune.torrent", AddOptions{Category: "audiobooks", Tags: []string{"a"}, Paused: true})
+	require.NoError(t, err)
+
+	err = client.AddTorrentFromBytes(ctx, []byte("d8:announce0:e"), AddOptions{})
+	require.NoError(t, err)
+
+	calls := fake.callsTo("torrent-add")
+	require.Len(t, calls, 2)
+
+	assert.Equal(t, "https://example.com/dune.torrent", calls[0].Arguments["filename"])
+	assert.Equal(t, []any{"a", "category:audiobooks"}, calls[0].Arguments["labels"])
+	assert.Equal(t, true, calls[0].Arguments["paused"])
+
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("d8:announce0:e")), calls[1].Arguments["metainfo"])
+	assert.NotContains(t, calls[1].Arguments, "labels")
+}
+
+func TestTransmissionClient_Labels(t *testing.T) {
+	ctx := context.Background()
+	client, fake := newTestTransmission(t)
+
+	require.NoError(t, client.AddTags(ctx, []string{"abc", "def"}, "imported", "checked"))
+	assert.Equal(t, []string{"imported", "category:audiobooks", "checked"}, fake.labels("abc"))
+	assert.Equal(t, []string{"imported", "checked"}, fake.labels("def"))
+
+	require.NoError(t, client.RemoveTags(ctx, []string{"abc"}, "imported"))
+	assert.Equal(t, []string{"category:audiobooks", "checked"}, fake.labels("abc"))
+
+	require.NoError(t, client.SetTags(ctx, []string{"abc"}, "one", "two"))
+	assert.Equal(t, []string{"one", "two", "category:audiobooks"}, fake.labels("abc"))
+
+	require.NoError(t, client.SetCategory(ctx, []string{"abc"}, "books"))
+	assert.Equal(t, []string{"one", "two", "category:books"}, fake.labels("abc"))
+
+	require.NoError(t, client.SetCategory(ctx, []string{"abc"}, ""))
+	assert.Equal(t, []string{"one", "two"}, fake.labels("abc"))
+}
+
+func TestTransmissionClient_RemoveTorrents(t *testing.T) {
+	client, fake := newTestTransmission(t)
+
+	require.NoError(t, client.RemoveTorrents(context.Background(), []string{"abc"}, true))
+
+	calls := fake.callsTo("torrent-remove")
+	require.Len(t, calls, 1)
+	assert.Equal(t, []any{"abc"}, calls[0].Arguments["ids"])
+	assert.Equal(t, true, calls[0].Arguments["delete-local-data"])
+}
+
+func TestTransmissionClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result": "invalid or corrupt torrent file"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewTransmissionClient(config.TransmissionConfig{URL: server.URL})
+
+	err := client.AddTorrentFromBytes(context.Background(), []byte("junk"), AddOptions{})
+	assert.ErrorContains(t, err, "invalid or corrupt torrent file")
+	assert.False(t, IsTransient(err))
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(unavailable.Close)
+
+	client = NewTransmissionClient(config.TransmissionConfig{URL: unavailable.URL})
+
+	_, err = client.GetTorrents(context.Background(), TorrentFilter{})
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.True(t, IsTransient(err))
+}
+
+func TestTransmissionState(t *testing.T) {
+	assert.Equal(t, StatePausedSeeding, transmissionState(transmissionTorrent{Status: transmissionStopped, PercentDone: 1}))
+	assert.Equal(t, StatePausedDownloading, transmissionState(transmissionTorrent{Status: transmissionStopped, PercentDone: 0.5}))
+	assert.Equal(t, StateChecking, transmissionState(transmissionTorrent{Status: transmissionCheck}))
+	assert.Equal(t, StateQueuedDownloading, transmissionState(transmissionTorrent{Status: transmissionDownloadWait}))
+	assert.Equal(t, StateMetadata, transmissionState(transmissionTorrent{Status: transmissionDownload}))
+	assert.Equal(t, StateQueuedSeeding, transmissionState(transmissionTorrent{Status: transmissionSeedWait}))
+	assert.Equal(t, StateError, transmissionState(transmissionTorrent{Status: transmissionSeed, Error: transmissionLocalError}))
+}
@@ -0,0 +1,103 @@
+package downloadclient
+
+import (
+	"slices"
+	"strings"
+)
+
+// TorrentState is a torrent's state, mapped from each client's own states.
+type TorrentState string
+
+const (
+	StateMetadata           TorrentState = "metadata"
+	StateDownloading        TorrentState = "downloading"
+	StateStalledDownloading TorrentState = "stalledDownloading"
+	StateQueuedDownloading  TorrentState = "queuedDownloading"
+	StatePausedDownloading  TorrentState = "pausedDownloading"
+	StateSeeding            TorrentState = "seeding"
+	StateStalledSeeding     TorrentState = "stalledSeeding"
+	StateQueuedSeeding      TorrentState = "queuedSeeding"
+	StatePausedSeeding      TorrentState = "pausedSeeding"
+	StateChecking           TorrentState = "checking"
+	StateMoving             TorrentState = "moving"
+	StateError              TorrentState = "error"
+	StateMissingFiles       TorrentState = "missingFiles"
+	StateUnknown            TorrentState = "unknown"
+)
+
+// Torrent is a torrent as Stronghold sees it, whatever client holds it.
+type Torrent struct {
+	Hash     string
+	Name     string
+	Category string
+	Tags     []string
+	State    TorrentState
+
+	// Progress is the downloaded fraction, 0 to 1
+	Progress   float64
+	Size       int64
+	AmountLeft int64
+
+	// SavePath is the directory the torrent downloads into and ContentPath
+	// its root file or folder, both as the client sees them
+	SavePath    string
+	ContentPath string
+
+	// AddedOn is a Unix timestamp, SeedingTime is in seconds
+	AddedOn     int64
+	SeedingTime int64
+	Ratio       float64
+	Private     bool
+
+	// DownloadSpeed is in bytes per second. Seeds counts the connected peers
+	// that have the whole torrent.
+	DownloadSpeed int64
+	Seeds         int64
+
+	// Availability is how many complete copies the connected peers and the
+	// local data make up; below 1 the torrent cannot finish until more peers
+	// show up. Transmission only reports whether the rest is available, so it
+	// tops out at 1. Negative when the client does not know.
+	Availability float64
+}
+
+// HasTag reports whether the torrent has tag.
+func (t Torrent) HasTag(tag string) bool {
+	return slices.Contains(t.Tags, tag)
+}
+
+// TorrentFile is a file in a torrent. Name is relative to the torrent's save path.
+type TorrentFile struct {
+	Name     string
+	Size     int64
+	Progress float64
+}
+
+// TorrentFilter narrows GetTorrents. Empty fields match every torrent.
+type TorrentFilter struct {
+	Category string
+	Tag      string
+	Hashes   []string
+}
+
+// AddOptions are applied to a torrent when it is added.
+type AddOptions struct {
+	Category string
+	Tags     []string
+	Paused   bool
+}
+
+// ParseTagList parses a comma-separated tag list, as qBittorrent and the API
+// use, dropping blanks.
+func ParseTagList(tagList string) []string {
+	tags := make([]string, 0)
+
+	for _, tag := range strings.Split(tagList, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
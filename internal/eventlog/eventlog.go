@@ -18,6 +18,8 @@ const (
 	CategorySearch       = "search"
 	CategoryFeed         = "feed"
 	CategoryMutation     = "mutation"
+	CategoryLibrary      = "library"
+	CategoryCredential   = "credential"
 )
 
 // Event types
@@ -25,19 +27,25 @@ const (
 	// Download events
 	EventTorrentAdded           = "torrent.added"
 	EventTorrentDuplicateSkipped = "torrent.duplicate_skipped"
+	EventTorrentRemoved          = "torrent.removed"
+	EventTorrentRejected         = "torrent.rejected"
+	EventTorrentStalled          = "torrent.stalled"
 
 	// Import events
 	EventImportStarted            = "import.started"
 	EventImportCompleted          = "import.completed"
 	EventImportFailed             = "import.failed"
 	EventImportManualIntervention = "import.manual_intervention"
+	EventImportManualResolved     = "import.manual_resolved"
 
 	// Notification events
 	EventNotificationSent   = "notification.sent"
 	EventNotificationFailed = "notification.failed"
 
 	// Subscription events
-	EventSubscriptionMatched = "subscription.matched"
+	EventSubscriptionMatched      = "subscription.matched"
+	EventSubscriptionItemDrifted  = "subscription.item_drifted"
+	EventSubscriptionItemReleased = "subscription.item_released"
 
 	// Search events
 	EventSearchRequested = "search.requested"
@@ -52,6 +60,14 @@ const (
 	EventCreated = "created"
 	EventUpdated = "updated"
 	EventDeleted = "deleted"
+
+	// Library events
+	EventLibraryVerified = "library.verified"
+	EventLibraryScanned  = "library.scanned"
+
+	// Credential events
+	EventCredentialRefreshed     = "credential.refreshed"
+	EventCredentialRefreshFailed = "credential.refresh_failed"
 )
 
 // Sources
@@ -62,6 +78,11 @@ const (
 	SourceEbookImporter             = "ebook-importer"
 	SourceAudiobookImporter         = "audiobook-importer"
 	SourceAuthorSubscriptionImporter = "author-subscription-importer"
+	SourceDoctor                    = "doctor"
+	SourceTorrentCleanup            = "torrent-cleanup"
+	SourceSubscriptionReconcile     = "subscription-reconcile"
+	SourceTorrentHealth             = "torrent-health"
+	SourceBookSearch                = "book-search"
 )
 
 // Entity types
@@ -73,7 +94,9 @@ const (
 	EntityFeed         = "feed"
 	EntityNotifier     = "notifier"
 	EntityLibrary      = "library"
+	EntityImportType   = "import_type"
 	EntitySearch       = "search"
+	EntityCredential   = "credential"
 )
 
 // Log creates an event log entry. It is fire-and-forget: errors are logged but never returned.
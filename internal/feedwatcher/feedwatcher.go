@@ -15,9 +15,9 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/bobbyrward/stronghold/internal/notifications"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 )
 
 type FeedWatcher struct{}
@@ -186,9 +186,9 @@ func NewFeedWatcher() *FeedWatcher {
 }
 
 func (fw *FeedWatcher) Run(ctx context.Context, db *gorm.DB) error {
-	client, err := qbit.CreateClient()
+	client, err := downloadclient.CreateClient()
 	if err != nil {
-		return fmt.Errorf("failed to create qBittorrent client: %w", err)
+		return fmt.Errorf("failed to create download client: %w", err)
 	}
 
 	config.Config.FeedWatcher.Preprocess()
@@ -209,7 +209,7 @@ func (fw *FeedWatcher) Run(ctx context.Context, db *gorm.DB) error {
 	return nil
 }
 
-func (fw *FeedWatcher) watchFeed(ctx context.Context, feedConfig *config.FeedWatcherConfigFeed, qbitClient qbit.QbitClient, db *gorm.DB) error {
+func (fw *FeedWatcher) watchFeed(ctx context.Context, feedConfig *config.FeedWatcherConfigFeed, downloadClient downloadclient.DownloadClient, db *gorm.DB) error {
 	parser := gofeed.NewParser()
 
 	feed, err := parser.ParseURL(feedConfig.URL)
@@ -279,16 +279,11 @@ func (fw *FeedWatcher) watchFeed(ctx context.Context, feedConfig *config.FeedWat
 				slog.String("Description", entry.Description),
 			)
 
-			addResponse, err := qbitClient.AddTorrentFromUrlCtx(
-				ctx,
-				entry.Link,
-				map[string]string{
-					"autoTMM":  "true",
-					"category": filter.Category,
-				},
-			)
+			err := downloadClient.AddTorrentFromURL(ctx, entry.Link, downloadclient.AddOptions{
+				Category: filter.Category,
+			})
 			if err != nil {
-				slog.ErrorContext(ctx, "Failed to add torrent to qBittorrent",
+				slog.ErrorContext(ctx, "Failed to add torrent to download client",
 					slog.String("link", entry.Link),
 					slog.Any("err", err),
 					slog.String("guid", entry.Guid),
@@ -298,19 +293,6 @@ func (fw *FeedWatcher) watchFeed(ctx context.Context, feedConfig *config.FeedWat
 					),
 				)
 			}
-			if addResponse.FailureCount != 0 {
-				slog.ErrorContext(ctx, "Failed to add torrent to qBittorrent",
-					slog.String("link", entry.Link),
-					slog.String("guid", entry.Guid),
-					slog.Int64("failureCount", addResponse.FailureCount),
-					slog.Int64("pendingCount", addResponse.PendingCount),
-					slog.Int64("successCount", addResponse.SuccessCount),
-					slog.Group("feedFilter",
-						slog.String("feedName", feedConfig.Name),
-						slog.String("filterName", filter.Name),
-					),
-				)
-			}
 
 			pubDate := time.Now()
 			if item.PublishedParsed != nil {
@@ -10,10 +10,12 @@ import (
 
 	"github.com/mmcdole/gofeed"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/models"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 	"github.com/bobbyrward/stronghold/internal/torrentutil"
 )
 
@@ -23,7 +25,7 @@ const (
 	MediaTypeAudiobook = "audiobook"
 )
 
-// AuthorSubscriptionCategory is the qBittorrent category used for all author subscription downloads.
+// AuthorSubscriptionCategory is the download client category used for all author subscription downloads.
 const AuthorSubscriptionCategory = "author-subscriptions"
 
 // extractIDFromGUID extracts the numeric ID from a GUID URL.
@@ -45,21 +47,27 @@ func determineBookTypeName(category string) string {
 	return MediaTypeEbook
 }
 
+// defaultRejectedReleaseDays is how long a rejected release is skipped when
+// feedWatcher2.rejectedReleaseDays is unset.
+const defaultRejectedReleaseDays = 7
+
 // FeedWatcher2 monitors RSS feeds and downloads torrents for subscribed authors.
 type FeedWatcher2 struct {
 	db                *gorm.DB
-	qbitClient        qbit.QbitClient
+	downloadClient    downloadclient.DownloadClient
 	torrentDownloader *torrentutil.TorrentDownloader
 	authorMatcher     *AuthorMatcher
+	releaseRules      config.FeedWatcher2Config
 }
 
 // NewFeedWatcher2 creates a new FeedWatcher2 instance.
-func NewFeedWatcher2(db *gorm.DB, qbitClient qbit.QbitClient, httpProxy, httpsProxy string) *FeedWatcher2 {
+func NewFeedWatcher2(db *gorm.DB, downloadClient downloadclient.DownloadClient, httpProxy, httpsProxy string, releaseRules config.FeedWatcher2Config) *FeedWatcher2 {
 	return &FeedWatcher2{
 		db:                db,
-		qbitClient:        qbitClient,
+		downloadClient:    downloadClient,
 		torrentDownloader: torrentutil.NewTorrentDownloader(httpProxy, httpsProxy),
 		authorMatcher:     NewAuthorMatcher(db),
+		releaseRules:      releaseRules,
 	}
 }
 
@@ -73,6 +81,8 @@ func (fw *FeedWatcher2) Run(ctx context.Context) error {
 		return fmt.Errorf("failed to load subscriptions: %w", err)
 	}
 
+	fw.pruneRejectedReleases(ctx)
+
 	// Query all feeds from database
 	var feeds []models.Feed
 	result := fw.db.Find(&feeds)
@@ -103,6 +113,29 @@ func (fw *FeedWatcher2) Run(ctx context.Context) error {
 	return nil
 }
 
+// rejectedReleaseCutoff returns the time before which a rejected release has
+// expired and is checked again.
+func (fw *FeedWatcher2) rejectedReleaseCutoff() time.Time {
+	days := fw.releaseRules.RejectedReleaseDays
+	if days <= 0 {
+		days = defaultRejectedReleaseDays
+	}
+	return time.Now().AddDate(0, 0, -days)
+}
+
+// pruneRejectedReleases deletes expired rejected releases. Errors are logged,
+// since expired rows are ignored by the rejection check anyway.
+func (fw *FeedWatcher2) pruneRejectedReleases(ctx context.Context) {
+	result := fw.db.Where("created_at < ?", fw.rejectedReleaseCutoff()).Delete(&models.RejectedRelease{})
+	if result.Error != nil {
+		slog.WarnContext(ctx, "Failed to prune rejected releases", slog.Any("error", result.Error))
+		return
+	}
+	if result.RowsAffected > 0 {
+		slog.InfoContext(ctx, "Pruned expired rejected releases", slog.Int64("count", result.RowsAffected))
+	}
+}
+
 // watchFeed processes a single RSS feed.
 func (fw *FeedWatcher2) watchFeed(ctx context.Context, feed *models.Feed) error {
 	slog.InfoContext(ctx, "Processing feed",
@@ -200,6 +233,20 @@ func (fw *FeedWatcher2) processItem(ctx context.Context, feed *models.Feed, item
 		return fmt.Errorf("failed to check for existing item: %w", result.Error)
 	}
 
+	// Releases rejected on an earlier poll are not fetched again
+	var rejected models.RejectedRelease
+	result = fw.db.Where("booksearch_id = ? AND created_at >= ?", booksearchID, fw.rejectedReleaseCutoff()).First(&rejected)
+	if result.Error == nil {
+		slog.DebugContext(ctx, "Release already rejected, skipping",
+			slog.String("booksearch_id", booksearchID),
+			slog.String("title", entry.Title),
+			slog.String("reason", rejected.Reason))
+		return nil
+	}
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check for rejected release: %w", result.Error)
+	}
+
 	// Determine book type from category
 	bookTypeName := determineBookTypeName(entry.Category)
 	var bookType models.BookType
@@ -212,33 +259,75 @@ func (fw *FeedWatcher2) processItem(ctx context.Context, feed *models.Feed, item
 		slog.String("category", entry.Category),
 		slog.String("book_type", bookTypeName))
 
-	// Download torrent and extract hash
-	hash, err := fw.torrentDownloader.DownloadAndHash(ctx, entry.Link)
+	// Download the torrent, or parse the magnet link, to learn its hash
+	release, err := fw.torrentDownloader.Fetch(ctx, entry.Link)
 	if err != nil {
-		return fmt.Errorf("failed to download torrent: %w", err)
+		return err
+	}
+	hash := release.Hash
+
+	// The same torrent may be listed under another ID
+	result = fw.db.Where("torrent_hash = ?", hash).First(&existingItem)
+	if result.Error == nil {
+		slog.InfoContext(ctx, "Torrent already downloaded, skipping",
+			slog.String("hash", hash),
+			slog.String("title", entry.Title))
+		eventlog.Log(fw.db, eventlog.CategoryDownload, eventlog.EventTorrentDuplicateSkipped, eventlog.SourceFeedwatcher2,
+			eventlog.EntityTorrent, hash,
+			fmt.Sprintf("Duplicate skipped: %s", entry.Title),
+			map[string]string{"title": entry.Title, "booksearch_id": booksearchID, "author": subscription.Author.Name, "hash": hash})
+		return nil
+	}
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check for existing item: %w", result.Error)
 	}
 
-	slog.InfoContext(ctx, "Downloaded torrent",
-		slog.String("hash", hash),
-		slog.String("title", entry.Title))
+	// Magnet links carry no file list, so only .torrent files can be checked
+	if release.Metainfo != nil {
+		if reason := checkRelease(release.Metainfo, bookTypeName, fw.releaseRules); reason != "" {
+			slog.InfoContext(ctx, "Rejected release",
+				slog.String("title", entry.Title),
+				slog.String("hash", hash),
+				slog.String("reason", reason))
+			eventlog.Log(fw.db, eventlog.CategoryDownload, eventlog.EventTorrentRejected, eventlog.SourceFeedwatcher2,
+				eventlog.EntityTorrent, hash,
+				fmt.Sprintf("Rejected: %s: %s", entry.Title, reason),
+				map[string]any{
+					"title":         entry.Title,
+					"author":        subscription.Author.Name,
+					"booksearch_id": booksearchID,
+					"reason":        reason,
+					"size":          release.Metainfo.Size,
+					"file_count":    len(release.Metainfo.Files),
+				})
+
+			// An expired rejection that was not pruned yet is replaced
+			result = fw.db.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "booksearch_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"created_at", "updated_at", "torrent_hash", "title", "reason"}),
+			}).Create(&models.RejectedRelease{
+				BooksearchID: booksearchID,
+				TorrentHash:  hash,
+				Title:        entry.Title,
+				Reason:       reason,
+			})
+			if result.Error != nil {
+				return fmt.Errorf("failed to record rejected release: %w", result.Error)
+			}
+
+			return nil
+		}
+	}
 
-	// Add torrent to qBittorrent
-	addResponse, err := fw.qbitClient.AddTorrentFromUrlCtx(
-		ctx,
-		entry.Link,
-		map[string]string{
-			"autoTMM":  "true",
-			"category": AuthorSubscriptionCategory,
-		},
-	)
+	// Upload the downloaded bytes, so the stored hash matches what the client received
+	err = release.AddTo(ctx, fw.downloadClient, downloadclient.AddOptions{
+		Category: AuthorSubscriptionCategory,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to add torrent to qBittorrent: %w", err)
-	}
-	if addResponse.FailureCount != 0 {
-		return fmt.Errorf("failed to add torrent to qBittorrent")
+		return fmt.Errorf("failed to add torrent to download client: %w", err)
 	}
 
-	slog.InfoContext(ctx, "Added torrent to qBittorrent",
+	slog.InfoContext(ctx, "Added torrent to the download client",
 		slog.String("title", entry.Title),
 		slog.String("category", AuthorSubscriptionCategory),
 		slog.String("hash", hash))
@@ -263,6 +352,7 @@ func (fw *FeedWatcher2) processItem(ctx context.Context, feed *models.Feed, item
 		BooksearchID:         booksearchID,
 		Title:                entry.Title,
 		DownloadedAt:         time.Now(),
+		DownloadLink:         entry.Link,
 	}
 
 	result = fw.db.Create(&subscriptionItem)
@@ -270,7 +360,7 @@ func (fw *FeedWatcher2) processItem(ctx context.Context, feed *models.Feed, item
 		slog.ErrorContext(ctx, "Failed to create subscription item record",
 			slog.String("hash", hash),
 			slog.Any("error", result.Error))
-		// Don't return error - torrent was already added to qBittorrent
+		// Don't return error - torrent was already added to the download client
 	}
 
 	// Send notification
@@ -15,9 +15,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/bobbyrward/stronghold/internal/notifications"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 	"github.com/bobbyrward/stronghold/internal/testutil"
 	"github.com/bobbyrward/stronghold/internal/torrentutil"
 )
@@ -44,10 +45,10 @@ func createTestTorrentBytes(t *testing.T, name string) []byte {
 }
 
 // createTestFeedWatcher creates a FeedWatcher2 for testing with a no-proxy TorrentDownloader.
-func createTestFeedWatcher(db *gorm.DB, qbitClient qbit.QbitClient) *FeedWatcher2 {
+func createTestFeedWatcher(db *gorm.DB, downloadClient downloadclient.DownloadClient) *FeedWatcher2 {
 	return &FeedWatcher2{
 		db:                db,
-		qbitClient:        qbitClient,
+		downloadClient:    downloadClient,
 		torrentDownloader: torrentutil.NewTestTorrentDownloader(),
 		authorMatcher:     NewAuthorMatcher(db),
 	}
@@ -135,14 +136,14 @@ func TestWatchFeed_NoSubscriptions(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create mock qBit client
-	mockQbit := &testutil.MockQbitClient{}
+	mockClient := &testutil.MockDownloadClient{}
 
-	fw := createTestFeedWatcher(db, mockQbit)
+	fw := createTestFeedWatcher(db, mockClient)
 	err = fw.Run(context.Background())
 	require.NoError(t, err)
 
 	// No torrents should be added since no subscriptions match
-	assert.Empty(t, mockQbit.AddTorrentFromUrlCtxCalls)
+	assert.Empty(t, mockClient.AddTorrentCalls)
 }
 
 func TestWatchFeed_MatchByAuthorName(t *testing.T) {
@@ -185,15 +186,15 @@ func TestWatchFeed_MatchByAuthorName(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create mock qBit client
-	mockQbit := &testutil.MockQbitClient{}
+	mockClient := &testutil.MockDownloadClient{}
 
-	fw := createTestFeedWatcher(db, mockQbit)
+	fw := createTestFeedWatcher(db, mockClient)
 	err = fw.Run(context.Background())
 	require.NoError(t, err)
 
 	// Should have added one torrent
-	require.Len(t, mockQbit.AddTorrentFromUrlCtxCalls, 1)
-	assert.Equal(t, AuthorSubscriptionCategory, mockQbit.AddTorrentFromUrlCtxCalls[0].Options["category"])
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Equal(t, AuthorSubscriptionCategory, mockClient.AddTorrentCalls[0].Options.Category)
 
 	// Should have created subscription item with extracted ID
 	var items []models.AuthorSubscriptionItem
@@ -201,6 +202,197 @@ func TestWatchFeed_MatchByAuthorName(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, items, 1)
 	assert.Equal(t, "1001", items[0].BooksearchID)
+	assert.Equal(t, torrentServer.URL+"/mistborn.torrent", items[0].DownloadLink)
+}
+
+func TestWatchFeed_TorrentDownloadFails(t *testing.T) {
+	db := setupIntegrationTestDB(t)
+
+	scope := createTestScope(t, db, "personal")
+	createTestTorrentCategory(t, db, "personal-audiobooks", scope.ID, "audiobook")
+
+	author := models.Author{Name: "Brandon Sanderson"}
+	err := db.Create(&author).Error
+	require.NoError(t, err)
+
+	subscription := models.AuthorSubscription{AuthorID: author.ID, ScopeID: scope.ID}
+	err = db.Create(&subscription).Error
+	require.NoError(t, err)
+
+	// Torrent link has expired
+	torrentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer torrentServer.Close()
+
+	rssServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		feed := createMockRSSFeed([]mockFeedItem{
+			{GUID: "https://www.example.net/t/1001", Title: "Mistborn", Link: torrentServer.URL + "/mistborn.torrent", Author: "Brandon Sanderson", Category: "Audiobooks - Fantasy"},
+		})
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(feed))
+	}))
+	defer rssServer.Close()
+
+	feed := models.Feed{Name: "Test Feed", URL: rssServer.URL}
+	err = db.Create(&feed).Error
+	require.NoError(t, err)
+
+	mockClient := &testutil.MockDownloadClient{}
+
+	fw := createTestFeedWatcher(db, mockClient)
+	_ = fw.Run(context.Background())
+
+	// Nothing is handed to the download client or recorded
+	assert.Empty(t, mockClient.AddTorrentCalls)
+
+	var count int64
+	err = db.Model(&models.AuthorSubscriptionItem{}).Count(&count).Error
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestWatchFeed_RejectsReleaseWithoutBookFiles(t *testing.T) {
+	db := setupIntegrationTestDB(t)
+
+	scope := createTestScope(t, db, "personal")
+	createTestTorrentCategory(t, db, "personal-audiobooks", scope.ID, "audiobook")
+
+	author := models.Author{Name: "Brandon Sanderson"}
+	err := db.Create(&author).Error
+	require.NoError(t, err)
+
+	subscription := models.AuthorSubscription{AuthorID: author.ID, ScopeID: scope.ID}
+	err = db.Create(&subscription).Error
+	require.NoError(t, err)
+
+	// An audiobook release holding only a PDF
+	torrentData := createTestTorrentBytes(t, "mistborn.pdf")
+	fetches := 0
+	torrentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		w.Header().Set("Content-Type", "application/x-bittorrent")
+		_, _ = w.Write(torrentData)
+	}))
+	defer torrentServer.Close()
+
+	rssServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		feed := createMockRSSFeed([]mockFeedItem{
+			{GUID: "https://www.example.net/t/1001", Title: "Mistborn", Link: torrentServer.URL + "/mistborn.torrent", Author: "Brandon Sanderson", Category: "Audiobooks - Fantasy"},
+		})
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(feed))
+	}))
+	defer rssServer.Close()
+
+	feed := models.Feed{Name: "Test Feed", URL: rssServer.URL}
+	err = db.Create(&feed).Error
+	require.NoError(t, err)
+
+	mockClient := &testutil.MockDownloadClient{}
+
+	fw := createTestFeedWatcher(db, mockClient)
+
+	// The second poll skips the rejected release without fetching it again
+	for range 2 {
+		err = fw.Run(context.Background())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 1, fetches)
+	assert.Empty(t, mockClient.AddTorrentCalls)
+
+	var count int64
+	err = db.Model(&models.AuthorSubscriptionItem{}).Count(&count).Error
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	var rejected []models.RejectedRelease
+	err = db.Find(&rejected).Error
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, "1001", rejected[0].BooksearchID)
+	assert.Equal(t, "Mistborn", rejected[0].Title)
+	assert.Equal(t, "no audiobook files in torrent", rejected[0].Reason)
+
+	var events []models.EventLog
+	err = db.Where("event_type = ?", eventlog.EventTorrentRejected).Find(&events).Error
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0].Summary, "no audiobook files in torrent")
+
+	// An expired rejection is pruned and the release is checked again
+	err = db.Model(&rejected[0]).UpdateColumn("created_at", time.Now().AddDate(0, 0, -defaultRejectedReleaseDays-1)).Error
+	require.NoError(t, err)
+
+	err = fw.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, fetches)
+
+	rejected = nil
+	err = db.Find(&rejected).Error
+	require.NoError(t, err)
+	require.Len(t, rejected, 1)
+	assert.WithinDuration(t, time.Now(), rejected[0].CreatedAt, time.Minute)
+}
+
+func TestWatchFeed_MagnetLink(t *testing.T) {
+	db := setupIntegrationTestDB(t)
+
+	scope := createTestScope(t, db, "personal")
+	createTestTorrentCategory(t, db, "personal-audiobooks", scope.ID, "audiobook")
+
+	author := models.Author{Name: "Brandon Sanderson"}
+	err := db.Create(&author).Error
+	require.NoError(t, err)
+
+	subscription := models.AuthorSubscription{AuthorID: author.ID, ScopeID: scope.ID}
+	err = db.Create(&subscription).Error
+	require.NoError(t, err)
+
+	// The same torrent listed twice under different IDs, once with a base32 hash
+	hash := "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"
+	magnetURI := "magnet:?xt=urn:btih:" + hash
+	rssServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		feed := createMockRSSFeed([]mockFeedItem{
+			{GUID: "https://www.example.net/t/1001", Title: "Mistborn", Link: magnetURI, Author: "Brandon Sanderson", Category: "Audiobooks - Fantasy"},
+			{GUID: "https://www.example.net/t/1002", Title: "Mistborn (relisted)", Link: "magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK", Author: "Brandon Sanderson", Category: "Audiobooks - Fantasy"},
+		})
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(feed))
+	}))
+	defer rssServer.Close()
+
+	feed := models.Feed{Name: "Test Feed", URL: rssServer.URL}
+	err = db.Create(&feed).Error
+	require.NoError(t, err)
+
+	mockClient := &testutil.MockDownloadClient{}
+
+	fw := createTestFeedWatcher(db, mockClient)
+	err = fw.Run(context.Background())
+	require.NoError(t, err)
+
+	// The magnet link is handed to the client once
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Equal(t, magnetURI, mockClient.AddTorrentCalls[0].URL)
+	assert.Nil(t, mockClient.AddTorrentCalls[0].Data)
+	assert.Equal(t, AuthorSubscriptionCategory, mockClient.AddTorrentCalls[0].Options.Category)
+
+	var items []models.AuthorSubscriptionItem
+	err = db.Find(&items).Error
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, hash, items[0].TorrentHash)
+	assert.Equal(t, "1001", items[0].BooksearchID)
+
+	var skipped int64
+	err = db.Model(&models.EventLog{}).
+		Where("event_type = ? AND entity_id = ?", eventlog.EventTorrentDuplicateSkipped, hash).
+		Count(&skipped).Error
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), skipped)
 }
 
 func TestWatchFeed_MatchByAlias(t *testing.T) {
@@ -246,15 +438,15 @@ func TestWatchFeed_MatchByAlias(t *testing.T) {
 	err = db.Create(&feed).Error
 	require.NoError(t, err)
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockClient := &testutil.MockDownloadClient{}
 
-	fw := createTestFeedWatcher(db, mockQbit)
+	fw := createTestFeedWatcher(db, mockClient)
 	err = fw.Run(context.Background())
 	require.NoError(t, err)
 
 	// Should match via alias
-	require.Len(t, mockQbit.AddTorrentFromUrlCtxCalls, 1)
-	assert.Equal(t, AuthorSubscriptionCategory, mockQbit.AddTorrentFromUrlCtxCalls[0].Options["category"])
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Equal(t, AuthorSubscriptionCategory, mockClient.AddTorrentCalls[0].Options.Category)
 }
 
 func TestWatchFeed_Deduplication(t *testing.T) {
@@ -299,14 +491,14 @@ func TestWatchFeed_Deduplication(t *testing.T) {
 	err = db.Create(&feed).Error
 	require.NoError(t, err)
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockClient := &testutil.MockDownloadClient{}
 
-	fw := createTestFeedWatcher(db, mockQbit)
+	fw := createTestFeedWatcher(db, mockClient)
 	err = fw.Run(context.Background())
 	require.NoError(t, err)
 
 	// Should NOT add torrent since ID already exists
-	assert.Empty(t, mockQbit.AddTorrentFromUrlCtxCalls)
+	assert.Empty(t, mockClient.AddTorrentCalls)
 
 	// Should still only have one item
 	var items []models.AuthorSubscriptionItem
@@ -347,13 +539,13 @@ func TestWatchFeed_CorrectCategory_PersonalAudiobook(t *testing.T) {
 	err = db.Create(&feed).Error
 	require.NoError(t, err)
 
-	mockQbit := &testutil.MockQbitClient{}
-	fw := createTestFeedWatcher(db, mockQbit)
+	mockClient := &testutil.MockDownloadClient{}
+	fw := createTestFeedWatcher(db, mockClient)
 	err = fw.Run(context.Background())
 	require.NoError(t, err)
 
-	require.Len(t, mockQbit.AddTorrentFromUrlCtxCalls, 1)
-	assert.Equal(t, AuthorSubscriptionCategory, mockQbit.AddTorrentFromUrlCtxCalls[0].Options["category"])
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Equal(t, AuthorSubscriptionCategory, mockClient.AddTorrentCalls[0].Options.Category)
 }
 
 func TestWatchFeed_CorrectCategory_FamilyEbook(t *testing.T) {
@@ -388,13 +580,13 @@ func TestWatchFeed_CorrectCategory_FamilyEbook(t *testing.T) {
 	err = db.Create(&feed).Error
 	require.NoError(t, err)
 
-	mockQbit := &testutil.MockQbitClient{}
-	fw := createTestFeedWatcher(db, mockQbit)
+	mockClient := &testutil.MockDownloadClient{}
+	fw := createTestFeedWatcher(db, mockClient)
 	err = fw.Run(context.Background())
 	require.NoError(t, err)
 
-	require.Len(t, mockQbit.AddTorrentFromUrlCtxCalls, 1)
-	assert.Equal(t, AuthorSubscriptionCategory, mockQbit.AddTorrentFromUrlCtxCalls[0].Options["category"])
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Equal(t, AuthorSubscriptionCategory, mockClient.AddTorrentCalls[0].Options.Category)
 }
 
 // E2E Test
@@ -477,24 +669,28 @@ func TestFeedWatcher2_E2E(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create mock qBit client
-	mockQbit := &testutil.MockQbitClient{}
+	mockClient := &testutil.MockDownloadClient{}
 
 	// Run feedwatcher2
-	fw := createTestFeedWatcher(db, mockQbit)
+	fw := createTestFeedWatcher(db, mockClient)
 	err = fw.Run(context.Background())
 	require.NoError(t, err)
 
 	// Verify torrent added to qBittorrent with correct category
-	require.Len(t, mockQbit.AddTorrentFromUrlCtxCalls, 1)
-	assert.Equal(t, AuthorSubscriptionCategory, mockQbit.AddTorrentFromUrlCtxCalls[0].Options["category"])
-	assert.Contains(t, mockQbit.AddTorrentFromUrlCtxCalls[0].URL, torrentServer.URL)
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Equal(t, AuthorSubscriptionCategory, mockClient.AddTorrentCalls[0].Options.Category)
+	// The downloaded bytes are uploaded rather than the client fetching the URL again
+	assert.Empty(t, mockClient.AddTorrentCalls[0].URL)
+	assert.Equal(t, torrentData, mockClient.AddTorrentCalls[0].Data)
+	expectedHash, err := torrentutil.ExtractInfoHash(torrentData)
+	require.NoError(t, err)
 
 	// Verify AuthorSubscriptionItem created with extracted ID
 	var items []models.AuthorSubscriptionItem
 	err = db.Find(&items).Error
 	require.NoError(t, err)
 	require.Len(t, items, 1)
-	assert.NotEmpty(t, items[0].TorrentHash)
+	assert.Equal(t, expectedHash, items[0].TorrentHash)
 	assert.Equal(t, "1006", items[0].BooksearchID)
 	assert.Equal(t, subscription.ID, items[0].AuthorSubscriptionID)
 
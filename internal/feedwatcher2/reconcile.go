@@ -0,0 +1,401 @@
+package feedwatcher2
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
+)
+
+// Subscription item statuses recorded by reconciliation
+const (
+	ItemStatusOK            = "ok"
+	ItemStatusImported      = "imported"
+	ItemStatusMissing       = "missing"
+	ItemStatusErrored       = "errored"
+	ItemStatusStalled       = "stalled"
+	ItemStatusRecategorized = "recategorized"
+)
+
+// Reconcile outcomes for drifted items
+const (
+	OutcomeMarked   = "marked"
+	OutcomeReadded  = "readded"
+	OutcomeReleased = "released"
+	OutcomeSkipped  = "skipped"
+)
+
+// Drift is a subscription item whose torrent is not downloading as expected,
+// and what reconciliation did about it.
+type Drift struct {
+	Item    models.AuthorSubscriptionItem
+	Status  string
+	Outcome string
+	Reason  string
+}
+
+// Reconciler compares AuthorSubscriptionItem rows against the download
+// client. Items feedwatcher2 believes it downloaded but whose torrents are
+// gone, errored, stalled or moved to another category are marked, and
+// optionally re-added or released so the next feed hit retries them.
+type Reconciler struct {
+	db                *gorm.DB
+	downloadClient    downloadclient.DownloadClient
+	torrentDownloader *torrentutil.TorrentDownloader
+	config            config.ReconcileConfig
+
+	// DryRun logs what would change without touching the database or the client
+	DryRun bool
+}
+
+func NewReconciler(db *gorm.DB, downloadClient downloadclient.DownloadClient, httpProxy, httpsProxy string, reconcileConfig config.ReconcileConfig) *Reconciler {
+	return &Reconciler{
+		db:                db,
+		downloadClient:    downloadClient,
+		torrentDownloader: torrentutil.NewTorrentDownloader(httpProxy, httpsProxy),
+		config:            reconcileConfig,
+	}
+}
+
+// Run checks every subscription item that has not been imported yet and
+// returns the ones that drifted.
+func (r *Reconciler) Run(ctx context.Context) ([]Drift, error) {
+	switch r.config.Action {
+	case "", config.ReconcileActionReadd, config.ReconcileActionRelease:
+	default:
+		return nil, fmt.Errorf("unknown reconcile action %q", r.config.Action)
+	}
+
+	slog.InfoContext(ctx, "Reconciling subscription items...",
+		slog.String("action", r.config.Action),
+		slog.Bool("dryRun", r.DryRun))
+
+	var items []models.AuthorSubscriptionItem
+	if err := r.db.Where("status <> ?", ItemStatusImported).Order("id").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to load subscription items: %w", err)
+	}
+
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]string, 0, len(items))
+	for _, item := range items {
+		hashes = append(hashes, item.TorrentHash)
+	}
+
+	imported, err := r.importedHashes(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	stalledSince, err := r.stalledSince(hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	torrents, err := r.downloadClient.GetTorrents(ctx, downloadclient.TorrentFilter{Hashes: hashes})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get torrents: %w", err)
+	}
+
+	byHash := make(map[string]downloadclient.Torrent, len(torrents))
+	for _, torrent := range torrents {
+		byHash[torrent.Hash] = torrent
+	}
+
+	now := time.Now()
+	var drifts []Drift
+
+	for _, item := range items {
+		var status, reason string
+		if imported[item.TorrentHash] {
+			// Retention cleanup removes imported torrents, so they are never drift
+			status = ItemStatusImported
+		} else {
+			torrent, found := byHash[item.TorrentHash]
+			status, reason = r.classify(torrent, found, stalledSince[item.TorrentHash], now)
+		}
+
+		if status == ItemStatusOK || status == ItemStatusImported {
+			r.setStatus(ctx, &item, status, now)
+			continue
+		}
+
+		drift := r.resolve(ctx, item, byHash[item.TorrentHash], status, reason, now)
+		drifts = append(drifts, drift)
+	}
+
+	slog.InfoContext(ctx, "Subscription reconciliation finished",
+		slog.Int("checked", len(items)),
+		slog.Int("drifted", len(drifts)),
+		slog.Bool("dryRun", r.DryRun))
+
+	return drifts, nil
+}
+
+// importedHashes returns which hashes an importer has finished with.
+func (r *Reconciler) importedHashes(hashes []string) (map[string]bool, error) {
+	imported := make(map[string]bool)
+
+	var jobs []models.ImportJob
+	err := r.db.Where("torrent_hash IN ? AND status = ?", hashes, common.ImportJobCompleted).Find(&jobs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load import jobs: %w", err)
+	}
+	for _, job := range jobs {
+		imported[job.TorrentHash] = true
+	}
+
+	var importedItems []models.ImportedItem
+	if err := r.db.Where("torrent_hash IN ?", hashes).Find(&importedItems).Error; err != nil {
+		return nil, fmt.Errorf("failed to load imported items: %w", err)
+	}
+	for _, importedItem := range importedItems {
+		imported[importedItem.TorrentHash] = true
+	}
+
+	return imported, nil
+}
+
+// stalledSince returns when the torrent health monitor first saw each stalled
+// torrent. Torrents that are making progress have no entry.
+func (r *Reconciler) stalledSince(hashes []string) (map[string]time.Time, error) {
+	var rows []models.TorrentHealth
+	if err := r.db.Where("torrent_hash IN ? AND stalled_since IS NOT NULL", hashes).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load torrent health: %w", err)
+	}
+
+	stalledSince := make(map[string]time.Time, len(rows))
+	for _, row := range rows {
+		stalledSince[row.TorrentHash] = *row.StalledSince
+	}
+
+	return stalledSince, nil
+}
+
+// classify returns an item's status from its torrent, and why it drifted. A
+// torrent is stalled once the torrent health monitor has seen it stalled for
+// longer than StalledAfterHours; stalledSince is zero when it has not.
+func (r *Reconciler) classify(torrent downloadclient.Torrent, found bool, stalledSince time.Time, now time.Time) (string, string) {
+	if !found {
+		return ItemStatusMissing, "torrent not found in the download client"
+	}
+
+	importedTag := config.Config.Importers.ImportedTag
+	if importedTag != "" && slices.Contains(torrent.Tags, importedTag) {
+		return ItemStatusImported, ""
+	}
+
+	switch torrent.State {
+	case downloadclient.StateError, downloadclient.StateMissingFiles:
+		return ItemStatusErrored, fmt.Sprintf("torrent is in state %s", torrent.State)
+	}
+
+	if torrent.Category != AuthorSubscriptionCategory {
+		return ItemStatusRecategorized, fmt.Sprintf("torrent moved to category %q", torrent.Category)
+	}
+
+	if r.config.StalledAfterHours > 0 && !stalledSince.IsZero() {
+		stalledAfter := time.Duration(r.config.StalledAfterHours) * time.Hour
+		stalledFor := now.Sub(stalledSince)
+		if stalledFor > stalledAfter {
+			return ItemStatusStalled, fmt.Sprintf("torrent has been stalled for %s", stalledFor.Truncate(time.Hour))
+		}
+	}
+
+	return ItemStatusOK, ""
+}
+
+// resolve marks a drifted item and applies the configured action to it.
+func (r *Reconciler) resolve(ctx context.Context, item models.AuthorSubscriptionItem, torrent downloadclient.Torrent, status, reason string, now time.Time) Drift {
+	drift := Drift{Item: item, Status: status, Outcome: OutcomeMarked, Reason: reason}
+
+	logAttrs := []any{
+		slog.Uint64("item_id", uint64(item.ID)),
+		slog.String("title", item.Title),
+		slog.String("hash", item.TorrentHash),
+		slog.String("status", status),
+		slog.String("reason", reason),
+	}
+
+	if r.DryRun {
+		drift.Outcome = r.plannedOutcome(item, status)
+		slog.InfoContext(ctx, "Would reconcile subscription item", append(logAttrs, slog.String("outcome", drift.Outcome))...)
+		return drift
+	}
+
+	if item.Status != status {
+		slog.WarnContext(ctx, "Subscription item drifted", logAttrs...)
+		eventlog.Log(r.db, eventlog.CategorySubscription, eventlog.EventSubscriptionItemDrifted, eventlog.SourceSubscriptionReconcile,
+			eventlog.EntityTorrent, item.TorrentHash,
+			fmt.Sprintf("%s: %s (%s)", status, item.Title, reason),
+			map[string]any{
+				"item_id":         item.ID,
+				"title":           item.Title,
+				"booksearch_id":   item.BooksearchID,
+				"status":          status,
+				"previous_status": item.Status,
+				"reason":          reason,
+			})
+	}
+
+	var err error
+	switch r.config.Action {
+	case config.ReconcileActionReadd:
+		drift.Outcome, err = r.readd(ctx, &item, torrent, status)
+	case config.ReconcileActionRelease:
+		drift.Outcome, err = r.release(ctx, item, status)
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to reconcile subscription item", append(logAttrs, slogx.Error(err))...)
+		drift.Outcome = OutcomeSkipped
+	}
+
+	switch drift.Outcome {
+	case OutcomeReleased:
+		return drift
+	case OutcomeReadded:
+		r.setStatus(ctx, &item, ItemStatusOK, now)
+	default:
+		r.setStatus(ctx, &item, status, now)
+	}
+
+	drift.Item = item
+	return drift
+}
+
+// plannedOutcome is what resolve would do with an item, for dry runs.
+func (r *Reconciler) plannedOutcome(item models.AuthorSubscriptionItem, status string) string {
+	switch r.config.Action {
+	case config.ReconcileActionReadd:
+		if status == ItemStatusRecategorized || (status == ItemStatusMissing && item.DownloadLink != "") {
+			return OutcomeReadded
+		}
+	case config.ReconcileActionRelease:
+		return OutcomeReleased
+	}
+
+	return OutcomeMarked
+}
+
+// readd puts a missing torrent back into the download client and moves a
+// recategorized one back to the subscription category. Errored and stalled
+// torrents are already in the client, so re-adding would not help them.
+func (r *Reconciler) readd(ctx context.Context, item *models.AuthorSubscriptionItem, torrent downloadclient.Torrent, status string) (string, error) {
+	switch status {
+	case ItemStatusRecategorized:
+		if err := r.downloadClient.SetCategory(ctx, []string{torrent.Hash}, AuthorSubscriptionCategory); err != nil {
+			return "", fmt.Errorf("failed to restore torrent category: %w", err)
+		}
+
+		slog.InfoContext(ctx, "Restored subscription torrent category",
+			slog.String("title", item.Title),
+			slog.String("hash", torrent.Hash),
+			slog.String("previous_category", torrent.Category))
+		return OutcomeReadded, nil
+
+	case ItemStatusMissing:
+		if item.DownloadLink == "" {
+			slog.WarnContext(ctx, "Subscription item has no download link to re-add from",
+				slog.String("title", item.Title),
+				slog.String("hash", item.TorrentHash))
+			return OutcomeMarked, nil
+		}
+
+		hash, err := r.torrentDownloader.DownloadAndAdd(ctx, r.downloadClient, item.DownloadLink, downloadclient.AddOptions{
+			Category: AuthorSubscriptionCategory,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		eventlog.Log(r.db, eventlog.CategoryDownload, eventlog.EventTorrentAdded, eventlog.SourceSubscriptionReconcile,
+			eventlog.EntityTorrent, hash,
+			fmt.Sprintf("Re-added: %s", item.Title),
+			map[string]string{
+				"title":         item.Title,
+				"hash":          hash,
+				"previous_hash": item.TorrentHash,
+				"booksearch_id": item.BooksearchID,
+			})
+
+		// The release may have been re-uploaded since it was first downloaded
+		item.TorrentHash = hash
+		return OutcomeReadded, nil
+	}
+
+	return OutcomeMarked, nil
+}
+
+// release deletes an item so its booksearch ID and hash no longer block the
+// next feed hit. Errored and stalled torrents are removed from the client
+// first, with their partial data, or the client would ignore the re-add.
+func (r *Reconciler) release(ctx context.Context, item models.AuthorSubscriptionItem, status string) (string, error) {
+	if status == ItemStatusErrored || status == ItemStatusStalled {
+		if err := r.downloadClient.RemoveTorrents(ctx, []string{item.TorrentHash}, true); err != nil {
+			return "", fmt.Errorf("failed to remove torrent: %w", err)
+		}
+
+		eventlog.Log(r.db, eventlog.CategoryDownload, eventlog.EventTorrentRemoved, eventlog.SourceSubscriptionReconcile,
+			eventlog.EntityTorrent, item.TorrentHash,
+			fmt.Sprintf("Removed %s torrent: %s", status, item.Title),
+			map[string]any{"title": item.Title, "status": status, "deleteFiles": true})
+	}
+
+	if err := r.db.Delete(&item).Error; err != nil {
+		return "", fmt.Errorf("failed to delete subscription item: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Released subscription item",
+		slog.String("title", item.Title),
+		slog.String("hash", item.TorrentHash),
+		slog.String("booksearch_id", item.BooksearchID),
+		slog.String("status", status))
+
+	eventlog.Log(r.db, eventlog.CategorySubscription, eventlog.EventSubscriptionItemReleased, eventlog.SourceSubscriptionReconcile,
+		eventlog.EntityTorrent, item.TorrentHash,
+		fmt.Sprintf("Released: %s (%s)", item.Title, status),
+		map[string]any{
+			"item_id":       item.ID,
+			"title":         item.Title,
+			"booksearch_id": item.BooksearchID,
+			"status":        status,
+		})
+
+	return OutcomeReleased, nil
+}
+
+// setStatus records an item's status and when it was checked. Failures are
+// logged; the next run checks the item again.
+func (r *Reconciler) setStatus(ctx context.Context, item *models.AuthorSubscriptionItem, status string, now time.Time) {
+	if r.DryRun {
+		return
+	}
+
+	item.Status = status
+	item.StatusCheckedAt = &now
+
+	err := r.db.Model(item).Updates(map[string]any{
+		"status":            item.Status,
+		"status_checked_at": item.StatusCheckedAt,
+		"torrent_hash":      item.TorrentHash,
+	}).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to update subscription item status",
+			slog.Uint64("item_id", uint64(item.ID)),
+			slogx.Error(err))
+	}
+}
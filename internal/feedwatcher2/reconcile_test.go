@@ -0,0 +1,317 @@
+package feedwatcher2
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/testutil"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
+)
+
+func createTestReconciler(db *gorm.DB, downloadClient downloadclient.DownloadClient, action string) *Reconciler {
+	return &Reconciler{
+		db:                db,
+		downloadClient:    downloadClient,
+		torrentDownloader: torrentutil.NewTestTorrentDownloader(),
+		config:            config.ReconcileConfig{StalledAfterHours: 24, Action: action},
+	}
+}
+
+// mockClientWithTorrents returns a client holding torrents that answers hash filters.
+func mockClientWithTorrents(torrents ...downloadclient.Torrent) *testutil.MockDownloadClient {
+	return &testutil.MockDownloadClient{
+		GetTorrentsFunc: func(ctx context.Context, filter downloadclient.TorrentFilter) ([]downloadclient.Torrent, error) {
+			var matched []downloadclient.Torrent
+			for _, torrent := range torrents {
+				if slices.Contains(filter.Hashes, torrent.Hash) {
+					matched = append(matched, torrent)
+				}
+			}
+			return matched, nil
+		},
+	}
+}
+
+func subscriptionTorrent(hash string, state downloadclient.TorrentState) downloadclient.Torrent {
+	return downloadclient.Torrent{
+		Hash:       hash,
+		Name:       hash,
+		Category:   AuthorSubscriptionCategory,
+		State:      state,
+		AmountLeft: 1024,
+		AddedOn:    time.Now().Add(-time.Hour).Unix(),
+	}
+}
+
+func createTestSubscriptionItem(t *testing.T, db *gorm.DB, booksearchID, hash string) models.AuthorSubscriptionItem {
+	t.Helper()
+
+	scope := createTestScope(t, db, "personal")
+
+	var author models.Author
+	require.NoError(t, db.FirstOrCreate(&author, models.Author{Name: "Brandon Sanderson"}).Error)
+
+	var subscription models.AuthorSubscription
+	require.NoError(t, db.FirstOrCreate(&subscription, models.AuthorSubscription{AuthorID: author.ID, ScopeID: scope.ID}).Error)
+
+	var bookType models.BookType
+	require.NoError(t, db.Where("name = ?", MediaTypeAudiobook).First(&bookType).Error)
+
+	item := models.AuthorSubscriptionItem{
+		AuthorSubscriptionID: subscription.ID,
+		BookTypeID:           bookType.ID,
+		TorrentHash:          hash,
+		BooksearchID:         booksearchID,
+		Title:                "Book " + booksearchID,
+		DownloadedAt:         time.Now(),
+		DownloadLink:         "magnet:?xt=urn:btih:" + hash,
+	}
+	require.NoError(t, db.Create(&item).Error)
+
+	return item
+}
+
+func reloadItem(t *testing.T, db *gorm.DB, id uint) models.AuthorSubscriptionItem {
+	t.Helper()
+
+	var item models.AuthorSubscriptionItem
+	require.NoError(t, db.First(&item, id).Error)
+	return item
+}
+
+const (
+	hashOK            = "1111111111111111111111111111111111111111"
+	hashMissing       = "2222222222222222222222222222222222222222"
+	hashErrored       = "3333333333333333333333333333333333333333"
+	hashStalled       = "4444444444444444444444444444444444444444"
+	hashRecategorized = "5555555555555555555555555555555555555555"
+	hashImported      = "6666666666666666666666666666666666666666"
+)
+
+// setupReconcileTest creates one item per status and a client holding their torrents.
+func setupReconcileTest(t *testing.T) (*gorm.DB, *testutil.MockDownloadClient, map[string]models.AuthorSubscriptionItem) {
+	t.Helper()
+
+	db := setupIntegrationTestDB(t)
+
+	items := map[string]models.AuthorSubscriptionItem{
+		ItemStatusOK:            createTestSubscriptionItem(t, db, "1", hashOK),
+		ItemStatusMissing:       createTestSubscriptionItem(t, db, "2", hashMissing),
+		ItemStatusErrored:       createTestSubscriptionItem(t, db, "3", hashErrored),
+		ItemStatusStalled:       createTestSubscriptionItem(t, db, "4", hashStalled),
+		ItemStatusRecategorized: createTestSubscriptionItem(t, db, "5", hashRecategorized),
+		ItemStatusImported:      createTestSubscriptionItem(t, db, "6", hashImported),
+	}
+
+	// Imported torrents may have been removed by retention cleanup
+	require.NoError(t, db.Create(&models.ImportJob{
+		TorrentHash: hashImported,
+		TorrentName: "imported",
+		Category:    AuthorSubscriptionCategory,
+		Status:      common.ImportJobCompleted,
+	}).Error)
+
+	// Only stalls timed by the torrent health monitor count; the OK torrent
+	// stalled recently
+	stalledSince := time.Now().Add(-48 * time.Hour)
+	recentlyStalled := time.Now().Add(-time.Hour)
+	require.NoError(t, db.Create(&[]models.TorrentHealth{
+		{TorrentHash: hashStalled, TorrentName: "stalled", Category: AuthorSubscriptionCategory, StalledSince: &stalledSince},
+		{TorrentHash: hashOK, TorrentName: "ok", Category: AuthorSubscriptionCategory, StalledSince: &recentlyStalled},
+	}).Error)
+
+	stalled := subscriptionTorrent(hashStalled, downloadclient.StateStalledDownloading)
+
+	// An old torrent is not stalled just because it is stalled right now
+	ok := subscriptionTorrent(hashOK, downloadclient.StateStalledDownloading)
+	ok.AddedOn = time.Now().Add(-48 * time.Hour).Unix()
+
+	recategorized := subscriptionTorrent(hashRecategorized, downloadclient.StateDownloading)
+	recategorized.Category = "audiobooks"
+
+	client := mockClientWithTorrents(
+		ok,
+		subscriptionTorrent(hashErrored, downloadclient.StateError),
+		stalled,
+		recategorized,
+	)
+
+	return db, client, items
+}
+
+func driftStatuses(drifts []Drift) map[string]Drift {
+	byStatus := make(map[string]Drift)
+	for _, drift := range drifts {
+		byStatus[drift.Status] = drift
+	}
+	return byStatus
+}
+
+func TestReconciler_MarksDrift(t *testing.T) {
+	db, client, items := setupReconcileTest(t)
+
+	drifts, err := createTestReconciler(db, client, "").Run(context.Background())
+	require.NoError(t, err)
+
+	byStatus := driftStatuses(drifts)
+	require.Len(t, byStatus, 4)
+	for _, status := range []string{ItemStatusMissing, ItemStatusErrored, ItemStatusStalled, ItemStatusRecategorized} {
+		assert.Equal(t, OutcomeMarked, byStatus[status].Outcome, status)
+		assert.Equal(t, items[status].ID, byStatus[status].Item.ID, status)
+	}
+
+	for status, item := range items {
+		reloaded := reloadItem(t, db, item.ID)
+		assert.Equal(t, status, reloaded.Status, item.Title)
+		assert.NotNil(t, reloaded.StatusCheckedAt, item.Title)
+	}
+
+	// Nothing is changed in the client without an action
+	assert.Empty(t, client.AddTorrentCalls)
+	assert.Empty(t, client.SetCategoryCalls)
+	assert.Empty(t, client.RemoveTorrentsCalls)
+
+	var events []models.EventLog
+	require.NoError(t, db.Where("event_type = ?", eventlog.EventSubscriptionItemDrifted).Find(&events).Error)
+	assert.Len(t, events, 4)
+
+	// Imported items are not checked again, and drift is only logged when it changes
+	_, err = createTestReconciler(db, client, "").Run(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, client.GetTorrentsCalls[1].Hashes, hashImported)
+
+	require.NoError(t, db.Where("event_type = ?", eventlog.EventSubscriptionItemDrifted).Find(&events).Error)
+	assert.Len(t, events, 4)
+}
+
+func TestReconciler_DryRun(t *testing.T) {
+	db, client, items := setupReconcileTest(t)
+
+	reconciler := createTestReconciler(db, client, config.ReconcileActionRelease)
+	reconciler.DryRun = true
+
+	drifts, err := reconciler.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, drifts, 4)
+	for _, drift := range drifts {
+		assert.Equal(t, OutcomeReleased, drift.Outcome)
+	}
+
+	for _, item := range items {
+		reloaded := reloadItem(t, db, item.ID)
+		assert.Empty(t, reloaded.Status)
+		assert.Nil(t, reloaded.StatusCheckedAt)
+	}
+	assert.Empty(t, client.RemoveTorrentsCalls)
+}
+
+func TestReconciler_Readd(t *testing.T) {
+	db, client, items := setupReconcileTest(t)
+
+	drifts, err := createTestReconciler(db, client, config.ReconcileActionReadd).Run(context.Background())
+	require.NoError(t, err)
+
+	byStatus := driftStatuses(drifts)
+	assert.Equal(t, OutcomeReadded, byStatus[ItemStatusMissing].Outcome)
+	assert.Equal(t, OutcomeReadded, byStatus[ItemStatusRecategorized].Outcome)
+	assert.Equal(t, OutcomeMarked, byStatus[ItemStatusErrored].Outcome)
+	assert.Equal(t, OutcomeMarked, byStatus[ItemStatusStalled].Outcome)
+
+	// The missing torrent is added again from its stored link
+	require.Len(t, client.AddTorrentCalls, 1)
+	assert.Equal(t, items[ItemStatusMissing].DownloadLink, client.AddTorrentCalls[0].URL)
+	assert.Equal(t, AuthorSubscriptionCategory, client.AddTorrentCalls[0].Options.Category)
+
+	// The recategorized torrent is moved back
+	require.Len(t, client.SetCategoryCalls, 1)
+	assert.Equal(t, []string{hashRecategorized}, client.SetCategoryCalls[0].Hashes)
+	assert.Equal(t, AuthorSubscriptionCategory, client.SetCategoryCalls[0].Category)
+
+	assert.Equal(t, ItemStatusOK, reloadItem(t, db, items[ItemStatusMissing].ID).Status)
+	assert.Equal(t, ItemStatusOK, reloadItem(t, db, items[ItemStatusRecategorized].ID).Status)
+	assert.Equal(t, ItemStatusErrored, reloadItem(t, db, items[ItemStatusErrored].ID).Status)
+	assert.Empty(t, client.RemoveTorrentsCalls)
+}
+
+func TestReconciler_ReaddWithoutLink(t *testing.T) {
+	db := setupIntegrationTestDB(t)
+	item := createTestSubscriptionItem(t, db, "1", hashMissing)
+	require.NoError(t, db.Model(&item).Update("download_link", "").Error)
+
+	client := mockClientWithTorrents()
+
+	drifts, err := createTestReconciler(db, client, config.ReconcileActionReadd).Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, drifts, 1)
+	assert.Equal(t, OutcomeMarked, drifts[0].Outcome)
+	assert.Empty(t, client.AddTorrentCalls)
+	assert.Equal(t, ItemStatusMissing, reloadItem(t, db, item.ID).Status)
+}
+
+func TestReconciler_Release(t *testing.T) {
+	db, client, items := setupReconcileTest(t)
+
+	drifts, err := createTestReconciler(db, client, config.ReconcileActionRelease).Run(context.Background())
+	require.NoError(t, err)
+
+	require.Len(t, drifts, 4)
+	for _, drift := range drifts {
+		assert.Equal(t, OutcomeReleased, drift.Outcome, drift.Status)
+
+		err := db.First(&models.AuthorSubscriptionItem{}, drift.Item.ID).Error
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound, drift.Status)
+	}
+
+	// Only torrents the client would otherwise keep are removed, with their data
+	require.Len(t, client.RemoveTorrentsCalls, 2)
+	var removed []string
+	for _, call := range client.RemoveTorrentsCalls {
+		assert.True(t, call.DeleteFiles)
+		removed = append(removed, call.Hashes...)
+	}
+	assert.ElementsMatch(t, []string{hashErrored, hashStalled}, removed)
+
+	// Healthy and imported items are kept
+	assert.Equal(t, ItemStatusOK, reloadItem(t, db, items[ItemStatusOK].ID).Status)
+	assert.Equal(t, ItemStatusImported, reloadItem(t, db, items[ItemStatusImported].ID).Status)
+
+	var events []models.EventLog
+	require.NoError(t, db.Where("event_type = ?", eventlog.EventSubscriptionItemReleased).Find(&events).Error)
+	assert.Len(t, events, 4)
+}
+
+func TestReconciler_ImportedTag(t *testing.T) {
+	db := setupIntegrationTestDB(t)
+	item := createTestSubscriptionItem(t, db, "1", hashOK)
+
+	config.Config.Importers.ImportedTag = "imported"
+	t.Cleanup(func() { config.Config.Importers.ImportedTag = "" })
+
+	torrent := subscriptionTorrent(hashOK, downloadclient.StateStalledSeeding)
+	torrent.Tags = []string{"imported"}
+
+	drifts, err := createTestReconciler(db, mockClientWithTorrents(torrent), "").Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Empty(t, drifts)
+	assert.Equal(t, ItemStatusImported, reloadItem(t, db, item.ID).Status)
+}
+
+func TestReconciler_UnknownAction(t *testing.T) {
+	db := setupIntegrationTestDB(t)
+
+	_, err := createTestReconciler(db, mockClientWithTorrents(), "delete").Run(context.Background())
+	assert.ErrorContains(t, err, `unknown reconcile action "delete"`)
+}
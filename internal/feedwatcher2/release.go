@@ -0,0 +1,47 @@
+package feedwatcher2
+
+import (
+	"fmt"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/source"
+	"github.com/bobbyrward/stronghold/internal/importers/ebooks"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
+)
+
+const bytesPerMB = 1024 * 1024
+
+// checkRelease returns why a matched release should not be downloaded, or an
+// empty string to accept it. Only the files the importer for bookTypeName
+// would import count toward the size limits.
+func checkRelease(metainfo *torrentutil.Metainfo, bookTypeName string, rules config.FeedWatcher2Config) string {
+	isBookFile := ebooks.IsEbookFile
+	limits := rules.Ebooks
+	if bookTypeName == MediaTypeAudiobook {
+		isBookFile = source.IsAudioFile
+		limits = rules.Audiobooks
+	}
+
+	var count int
+	var size int64
+	for _, file := range metainfo.Files {
+		if isBookFile(file.Path) {
+			count++
+			size += file.Size
+		}
+	}
+
+	if count == 0 {
+		return fmt.Sprintf("no %s files in torrent", bookTypeName)
+	}
+
+	if limits.MinSizeMB > 0 && size < limits.MinSizeMB*bytesPerMB {
+		return fmt.Sprintf("%s files total %d MB, below the %d MB minimum", bookTypeName, size/bytesPerMB, limits.MinSizeMB)
+	}
+
+	if limits.MaxSizeMB > 0 && size > limits.MaxSizeMB*bytesPerMB {
+		return fmt.Sprintf("%s files total %d MB, above the %d MB maximum", bookTypeName, size/bytesPerMB, limits.MaxSizeMB)
+	}
+
+	return ""
+}
@@ -0,0 +1,85 @@
+package feedwatcher2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
+)
+
+func TestCheckRelease(t *testing.T) {
+	rules := config.FeedWatcher2Config{
+		Audiobooks: config.ReleaseRules{MinSizeMB: 10, MaxSizeMB: 2000},
+		Ebooks:     config.ReleaseRules{MaxSizeMB: 50},
+	}
+
+	tests := []struct {
+		name     string
+		bookType string
+		files    []torrentutil.MetainfoFile
+		rules    config.FeedWatcher2Config
+		reason   string
+	}{
+		{
+			name:     "audiobook within limits",
+			bookType: MediaTypeAudiobook,
+			files: []torrentutil.MetainfoFile{
+				{Path: "Dune/Part 1.mp3", Size: 300 * bytesPerMB},
+				{Path: "Dune/cover.jpg", Size: 1 * bytesPerMB},
+			},
+			rules: rules,
+		},
+		{
+			name:     "audiobook without audio files",
+			bookType: MediaTypeAudiobook,
+			files:    []torrentutil.MetainfoFile{{Path: "Dune/Dune.epub", Size: 300 * bytesPerMB}},
+			rules:    rules,
+			reason:   "no audiobook files in torrent",
+		},
+		{
+			name:     "audiobook too small",
+			bookType: MediaTypeAudiobook,
+			files: []torrentutil.MetainfoFile{
+				{Path: "Dune/Dune.m4b", Size: 5 * bytesPerMB},
+				{Path: "Dune/extras.zip", Size: 500 * bytesPerMB},
+			},
+			rules:  rules,
+			reason: "audiobook files total 5 MB, below the 10 MB minimum",
+		},
+		{
+			name:     "audiobook too large",
+			bookType: MediaTypeAudiobook,
+			files:    []torrentutil.MetainfoFile{{Path: "Dune.m4b", Size: 3000 * bytesPerMB}},
+			rules:    rules,
+			reason:   "audiobook files total 3000 MB, above the 2000 MB maximum",
+		},
+		{
+			name:     "ebook without ebook files",
+			bookType: MediaTypeEbook,
+			files:    []torrentutil.MetainfoFile{{Path: "Emma/Emma.pdf", Size: bytesPerMB}},
+			rules:    rules,
+			reason:   "no ebook files in torrent",
+		},
+		{
+			name:     "ebook too large",
+			bookType: MediaTypeEbook,
+			files:    []torrentutil.MetainfoFile{{Path: "Emma/Emma.EPUB", Size: 60 * bytesPerMB}},
+			rules:    rules,
+			reason:   "ebook files total 60 MB, above the 50 MB maximum",
+		},
+		{
+			name:     "no limits configured",
+			bookType: MediaTypeEbook,
+			files:    []torrentutil.MetainfoFile{{Path: "Emma.azw3", Size: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metainfo := &torrentutil.Metainfo{Files: tt.files}
+			assert.Equal(t, tt.reason, checkRelease(metainfo, tt.bookType, tt.rules))
+		})
+	}
+}
@@ -17,4 +17,8 @@ type Client interface {
 	// GetAuthorBooks fetches an author's bibliography (list of works) by their
 	// canonical id, ordered by release date.
 	GetAuthorBooks(ctx context.Context, id string) ([]BookResult, error)
+
+	// SearchBooks finds works by exact title, with the details needed to fill
+	// in import metadata.
+	SearchBooks(ctx context.Context, title string) ([]BookDetails, error)
 }
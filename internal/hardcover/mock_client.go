@@ -13,6 +13,8 @@ type MockClient struct {
 	GetAuthorBySlugFunc func(ctx context.Context, slug string) (*AuthorSearchResult, error)
 	GetAuthorByIDFunc   func(ctx context.Context, id string) (*AuthorSearchResult, error)
 	GetAuthorBooksFunc  func(ctx context.Context, id string) ([]BookResult, error)
+	BookDetails         []BookDetails
+	SearchBooksFunc     func(ctx context.Context, title string) ([]BookDetails, error)
 }
 
 // Compile-time check that MockClient implements Client interface.
@@ -86,3 +88,19 @@ func (m *MockClient) GetAuthorBooks(ctx context.Context, id string) ([]BookResul
 	}
 	return m.Books[id], nil
 }
+
+// SearchBooks finds works by title.
+// If SearchBooksFunc is set, it delegates to that function.
+// Otherwise, it returns the BookDetails entries whose title matches (case-insensitive).
+func (m *MockClient) SearchBooks(ctx context.Context, title string) ([]BookDetails, error) {
+	if m.SearchBooksFunc != nil {
+		return m.SearchBooksFunc(ctx, title)
+	}
+	var results []BookDetails
+	for _, book := range m.BookDetails {
+		if strings.EqualFold(book.Title, title) {
+			results = append(results, book)
+		}
+	}
+	return results, nil
+}
@@ -228,3 +228,78 @@ func (c *RealClient) GetAuthorBooks(ctx context.Context, id string) ([]BookResul
 	slog.DebugContext(ctx, "Fetched author bibliography", slog.String("id", id), slog.Int("count", len(results)))
 	return results, nil
 }
+
+// bookDetailsRow is the GraphQL selection for a work searched by title.
+type bookDetailsRow struct {
+	ID          int     `graphql:"id"`
+	Title       string  `graphql:"title"`
+	Subtitle    *string `graphql:"subtitle"`
+	Description *string `graphql:"description"`
+	ReleaseDate *string `graphql:"release_date"`
+
+	Image *struct {
+		URL *string `graphql:"url"`
+	} `graphql:"image"`
+
+	Contributions []struct {
+		Author struct {
+			Name string `graphql:"name"`
+		} `graphql:"author"`
+	} `graphql:"contributions"`
+
+	BookSeries []struct {
+		Position *float64 `graphql:"position"`
+		Series   struct {
+			Name string `graphql:"name"`
+		} `graphql:"series"`
+	} `graphql:"book_series"`
+}
+
+func (b bookDetailsRow) toResult() BookDetails {
+	result := BookDetails{
+		HardcoverID: strconv.Itoa(b.ID),
+		Title:       b.Title,
+		Subtitle:    b.Subtitle,
+		Description: b.Description,
+		ReleaseDate: b.ReleaseDate,
+	}
+
+	if b.Image != nil {
+		result.ImageURL = b.Image.URL
+	}
+
+	for _, contribution := range b.Contributions {
+		result.Authors = append(result.Authors, contribution.Author.Name)
+	}
+
+	if len(b.BookSeries) > 0 {
+		result.SeriesName = &b.BookSeries[0].Series.Name
+		result.SeriesPosition = b.BookSeries[0].Position
+	}
+
+	return result
+}
+
+// SearchBooks finds works by exact title, most popular first.
+func (c *RealClient) SearchBooks(ctx context.Context, title string) ([]BookDetails, error) {
+	slog.InfoContext(ctx, "Searching Hardcover books", slog.String("title", title))
+
+	var q struct {
+		Books []bookDetailsRow `graphql:"books(where: {title: {_eq: $title}}, order_by: {users_count: desc}, limit: 10)"`
+	}
+
+	variables := map[string]any{
+		"title": title,
+	}
+
+	if err := c.graphqlClient.Query(ctx, &q, variables); err != nil {
+		return nil, err
+	}
+
+	results := make([]BookDetails, len(q.Books))
+	for idx, book := range q.Books {
+		results[idx] = book.toResult()
+	}
+
+	return results, nil
+}
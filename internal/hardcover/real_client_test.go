@@ -42,3 +42,32 @@ func TestBookRowToResult(t *testing.T) {
 		}
 	})
 }
+
+func TestBookDetailsRowToResult(t *testing.T) {
+	position := 3.0
+	row := bookDetailsRow{ID: 5, Title: "Oathbringer"}
+	row.Contributions = append(row.Contributions, struct {
+		Author struct {
+			Name string `graphql:"name"`
+		} `graphql:"author"`
+	}{})
+	row.Contributions[0].Author.Name = "Brandon Sanderson"
+	row.BookSeries = append(row.BookSeries, struct {
+		Position *float64 `graphql:"position"`
+		Series   struct {
+			Name string `graphql:"name"`
+		} `graphql:"series"`
+	}{Position: &position})
+	row.BookSeries[0].Series.Name = "The Stormlight Archive"
+
+	got := row.toResult()
+	if got.HardcoverID != "5" || len(got.Authors) != 1 || got.Authors[0] != "Brandon Sanderson" {
+		t.Fatalf("unexpected result: %+v", got)
+	}
+	if got.SeriesName == nil || *got.SeriesName != "The Stormlight Archive" || got.SeriesPosition == nil || *got.SeriesPosition != 3 {
+		t.Fatalf("unexpected series: %+v", got)
+	}
+	if got.ImageURL != nil {
+		t.Fatalf("expected nil image, got: %v", *got.ImageURL)
+	}
+}
@@ -14,3 +14,16 @@ type BookResult struct {
 	Title       string
 	ReleaseDate *string // ISO date from Hardcover; nil when unknown
 }
+
+// BookDetails is a work with the descriptive fields used as an import metadata source.
+type BookDetails struct {
+	HardcoverID    string
+	Title          string
+	Subtitle       *string
+	Description    *string
+	ReleaseDate    *string // ISO date; nil when unknown
+	ImageURL       *string
+	Authors        []string
+	SeriesName     *string
+	SeriesPosition *float64
+}
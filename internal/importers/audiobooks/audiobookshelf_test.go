@@ -0,0 +1,153 @@
+package audiobooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/audiobookshelf"
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func TestAudiobookshelfPath(t *testing.T) {
+	library := &config.ImportLibrary{Path: "/data/audiobooks/"}
+	assert.Equal(t, "/data/audiobooks/Author/Book", audiobookshelfPath(library, "/data/audiobooks/Author/Book"))
+
+	library.AudiobookshelfPath = "/audiobooks"
+	assert.Equal(t, "/audiobooks/Author/Book", audiobookshelfPath(library, "/data/audiobooks/Author/Book"))
+}
+
+func TestLinkAudiobookshelfItem(t *testing.T) {
+	ctx := context.Background()
+
+	var scanned string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/watcher/update":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			scanned = body["path"]
+		case "/api/libraries/lib1/search":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"book": []map[string]any{
+					{"libraryItem": map[string]any{"id": "li_dune", "path": "/audiobooks/Frank Herbert/Dune"}},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	destination := "/data/audiobooks/Frank Herbert/Dune"
+	common.RecordImportedItem(ctx, db, "hash", destination, "Dune", "B002V1OF70", nil)
+
+	abis := &AudiobookImporterSystem{
+		db: db,
+		audiobookshelf: &audiobookshelf.Client{
+			BaseURL:        server.URL,
+			LookupAttempts: 2,
+			LookupInterval: time.Millisecond,
+		},
+	}
+
+	library := &config.ImportLibrary{
+		Path:                    "/data/audiobooks",
+		AudiobookshelfLibraryID: "lib1",
+		AudiobookshelfPath:      "/audiobooks",
+	}
+
+	itemURL := abis.LinkAudiobookshelfItem(ctx, library, destination, createTestBookMetadata("Dune", "B002V1OF70"))
+	assert.Equal(t, server.URL+"/item/li_dune", itemURL)
+	assert.Equal(t, "/audiobooks/Frank Herbert/Dune/metadata.opf", scanned)
+
+	var item models.ImportedItem
+	require.NoError(t, db.Where("path = ?", destination).First(&item).Error)
+	assert.Equal(t, "li_dune", item.AudiobookshelfItemID)
+
+	// Libraries that are not mapped to Audiobookshelf are skipped
+	scanned = ""
+	assert.Empty(t, abis.LinkAudiobookshelfItem(ctx, &config.ImportLibrary{Path: "/data/audiobooks"}, destination, createTestBookMetadata("Dune", "")))
+	assert.Empty(t, scanned)
+}
+
+func TestFinish_LinksPendingImports(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var scanned []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/watcher/update":
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			scanned = append(scanned, body["path"])
+			mu.Unlock()
+		case "/api/libraries/lib1/search":
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"book": []map[string]any{
+					{"libraryItem": map[string]any{"id": "li_dune", "path": "/data/audiobooks/Frank Herbert/Dune"}},
+					{"libraryItem": map[string]any{"id": "li_emma", "path": "/data/audiobooks/Jane Austen/Emma"}},
+				},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	abis := &AudiobookImporterSystem{
+		db: db,
+		audiobookshelf: &audiobookshelf.Client{
+			BaseURL:        server.URL,
+			LookupAttempts: 2,
+			LookupInterval: time.Millisecond,
+		},
+	}
+
+	library := &config.ImportLibrary{Path: "/data/audiobooks", AudiobookshelfLibraryID: "lib1"}
+	destinations := map[string]string{
+		"/data/audiobooks/Frank Herbert/Dune": "li_dune",
+		"/data/audiobooks/Jane Austen/Emma":   "li_emma",
+	}
+
+	for destination := range destinations {
+		common.RecordImportedItem(ctx, db, destination, destination, "Book", "", nil)
+		abis.pendingLinks = append(abis.pendingLinks, pendingLink{
+			library:      library,
+			destination:  destination,
+			bookMetadata: createTestBookMetadata("Book", ""),
+		})
+	}
+
+	abis.Finish(ctx)
+
+	assert.Len(t, scanned, 2)
+	assert.Empty(t, abis.pendingLinks)
+
+	for destination, itemID := range destinations {
+		var item models.ImportedItem
+		require.NoError(t, db.Where("path = ?", destination).First(&item).Error)
+		assert.Equal(t, itemID, item.AudiobookshelfItemID)
+	}
+
+	// Nothing is left to link
+	abis.Finish(ctx)
+	assert.Len(t, scanned, 2)
+}
@@ -6,50 +6,82 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/exec"
 	"path"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
-	"github.com/autobrr/go-qbittorrent"
 	"github.com/cappuccinotm/slogx"
 	"gorm.io/gorm"
 
+	"github.com/bobbyrward/stronghold/internal/audiobookshelf"
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
-	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/audible"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/providers"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/torrent"
 	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/bobbyrward/stronghold/internal/notifications"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 )
 
 const (
 	TITLE_SKIPPED = "__SKIPPED__"
 )
 
+// maxConcurrentLinks bounds how many imports Finish waits on Audiobookshelf
+// for at once.
+const maxConcurrentLinks = 8
+
 type AudiobookImporterSystem struct {
 	cfg              config.ImportersConfig
-	qbitClient       qbit.QbitClient
+	downloadClient   downloadclient.DownloadClient
 	metadataProvider metadata.MetadataProvider
-	audible          *audible.AudibleApiClient
+	mediaProbe       metadata.MediaProbe
+	tagWriter        metadata.TagWriter
+	metadataSources  providers.Registry
+	httpClient       *http.Client
 	db               *gorm.DB
+	audiobookshelf   *audiobookshelf.Client
+
+	// pendingLinks are imports waiting for Finish to link their Audiobookshelf
+	// item and send their notification
+	pendingMu    sync.Mutex
+	pendingLinks []pendingLink
+}
+
+// pendingLink is a completed import whose Audiobookshelf item and notification
+// are put off until Finish.
+type pendingLink struct {
+	library      *config.ImportLibrary
+	destination  string
+	bookMetadata metadata.BookMetadata
+	importType   config.ImportType
 }
 
 func NewAudiobookImporterSystem(
-	qbitClient qbit.QbitClient,
+	downloadClient downloadclient.DownloadClient,
 	cfg config.ImportersConfig,
 	metadataProvider metadata.MetadataProvider,
-	audibleApiClient *audible.AudibleApiClient,
+	mediaProbe metadata.MediaProbe,
+	metadataSources providers.Registry,
 	db *gorm.DB,
 ) (*AudiobookImporterSystem, error) {
 	importer := &AudiobookImporterSystem{
 		cfg:              cfg,
-		qbitClient:       qbitClient,
+		downloadClient:   downloadClient,
 		metadataProvider: metadataProvider,
-		audible:          audibleApiClient,
+		mediaProbe:       mediaProbe,
+		tagWriter:        metadata.NewFFmpegTagWriter(),
+		metadataSources:  metadataSources,
+		httpClient:       &http.Client{Timeout: coverDownloadTimeout},
 		db:               db,
+		audiobookshelf:   audiobookshelf.NewClient(config.Config.Audiobookshelf),
 	}
 
 	return importer, nil
@@ -58,48 +90,40 @@ func NewAudiobookImporterSystem(
 func (abis *AudiobookImporterSystem) Run(ctx context.Context) error {
 	slog.InfoContext(ctx, "Running audiobook import process...")
 
-	for _, importType := range abis.cfg.AudiobookImporter.ImportTypes {
-		slog.InfoContext(ctx, "Processing import type", slog.String("category", importType.Category))
-
-		library, ok := config.FindLibraryByName(abis.cfg.AudiobookImporter.Libraries, importType.Library)
-		if !ok {
-			return fmt.Errorf("unabled to find library: %s", importType.Library)
-		}
-
-		err := abis.ProcessImportType(ctx, importType, library)
-		if err != nil {
-			return fmt.Errorf("failed to process import type %s: %w", importType.Category, err)
-		}
-	}
+	summary := common.NewRunSummary("audiobook")
+	defer summary.Log(ctx)
 
-	return nil
-}
+	router := common.NewRouter(abis.db, abis)
 
-func (abis *AudiobookImporterSystem) ProcessImportType(ctx context.Context, importType config.ImportType, library *config.ImportLibrary) error {
-	torrents, err := qbit.GetUnimportedTorrentsByCategory(
-		ctx,
-		abis.qbitClient,
-		importType.Category,
-	)
+	routes, err := router.Routes(ctx, common.RouteFilter{MediaType: common.MediaTypeAudiobook})
 	if err != nil {
-		return fmt.Errorf("failed to get unimported torrents for category %s: %w", importType.Category, err)
+		return err
 	}
 
-	for _, torrent := range torrents {
-		slog.InfoContext(ctx, "Found unimported torrent", slog.String("name", torrent.Name))
-		abis.importTorrent(ctx, torrent, importType, library)
-	}
+	// A category with an unusable metadata chain is skipped, like any other
+	// unusable route, so it does not stop the other categories importing
+	routes = slices.DeleteFunc(routes, func(route common.Route) bool {
+		_, err := abis.metadataSources.Chain(route.ImportType.MetadataProviders, nil)
+		if err != nil {
+			slog.ErrorContext(ctx, "Skipping category with invalid metadata providers",
+				slog.String("category", route.ImportType.Category),
+				slog.Any("metadataProviders", route.ImportType.MetadataProviders),
+				slogx.Error(err))
+			return true
+		}
+		return false
+	})
 
-	return nil
+	return router.ImportRoutes(ctx, abis.downloadClient, routes, summary)
 }
 
-func (abis *AudiobookImporterSystem) MarkForManualIntervention(ctx context.Context, importTorrent qbittorrent.Torrent) {
+func (abis *AudiobookImporterSystem) MarkForManualIntervention(ctx context.Context, importTorrent downloadclient.Torrent) {
 	abis.MarkForManualInterventionWithNotification(ctx, importTorrent, "", "")
 }
 
 // MarkForManualInterventionWithNotification marks a torrent for manual intervention and sends a notification.
-func (abis *AudiobookImporterSystem) MarkForManualInterventionWithNotification(ctx context.Context, importTorrent qbittorrent.Torrent, notifierName string, reason string) {
-	err := qbit.TagTorrent(ctx, abis.qbitClient, importTorrent, config.Config.Importers.ManualInterventionTag)
+func (abis *AudiobookImporterSystem) MarkForManualInterventionWithNotification(ctx context.Context, importTorrent downloadclient.Torrent, notifierName string, reason string) {
+	err := downloadclient.TagTorrent(ctx, abis.downloadClient, importTorrent, config.Config.Importers.ManualInterventionTag)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to add manual intervention tag",
 			slog.String("name", importTorrent.Name),
@@ -120,13 +144,15 @@ func (abis *AudiobookImporterSystem) MarkForManualInterventionWithNotification(c
 		fmt.Sprintf("Manual intervention: %s: %s", importTorrent.Name, reason),
 		map[string]string{"name": importTorrent.Name, "hash": importTorrent.Hash, "reason": reason})
 
+	common.RecordManualIntervention(ctx, abis.db, importTorrent, eventlog.SourceAudiobookImporter, reason)
+
 	// Send notification if notifier is configured
 	if notifierName != "" {
 		abis.sendManualInterventionNotification(ctx, importTorrent, notifierName, reason)
 	}
 }
 
-func (abis *AudiobookImporterSystem) sendManualInterventionNotification(ctx context.Context, importTorrent qbittorrent.Torrent, notifierName string, reason string) {
+func (abis *AudiobookImporterSystem) sendManualInterventionNotification(ctx context.Context, importTorrent downloadclient.Torrent, notifierName string, reason string) {
 	message := notifications.DiscordWebhookMessage{
 		Username: "Stronghold Audiobook Importer",
 		Embeds: []notifications.DiscordEmbed{
@@ -158,8 +184,8 @@ func (abis *AudiobookImporterSystem) sendManualInterventionNotification(ctx cont
 	}
 }
 
-func (abis *AudiobookImporterSystem) MarkAsImported(ctx context.Context, importTorrent qbittorrent.Torrent) {
-	err := qbit.TagTorrent(ctx, abis.qbitClient, importTorrent, config.Config.Importers.ImportedTag)
+func (abis *AudiobookImporterSystem) MarkAsImported(ctx context.Context, importTorrent downloadclient.Torrent) {
+	err := downloadclient.TagTorrent(ctx, abis.downloadClient, importTorrent, config.Config.Importers.ImportedTag)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to mark as imported",
 			slog.String("name", importTorrent.Name),
@@ -174,7 +200,9 @@ func (abis *AudiobookImporterSystem) MarkAsImported(ctx context.Context, importT
 	}
 }
 
-func (abis *AudiobookImporterSystem) SendDiscordNotification(ctx context.Context, bookMetadata metadata.BookMetadata, importType config.ImportType) {
+// SendDiscordNotification announces an imported audiobook. itemURL links to its
+// Audiobookshelf item and may be empty.
+func (abis *AudiobookImporterSystem) SendDiscordNotification(ctx context.Context, bookMetadata metadata.BookMetadata, importType config.ImportType, itemURL string) {
 	if importType.DiscordNotifier == "" {
 		return
 	}
@@ -225,6 +253,14 @@ func (abis *AudiobookImporterSystem) SendDiscordNotification(ctx context.Context
 		Inline: true,
 	})
 
+	if itemURL != "" {
+		fields = append(fields, notifications.DiscordEmbedField{
+			Name:   "Audiobookshelf",
+			Value:  fmt.Sprintf("[Open in Audiobookshelf](%s)", itemURL),
+			Inline: true,
+		})
+	}
+
 	message := notifications.DiscordWebhookMessage{
 		Username: "Stronghold Audiobook Importer",
 		Embeds: []notifications.DiscordEmbed{
@@ -246,10 +282,10 @@ func (abis *AudiobookImporterSystem) SendDiscordNotification(ctx context.Context
 // ExtractTorrentMetadata extracts metadata from a torrent by analyzing its files
 // and looking up book information via ASIN or title/author tags.
 // Returns BookMetadata or an error if metadata cannot be extracted.
-func (abis *AudiobookImporterSystem) ExtractTorrentMetadata(ctx context.Context, importTorrent qbittorrent.Torrent) (metadata.BookMetadata, error) {
+func (abis *AudiobookImporterSystem) ExtractTorrentMetadata(ctx context.Context, importTorrent downloadclient.Torrent) (metadata.BookMetadata, error) {
 	var bookMetadata metadata.BookMetadata
 
-	files, err := common.MapTorrentFilesToLocalPaths(ctx, abis.qbitClient, importTorrent)
+	files, err := common.MapTorrentFilesToLocalPaths(ctx, abis.downloadClient, importTorrent)
 	if err != nil {
 		slog.InfoContext(ctx, "Failed to map torrent files",
 			slog.String("name", importTorrent.Name),
@@ -259,7 +295,7 @@ func (abis *AudiobookImporterSystem) ExtractTorrentMetadata(ctx context.Context,
 		return bookMetadata, err
 	}
 
-	torrentMetadata, err := torrent.NewAudiobookFilesMetadata(ctx, abis.qbitClient, abis.metadataProvider, importTorrent, files)
+	torrentMetadata, err := torrent.NewAudiobookFilesMetadata(ctx, abis.downloadClient, abis.metadataProvider, importTorrent, files)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to get audiobook files metadata",
 			slog.String("name", importTorrent.Name),
@@ -273,7 +309,7 @@ func (abis *AudiobookImporterSystem) ExtractTorrentMetadata(ctx context.Context,
 	// Try ASIN lookup first
 	asin, ok := torrentMetadata.Tags().AudibleASIN()
 	if ok {
-		bookMetadata, err = abis.lookupMetadataByAsin(ctx, asin)
+		bookMetadata, err = abis.lookupMetadataByAsin(ctx, importTorrent.Category, asin)
 		if err == nil {
 			slog.InfoContext(ctx, "book metadata found by ASIN",
 				slog.String("asin", asin),
@@ -302,10 +338,12 @@ func (abis *AudiobookImporterSystem) ExtractTorrentMetadata(ctx context.Context,
 			slog.String("name", importTorrent.Name),
 			slog.String("hash", importTorrent.Hash),
 		)
-		return bookMetadata, errors.New("title tag not found in torrent metadata tags")
+		return bookMetadata, common.Permanent(errors.New("title tag not found in torrent metadata tags"))
 	}
 
-	bookMetadata, err = abis.lookupMetadataByTitle(ctx, title, author)
+	hints := HintsFromTags(torrentMetadata.Tags(), abis.audioDuration(ctx, files))
+
+	bookMetadata, err = abis.lookupMetadataByTitle(ctx, importTorrent, hints)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to lookup metadata by title",
 			slog.String("name", importTorrent.Name),
@@ -323,7 +361,7 @@ func (abis *AudiobookImporterSystem) ExtractTorrentMetadata(ctx context.Context,
 
 // ExecuteImport performs the actual import operation: moving files to the library and writing metadata.
 // Returns the destination path and any error encountered during the import process.
-func (abis *AudiobookImporterSystem) ExecuteImport(ctx context.Context, importTorrent qbittorrent.Torrent, bookMetadata metadata.BookMetadata, library *config.ImportLibrary, localPath string) (string, error) {
+func (abis *AudiobookImporterSystem) ExecuteImport(ctx context.Context, importTorrent downloadclient.Torrent, bookMetadata metadata.BookMetadata, library *config.ImportLibrary, localPath string) (string, error) {
 	directoryName, err := bookMetadata.GenerateDirectoryName()
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to generate directory name from metadata",
@@ -331,7 +369,7 @@ func (abis *AudiobookImporterSystem) ExecuteImport(ctx context.Context, importTo
 			slog.String("hash", importTorrent.Hash),
 			slogx.Error(err),
 		)
-		return "", fmt.Errorf("failed to generate directory name: %w", err)
+		return "", common.Permanent(fmt.Errorf("failed to generate directory name: %w", err))
 	}
 
 	directoryName = sanitizeName(directoryName)
@@ -384,6 +422,16 @@ func (abis *AudiobookImporterSystem) ExecuteImport(ctx context.Context, importTo
 		return "", fmt.Errorf("failed to write OPF metadata: %w", err)
 	}
 
+	var rewritten []string
+	if library.WriteAudioTags {
+		rewritten = abis.writeAudioTags(ctx, bookMetadata, fullDirName)
+	}
+
+	abis.writeSidecars(ctx, bookMetadata, fullDirName)
+
+	common.RecordImportedItem(ctx, abis.db, importTorrent.Hash, fullDirName, bookMetadata.Title, bookMetadata.Asin, bookMetadata)
+	common.SetImportedItemRewrittenFiles(ctx, abis.db, fullDirName, rewritten)
+
 	slog.InfoContext(ctx, "Successfully imported audiobook",
 		slog.String("name", importTorrent.Name),
 		slog.String("destination", fullDirName),
@@ -392,13 +440,38 @@ func (abis *AudiobookImporterSystem) ExecuteImport(ctx context.Context, importTo
 	return fullDirName, nil
 }
 
-func (abis *AudiobookImporterSystem) importTorrent(ctx context.Context, importTorrent qbittorrent.Torrent, importType config.ImportType, library *config.ImportLibrary) {
+// MediaType implements common.Importer.
+func (abis *AudiobookImporterSystem) MediaType() string {
+	return common.MediaTypeAudiobook
+}
+
+// ImportTorrent implements common.Importer.
+func (abis *AudiobookImporterSystem) ImportTorrent(ctx context.Context, importTorrent downloadclient.Torrent, importType config.ImportType, library *config.ImportLibrary) {
 	abis.ImportTorrentWithLibrary(ctx, importTorrent, importType, library)
 }
 
 // ImportTorrentWithLibrary imports a single audiobook torrent using the specified library.
-// This is the public entry point for external callers like the AuthorSubscriptionImporter.
-func (abis *AudiobookImporterSystem) ImportTorrentWithLibrary(ctx context.Context, importTorrent qbittorrent.Torrent, importType config.ImportType, library *config.ImportLibrary) {
+// Transient failures are retried with backoff on later runs; permanent failures and
+// exhausted retries are marked for manual intervention.
+func (abis *AudiobookImporterSystem) ImportTorrentWithLibrary(ctx context.Context, importTorrent downloadclient.Torrent, importType config.ImportType, library *config.ImportLibrary) {
+	job, ready, err := common.StartImportJob(ctx, abis.db, importTorrent)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to start import job, importing without retry tracking",
+			slog.String("name", importTorrent.Name),
+			slog.String("hash", importTorrent.Hash),
+			slogx.Error(err),
+		)
+	}
+
+	if !ready {
+		slog.InfoContext(ctx, "Import is waiting for its next retry",
+			slog.String("name", importTorrent.Name),
+			slog.String("hash", importTorrent.Hash),
+			slog.Any("nextAttemptAt", job.NextAttemptAt),
+		)
+		return
+	}
+
 	bookMetadata, err := abis.ExtractTorrentMetadata(ctx, importTorrent)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to extract metadata for torrent",
@@ -407,14 +480,35 @@ func (abis *AudiobookImporterSystem) ImportTorrentWithLibrary(ctx context.Contex
 			slogx.Error(err),
 		)
 
-		abis.MarkForManualInterventionWithNotification(ctx, importTorrent, importType.DiscordNotifier, "Failed to extract metadata: "+err.Error())
+		abis.failImport(ctx, importTorrent, importType, job, "Failed to extract metadata", err)
 
 		return
 	}
 
-	localPath := common.MapTorrentContentPathToLocalPath(importTorrent, config.Config.Qbit.DownloadPath, config.Config.Qbit.LocalDownloadPath)
+	common.SetImportJobMetadata(ctx, abis.db, job, bookMetadata)
 
-	_, err = abis.ExecuteImport(ctx, importTorrent, bookMetadata, library, localPath)
+	downloadPath, localDownloadPath := config.Config.DownloadPaths()
+	localPath := common.MapTorrentContentPathToLocalPath(importTorrent, downloadPath, localDownloadPath)
+
+	err = abis.VerifyRuntime(ctx, bookMetadata, localPath)
+	if err != nil {
+		slog.ErrorContext(ctx, "Runtime verification failed for torrent",
+			slog.String("name", importTorrent.Name),
+			slog.String("hash", importTorrent.Hash),
+			slog.String("asin", bookMetadata.Asin),
+			slogx.Error(err),
+		)
+
+		if errors.Is(err, ErrRuntimeMismatch) {
+			abis.failImport(ctx, importTorrent, importType, job, "Likely wrong edition matched", common.Permanent(err))
+		} else {
+			abis.failImport(ctx, importTorrent, importType, job, "Failed to verify runtime", err)
+		}
+
+		return
+	}
+
+	destination, err := abis.ExecuteImport(ctx, importTorrent, bookMetadata, library, localPath)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to execute import for torrent",
 			slog.String("name", importTorrent.Name),
@@ -422,78 +516,236 @@ func (abis *AudiobookImporterSystem) ImportTorrentWithLibrary(ctx context.Contex
 			slogx.Error(err),
 		)
 
-		abis.MarkForManualInterventionWithNotification(ctx, importTorrent, importType.DiscordNotifier, "Failed to execute import: "+err.Error())
+		abis.failImport(ctx, importTorrent, importType, job, "Failed to execute import", err)
 
 		return
 	}
 
 	abis.MarkAsImported(ctx, importTorrent)
+	common.CompleteImportJob(ctx, abis.db, job)
 
 	eventlog.Log(abis.db, eventlog.CategoryImport, eventlog.EventImportCompleted, eventlog.SourceAudiobookImporter,
 		eventlog.EntityTorrent, importTorrent.Hash,
 		fmt.Sprintf("Imported audiobook: %s", bookMetadata.Title),
 		map[string]any{"name": importTorrent.Name, "hash": importTorrent.Hash, "title": bookMetadata.Title, "asin": bookMetadata.Asin})
 
-	abis.SendDiscordNotification(ctx, bookMetadata, importType)
+	// Waiting for Audiobookshelf to scan the book can take a while, so it is
+	// left until the run's other torrents have been imported
+	abis.pendingMu.Lock()
+	abis.pendingLinks = append(abis.pendingLinks, pendingLink{
+		library:      library,
+		destination:  destination,
+		bookMetadata: bookMetadata,
+		importType:   importType,
+	})
+	abis.pendingMu.Unlock()
 }
 
-func (abis *AudiobookImporterSystem) lookupMetadataByAsin(ctx context.Context, asin string) (metadata.BookMetadata, error) {
-	md := metadata.BookMetadata{}
+// Finish implements common.Finisher. It links the Audiobookshelf items of the
+// imports made since the last call and sends their notifications, waiting on
+// up to maxConcurrentLinks scans at once.
+func (abis *AudiobookImporterSystem) Finish(ctx context.Context) {
+	abis.pendingMu.Lock()
+	pending := abis.pendingLinks
+	abis.pendingLinks = nil
+	abis.pendingMu.Unlock()
+
+	if len(pending) == 0 {
+		return
+	}
 
-	metadatas, err := abis.getBookMetadataFromASINs(ctx, []string{asin})
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, maxConcurrentLinks)
+
+	for _, link := range pending {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			itemURL := abis.LinkAudiobookshelfItem(ctx, link.library, link.destination, link.bookMetadata)
+			abis.SendDiscordNotification(ctx, link.bookMetadata, link.importType, itemURL)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// LinkAudiobookshelfItem asks Audiobookshelf to scan an imported book's folder,
+// records the item it creates and returns the item's URL. It returns "" when
+// the library is not mapped to Audiobookshelf or the item cannot be found; the
+// import has succeeded either way, so failures are only logged.
+func (abis *AudiobookImporterSystem) LinkAudiobookshelfItem(ctx context.Context, library *config.ImportLibrary, destination string, bookMetadata metadata.BookMetadata) string {
+	if abis.audiobookshelf == nil || library.AudiobookshelfLibraryID == "" {
+		return ""
+	}
+
+	itemPath := audiobookshelfPath(library, destination)
+
+	// The watcher API takes a file; the OPF is always written, so it is used
+	// to point the scan at the book's folder.
+	err := abis.audiobookshelf.ScanFolder(ctx, library.AudiobookshelfLibraryID, path.Join(itemPath, "metadata.opf"))
 	if err != nil {
-		return md, err
+		slog.WarnContext(ctx, "Failed to trigger Audiobookshelf scan", slog.String("path", itemPath), slogx.Error(err))
+		return ""
 	}
 
-	if len(metadatas) == 0 {
-		return md, errors.New("no metadata found for ASIN")
+	itemID, err := abis.audiobookshelf.WaitForItem(ctx, library.AudiobookshelfLibraryID, itemPath, bookMetadata.Title)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to find Audiobookshelf item", slog.String("path", itemPath), slogx.Error(err))
+		return ""
 	}
 
-	return metadatas[0], nil
+	slog.InfoContext(ctx, "Found Audiobookshelf item", slog.String("path", itemPath), slog.String("itemID", itemID))
+	common.SetImportedItemAudiobookshelfID(ctx, abis.db, destination, itemID)
+
+	return abis.audiobookshelf.ItemURL(itemID)
+}
+
+// audiobookshelfPath maps a path in library to the same path as Audiobookshelf
+// sees it.
+func audiobookshelfPath(library *config.ImportLibrary, localPath string) string {
+	if library.AudiobookshelfPath == "" {
+		return localPath
+	}
+
+	relative := strings.TrimPrefix(strings.TrimPrefix(localPath, strings.TrimSuffix(library.Path, "/")), "/")
+	return path.Join(library.AudiobookshelfPath, relative)
+}
+
+// failImport records a failed import attempt. The torrent is marked for manual intervention
+// when the failure is permanent or out of retries, otherwise it is left for a later run.
+func (abis *AudiobookImporterSystem) failImport(ctx context.Context, importTorrent downloadclient.Torrent, importType config.ImportType, job *models.ImportJob, reason string, err error) {
+	reason = reason + ": " + err.Error()
+
+	if common.FailImportJob(ctx, abis.db, job, err, common.NewRetryPolicy(abis.cfg)) {
+		abis.MarkForManualInterventionWithNotification(ctx, importTorrent, importType.DiscordNotifier, reason)
+		return
+	}
+
+	slog.InfoContext(ctx, "Scheduled import retry",
+		slog.String("name", importTorrent.Name),
+		slog.String("hash", importTorrent.Hash),
+		slog.Int("attempt", job.Attempts),
+		slog.Any("nextAttemptAt", job.NextAttemptAt),
+	)
+
+	eventlog.Log(abis.db, eventlog.CategoryImport, eventlog.EventImportFailed, eventlog.SourceAudiobookImporter,
+		eventlog.EntityTorrent, importTorrent.Hash,
+		fmt.Sprintf("Import attempt %d failed, will retry: %s: %s", job.Attempts, importTorrent.Name, reason),
+		map[string]any{"name": importTorrent.Name, "hash": importTorrent.Hash, "reason": reason, "attempt": job.Attempts, "nextAttemptAt": job.NextAttemptAt})
 }
 
-func (abis *AudiobookImporterSystem) getBookMetadataFromASINs(ctx context.Context, asins []string) ([]metadata.BookMetadata, error) {
-	metadatas := make([]metadata.BookMetadata, 0, len(asins))
+// metadataChain returns the metadata provider chain configured for the import type
+// matching category, or the default chain when the category has none.
+func (abis *AudiobookImporterSystem) metadataChain(ctx context.Context, category string) (*providers.Chain, error) {
+	var names []string
 
-	for _, asin := range asins {
-		md, err := abis.audible.GetMetadataFromAsin(asin)
+	if abis.db != nil {
+		importType, ok, err := common.FindImportType(ctx, abis.db, category)
 		if err != nil {
-			slog.WarnContext(ctx, "Failed to get metadata from ASIN", slog.String("asin", asin), slogx.Error(err))
-			continue
+			return nil, err
 		}
+		if ok {
+			names = common.SplitMetadataProviders(importType.MetadataProviders)
+		}
+	}
+
+	return abis.metadataSources.Chain(names, abis.cfg.AudiobookImporter.FieldPrecedence)
+}
+
+func (abis *AudiobookImporterSystem) lookupMetadataByAsin(ctx context.Context, category string, asin string) (metadata.BookMetadata, error) {
+	md := metadata.BookMetadata{}
+
+	chain, err := abis.metadataChain(ctx, category)
+	if err != nil {
+		return md, err
+	}
+
+	metadatas, provider, err := chain.Search(ctx, providers.Query{ASIN: asin})
+	if err != nil {
+		return md, err
+	}
 
-		metadatas = append(metadatas, md)
+	if len(metadatas) == 0 {
+		return md, errors.New("no metadata found for ASIN")
 	}
 
-	return metadatas, nil
+	return chain.Enrich(ctx, provider, metadatas[0]), nil
 }
 
-func (abis *AudiobookImporterSystem) lookupMetadataByTitle(ctx context.Context, title string, author string) (metadata.BookMetadata, error) {
+// lookupMetadataByTitle searches the metadata providers by title and author. When several
+// editions match, each candidate is scored against the hints and the best one is auto-selected
+// if it is confident enough. Otherwise the ranked candidates are saved for manual selection.
+func (abis *AudiobookImporterSystem) lookupMetadataByTitle(ctx context.Context, importTorrent downloadclient.Torrent, hints MatchHints) (metadata.BookMetadata, error) {
 	md := metadata.BookMetadata{}
 
-	asins, err := abis.audible.SearchByTitle(title, author)
+	chain, err := abis.metadataChain(ctx, importTorrent.Category)
+	if err != nil {
+		return md, common.Permanent(err)
+	}
+
+	candidates, provider, err := chain.Search(ctx, providers.Query{Title: hints.Title, Author: hints.Author})
 	if err != nil {
 		return md, err
 	}
 
-	switch len(asins) {
+	switch len(candidates) {
 	case 0:
-		return md, errors.New("no ASINs found for title")
+		return md, common.Permanent(errors.New("no metadata found for title"))
 	case 1:
-		return abis.lookupMetadataByAsin(ctx, asins[0])
+		return chain.Enrich(ctx, provider, candidates[0]), nil
 	default:
-		asinMetadatas, _ := abis.getBookMetadataFromASINs(ctx, asins)
+		ranked := RankCandidates(hints, candidates)
+
+		selected, ok := SelectCandidate(ranked, abis.autoSelectThreshold())
+		if ok {
+			slog.InfoContext(ctx, "Auto-selected best matching candidate",
+				slog.String("provider", provider),
+				slog.String("asin", selected.Metadata.Asin),
+				slog.String("title", selected.Metadata.Title),
+				slog.Float64("score", selected.Score),
+				slog.Int("candidates", len(ranked)))
+
+			return chain.Enrich(ctx, provider, selected.Metadata), nil
+		}
 
-		var summaries []string
+		abis.saveCandidates(ctx, importTorrent.Hash, ranked)
 
-		if len(asinMetadatas) > 0 {
-			summaries, _ = summarizeBookMetadatas(asinMetadatas)
-		}
+		summaries, _ := summarizeBookMetadatas(candidates)
+
+		slog.InfoContext(ctx, "Multiple candidates found for title, manual selection required",
+			slog.String("provider", provider),
+			slog.Any("summaries", summaries),
+			slog.Float64("bestScore", ranked[0].Score))
 
-		slog.Info("Multiple ASINs found for title, manual selection required", slog.Any("asins", asins), slog.Any("summaries", summaries))
+		return md, common.Permanent(fmt.Errorf("multiple candidates found for title, manual selection required (best score %.2f)", ranked[0].Score))
+	}
+}
 
-		return md, errors.New("multiple ASINs found for title, manual selection required")
+// audioDuration returns the total duration of the audio files, or zero when it cannot be determined.
+func (abis *AudiobookImporterSystem) audioDuration(ctx context.Context, files []common.MappedTorrentFile) time.Duration {
+	if abis.mediaProbe == nil {
+		return 0
 	}
+
+	audioFiles, err := bookFilePaths(files)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to analyze audio files for matching", slogx.Error(err))
+		return 0
+	}
+
+	duration, _, err := abis.probeAudioFiles(ctx, audioFiles)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to probe audio duration for matching", slogx.Error(err))
+		return 0
+	}
+
+	return duration
 }
 
 func summarizeBookMetadatas(metadatas []metadata.BookMetadata) ([]string, error) {
@@ -7,12 +7,15 @@ import (
 	"testing"
 	"time"
 
-	"github.com/autobrr/go-qbittorrent"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/providers"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/testutil"
 )
 
 func createTestBookMetadata(title, asin string) metadata.BookMetadata {
@@ -58,7 +61,7 @@ func TestExecuteImport_WithFolder(t *testing.T) {
 	err = os.WriteFile(testFile, []byte("test content"), 0644)
 	require.NoError(t, err)
 
-	testTorrent := qbittorrent.Torrent{
+	testTorrent := downloadclient.Torrent{
 		Hash: "folder123",
 		Name: "Test Audiobook Folder",
 	}
@@ -97,7 +100,7 @@ func TestExecuteImport_WithFile(t *testing.T) {
 	err := os.WriteFile(sourceFile, []byte("test content"), 0644)
 	require.NoError(t, err)
 
-	testTorrent := qbittorrent.Torrent{
+	testTorrent := downloadclient.Torrent{
 		Hash: "file123",
 		Name: "Test Audiobook File",
 	}
@@ -137,7 +140,7 @@ func TestExecuteImport_InvalidLocalPath(t *testing.T) {
 
 	tempDir := t.TempDir()
 
-	testTorrent := qbittorrent.Torrent{
+	testTorrent := downloadclient.Torrent{
 		Hash: "invalid123",
 		Name: "Test Audiobook",
 	}
@@ -157,3 +160,44 @@ func TestExecuteImport_InvalidLocalPath(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to stat local path")
 }
+
+func TestRun_SkipsInvalidMetadataProviders(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	var bookType models.BookType
+	require.NoError(t, db.Where("name = ?", "audiobook").First(&bookType).Error)
+
+	library := models.Library{Name: "audiobooks", Path: t.TempDir(), BookTypeID: bookType.ID}
+	require.NoError(t, db.Create(&library).Error)
+
+	for category, metadataProviders := range map[string]string{
+		"audiobooks":          providers.AudibleProviderName,
+		"personal-audiobooks": "goodreads",
+	} {
+		var torrentCategory models.TorrentCategory
+		require.NoError(t, db.Where("name = ?", category).First(&torrentCategory).Error)
+		require.NoError(t, db.Create(&models.ImportType{
+			TorrentCategoryID: torrentCategory.ID,
+			LibraryID:         library.ID,
+			MetadataProviders: metadataProviders,
+		}).Error)
+	}
+
+	var categories []string
+	client := &testutil.MockDownloadClient{
+		GetTorrentsFunc: func(ctx context.Context, filter downloadclient.TorrentFilter) ([]downloadclient.Torrent, error) {
+			categories = append(categories, filter.Category)
+			return nil, nil
+		},
+	}
+
+	importer := &AudiobookImporterSystem{
+		downloadClient:  client,
+		metadataSources: providers.NewRegistry(&stubProvider{name: providers.AudibleProviderName}),
+		db:              db,
+	}
+
+	require.NoError(t, importer.Run(context.Background()))
+	assert.Equal(t, []string{"audiobooks"}, categories)
+}
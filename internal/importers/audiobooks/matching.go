@@ -0,0 +1,328 @@
+package audiobooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/cappuccinotm/slogx"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+const (
+	defaultAutoSelectThreshold = 0.8
+
+	// autoSelectMinMargin is how far the best candidate must lead the runner-up
+	// to be auto-selected, so near-identical editions still go to a human.
+	autoSelectMinMargin = 0.05
+)
+
+// Relative weight of each signal. Signals missing from the file tags are left
+// out of the weighted average rather than counted as a mismatch.
+const (
+	titleWeight    = 0.45
+	authorWeight   = 0.25
+	narratorWeight = 0.10
+	runtimeWeight  = 0.15
+	seriesWeight   = 0.05
+)
+
+var parentheticalPattern = regexp.MustCompile(`[\(\[][^\)\]]*[\)\]]`)
+
+// MatchHints is what we know about a torrent's book from its files.
+type MatchHints struct {
+	Title          string
+	Author         string
+	Narrator       string
+	SeriesPosition string
+	Duration       time.Duration
+}
+
+// ScoredCandidate is a metadata candidate with its confidence score in the range [0, 1].
+type ScoredCandidate struct {
+	Metadata metadata.BookMetadata
+	Score    float64
+}
+
+// HintsFromTags builds MatchHints from audio file tags and the total audio duration.
+func HintsFromTags(tags metadata.MetadataTags, duration time.Duration) MatchHints {
+	hints := MatchHints{Duration: duration}
+
+	hints.Title, _ = tags.Title()
+	hints.Author, _ = tags.Artist()
+	hints.Narrator, _ = tags.Narrator()
+	hints.SeriesPosition, _ = tags.SeriesPosition()
+
+	return hints
+}
+
+// ScoreCandidate scores how well a metadata candidate matches the hints.
+func ScoreCandidate(hints MatchHints, candidate metadata.BookMetadata) float64 {
+	total := titleWeight * titleSimilarity(hints.Title, candidate)
+	weights := titleWeight
+
+	if hints.Author != "" && len(candidate.Authors) > 0 {
+		total += authorWeight * peopleOverlap(hints.Author, candidate.Authors)
+		weights += authorWeight
+	}
+
+	if hints.Narrator != "" && len(candidate.Narrators) > 0 {
+		total += narratorWeight * peopleOverlap(hints.Narrator, candidate.Narrators)
+		weights += narratorWeight
+	}
+
+	if hints.Duration > 0 && candidate.RuntimeLength > 0 {
+		total += runtimeWeight * runtimeSimilarity(hints.Duration, candidate.RuntimeLength)
+		weights += runtimeWeight
+	}
+
+	if hints.SeriesPosition != "" && candidate.PrimarySeries != nil && candidate.PrimarySeries.Position != nil {
+		total += seriesWeight * seriesPositionMatch(hints.SeriesPosition, *candidate.PrimarySeries.Position)
+		weights += seriesWeight
+	}
+
+	return total / weights
+}
+
+// RankCandidates scores every candidate and sorts them best first.
+func RankCandidates(hints MatchHints, candidates []metadata.BookMetadata) []ScoredCandidate {
+	ranked := make([]ScoredCandidate, len(candidates))
+
+	for i, candidate := range candidates {
+		ranked[i] = ScoredCandidate{
+			Metadata: candidate,
+			Score:    ScoreCandidate(hints, candidate),
+		}
+	}
+
+	slices.SortStableFunc(ranked, func(a, b ScoredCandidate) int {
+		switch {
+		case a.Score > b.Score:
+			return -1
+		case a.Score < b.Score:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return ranked
+}
+
+// SelectCandidate returns the best ranked candidate when it scores at or above
+// threshold and clearly beats the runner-up.
+func SelectCandidate(ranked []ScoredCandidate, threshold float64) (ScoredCandidate, bool) {
+	if len(ranked) == 0 || ranked[0].Score < threshold {
+		return ScoredCandidate{}, false
+	}
+
+	if len(ranked) > 1 && ranked[0].Score-ranked[1].Score < autoSelectMinMargin {
+		return ScoredCandidate{}, false
+	}
+
+	return ranked[0], true
+}
+
+// autoSelectThreshold returns the configured threshold, or the default when unset.
+func (abis *AudiobookImporterSystem) autoSelectThreshold() float64 {
+	if abis.cfg.AudiobookImporter.AutoSelectThreshold > 0 {
+		return abis.cfg.AudiobookImporter.AutoSelectThreshold
+	}
+
+	return defaultAutoSelectThreshold
+}
+
+// saveCandidates replaces the stored candidates for a torrent with the ranked list.
+func (abis *AudiobookImporterSystem) saveCandidates(ctx context.Context, torrentHash string, ranked []ScoredCandidate) {
+	if abis.db == nil || torrentHash == "" {
+		return
+	}
+
+	rows := make([]models.AudiobookMatchCandidate, 0, len(ranked))
+
+	for i, candidate := range ranked {
+		encoded, err := json.Marshal(candidate.Metadata)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to marshal candidate metadata",
+				slog.String("asin", candidate.Metadata.Asin),
+				slogx.Error(err))
+			continue
+		}
+
+		rows = append(rows, models.AudiobookMatchCandidate{
+			TorrentHash: torrentHash,
+			Asin:        candidate.Metadata.Asin,
+			Rank:        i + 1,
+			Score:       candidate.Score,
+			Summary:     candidate.Metadata.Summarize(),
+			Metadata:    string(encoded),
+		})
+	}
+
+	err := abis.db.Where("torrent_hash = ?", torrentHash).Delete(&models.AudiobookMatchCandidate{}).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to clear match candidates", slog.String("hash", torrentHash), slogx.Error(err))
+		return
+	}
+
+	if len(rows) == 0 {
+		return
+	}
+
+	err = abis.db.Create(&rows).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save match candidates", slog.String("hash", torrentHash), slogx.Error(err))
+	}
+}
+
+// LoadCandidates returns the stored candidates for a torrent, best first.
+func LoadCandidates(db *gorm.DB, torrentHash string) ([]ScoredCandidate, error) {
+	var rows []models.AudiobookMatchCandidate
+
+	err := db.Where("torrent_hash = ?", torrentHash).Order("rank").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return candidatesFromRows(rows)
+}
+
+func candidatesFromRows(rows []models.AudiobookMatchCandidate) ([]ScoredCandidate, error) {
+	candidates := make([]ScoredCandidate, 0, len(rows))
+
+	for _, row := range rows {
+		var md metadata.BookMetadata
+
+		err := json.Unmarshal([]byte(row.Metadata), &md)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal candidate %s: %w", row.Asin, err)
+		}
+
+		candidates = append(candidates, ScoredCandidate{Metadata: md, Score: row.Score})
+	}
+
+	return candidates, nil
+}
+
+// normalizeWords lowercases s, strips parenthesized qualifiers like "(Unabridged)"
+// and punctuation, and splits it into words.
+func normalizeWords(s string) []string {
+	s = parentheticalPattern.ReplaceAllString(strings.ToLower(s), " ")
+
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// wordSimilarity is the Dice coefficient of the word sets of a and b.
+func wordSimilarity(a, b string) float64 {
+	aWords := normalizeWords(a)
+	bWords := normalizeWords(b)
+
+	if len(aWords) == 0 || len(bWords) == 0 {
+		return 0
+	}
+
+	bSet := make(map[string]bool, len(bWords))
+	for _, word := range bWords {
+		bSet[word] = true
+	}
+
+	aSet := make(map[string]bool, len(aWords))
+	shared := 0
+
+	for _, word := range aWords {
+		if aSet[word] {
+			continue
+		}
+		aSet[word] = true
+
+		if bSet[word] {
+			shared++
+		}
+	}
+
+	return 2 * float64(shared) / float64(len(aSet)+len(bSet))
+}
+
+// titleSimilarity compares the tag title against the candidate title, with and
+// without its subtitle, since taggers are inconsistent about including it.
+func titleSimilarity(title string, candidate metadata.BookMetadata) float64 {
+	score := wordSimilarity(title, candidate.Title)
+
+	if candidate.Subtitle != nil {
+		score = math.Max(score, wordSimilarity(title, candidate.Title+" "+*candidate.Subtitle))
+	}
+
+	return score
+}
+
+// peopleOverlap is the fraction of people whose names all appear in the tag value.
+// Tags often hold several names separated by commas, ampersands or slashes.
+func peopleOverlap(tagValue string, people []metadata.Person) float64 {
+	tagWords := make(map[string]bool)
+	for _, word := range normalizeWords(tagValue) {
+		tagWords[word] = true
+	}
+
+	matched := 0
+
+	for _, person := range people {
+		words := normalizeWords(person.Name)
+		if len(words) == 0 {
+			continue
+		}
+
+		found := true
+		for _, word := range words {
+			if !tagWords[word] {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			matched++
+		}
+	}
+
+	return float64(matched) / float64(len(people))
+}
+
+// runtimeSimilarity falls off linearly, reaching zero when the file duration is
+// half or double the candidate runtime away.
+func runtimeSimilarity(duration time.Duration, runtimeMinutes int) float64 {
+	expected := float64(time.Duration(runtimeMinutes) * time.Minute)
+	difference := math.Abs(float64(duration) - expected)
+
+	return math.Max(0, 1-difference/(expected/2))
+}
+
+func seriesPositionMatch(tagPosition string, candidatePosition string) float64 {
+	a, aErr := strconv.ParseFloat(strings.TrimSpace(tagPosition), 64)
+	b, bErr := strconv.ParseFloat(strings.TrimSpace(candidatePosition), 64)
+
+	if aErr == nil && bErr == nil {
+		if a == b {
+			return 1
+		}
+		return 0
+	}
+
+	if strings.EqualFold(strings.TrimSpace(tagPosition), strings.TrimSpace(candidatePosition)) {
+		return 1
+	}
+
+	return 0
+}
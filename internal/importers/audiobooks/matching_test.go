@@ -0,0 +1,225 @@
+package audiobooks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/providers"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func createTestCandidate(asin, title, author, narrator string, runtimeMinutes int) metadata.BookMetadata {
+	md := createTestBookMetadata(title, asin)
+	md.Authors = []metadata.Person{{Name: author}}
+	md.Narrators = []metadata.Person{{Name: narrator}}
+	md.RuntimeLength = runtimeMinutes
+	return md
+}
+
+func TestScoreCandidate(t *testing.T) {
+	hints := MatchHints{
+		Title:    "The Way of Kings (Unabridged)",
+		Author:   "Brandon Sanderson",
+		Narrator: "Michael Kramer, Kate Reading",
+		Duration: 45 * time.Hour,
+	}
+
+	exact := createTestCandidate("B003P2WO5E", "The Way of Kings", "Brandon Sanderson", "Michael Kramer", 45*60)
+	assert.InDelta(t, 1.0, ScoreCandidate(hints, exact), 0.001)
+
+	dramatized := createTestCandidate("B0D1", "The Way of Kings", "Brandon Sanderson", "Full Cast", 15*60)
+	assert.Less(t, ScoreCandidate(hints, dramatized), ScoreCandidate(hints, exact))
+
+	otherBook := createTestCandidate("B0D2", "Words of Radiance", "Brandon Sanderson", "Michael Kramer", 48*60)
+	assert.Less(t, ScoreCandidate(hints, otherBook), defaultAutoSelectThreshold)
+}
+
+func TestScoreCandidate_MissingSignalsAreIgnored(t *testing.T) {
+	// Only a title is known, so a title match alone is a perfect score
+	hints := MatchHints{Title: "Project Hail Mary"}
+	candidate := createTestCandidate("B08G9PRS1K", "Project Hail Mary", "Andy Weir", "Ray Porter", 16*60)
+
+	assert.InDelta(t, 1.0, ScoreCandidate(hints, candidate), 0.001)
+}
+
+func TestScoreCandidate_SeriesPosition(t *testing.T) {
+	first := "1"
+	second := "2.0"
+
+	hints := MatchHints{Title: "Dungeon Crawler Carl", SeriesPosition: "2"}
+
+	book1 := createTestCandidate("B1", "Dungeon Crawler Carl", "Matt Dinniman", "Jeff Hays", 0)
+	book1.PrimarySeries = &metadata.Series{Name: "Dungeon Crawler Carl", Position: &first}
+
+	book2 := createTestCandidate("B2", "Dungeon Crawler Carl", "Matt Dinniman", "Jeff Hays", 0)
+	book2.PrimarySeries = &metadata.Series{Name: "Dungeon Crawler Carl", Position: &second}
+
+	assert.Greater(t, ScoreCandidate(hints, book2), ScoreCandidate(hints, book1))
+}
+
+func TestRankCandidates(t *testing.T) {
+	hints := MatchHints{Title: "Dune", Author: "Frank Herbert", Duration: 21 * time.Hour}
+
+	ranked := RankCandidates(hints, []metadata.BookMetadata{
+		createTestCandidate("B_ABRIDGED", "Dune", "Frank Herbert", "Narrator", 8*60),
+		createTestCandidate("B_OTHER", "Dune Messiah", "Frank Herbert", "Narrator", 9*60),
+		createTestCandidate("B_FULL", "Dune", "Frank Herbert", "Narrator", 21*60),
+	})
+
+	require.Len(t, ranked, 3)
+	assert.Equal(t, "B_FULL", ranked[0].Metadata.Asin)
+	assert.GreaterOrEqual(t, ranked[0].Score, ranked[1].Score)
+	assert.GreaterOrEqual(t, ranked[1].Score, ranked[2].Score)
+}
+
+func TestSelectCandidate(t *testing.T) {
+	candidate := func(asin string, score float64) ScoredCandidate {
+		return ScoredCandidate{Metadata: metadata.BookMetadata{Asin: asin}, Score: score}
+	}
+
+	tests := []struct {
+		name     string
+		ranked   []ScoredCandidate
+		wantAsin string
+		wantOk   bool
+	}{
+		{name: "no candidates"},
+		{name: "below threshold", ranked: []ScoredCandidate{candidate("A", 0.7), candidate("B", 0.2)}},
+		{name: "too close to runner-up", ranked: []ScoredCandidate{candidate("A", 0.95), candidate("B", 0.93)}},
+		{name: "confident", ranked: []ScoredCandidate{candidate("A", 0.95), candidate("B", 0.6)}, wantAsin: "A", wantOk: true},
+		{name: "single candidate", ranked: []ScoredCandidate{candidate("A", 0.85)}, wantAsin: "A", wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, ok := SelectCandidate(tt.ranked, defaultAutoSelectThreshold)
+			assert.Equal(t, tt.wantOk, ok)
+			assert.Equal(t, tt.wantAsin, selected.Metadata.Asin)
+		})
+	}
+}
+
+func TestSaveAndLoadCandidates(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	importer := &AudiobookImporterSystem{db: db}
+
+	hints := MatchHints{Title: "Dune", Duration: 21 * time.Hour}
+	ranked := RankCandidates(hints, []metadata.BookMetadata{
+		createTestCandidate("B_ABRIDGED", "Dune", "Frank Herbert", "Narrator", 8*60),
+		createTestCandidate("B_FULL", "Dune", "Frank Herbert", "Narrator", 21*60),
+	})
+
+	importer.saveCandidates(ctx, "abc123", ranked)
+
+	loaded, err := LoadCandidates(db, "abc123")
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	assert.Equal(t, "B_FULL", loaded[0].Metadata.Asin)
+	assert.Equal(t, "Dune", loaded[0].Metadata.Title)
+	assert.InDelta(t, ranked[0].Score, loaded[0].Score, 0.0001)
+
+	// Saving again replaces the previous candidates
+	importer.saveCandidates(ctx, "abc123", ranked[:1])
+
+	loaded, err = LoadCandidates(db, "abc123")
+	require.NoError(t, err)
+	assert.Len(t, loaded, 1)
+
+	loaded, err = LoadCandidates(db, "unknown")
+	require.NoError(t, err)
+	assert.Empty(t, loaded)
+}
+
+// stubProvider is a metadata provider returning fixed candidates
+type stubProvider struct {
+	name    string
+	results []metadata.BookMetadata
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) Search(ctx context.Context, query providers.Query) ([]metadata.BookMetadata, error) {
+	return p.results, nil
+}
+
+func TestLookupMetadataByTitle(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	importTorrent := downloadclient.Torrent{Hash: "abc123", Category: "audiobooks"}
+	hints := MatchHints{Title: "Dune", Author: "Frank Herbert", Duration: 21 * time.Hour}
+
+	newImporter := func(candidates ...metadata.BookMetadata) *AudiobookImporterSystem {
+		return &AudiobookImporterSystem{
+			db:              db,
+			metadataSources: providers.NewRegistry(&stubProvider{name: providers.AudibleProviderName, results: candidates}),
+		}
+	}
+
+	t.Run("confident match is auto-selected", func(t *testing.T) {
+		importer := newImporter(
+			createTestCandidate("B_ABRIDGED", "Dune", "Frank Herbert", "Narrator", 8*60),
+			createTestCandidate("B_FULL", "Dune", "Frank Herbert", "Narrator", 21*60),
+		)
+
+		md, err := importer.lookupMetadataByTitle(ctx, importTorrent, hints)
+		require.NoError(t, err)
+		assert.Equal(t, "B_FULL", md.Asin)
+
+		// Nothing is left for manual selection
+		loaded, err := LoadCandidates(db, importTorrent.Hash)
+		require.NoError(t, err)
+		assert.Empty(t, loaded)
+	})
+
+	t.Run("ambiguous match requires manual selection", func(t *testing.T) {
+		importer := newImporter(
+			createTestCandidate("B_ONE", "Dune", "Frank Herbert", "Narrator", 21*60),
+			createTestCandidate("B_TWO", "Dune", "Frank Herbert", "Narrator", 21*60),
+		)
+
+		_, err := importer.lookupMetadataByTitle(ctx, importTorrent, hints)
+		assert.ErrorContains(t, err, "manual selection required")
+
+		loaded, err := LoadCandidates(db, importTorrent.Hash)
+		require.NoError(t, err)
+		assert.Len(t, loaded, 2)
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		_, err := newImporter().lookupMetadataByTitle(ctx, importTorrent, hints)
+		assert.Error(t, err)
+	})
+
+	t.Run("unknown provider in import type", func(t *testing.T) {
+		var bookType models.BookType
+		require.NoError(t, db.Where("name = ?", "audiobook").First(&bookType).Error)
+
+		library := models.Library{Name: "audiobooks", Path: t.TempDir(), BookTypeID: bookType.ID}
+		require.NoError(t, db.Create(&library).Error)
+
+		var category models.TorrentCategory
+		require.NoError(t, db.Where("name = ?", "audiobooks").First(&category).Error)
+
+		require.NoError(t, db.Create(&models.ImportType{
+			TorrentCategoryID: category.ID,
+			LibraryID:         library.ID,
+			MetadataProviders: "goodreads",
+		}).Error)
+
+		_, err := newImporter().lookupMetadataByTitle(ctx, importTorrent, hints)
+		assert.ErrorContains(t, err, "unknown metadata provider")
+	})
+}
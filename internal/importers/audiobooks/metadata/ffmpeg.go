@@ -0,0 +1,80 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FFmpegTagWriter implements TagWriter by remuxing the file with ffmpeg
+type FFmpegTagWriter struct {
+	// Command is the ffmpeg binary, "ffmpeg" when empty
+	Command string
+}
+
+// NewFFmpegTagWriter creates a new FFmpegTagWriter instance
+func NewFFmpegTagWriter() TagWriter {
+	return &FFmpegTagWriter{}
+}
+
+// WriteTags copies the streams of path into a temporary file next to it with
+// the new tags, then renames it over path. Renaming gives the library its own
+// copy of a file that was hard linked from the download, so the seeding torrent
+// is left untouched.
+func (fftw *FFmpegTagWriter) WriteTags(ctx context.Context, path string, tags AudioTags) error {
+	command := fftw.Command
+	if command == "" {
+		command = "ffmpeg"
+	}
+
+	ext := filepath.Ext(path)
+	tempPath := strings.TrimSuffix(path, ext) + ".tagging" + ext
+
+	args := []string{"-y", "-loglevel", "error", "-i", path, "-map", "0", "-c", "copy", "-map_metadata", "0"}
+	args = append(args, formatArgs(ext)...)
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		if tags[key] != "" {
+			args = append(args, "-metadata", key+"="+tags[key])
+		}
+	}
+	args = append(args, tempPath)
+
+	output, err := exec.CommandContext(ctx, command, args...).CombinedOutput()
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("unable to write tags to %s: %w: %s", path, err, strings.TrimSpace(string(output)))
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		_ = os.Remove(tempPath)
+		return fmt.Errorf("unable to replace %s with tagged file: %w", path, err)
+	}
+
+	return nil
+}
+
+// formatArgs returns the muxer options needed to store every tag for the
+// file's container.
+func formatArgs(ext string) []string {
+	switch strings.ToLower(ext) {
+	case ".mp3":
+		// ID3v2.3 is the version most players read; other tags become TXXX frames
+		return []string{"-id3v2_version", "3"}
+	case ".m4b", ".m4a", ".mp4":
+		// Without this the mp4 muxer drops tags it has no atom for, such as AUDIBLE_ASIN
+		return []string{"-f", "ipod", "-movflags", "use_metadata_tags"}
+	}
+
+	return nil
+}
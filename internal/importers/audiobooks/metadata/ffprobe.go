@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"os/exec"
 	"strings"
 
 	"gopkg.in/vansante/go-ffprobe.v2"
@@ -51,6 +52,28 @@ func (fft *FFProbeTags) AudibleASIN() (string, bool) {
 	return strings.TrimPrefix(value, mp3AudibleAsinPrefix), true
 }
 
+// Narrator retrieves the narrator, which audiobook taggers store in the composer tag
+func (fft *FFProbeTags) Narrator() (string, bool) {
+	for _, key := range []string{"composer", "narrator"} {
+		value, err := fft.tagList.GetString(key)
+		if err == nil && value != "" {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+// SeriesPosition retrieves the series-part tag
+func (fft *FFProbeTags) SeriesPosition() (string, bool) {
+	value, err := fft.tagList.GetString("series-part")
+	if err != nil || value == "" {
+		return "", false
+	}
+
+	return value, true
+}
+
 // FFProbeMetadataProvider implements MetadataProvider using ffprobe
 type FFProbeMetadataProvider struct{}
 
@@ -83,3 +106,60 @@ func (ffmp *FFProbeMetadataProvider) GetMetadata(ctx context.Context, path strin
 
 	return NewFFProbeTags(ffprobe.Tags{}), nil
 }
+
+// FFProbeMediaProbe implements MediaProbe using ffprobe, with ffmpeg for artwork extraction
+type FFProbeMediaProbe struct{}
+
+// NewFFProbeMediaProbe creates a new FFProbeMediaProbe instance
+func NewFFProbeMediaProbe() MediaProbe {
+	return &FFProbeMediaProbe{}
+}
+
+// ProbeMedia retrieves duration, chapters and artwork presence for the given file path
+func (ffmp *FFProbeMediaProbe) ProbeMedia(ctx context.Context, path string) (MediaInfo, error) {
+	var info MediaInfo
+
+	probeData, err := ffprobe.ProbeURL(ctx, path)
+	if err != nil {
+		return info, fmt.Errorf("unable to probe media %s: %w", path, err)
+	}
+
+	if probeData.Format != nil {
+		info.Duration = probeData.Format.Duration()
+	}
+
+	for _, chapter := range probeData.Chapters {
+		info.Chapters = append(info.Chapters, Chapter{
+			Title: chapter.Title(),
+			Start: chapter.StartTimeSeconds,
+			End:   chapter.EndTimeSeconds,
+		})
+	}
+
+	for _, stream := range probeData.Streams {
+		if stream.Disposition.AttachedPic == 1 {
+			info.HasEmbeddedCover = true
+			break
+		}
+	}
+
+	slog.InfoContext(ctx, "ffprobe media info",
+		slog.String("path", path),
+		slog.Duration("duration", info.Duration),
+		slog.Int("chapters", len(info.Chapters)),
+		slog.Bool("hasEmbeddedCover", info.HasEmbeddedCover))
+
+	return info, nil
+}
+
+// ExtractCover writes the first attached picture of the given file to dest using ffmpeg
+func (ffmp *FFProbeMediaProbe) ExtractCover(ctx context.Context, path string, dest string) error {
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-loglevel", "error", "-i", path, "-an", "-map", "0:v:0", "-frames:v", "1", dest)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("unable to extract cover from %s: %w: %s", path, err, strings.TrimSpace(string(output)))
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package metadata
+
+import (
+	"context"
+	"time"
+)
+
+// Chapter is a single chapter marker within an audiobook, in seconds from the start of the book
+type Chapter struct {
+	Title string  `json:"title"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// MediaInfo describes the audio content of a single file
+type MediaInfo struct {
+	Duration         time.Duration
+	Chapters         []Chapter
+	HasEmbeddedCover bool
+}
+
+// MediaProbe defines methods to inspect audio files beyond their tags
+type MediaProbe interface {
+	// ProbeMedia returns the duration, chapters and artwork presence for the given file
+	ProbeMedia(ctx context.Context, path string) (MediaInfo, error)
+
+	// ExtractCover writes the embedded artwork of the given file to dest as a JPEG
+	ExtractCover(ctx context.Context, path string, dest string) error
+}
@@ -15,6 +15,8 @@ type MetadataTags interface {
 	Artist() (string, bool)
 	Title() (string, bool)
 	AudibleASIN() (string, bool)
+	Narrator() (string, bool)
+	SeriesPosition() (string, bool)
 }
 
 // MetadataProvider defines method to get metadata for a given path
@@ -0,0 +1,63 @@
+package metadata
+
+import (
+	"context"
+	"strings"
+)
+
+// AudioTags maps tag names, as ffmpeg calls them, to values. Empty values are
+// left out when writing.
+type AudioTags map[string]string
+
+// TagWriter defines a method to rewrite the tags of an audio file in place
+type TagWriter interface {
+	// WriteTags replaces the given tags of the file at path, keeping its audio,
+	// chapters, artwork and any tags not in tags
+	WriteTags(ctx context.Context, path string, tags AudioTags) error
+}
+
+// AudioTags returns the tags written to imported audio files. The narrator goes
+// in the composer tag, where audiobook players and FFProbeTags look for it.
+func (md *BookMetadata) AudioTags() AudioTags {
+	authors := joinPeople(md.Authors)
+
+	tags := AudioTags{
+		"title":        md.Title,
+		"album":        md.Title,
+		"artist":       authors,
+		"album_artist": authors,
+		"composer":     joinPeople(md.Narrators),
+		"AUDIBLE_ASIN": md.Asin,
+	}
+
+	if !md.ReleaseDate.IsZero() {
+		tags["date"] = md.ReleaseDate.Format("2006")
+	}
+
+	var genres []string
+	for _, genre := range md.Genres {
+		// Audible also returns free-form tags alongside the genres
+		if genre.Type == "" || genre.Type == "genre" {
+			genres = append(genres, genre.Name)
+		}
+	}
+	tags["genre"] = strings.Join(genres, "/")
+
+	if md.PrimarySeries != nil {
+		tags["series"] = md.PrimarySeries.Name
+		if md.PrimarySeries.Position != nil {
+			tags["series-part"] = *md.PrimarySeries.Position
+		}
+	}
+
+	return tags
+}
+
+func joinPeople(people []Person) string {
+	names := make([]string, 0, len(people))
+	for _, person := range people {
+		names = append(names, person.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
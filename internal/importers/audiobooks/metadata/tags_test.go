@@ -0,0 +1,109 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBookMetadata_AudioTags(t *testing.T) {
+	position := "1"
+	md := BookMetadata{
+		Asin:        "B002V1OF70",
+		Title:       "Dune",
+		Authors:     []Person{{Name: "Frank Herbert"}},
+		Narrators:   []Person{{Name: "Scott Brick"}, {Name: "Orlagh Cassidy"}},
+		ReleaseDate: time.Date(2007, 12, 31, 0, 0, 0, 0, time.UTC),
+		Genres: []Genre{
+			{Name: "Science Fiction & Fantasy", Type: "genre"},
+			{Name: "Space Opera", Type: "tag"},
+		},
+		PrimarySeries: &Series{Name: "Dune", Position: &position},
+	}
+
+	assert.Equal(t, AudioTags{
+		"title":        "Dune",
+		"album":        "Dune",
+		"artist":       "Frank Herbert",
+		"album_artist": "Frank Herbert",
+		"composer":     "Scott Brick, Orlagh Cassidy",
+		"AUDIBLE_ASIN": "B002V1OF70",
+		"date":         "2007",
+		"genre":        "Science Fiction & Fantasy",
+		"series":       "Dune",
+		"series-part":  "1",
+	}, md.AudioTags())
+}
+
+// writeStubFFmpeg writes a shell script standing in for ffmpeg that records its
+// arguments and copies the input to the output, the last argument.
+func writeStubFFmpeg(t *testing.T, dir string, fail bool) (string, string) {
+	t.Helper()
+
+	argsFile := filepath.Join(dir, "args")
+	script := "#!/bin/sh\necho \"$@\" > \"" + argsFile + "\"\n"
+	if fail {
+		script += "echo 'Invalid data found' >&2\nexit 1\n"
+	} else {
+		script += "for last; do :; done\necho tagged > \"$last\"\n"
+	}
+
+	command := filepath.Join(dir, "ffmpeg")
+	require.NoError(t, os.WriteFile(command, []byte(script), 0755))
+	return command, argsFile
+}
+
+func TestFFmpegTagWriter_WriteTags(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("replaces the file", func(t *testing.T) {
+		dir := t.TempDir()
+		command, argsFile := writeStubFFmpeg(t, dir, false)
+
+		file := filepath.Join(dir, "book.m4b")
+		link := filepath.Join(dir, "download.m4b")
+		require.NoError(t, os.WriteFile(file, []byte("original"), 0644))
+		require.NoError(t, os.Link(file, link))
+
+		writer := &FFmpegTagWriter{Command: command}
+		require.NoError(t, writer.WriteTags(ctx, file, AudioTags{"title": "Dune", "genre": ""}))
+
+		args, err := os.ReadFile(argsFile)
+		require.NoError(t, err)
+		assert.Equal(t, "-y -loglevel error -i "+file+" -map 0 -c copy -map_metadata 0 -f ipod -movflags use_metadata_tags -metadata title=Dune "+filepath.Join(dir, "book.tagging.m4b")+"\n", string(args))
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "tagged\n", string(content))
+
+		// The hard linked download keeps its original content
+		content, err = os.ReadFile(link)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(content))
+	})
+
+	t.Run("failure keeps the original", func(t *testing.T) {
+		dir := t.TempDir()
+		command, _ := writeStubFFmpeg(t, dir, true)
+
+		file := filepath.Join(dir, "book.mp3")
+		require.NoError(t, os.WriteFile(file, []byte("original"), 0644))
+
+		writer := &FFmpegTagWriter{Command: command}
+		err := writer.WriteTags(ctx, file, AudioTags{"title": "Dune"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Invalid data found")
+
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+		assert.Equal(t, "original", string(content))
+
+		_, err = os.Stat(filepath.Join(dir, "book.tagging.mp3"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,63 @@
+package providers
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/audible"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// AudibleProvider searches the Audible catalog and fetches details from Audnexus.
+type AudibleProvider struct {
+	client *audible.AudibleApiClient
+}
+
+// Compile-time check that AudibleProvider implements Provider interface.
+var _ Provider = (*AudibleProvider)(nil)
+
+// NewAudibleProvider creates an Audible provider with its own API client.
+func NewAudibleProvider() *AudibleProvider {
+	return &AudibleProvider{client: audible.NewAudibleApiClient()}
+}
+
+func (p *AudibleProvider) Name() string {
+	return AudibleProviderName
+}
+
+// Search looks up the ASIN directly when known, otherwise searches by title and author.
+func (p *AudibleProvider) Search(ctx context.Context, query Query) ([]metadata.BookMetadata, error) {
+	if query.ASIN != "" {
+		md, err := p.client.GetMetadataFromAsin(query.ASIN)
+		if err != nil {
+			return nil, err
+		}
+
+		return []metadata.BookMetadata{md}, nil
+	}
+
+	if query.Title == "" {
+		return nil, nil
+	}
+
+	asins, err := p.client.SearchByTitle(query.Title, query.Author)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]metadata.BookMetadata, 0, len(asins))
+
+	for _, asin := range asins {
+		md, err := p.client.GetMetadataFromAsin(asin)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to get metadata from ASIN", slog.String("asin", asin), slogx.Error(err))
+			continue
+		}
+
+		results = append(results, md)
+	}
+
+	return results, nil
+}
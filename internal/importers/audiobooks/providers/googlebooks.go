@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+const googleBooksBaseUrl = "https://www.googleapis.com/books/v1"
+
+type googleBooksResponse struct {
+	Items []struct {
+		VolumeInfo googleVolumeInfo `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+type googleVolumeInfo struct {
+	Title               string   `json:"title"`
+	Subtitle            string   `json:"subtitle"`
+	Authors             []string `json:"authors"`
+	Publisher           string   `json:"publisher"`
+	PublishedDate       string   `json:"publishedDate"`
+	Description         string   `json:"description"`
+	Language            string   `json:"language"`
+	Categories          []string `json:"categories"`
+	IndustryIdentifiers []struct {
+		Type       string `json:"type"`
+		Identifier string `json:"identifier"`
+	} `json:"industryIdentifiers"`
+	ImageLinks struct {
+		Thumbnail string `json:"thumbnail"`
+	} `json:"imageLinks"`
+}
+
+// GoogleBooksProvider searches the public Google Books volumes API.
+type GoogleBooksProvider struct {
+	httpClient *http.Client
+	baseUrl    string
+}
+
+// Compile-time check that GoogleBooksProvider implements Provider interface.
+var _ Provider = (*GoogleBooksProvider)(nil)
+
+// NewGoogleBooksProvider creates a Google Books provider.
+func NewGoogleBooksProvider() *GoogleBooksProvider {
+	return &GoogleBooksProvider{
+		httpClient: &http.Client{},
+		baseUrl:    googleBooksBaseUrl,
+	}
+}
+
+func (p *GoogleBooksProvider) Name() string {
+	return GoogleBooksProviderName
+}
+
+// Search queries by ISBN when known, otherwise by title and author.
+func (p *GoogleBooksProvider) Search(ctx context.Context, query Query) ([]metadata.BookMetadata, error) {
+	var terms []string
+
+	switch {
+	case query.ISBN != "":
+		terms = append(terms, "isbn:"+query.ISBN)
+	case query.Title != "":
+		terms = append(terms, fmt.Sprintf("intitle:%q", query.Title))
+		if query.Author != "" {
+			terms = append(terms, fmt.Sprintf("inauthor:%q", query.Author))
+		}
+	default:
+		return nil, nil
+	}
+
+	params := url.Values{}
+	params.Set("q", strings.Join(terms, " "))
+	params.Set("maxResults", "10")
+	params.Set("printType", "books")
+
+	var parsed googleBooksResponse
+
+	err := getJSON(ctx, p.httpClient, p.baseUrl+"/volumes?"+params.Encode(), &parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]metadata.BookMetadata, 0, len(parsed.Items))
+	for _, item := range parsed.Items {
+		results = append(results, googleVolumeToMetadata(item.VolumeInfo))
+	}
+
+	return results, nil
+}
+
+func googleVolumeToMetadata(volume googleVolumeInfo) metadata.BookMetadata {
+	md := metadata.BookMetadata{
+		Title:         volume.Title,
+		Authors:       people(volume.Authors),
+		PublisherName: volume.Publisher,
+		Description:   volume.Description,
+		Language:      volume.Language,
+		ReleaseDate:   parseReleaseDate(volume.PublishedDate),
+	}
+
+	if volume.Subtitle != "" {
+		md.Subtitle = &volume.Subtitle
+	}
+
+	if volume.ImageLinks.Thumbnail != "" {
+		image := strings.Replace(volume.ImageLinks.Thumbnail, "http://", "https://", 1)
+		md.Image = &image
+	}
+
+	for _, identifier := range volume.IndustryIdentifiers {
+		if identifier.Type == "ISBN_13" || (identifier.Type == "ISBN_10" && md.ISBN == nil) {
+			isbn := identifier.Identifier
+			md.ISBN = &isbn
+		}
+	}
+
+	for _, category := range volume.Categories {
+		md.Genres = append(md.Genres, metadata.Genre{Name: category, Type: "genre"})
+	}
+
+	return md
+}
+
+// getJSON performs a GET request and decodes the JSON response into out.
+func getJSON(ctx context.Context, httpClient *http.Client, requestUrl string, out any) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, requestUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("User-Agent", "stronghold/1.0")
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+
+	defer func() { _ = response.Body.Close() }()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: status=%d, body=%s", response.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, out)
+}
@@ -0,0 +1,88 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleBooksProvider_Search(t *testing.T) {
+	var gotQuery string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/volumes", r.URL.Path)
+		gotQuery = r.URL.Query().Get("q")
+
+		_, _ = w.Write([]byte(`{
+			"items": [{
+				"volumeInfo": {
+					"title": "Dune",
+					"subtitle": "Deluxe Edition",
+					"authors": ["Frank Herbert"],
+					"publisher": "Ace",
+					"publishedDate": "2019-10-01",
+					"description": "Set on the desert planet Arrakis",
+					"language": "en",
+					"categories": ["Fiction"],
+					"industryIdentifiers": [
+						{"type": "ISBN_10", "identifier": "0593099320"},
+						{"type": "ISBN_13", "identifier": "9780593099322"}
+					],
+					"imageLinks": {"thumbnail": "http://books.google.com/cover.jpg"}
+				}
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	provider := &GoogleBooksProvider{httpClient: server.Client(), baseUrl: server.URL}
+
+	results, err := provider.Search(context.Background(), Query{Title: "Dune", Author: "Frank Herbert"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	assert.Equal(t, `intitle:"Dune" inauthor:"Frank Herbert"`, gotQuery)
+
+	md := results[0]
+	assert.Equal(t, "Dune", md.Title)
+	assert.Equal(t, "Deluxe Edition", *md.Subtitle)
+	assert.Equal(t, "Frank Herbert", md.Authors[0].Name)
+	assert.Equal(t, "Ace", md.PublisherName)
+	assert.Equal(t, 2019, md.ReleaseDate.Year())
+	assert.Equal(t, "9780593099322", *md.ISBN)
+	assert.Equal(t, "https://books.google.com/cover.jpg", *md.Image)
+	assert.Equal(t, "Fiction", md.Genres[0].Name)
+}
+
+func TestGoogleBooksProvider_SearchByISBN(t *testing.T) {
+	var gotQuery string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("q")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	provider := &GoogleBooksProvider{httpClient: server.Client(), baseUrl: server.URL}
+
+	results, err := provider.Search(context.Background(), Query{Title: "Dune", ISBN: "9780593099322"})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Equal(t, "isbn:9780593099322", gotQuery)
+}
+
+func TestGoogleBooksProvider_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	provider := &GoogleBooksProvider{httpClient: server.Client(), baseUrl: server.URL}
+
+	_, err := provider.Search(context.Background(), Query{Title: "Dune"})
+	assert.ErrorContains(t, err, "status=429")
+}
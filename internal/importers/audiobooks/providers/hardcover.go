@@ -0,0 +1,81 @@
+package providers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bobbyrward/stronghold/internal/hardcover"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// HardcoverProvider searches Hardcover works by title.
+type HardcoverProvider struct {
+	client hardcover.Client
+}
+
+// Compile-time check that HardcoverProvider implements Provider interface.
+var _ Provider = (*HardcoverProvider)(nil)
+
+// NewHardcoverProvider creates a Hardcover provider using the given client.
+func NewHardcoverProvider(client hardcover.Client) *HardcoverProvider {
+	return &HardcoverProvider{client: client}
+}
+
+func (p *HardcoverProvider) Name() string {
+	return HardcoverProviderName
+}
+
+// Search finds works with the exact title. Hardcover has no author filter on
+// book search, so results by other authors are dropped here.
+func (p *HardcoverProvider) Search(ctx context.Context, query Query) ([]metadata.BookMetadata, error) {
+	if query.Title == "" {
+		return nil, nil
+	}
+
+	books, err := p.client.SearchBooks(ctx, query.Title)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]metadata.BookMetadata, 0, len(books))
+
+	for _, book := range books {
+		if query.Author != "" && !hasAuthor(book.Authors, query.Author) {
+			continue
+		}
+
+		results = append(results, hardcoverToMetadata(book))
+	}
+
+	return results, nil
+}
+
+func hardcoverToMetadata(book hardcover.BookDetails) metadata.BookMetadata {
+	md := metadata.BookMetadata{
+		Title:    book.Title,
+		Subtitle: book.Subtitle,
+		Image:    book.ImageURL,
+		Authors:  people(book.Authors),
+	}
+
+	if book.Description != nil {
+		md.Description = *book.Description
+	}
+
+	if book.ReleaseDate != nil {
+		md.ReleaseDate = parseReleaseDate(*book.ReleaseDate)
+	}
+
+	if book.SeriesName != nil {
+		series := &metadata.Series{Name: *book.SeriesName}
+
+		if book.SeriesPosition != nil {
+			position := strconv.FormatFloat(*book.SeriesPosition, 'f', -1, 64)
+			series.Position = &position
+		}
+
+		md.PrimarySeries = series
+	}
+
+	return md
+}
@@ -0,0 +1,131 @@
+package providers
+
+import (
+	"slices"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// Field names accepted in the fieldPrecedence config.
+const (
+	FieldAsin        = "asin"
+	FieldTitle       = "title"
+	FieldSubtitle    = "subtitle"
+	FieldAuthors     = "authors"
+	FieldNarrators   = "narrators"
+	FieldDescription = "description"
+	FieldImage       = "image"
+	FieldISBN        = "isbn"
+	FieldPublisher   = "publisher"
+	FieldLanguage    = "language"
+	FieldReleaseDate = "releaseDate"
+	FieldRuntime     = "runtime"
+	FieldSeries      = "series"
+	FieldGenres      = "genres"
+)
+
+// Merge combines results from several providers into one BookMetadata. Each
+// field is taken from the first provider in precedence order that has a value
+// for it. The precedence for a field is fieldPrecedence[field] when set,
+// followed by the remaining providers in chainOrder.
+func Merge(chainOrder []string, results map[string]metadata.BookMetadata, fieldPrecedence map[string][]string) metadata.BookMetadata {
+	var merged metadata.BookMetadata
+
+	pick := func(field string, has func(md metadata.BookMetadata) bool, set func(md metadata.BookMetadata)) {
+		for _, name := range fieldOrder(field, chainOrder, fieldPrecedence) {
+			md, ok := results[name]
+			if ok && has(md) {
+				set(md)
+				return
+			}
+		}
+	}
+
+	pick(FieldAsin,
+		func(md metadata.BookMetadata) bool { return md.Asin != "" },
+		func(md metadata.BookMetadata) { merged.Asin = md.Asin })
+	pick(FieldTitle,
+		func(md metadata.BookMetadata) bool { return md.Title != "" },
+		func(md metadata.BookMetadata) { merged.Title = md.Title })
+	pick(FieldSubtitle,
+		func(md metadata.BookMetadata) bool { return md.Subtitle != nil && *md.Subtitle != "" },
+		func(md metadata.BookMetadata) { merged.Subtitle = md.Subtitle })
+	pick(FieldAuthors,
+		func(md metadata.BookMetadata) bool { return len(md.Authors) > 0 },
+		func(md metadata.BookMetadata) { merged.Authors = md.Authors })
+	pick(FieldNarrators,
+		func(md metadata.BookMetadata) bool { return len(md.Narrators) > 0 },
+		func(md metadata.BookMetadata) { merged.Narrators = md.Narrators })
+	pick(FieldDescription,
+		func(md metadata.BookMetadata) bool { return md.Description != "" || md.Summary != "" },
+		func(md metadata.BookMetadata) {
+			merged.Description = md.Description
+			merged.Summary = md.Summary
+		})
+	pick(FieldImage,
+		func(md metadata.BookMetadata) bool { return md.Image != nil && *md.Image != "" },
+		func(md metadata.BookMetadata) { merged.Image = md.Image })
+	pick(FieldISBN,
+		func(md metadata.BookMetadata) bool { return md.ISBN != nil && *md.ISBN != "" },
+		func(md metadata.BookMetadata) { merged.ISBN = md.ISBN })
+	pick(FieldPublisher,
+		func(md metadata.BookMetadata) bool { return md.PublisherName != "" },
+		func(md metadata.BookMetadata) { merged.PublisherName = md.PublisherName })
+	pick(FieldLanguage,
+		func(md metadata.BookMetadata) bool { return md.Language != "" },
+		func(md metadata.BookMetadata) { merged.Language = md.Language })
+	pick(FieldReleaseDate,
+		func(md metadata.BookMetadata) bool { return !md.ReleaseDate.IsZero() },
+		func(md metadata.BookMetadata) {
+			merged.ReleaseDate = md.ReleaseDate
+			merged.Copyright = md.Copyright
+		})
+	pick(FieldRuntime,
+		func(md metadata.BookMetadata) bool { return md.RuntimeLength > 0 },
+		func(md metadata.BookMetadata) { merged.RuntimeLength = md.RuntimeLength })
+	pick(FieldSeries,
+		func(md metadata.BookMetadata) bool { return md.PrimarySeries != nil },
+		func(md metadata.BookMetadata) {
+			merged.PrimarySeries = md.PrimarySeries
+			merged.SecondarySeries = md.SecondarySeries
+		})
+	pick(FieldGenres,
+		func(md metadata.BookMetadata) bool { return len(md.Genres) > 0 },
+		func(md metadata.BookMetadata) { merged.Genres = md.Genres })
+
+	// The remaining fields have no equivalent outside Audible, so they come
+	// from the first provider in chain order that returned a result
+	for _, name := range chainOrder {
+		md, ok := results[name]
+		if !ok {
+			continue
+		}
+
+		merged.FormatType = md.FormatType
+		merged.Rating = md.Rating
+		merged.Region = md.Region
+		merged.IsAdult = md.IsAdult
+		merged.LiteratureType = md.LiteratureType
+		break
+	}
+
+	return merged
+}
+
+// fieldOrder returns the provider order for a single field.
+func fieldOrder(field string, chainOrder []string, fieldPrecedence map[string][]string) []string {
+	preferred := fieldPrecedence[field]
+	if len(preferred) == 0 {
+		return chainOrder
+	}
+
+	order := slices.Clone(preferred)
+
+	for _, name := range chainOrder {
+		if !slices.Contains(order, name) {
+			order = append(order, name)
+		}
+	}
+
+	return order
+}
@@ -0,0 +1,127 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+const (
+	openLibraryBaseUrl  = "https://openlibrary.org"
+	openLibraryCoverUrl = "https://covers.openlibrary.org/b/id/%d-L.jpg"
+
+	maxOpenLibrarySubjects = 5
+)
+
+type openLibrarySearchResponse struct {
+	Docs []openLibraryDoc `json:"docs"`
+}
+
+type openLibraryDoc struct {
+	Title            string   `json:"title"`
+	Subtitle         string   `json:"subtitle"`
+	AuthorName       []string `json:"author_name"`
+	FirstPublishYear int      `json:"first_publish_year"`
+	ISBN             []string `json:"isbn"`
+	Publisher        []string `json:"publisher"`
+	Language         []string `json:"language"`
+	CoverID          int      `json:"cover_i"`
+	Subject          []string `json:"subject"`
+}
+
+// OpenLibraryProvider searches the Open Library search API.
+type OpenLibraryProvider struct {
+	httpClient *http.Client
+	baseUrl    string
+}
+
+// Compile-time check that OpenLibraryProvider implements Provider interface.
+var _ Provider = (*OpenLibraryProvider)(nil)
+
+// NewOpenLibraryProvider creates an Open Library provider.
+func NewOpenLibraryProvider() *OpenLibraryProvider {
+	return &OpenLibraryProvider{
+		httpClient: &http.Client{},
+		baseUrl:    openLibraryBaseUrl,
+	}
+}
+
+func (p *OpenLibraryProvider) Name() string {
+	return OpenLibraryProviderName
+}
+
+// Search queries by ISBN when known, otherwise by title and author.
+func (p *OpenLibraryProvider) Search(ctx context.Context, query Query) ([]metadata.BookMetadata, error) {
+	params := url.Values{}
+
+	switch {
+	case query.ISBN != "":
+		params.Set("isbn", query.ISBN)
+	case query.Title != "":
+		params.Set("title", query.Title)
+		if query.Author != "" {
+			params.Set("author", query.Author)
+		}
+	default:
+		return nil, nil
+	}
+
+	params.Set("limit", "10")
+
+	var parsed openLibrarySearchResponse
+
+	err := getJSON(ctx, p.httpClient, p.baseUrl+"/search.json?"+params.Encode(), &parsed)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]metadata.BookMetadata, 0, len(parsed.Docs))
+	for _, doc := range parsed.Docs {
+		results = append(results, openLibraryDocToMetadata(doc))
+	}
+
+	return results, nil
+}
+
+func openLibraryDocToMetadata(doc openLibraryDoc) metadata.BookMetadata {
+	md := metadata.BookMetadata{
+		Title:   doc.Title,
+		Authors: people(doc.AuthorName),
+	}
+
+	if doc.Subtitle != "" {
+		md.Subtitle = &doc.Subtitle
+	}
+
+	if doc.FirstPublishYear > 0 {
+		md.ReleaseDate = parseReleaseDate(strconv.Itoa(doc.FirstPublishYear))
+	}
+
+	if len(doc.ISBN) > 0 {
+		md.ISBN = &doc.ISBN[0]
+	}
+
+	if len(doc.Publisher) > 0 {
+		md.PublisherName = doc.Publisher[0]
+	}
+
+	if len(doc.Language) > 0 {
+		md.Language = doc.Language[0]
+	}
+
+	if doc.CoverID > 0 {
+		image := fmt.Sprintf(openLibraryCoverUrl, doc.CoverID)
+		md.Image = &image
+	}
+
+	// Subjects are often long lists of loosely related tags; keep the first few
+	for _, subject := range doc.Subject[:min(len(doc.Subject), maxOpenLibrarySubjects)] {
+		md.Genres = append(md.Genres, metadata.Genre{Name: subject, Type: "genre"})
+	}
+
+	return md
+}
@@ -0,0 +1,56 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenLibraryProvider_Search(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search.json", r.URL.Path)
+		assert.Equal(t, "Dune", r.URL.Query().Get("title"))
+		assert.Equal(t, "Frank Herbert", r.URL.Query().Get("author"))
+
+		_, _ = w.Write([]byte(`{
+			"docs": [{
+				"title": "Dune",
+				"author_name": ["Frank Herbert"],
+				"first_publish_year": 1965,
+				"isbn": ["9780441013593"],
+				"publisher": ["Chilton Books"],
+				"language": ["eng"],
+				"cover_i": 12345,
+				"subject": ["Science fiction", "Arrakis", "Deserts", "Ecology", "Politics", "Religion"]
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	provider := &OpenLibraryProvider{httpClient: server.Client(), baseUrl: server.URL}
+
+	results, err := provider.Search(context.Background(), Query{Title: "Dune", Author: "Frank Herbert"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	md := results[0]
+	assert.Equal(t, "Dune", md.Title)
+	assert.Equal(t, 1965, md.ReleaseDate.Year())
+	assert.Equal(t, "9780441013593", *md.ISBN)
+	assert.Equal(t, "Chilton Books", md.PublisherName)
+	assert.Equal(t, "eng", md.Language)
+	assert.Equal(t, "https://covers.openlibrary.org/b/id/12345-L.jpg", *md.Image)
+	assert.Len(t, md.Genres, maxOpenLibrarySubjects)
+}
+
+func TestOpenLibraryProvider_EmptyQuery(t *testing.T) {
+	provider := NewOpenLibraryProvider()
+
+	results, err := provider.Search(context.Background(), Query{ASIN: "B002V1BOWY"})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/cappuccinotm/slogx"
+
+	"github.com/bobbyrward/stronghold/internal/hardcover"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// Provider names used in the metadataProviders config list.
+const (
+	AudibleProviderName     = "audible"
+	HardcoverProviderName   = "hardcover"
+	GoogleBooksProviderName = "googlebooks"
+	OpenLibraryProviderName = "openlibrary"
+)
+
+// DefaultChain is used by import types that do not configure metadataProviders.
+var DefaultChain = []string{AudibleProviderName}
+
+// Query describes the book being looked up. Providers use whichever fields
+// they support and ignore the rest.
+type Query struct {
+	Title  string
+	Author string
+	ASIN   string
+	ISBN   string
+}
+
+// Provider is a source of book metadata.
+type Provider interface {
+	// Name returns the provider name used in configuration
+	Name() string
+
+	// Search returns candidate books for the query, best first. A provider
+	// that finds nothing, or cannot use the query, returns an empty slice.
+	Search(ctx context.Context, query Query) ([]metadata.BookMetadata, error)
+}
+
+// Registry holds the available providers by name.
+type Registry map[string]Provider
+
+// NewRegistry creates a registry from the given providers.
+func NewRegistry(providers ...Provider) Registry {
+	registry := make(Registry, len(providers))
+
+	for _, provider := range providers {
+		registry[provider.Name()] = provider
+	}
+
+	return registry
+}
+
+// NewDefaultRegistry creates a registry with every built-in provider.
+// Hardcover is only included when an API token is configured.
+func NewDefaultRegistry(hardcoverToken string) Registry {
+	registry := NewRegistry(
+		NewAudibleProvider(),
+		NewGoogleBooksProvider(),
+		NewOpenLibraryProvider(),
+	)
+
+	if hardcoverToken != "" {
+		registry[HardcoverProviderName] = NewHardcoverProvider(hardcover.NewClient(hardcoverToken))
+	}
+
+	return registry
+}
+
+// Chain builds a fallback chain from provider names. Unknown names are an error.
+func (r Registry) Chain(names []string, fieldPrecedence map[string][]string) (*Chain, error) {
+	if len(names) == 0 {
+		names = DefaultChain
+	}
+
+	providers := make([]Provider, 0, len(names))
+
+	for _, name := range names {
+		provider, ok := r[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown metadata provider: %s", name)
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return NewChain(providers, fieldPrecedence), nil
+}
+
+// Chain queries providers in order and merges their results.
+type Chain struct {
+	providers       []Provider
+	fieldPrecedence map[string][]string
+}
+
+// NewChain creates a chain that tries providers in order. fieldPrecedence
+// optionally overrides the provider order for individual fields when merging.
+func NewChain(providers []Provider, fieldPrecedence map[string][]string) *Chain {
+	return &Chain{
+		providers:       providers,
+		fieldPrecedence: fieldPrecedence,
+	}
+}
+
+// Search returns the candidates from the first provider that finds any, along
+// with that provider's name. Provider errors are logged and the next provider
+// is tried. When no provider finds anything, the errors of those that failed
+// are returned joined; an empty result means every provider found nothing.
+func (c *Chain) Search(ctx context.Context, query Query) ([]metadata.BookMetadata, string, error) {
+	var errs []error
+
+	for _, provider := range c.providers {
+		results, err := provider.Search(ctx, query)
+		if err != nil {
+			slog.WarnContext(ctx, "Metadata provider search failed, trying next provider",
+				slog.String("provider", provider.Name()),
+				slog.String("title", query.Title),
+				slog.String("asin", query.ASIN),
+				slogx.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		if len(results) > 0 {
+			return results, provider.Name(), nil
+		}
+
+		slog.InfoContext(ctx, "Metadata provider found no results",
+			slog.String("provider", provider.Name()),
+			slog.String("title", query.Title),
+			slog.String("asin", query.ASIN))
+	}
+
+	return nil, "", errors.Join(errs...)
+}
+
+// Enrich looks up the book chosen from primaryProvider in the other providers
+// and merges their results by field precedence. Providers that fail or do
+// not find the same book are skipped.
+func (c *Chain) Enrich(ctx context.Context, primaryProvider string, md metadata.BookMetadata) metadata.BookMetadata {
+	if len(c.providers) < 2 {
+		return md
+	}
+
+	results := map[string]metadata.BookMetadata{primaryProvider: md}
+
+	query := Query{Title: md.Title, ASIN: md.Asin}
+	if len(md.Authors) > 0 {
+		query.Author = md.Authors[0].Name
+	}
+	if md.ISBN != nil {
+		query.ISBN = *md.ISBN
+	}
+
+	for _, provider := range c.providers {
+		if provider.Name() == primaryProvider {
+			continue
+		}
+
+		candidates, err := provider.Search(ctx, query)
+		if err != nil {
+			slog.WarnContext(ctx, "Metadata provider enrichment failed",
+				slog.String("provider", provider.Name()),
+				slog.String("title", md.Title),
+				slogx.Error(err))
+			continue
+		}
+
+		for _, candidate := range candidates {
+			if sameTitle(candidate.Title, md.Title) {
+				results[provider.Name()] = candidate
+				break
+			}
+		}
+	}
+
+	return Merge(c.order(), results, c.fieldPrecedence)
+}
+
+// order returns the provider names in chain order.
+func (c *Chain) order() []string {
+	names := make([]string, len(c.providers))
+
+	for i, provider := range c.providers {
+		names[i] = provider.Name()
+	}
+
+	return names
+}
+
+func sameTitle(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
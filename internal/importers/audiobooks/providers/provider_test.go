@@ -0,0 +1,256 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/hardcover"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// fakeProvider returns fixed results and records the queries it receives
+type fakeProvider struct {
+	name    string
+	results []metadata.BookMetadata
+	err     error
+	queries []Query
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Search(ctx context.Context, query Query) ([]metadata.BookMetadata, error) {
+	p.queries = append(p.queries, query)
+	return p.results, p.err
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func TestChainSearch_FallsBackInOrder(t *testing.T) {
+	ctx := context.Background()
+
+	failing := &fakeProvider{name: "audible", err: errors.New("service unavailable")}
+	empty := &fakeProvider{name: "hardcover"}
+	found := &fakeProvider{name: "googlebooks", results: []metadata.BookMetadata{{Title: "Dune"}}}
+	unused := &fakeProvider{name: "openlibrary", results: []metadata.BookMetadata{{Title: "Dune"}}}
+
+	chain := NewChain([]Provider{failing, empty, found, unused}, nil)
+
+	results, provider, err := chain.Search(ctx, Query{Title: "Dune"})
+	require.NoError(t, err)
+	assert.Equal(t, "googlebooks", provider)
+	assert.Len(t, results, 1)
+	assert.Empty(t, unused.queries)
+}
+
+func TestChainSearch_AllFailed(t *testing.T) {
+	ctx := context.Background()
+
+	unavailable := errors.New("service unavailable")
+	quota := errors.New("quota exceeded")
+
+	chain := NewChain([]Provider{
+		&fakeProvider{name: "audible", err: unavailable},
+		&fakeProvider{name: "googlebooks", err: quota},
+	}, nil)
+
+	_, _, err := chain.Search(ctx, Query{Title: "Dune"})
+	assert.ErrorIs(t, err, unavailable)
+	assert.ErrorIs(t, err, quota)
+	assert.ErrorContains(t, err, "audible: service unavailable")
+	assert.ErrorContains(t, err, "googlebooks: quota exceeded")
+}
+
+func TestChainSearch_NothingFound(t *testing.T) {
+	ctx := context.Background()
+	unavailable := errors.New("service unavailable")
+
+	tests := []struct {
+		name      string
+		providers func() []Provider
+		wantErr   bool
+		wantFound bool
+	}{
+		{
+			name: "every provider finds nothing",
+			providers: func() []Provider {
+				return []Provider{&fakeProvider{name: "audible"}, &fakeProvider{name: "googlebooks"}}
+			},
+		},
+		{
+			name: "failure and nothing found",
+			providers: func() []Provider {
+				return []Provider{&fakeProvider{name: "audible", err: unavailable}, &fakeProvider{name: "googlebooks"}}
+			},
+			wantErr: true,
+		},
+		{
+			name: "failure and found",
+			providers: func() []Provider {
+				return []Provider{
+					&fakeProvider{name: "audible", err: unavailable},
+					&fakeProvider{name: "googlebooks", results: []metadata.BookMetadata{{Title: "Dune"}}},
+				}
+			},
+			wantFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		for _, reversed := range []bool{false, true} {
+			providers := tt.providers()
+			if reversed {
+				slices.Reverse(providers)
+			}
+
+			t.Run(fmt.Sprintf("%s/reversed=%t", tt.name, reversed), func(t *testing.T) {
+				results, _, err := NewChain(providers, nil).Search(ctx, Query{Title: "Dune"})
+
+				if tt.wantFound {
+					require.NoError(t, err)
+					assert.Len(t, results, 1)
+					return
+				}
+
+				assert.Empty(t, results)
+				if tt.wantErr {
+					assert.ErrorIs(t, err, unavailable)
+				} else {
+					assert.NoError(t, err)
+				}
+			})
+		}
+	}
+}
+
+func TestChainEnrich(t *testing.T) {
+	ctx := context.Background()
+
+	primary := metadata.BookMetadata{
+		Asin:          "B002V1BOWY",
+		Title:         "Dune",
+		Authors:       []metadata.Person{{Name: "Frank Herbert"}},
+		Narrators:     []metadata.Person{{Name: "Scott Brick"}},
+		RuntimeLength: 1263,
+		FormatType:    "unabridged",
+	}
+
+	google := &fakeProvider{name: "googlebooks", results: []metadata.BookMetadata{
+		{Title: "Dune Messiah", Description: "Wrong book"},
+		{Title: "Dune", Description: "A desert planet", ISBN: stringPtr("9780441013593")},
+	}}
+	openLibrary := &fakeProvider{name: "openlibrary", err: errors.New("timeout")}
+
+	chain := NewChain([]Provider{&fakeProvider{name: "audible"}, google, openLibrary}, nil)
+
+	merged := chain.Enrich(ctx, "audible", primary)
+
+	assert.Equal(t, "B002V1BOWY", merged.Asin)
+	assert.Equal(t, "Scott Brick", merged.Narrators[0].Name)
+	assert.Equal(t, "A desert planet", merged.Description)
+	assert.Equal(t, "9780441013593", *merged.ISBN)
+	assert.Equal(t, "unabridged", merged.FormatType)
+	assert.Equal(t, []Query{{Title: "Dune", Author: "Frank Herbert", ASIN: "B002V1BOWY"}}, google.queries)
+}
+
+func TestChainEnrich_SingleProviderIsUnchanged(t *testing.T) {
+	provider := &fakeProvider{name: "audible"}
+	chain := NewChain([]Provider{provider}, nil)
+
+	md := metadata.BookMetadata{Title: "Dune", Rating: "4.5"}
+
+	assert.Equal(t, md, chain.Enrich(context.Background(), "audible", md))
+	assert.Empty(t, provider.queries)
+}
+
+func TestMerge_FieldPrecedence(t *testing.T) {
+	results := map[string]metadata.BookMetadata{
+		"audible": {
+			Title:       "Dune",
+			Description: "Audible blurb",
+			Image:       stringPtr("https://audible/cover.jpg"),
+		},
+		"googlebooks": {
+			Title:       "Dune (Deluxe Edition)",
+			Description: "Google description",
+			Image:       stringPtr("https://google/cover.jpg"),
+			Language:    "en",
+		},
+	}
+
+	merged := Merge([]string{"audible", "googlebooks"}, results, map[string][]string{
+		FieldDescription: {"googlebooks"},
+	})
+
+	assert.Equal(t, "Dune", merged.Title)
+	assert.Equal(t, "Google description", merged.Description)
+	assert.Equal(t, "https://audible/cover.jpg", *merged.Image)
+	assert.Equal(t, "en", merged.Language)
+}
+
+func TestRegistryChain(t *testing.T) {
+	registry := NewRegistry(&fakeProvider{name: "audible"}, &fakeProvider{name: "openlibrary"})
+
+	chain, err := registry.Chain(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultChain, chain.order())
+
+	chain, err = registry.Chain([]string{"openlibrary", "audible"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"openlibrary", "audible"}, chain.order())
+
+	_, err = registry.Chain([]string{"audible", "goodreads"}, nil)
+	assert.EqualError(t, err, "unknown metadata provider: goodreads")
+}
+
+func TestHardcoverProvider(t *testing.T) {
+	ctx := context.Background()
+	position := 2.0
+
+	client := hardcover.NewMockClient()
+	client.BookDetails = []hardcover.BookDetails{
+		{
+			HardcoverID:    "1",
+			Title:          "Dune Messiah",
+			Description:    stringPtr("The sequel"),
+			ReleaseDate:    stringPtr("1969-10-15"),
+			Authors:        []string{"Frank Herbert"},
+			SeriesName:     stringPtr("Dune"),
+			SeriesPosition: &position,
+		},
+		{HardcoverID: "2", Title: "Dune Messiah", Authors: []string{"Someone Else"}},
+	}
+
+	provider := NewHardcoverProvider(client)
+
+	results, err := provider.Search(ctx, Query{Title: "Dune Messiah", Author: "Frank Herbert"})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	md := results[0]
+	assert.Equal(t, "The sequel", md.Description)
+	assert.Equal(t, 1969, md.ReleaseDate.Year())
+	assert.Equal(t, "Dune", md.PrimarySeries.Name)
+	assert.Equal(t, "2", *md.PrimarySeries.Position)
+
+	results, err = provider.Search(ctx, Query{ASIN: "B002V1BOWY"})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestParseReleaseDate(t *testing.T) {
+	assert.Equal(t, "2006-05-02", parseReleaseDate("2006-05-02").Format("2006-01-02"))
+	assert.Equal(t, "2006-05-01", parseReleaseDate("2006-05").Format("2006-01-02"))
+	assert.Equal(t, "2006-01-01", parseReleaseDate("2006").Format("2006-01-02"))
+	assert.Equal(t, "2006-05-02", parseReleaseDate("May 2, 2006").Format("2006-01-02"))
+	assert.True(t, parseReleaseDate("sometime").IsZero())
+}
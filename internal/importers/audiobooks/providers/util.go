@@ -0,0 +1,45 @@
+package providers
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// parseReleaseDate accepts the partial dates book APIs return ("2006", "2006-05",
+// "2006-05-02" or "May 2, 2006"). Unparseable dates are returned as the zero time.
+func parseReleaseDate(value string) time.Time {
+	for _, layout := range []string{"2006-01-02", "2006-01", "2006", "January 2, 2006", "Jan 2, 2006"} {
+		parsed, err := time.Parse(layout, strings.TrimSpace(value))
+		if err == nil {
+			return parsed
+		}
+	}
+
+	return time.Time{}
+}
+
+func people(names []string) []metadata.Person {
+	result := make([]metadata.Person, 0, len(names))
+
+	for _, name := range names {
+		result = append(result, metadata.Person{Name: name})
+	}
+
+	return result
+}
+
+// hasAuthor reports whether any of names loosely matches author.
+func hasAuthor(names []string, author string) bool {
+	author = strings.ToLower(strings.TrimSpace(author))
+
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if strings.Contains(name, author) || strings.Contains(author, name) {
+			return true
+		}
+	}
+
+	return false
+}
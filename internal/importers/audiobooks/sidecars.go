@@ -0,0 +1,326 @@
+package audiobooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/source"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+)
+
+const (
+	coverFileName    = "cover.jpg"
+	chaptersFileName = "chapters.json"
+
+	defaultRuntimeTolerancePercent = 10
+
+	// coverDownloadTimeout bounds a cover download so a stalled image host
+	// cannot hang the import.
+	coverDownloadTimeout = 30 * time.Second
+)
+
+// ErrRuntimeMismatch is returned when the audio duration does not match the metadata runtime.
+var ErrRuntimeMismatch = errors.New("audio duration does not match metadata runtime")
+
+// collectAudioFiles returns the audiobook files at localPath, which may be a single file or a directory.
+// Files are returned in path order so multi-file books are probed in playback order.
+func collectAudioFiles(localPath string) ([]string, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		if source.IsAudioFile(localPath) {
+			return []string{localPath}, nil
+		}
+		return nil, nil
+	}
+
+	var files []string
+
+	err = filepath.WalkDir(localPath, func(filePath string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() && source.IsAudioFile(filePath) {
+			files = append(files, filePath)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.Sort(files)
+
+	return files, nil
+}
+
+// collectBookFiles returns the audio files at localPath in the format the
+// importer treats as the book, so a torrent carrying both an M4B and the MP3
+// parts is only counted once.
+func collectBookFiles(localPath string) ([]string, error) {
+	files, err := collectAudioFiles(localPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return bookFilePaths(mapLocalFiles(files))
+}
+
+// bookFilePaths returns the local paths of the files AnalyzeSource picks as the book.
+func bookFilePaths(files []common.MappedTorrentFile) ([]string, error) {
+	sourceInfo, err := source.AnalyzeSource(files)
+	if err != nil {
+		return nil, err
+	}
+
+	bookFiles := sourceInfo.BookFiles()
+	paths := make([]string, len(bookFiles))
+	for i, file := range bookFiles {
+		paths[i] = file.LocalPath
+	}
+
+	return paths, nil
+}
+
+func mapLocalFiles(files []string) []common.MappedTorrentFile {
+	mapped := make([]common.MappedTorrentFile, len(files))
+	for i, file := range files {
+		mapped[i] = common.MappedTorrentFile{BaseName: filepath.Base(file), LocalPath: file}
+	}
+
+	return mapped
+}
+
+// probeAudioFiles probes each file and returns the total duration and a combined chapter list.
+// Files without embedded chapters contribute a single chapter named after the file.
+func (abis *AudiobookImporterSystem) probeAudioFiles(ctx context.Context, files []string) (time.Duration, []metadata.Chapter, error) {
+	var total time.Duration
+	var chapters []metadata.Chapter
+
+	for _, file := range files {
+		info, err := abis.mediaProbe.ProbeMedia(ctx, file)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		offset := total.Seconds()
+
+		if len(info.Chapters) == 0 {
+			chapters = append(chapters, metadata.Chapter{
+				Title: strings.TrimSuffix(path.Base(file), filepath.Ext(file)),
+				Start: offset,
+				End:   offset + info.Duration.Seconds(),
+			})
+		} else {
+			for _, chapter := range info.Chapters {
+				chapters = append(chapters, metadata.Chapter{
+					Title: chapter.Title,
+					Start: offset + chapter.Start,
+					End:   offset + chapter.End,
+				})
+			}
+		}
+
+		total += info.Duration
+	}
+
+	return total, chapters, nil
+}
+
+// checkRuntime compares the actual audio duration against the expected runtime in minutes.
+// Returns ErrRuntimeMismatch when the difference exceeds tolerancePercent of the expected runtime.
+func checkRuntime(expectedMinutes int, actual time.Duration, tolerancePercent int) error {
+	if expectedMinutes <= 0 || actual <= 0 {
+		return nil
+	}
+
+	if tolerancePercent <= 0 {
+		tolerancePercent = defaultRuntimeTolerancePercent
+	}
+
+	expected := time.Duration(expectedMinutes) * time.Minute
+	difference := math.Abs(float64(actual - expected))
+	allowed := float64(expected) * float64(tolerancePercent) / 100
+
+	if difference > allowed {
+		return fmt.Errorf("%w: expected %s, found %s", ErrRuntimeMismatch, expected, actual.Round(time.Minute))
+	}
+
+	return nil
+}
+
+// VerifyRuntime compares the total duration of the book's audio files at localPath
+// against the runtime in bookMetadata. A large mismatch usually means the metadata matched
+// the wrong edition (abridged, dramatized, etc). Verification is skipped when no
+// media probe is configured or the metadata has no runtime.
+func (abis *AudiobookImporterSystem) VerifyRuntime(ctx context.Context, bookMetadata metadata.BookMetadata, localPath string) error {
+	if abis.mediaProbe == nil || bookMetadata.RuntimeLength == 0 {
+		return nil
+	}
+
+	files, err := collectBookFiles(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to collect audio files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	actual, _, err := abis.probeAudioFiles(ctx, files)
+	if err != nil {
+		return fmt.Errorf("failed to probe audio files: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Comparing audio duration with metadata runtime",
+		slog.String("title", bookMetadata.Title),
+		slog.Int("runtimeMinutes", bookMetadata.RuntimeLength),
+		slog.Duration("actual", actual))
+
+	return checkRuntime(bookMetadata.RuntimeLength, actual, abis.cfg.AudiobookImporter.RuntimeTolerancePercent)
+}
+
+// writeSidecars saves cover art and a chapter list next to the imported audio files.
+// Failures are logged but do not fail the import.
+func (abis *AudiobookImporterSystem) writeSidecars(ctx context.Context, bookMetadata metadata.BookMetadata, destDirectory string) {
+	files, err := collectBookFiles(destDirectory)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to collect imported audio files",
+			slog.String("destination", destDirectory),
+			slogx.Error(err))
+	}
+
+	err = abis.writeCover(ctx, bookMetadata, destDirectory, files)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to write cover",
+			slog.String("title", bookMetadata.Title),
+			slog.String("destination", destDirectory),
+			slogx.Error(err))
+	}
+
+	if abis.mediaProbe == nil || len(files) == 0 {
+		return
+	}
+
+	_, chapters, err := abis.probeAudioFiles(ctx, files)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to probe imported audio files for chapters",
+			slog.String("title", bookMetadata.Title),
+			slogx.Error(err))
+		return
+	}
+
+	err = writeChapters(path.Join(destDirectory, chaptersFileName), chapters)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to write chapters",
+			slog.String("title", bookMetadata.Title),
+			slog.String("destination", destDirectory),
+			slogx.Error(err))
+	}
+}
+
+// writeCover saves cover.jpg from the metadata image URL, falling back to the
+// artwork embedded in the first audio file.
+func (abis *AudiobookImporterSystem) writeCover(ctx context.Context, bookMetadata metadata.BookMetadata, destDirectory string, files []string) error {
+	coverPath := path.Join(destDirectory, coverFileName)
+
+	if bookMetadata.Image != nil && *bookMetadata.Image != "" {
+		err := abis.downloadCover(ctx, *bookMetadata.Image, coverPath)
+		if err == nil {
+			return nil
+		}
+
+		slog.WarnContext(ctx, "Failed to download cover, trying embedded artwork",
+			slog.String("url", *bookMetadata.Image),
+			slogx.Error(err))
+	}
+
+	if abis.mediaProbe == nil || len(files) == 0 {
+		return nil
+	}
+
+	info, err := abis.mediaProbe.ProbeMedia(ctx, files[0])
+	if err != nil {
+		return err
+	}
+
+	if !info.HasEmbeddedCover {
+		return nil
+	}
+
+	return abis.mediaProbe.ExtractCover(ctx, files[0], coverPath)
+}
+
+func (abis *AudiobookImporterSystem) downloadCover(ctx context.Context, imageURL string, coverPath string) error {
+	ctx, cancel := context.WithTimeout(ctx, coverDownloadTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return err
+	}
+
+	httpClient := abis.httpClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: status=%d", response.StatusCode)
+	}
+
+	// Download next to the cover and rename it into place so an interrupted
+	// transfer never leaves a truncated cover behind.
+	file, err := os.CreateTemp(path.Dir(coverPath), coverFileName+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(file.Name()) }()
+
+	_, err = io.Copy(file, response.Body)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), coverPath)
+}
+
+func writeChapters(filename string, chapters []metadata.Chapter) error {
+	data, err := json.MarshalIndent(chapters, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, 0644)
+}
@@ -0,0 +1,266 @@
+package audiobooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// MockMediaProbe is a mock implementation of metadata.MediaProbe for testing
+type MockMediaProbe struct {
+	MediaInfos        map[string]metadata.MediaInfo
+	ProbeMediaErr     error
+	ExtractCoverCalls []string
+}
+
+func (m *MockMediaProbe) ProbeMedia(ctx context.Context, path string) (metadata.MediaInfo, error) {
+	if m.ProbeMediaErr != nil {
+		return metadata.MediaInfo{}, m.ProbeMediaErr
+	}
+
+	return m.MediaInfos[path], nil
+}
+
+func (m *MockMediaProbe) ExtractCover(ctx context.Context, path string, dest string) error {
+	m.ExtractCoverCalls = append(m.ExtractCoverCalls, path)
+	return os.WriteFile(dest, []byte("embedded"), 0644)
+}
+
+func writeTestAudioFiles(t *testing.T, dir string, names ...string) []string {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = path.Join(dir, name)
+		require.NoError(t, os.WriteFile(paths[i], []byte("audio"), 0644))
+	}
+
+	return paths
+}
+
+func TestCheckRuntime(t *testing.T) {
+	tests := []struct {
+		name      string
+		expected  int
+		actual    time.Duration
+		tolerance int
+		wantErr   bool
+	}{
+		{name: "exact match", expected: 600, actual: 600 * time.Minute, tolerance: 10},
+		{name: "within tolerance", expected: 600, actual: 580 * time.Minute, tolerance: 10},
+		{name: "abridged edition", expected: 600, actual: 300 * time.Minute, tolerance: 10, wantErr: true},
+		{name: "default tolerance", expected: 600, actual: 500 * time.Minute, tolerance: 0, wantErr: true},
+		{name: "no runtime in metadata", expected: 0, actual: 300 * time.Minute, tolerance: 10},
+		{name: "no duration found", expected: 600, actual: 0, tolerance: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRuntime(tt.expected, tt.actual, tt.tolerance)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrRuntimeMismatch)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRuntime(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	files := writeTestAudioFiles(t, dir, "01.mp3", "02.mp3", "cover.png")
+
+	probe := &MockMediaProbe{
+		MediaInfos: map[string]metadata.MediaInfo{
+			files[0]: {Duration: 150 * time.Minute},
+			files[1]: {Duration: 150 * time.Minute},
+		},
+	}
+
+	importer := &AudiobookImporterSystem{mediaProbe: probe}
+
+	t.Run("matching runtime", func(t *testing.T) {
+		bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+		bookMetadata.RuntimeLength = 300
+
+		assert.NoError(t, importer.VerifyRuntime(ctx, bookMetadata, dir))
+	})
+
+	t.Run("wrong edition", func(t *testing.T) {
+		bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+		bookMetadata.RuntimeLength = 900
+
+		assert.ErrorIs(t, importer.VerifyRuntime(ctx, bookMetadata, dir), ErrRuntimeMismatch)
+	})
+
+	t.Run("both formats", func(t *testing.T) {
+		bothDir := t.TempDir()
+		bothFiles := writeTestAudioFiles(t, bothDir, "Book.m4b", "01.mp3", "02.mp3")
+
+		importer := &AudiobookImporterSystem{mediaProbe: &MockMediaProbe{
+			MediaInfos: map[string]metadata.MediaInfo{
+				bothFiles[0]: {Duration: 300 * time.Minute},
+				bothFiles[1]: {Duration: 150 * time.Minute},
+				bothFiles[2]: {Duration: 150 * time.Minute},
+			},
+		}}
+
+		bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+		bookMetadata.RuntimeLength = 300
+
+		assert.NoError(t, importer.VerifyRuntime(ctx, bookMetadata, bothDir))
+	})
+
+	t.Run("probe failure", func(t *testing.T) {
+		failing := &AudiobookImporterSystem{mediaProbe: &MockMediaProbe{ProbeMediaErr: errors.New("boom")}}
+		bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+
+		err := failing.VerifyRuntime(ctx, bookMetadata, dir)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrRuntimeMismatch)
+	})
+
+	t.Run("no media probe configured", func(t *testing.T) {
+		bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+		bookMetadata.RuntimeLength = 900
+
+		assert.NoError(t, (&AudiobookImporterSystem{}).VerifyRuntime(ctx, bookMetadata, dir))
+	})
+}
+
+func TestWriteSidecars_ChaptersAcrossFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	files := writeTestAudioFiles(t, dir, "part1.mp3", "part2.mp3")
+
+	probe := &MockMediaProbe{
+		MediaInfos: map[string]metadata.MediaInfo{
+			files[0]: {
+				Duration: 20 * time.Second,
+				Chapters: []metadata.Chapter{
+					{Title: "Opening", Start: 0, End: 10},
+					{Title: "Chapter 1", Start: 10, End: 20},
+				},
+			},
+			files[1]: {Duration: 30 * time.Second},
+		},
+	}
+
+	importer := &AudiobookImporterSystem{mediaProbe: probe}
+	importer.writeSidecars(ctx, createTestBookMetadata("Test Title", "B01234567"), dir)
+
+	data, err := os.ReadFile(path.Join(dir, chaptersFileName))
+	require.NoError(t, err)
+
+	var chapters []metadata.Chapter
+	require.NoError(t, json.Unmarshal(data, &chapters))
+
+	assert.Equal(t, []metadata.Chapter{
+		{Title: "Opening", Start: 0, End: 10},
+		{Title: "Chapter 1", Start: 10, End: 20},
+		{Title: "part2", Start: 20, End: 50},
+	}, chapters)
+
+	// No image URL and no embedded artwork means no cover
+	_, err = os.Stat(path.Join(dir, coverFileName))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteSidecars_CoverFromImageURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("jpeg-bytes"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeTestAudioFiles(t, dir, "book.m4b")
+
+	probe := &MockMediaProbe{}
+	importer := &AudiobookImporterSystem{mediaProbe: probe, httpClient: server.Client()}
+
+	bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+	imageURL := server.URL + "/cover.jpg"
+	bookMetadata.Image = &imageURL
+
+	importer.writeSidecars(ctx, bookMetadata, dir)
+
+	data, err := os.ReadFile(path.Join(dir, coverFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg-bytes", string(data))
+	assert.Empty(t, probe.ExtractCoverCalls)
+}
+
+func TestWriteSidecars_TruncatedCoverDownload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Promise more bytes than are sent so the client sees an unexpected EOF
+		w.Header().Set("Content-Length", "1024")
+		_, _ = w.Write([]byte("partial"))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	writeTestAudioFiles(t, dir, "book.m4b")
+
+	probe := &MockMediaProbe{}
+	importer := &AudiobookImporterSystem{mediaProbe: probe, httpClient: server.Client()}
+
+	bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+	imageURL := server.URL + "/cover.jpg"
+	bookMetadata.Image = &imageURL
+
+	importer.writeSidecars(ctx, bookMetadata, dir)
+
+	_, err := os.Stat(path.Join(dir, coverFileName))
+	assert.True(t, os.IsNotExist(err))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Name(), ".tmp")
+	}
+}
+
+func TestWriteSidecars_CoverFromEmbeddedArtwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	files := writeTestAudioFiles(t, dir, "book.m4b")
+
+	probe := &MockMediaProbe{
+		MediaInfos: map[string]metadata.MediaInfo{
+			files[0]: {Duration: time.Minute, HasEmbeddedCover: true},
+		},
+	}
+	importer := &AudiobookImporterSystem{mediaProbe: probe, httpClient: server.Client()}
+
+	bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+	imageURL := server.URL + "/missing.jpg"
+	bookMetadata.Image = &imageURL
+
+	importer.writeSidecars(ctx, bookMetadata, dir)
+
+	data, err := os.ReadFile(path.Join(dir, coverFileName))
+	require.NoError(t, err)
+	assert.Equal(t, "embedded", string(data))
+	assert.Equal(t, []string{files[0]}, probe.ExtractCoverCalls)
+}
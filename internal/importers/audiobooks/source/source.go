@@ -32,12 +32,33 @@ func (st SourceType) String() string {
 	}
 }
 
+// IsAudioFile reports whether filePath is an audio format AnalyzeSource picks
+// up. Like AnalyzeSource it matches extensions case-sensitively.
+func IsAudioFile(filePath string) bool {
+	switch filepath.Ext(filePath) {
+	case ".mp3", ".m4b":
+		return true
+	}
+
+	return false
+}
+
 type SourceInfo struct {
 	M4bFiles   []common.MappedTorrentFile
 	Mp3Files   []common.MappedTorrentFile
 	SourceType SourceType
 }
 
+// BookFiles returns the files imported as the book. A torrent with both
+// formats is treated as its M4B, matching the tag extraction.
+func (si SourceInfo) BookFiles() []common.MappedTorrentFile {
+	if len(si.M4bFiles) > 0 {
+		return si.M4bFiles
+	}
+
+	return si.Mp3Files
+}
+
 func AnalyzeSource(files []common.MappedTorrentFile) (SourceInfo, error) {
 	ctx := context.Background()
 
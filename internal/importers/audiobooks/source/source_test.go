@@ -5,6 +5,7 @@ import (
 
 	"github.com/bobbyrward/stronghold/internal/importers/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestSourceType_String(t *testing.T) {
@@ -354,3 +355,28 @@ func TestAnalyzeSource_OtherAudioFormats(t *testing.T) {
 	assert.Len(t, result.M4bFiles, 0)
 	assert.Len(t, result.Mp3Files, 0)
 }
+
+func TestSourceInfoBookFiles(t *testing.T) {
+	m4b := common.MappedTorrentFile{BaseName: "Book.m4b", LocalPath: "/path/to/Book.m4b"}
+	mp3 := common.MappedTorrentFile{BaseName: "01.mp3", LocalPath: "/path/to/01.mp3"}
+
+	both, err := AnalyzeSource([]common.MappedTorrentFile{mp3, m4b})
+	require.NoError(t, err)
+	assert.Equal(t, []common.MappedTorrentFile{m4b}, both.BookFiles())
+
+	mp3Only, err := AnalyzeSource([]common.MappedTorrentFile{mp3})
+	require.NoError(t, err)
+	assert.Equal(t, []common.MappedTorrentFile{mp3}, mp3Only.BookFiles())
+
+	unknown, err := AnalyzeSource(nil)
+	require.NoError(t, err)
+	assert.Empty(t, unknown.BookFiles())
+}
+
+func TestIsAudioFile(t *testing.T) {
+	assert.True(t, IsAudioFile("Book/Part 1.mp3"))
+	assert.True(t, IsAudioFile("Book.m4b"))
+	assert.False(t, IsAudioFile("Book/cover.jpg"))
+	assert.False(t, IsAudioFile("Book.MP3"))
+	assert.False(t, IsAudioFile("mp3"))
+}
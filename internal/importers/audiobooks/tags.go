@@ -0,0 +1,56 @@
+package audiobooks
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+
+	"github.com/cappuccinotm/slogx"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// writeAudioTags rewrites the tags of the imported audio files from the selected
+// metadata, numbering the tracks of multi-file books. Failures are logged but do
+// not fail the import; the OPF still carries the metadata. Tagging replaces the
+// hardlinked files with new ones, so it returns the base names of the files it
+// rewrote.
+func (abis *AudiobookImporterSystem) writeAudioTags(ctx context.Context, bookMetadata metadata.BookMetadata, destDirectory string) []string {
+	if abis.tagWriter == nil {
+		return nil
+	}
+
+	files, err := collectAudioFiles(destDirectory)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to collect imported audio files for tagging",
+			slog.String("destination", destDirectory),
+			slogx.Error(err))
+		return nil
+	}
+
+	var rewritten []string
+
+	for i, file := range files {
+		tags := bookMetadata.AudioTags()
+		if len(files) > 1 {
+			tags["track"] = fmt.Sprintf("%d/%d", i+1, len(files))
+			tags["title"] = bookMetadata.Title + " - Part " + strconv.Itoa(i+1)
+		}
+
+		err := abis.tagWriter.WriteTags(ctx, file, tags)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to write audio tags",
+				slog.String("title", bookMetadata.Title),
+				slog.String("file", file),
+				slogx.Error(err))
+			continue
+		}
+
+		rewritten = append(rewritten, filepath.Base(file))
+		slog.InfoContext(ctx, "Wrote audio tags", slog.String("file", file))
+	}
+
+	return rewritten
+}
@@ -0,0 +1,97 @@
+package audiobooks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+)
+
+// MockTagWriter is a mock implementation of metadata.TagWriter for testing
+type MockTagWriter struct {
+	Written map[string]metadata.AudioTags
+	Err     error
+}
+
+func (m *MockTagWriter) WriteTags(ctx context.Context, path string, tags metadata.AudioTags) error {
+	if m.Err != nil {
+		return m.Err
+	}
+
+	if m.Written == nil {
+		m.Written = map[string]metadata.AudioTags{}
+	}
+	m.Written[path] = tags
+	return nil
+}
+
+func TestWriteAudioTags_MultipleFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := writeTestAudioFiles(t, dir, "01.mp3", "02.mp3", "notes.txt")
+
+	writer := &MockTagWriter{}
+	importer := &AudiobookImporterSystem{tagWriter: writer}
+
+	rewritten := importer.writeAudioTags(context.Background(), createTestBookMetadata("Dune", "B002V1OF70"), dir)
+
+	require.Len(t, writer.Written, 2)
+	assert.Equal(t, "1/2", writer.Written[files[0]]["track"])
+	assert.Equal(t, "Dune - Part 1", writer.Written[files[0]]["title"])
+	assert.Equal(t, "2/2", writer.Written[files[1]]["track"])
+	assert.Equal(t, "Dune", writer.Written[files[1]]["album"])
+	assert.Equal(t, "B002V1OF70", writer.Written[files[1]]["AUDIBLE_ASIN"])
+	assert.Equal(t, []string{"01.mp3", "02.mp3"}, rewritten)
+}
+
+func TestExecuteImport_WriteAudioTags(t *testing.T) {
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	sourceFile := path.Join(tempDir, "audiobook.m4b")
+	require.NoError(t, os.WriteFile(sourceFile, []byte("test content"), 0644))
+
+	torrent := downloadclient.Torrent{Hash: "tags123", Name: "Tagged Audiobook"}
+	bookMetadata := createTestBookMetadata("Test Title", "B01234567")
+
+	t.Run("enabled", func(t *testing.T) {
+		writer := &MockTagWriter{}
+		importer := &AudiobookImporterSystem{tagWriter: writer}
+		library := &config.ImportLibrary{Name: "tagged", Path: path.Join(tempDir, "tagged"), WriteAudioTags: true}
+
+		destPath, err := importer.ExecuteImport(ctx, torrent, bookMetadata, library, sourceFile)
+		require.NoError(t, err)
+
+		tags := writer.Written[path.Join(destPath, "audiobook.m4b")]
+		require.NotNil(t, tags)
+		assert.Equal(t, "Test Title", tags["title"])
+		assert.Equal(t, "Test Author", tags["artist"])
+		assert.Equal(t, "Test Narrator", tags["composer"])
+		assert.NotContains(t, tags, "track")
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		writer := &MockTagWriter{}
+		importer := &AudiobookImporterSystem{tagWriter: writer}
+		library := &config.ImportLibrary{Name: "untagged", Path: path.Join(tempDir, "untagged")}
+
+		_, err := importer.ExecuteImport(ctx, torrent, bookMetadata, library, sourceFile)
+		require.NoError(t, err)
+		assert.Empty(t, writer.Written)
+	})
+
+	t.Run("failures do not fail the import", func(t *testing.T) {
+		importer := &AudiobookImporterSystem{tagWriter: &MockTagWriter{Err: errors.New("ffmpeg not found")}}
+		library := &config.ImportLibrary{Name: "failing", Path: path.Join(tempDir, "failing"), WriteAudioTags: true}
+
+		_, err := importer.ExecuteImport(ctx, torrent, bookMetadata, library, sourceFile)
+		assert.NoError(t, err)
+	})
+}
@@ -6,11 +6,10 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/autobrr/go-qbittorrent"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/source"
 	"github.com/bobbyrward/stronghold/internal/importers/common"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 	"github.com/cappuccinotm/slogx"
 )
 
@@ -25,14 +24,14 @@ type TorrentMetadata interface {
 
 // AudiobookFilesMetadata implements TorrentMetadata for audiobook torrents
 type AudiobookFilesMetadata struct {
-	torrent    qbittorrent.Torrent
+	torrent    downloadclient.Torrent
 	files      []common.MappedTorrentFile
 	sourceInfo source.SourceInfo
 	metadata   metadata.MetadataTags
 }
 
 // NewAudiobookFilesMetadata creates a new AudiobookFilesMetadata instance
-func NewAudiobookFilesMetadata(ctx context.Context, qbit qbit.QbitClient, metadataProvider metadata.MetadataProvider, torrent qbittorrent.Torrent, mappedFiles []common.MappedTorrentFile) (*AudiobookFilesMetadata, error) {
+func NewAudiobookFilesMetadata(ctx context.Context, client downloadclient.DownloadClient, metadataProvider metadata.MetadataProvider, torrent downloadclient.Torrent, mappedFiles []common.MappedTorrentFile) (*AudiobookFilesMetadata, error) {
 	sourceInfo, err := source.AnalyzeSource(mappedFiles)
 	if err != nil {
 		slog.ErrorContext(ctx, "unable to analyze torrent source files",
@@ -5,9 +5,9 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/autobrr/go-qbittorrent"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/source"
 	"github.com/bobbyrward/stronghold/internal/importers/common"
@@ -42,6 +42,10 @@ type MockMetadataTags struct {
 	TitleOk           bool
 	AudibleASINValue  string
 	AudibleASINOk     bool
+	NarratorValue     string
+	NarratorOk        bool
+	SeriesPosValue    string
+	SeriesPosOk       bool
 }
 
 func (m *MockMetadataTags) Artist() (string, bool) {
@@ -56,10 +60,18 @@ func (m *MockMetadataTags) AudibleASIN() (string, bool) {
 	return m.AudibleASINValue, m.AudibleASINOk
 }
 
+func (m *MockMetadataTags) Narrator() (string, bool) {
+	return m.NarratorValue, m.NarratorOk
+}
+
+func (m *MockMetadataTags) SeriesPosition() (string, bool) {
+	return m.SeriesPosValue, m.SeriesPosOk
+}
+
 func TestNewAudiobookFilesMetadata_M4B_Success(t *testing.T) {
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "abc123",
 		Name: "Test Audiobook",
 	}
@@ -90,7 +102,7 @@ func TestNewAudiobookFilesMetadata_M4B_Success(t *testing.T) {
 		},
 	}
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -107,7 +119,7 @@ func TestNewAudiobookFilesMetadata_M4B_Success(t *testing.T) {
 func TestNewAudiobookFilesMetadata_MP3_Success(t *testing.T) {
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "def456",
 		Name: "Test MP3 Audiobook",
 	}
@@ -140,7 +152,7 @@ func TestNewAudiobookFilesMetadata_MP3_Success(t *testing.T) {
 		},
 	}
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -157,7 +169,7 @@ func TestNewAudiobookFilesMetadata_MP3_Success(t *testing.T) {
 func TestNewAudiobookFilesMetadata_M4B_and_MP3_Success(t *testing.T) {
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "ghi789",
 		Name: "Mixed Format Audiobook",
 	}
@@ -194,7 +206,7 @@ func TestNewAudiobookFilesMetadata_M4B_and_MP3_Success(t *testing.T) {
 		},
 	}
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -211,7 +223,7 @@ func TestNewAudiobookFilesMetadata_M4B_and_MP3_Success(t *testing.T) {
 func TestNewAudiobookFilesMetadata_MetadataProviderError(t *testing.T) {
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "error123",
 		Name: "Error Audiobook",
 	}
@@ -235,7 +247,7 @@ func TestNewAudiobookFilesMetadata_MetadataProviderError(t *testing.T) {
 		},
 	}
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -251,7 +263,7 @@ func TestNewAudiobookFilesMetadata_MetadataProviderError(t *testing.T) {
 func TestNewAudiobookFilesMetadata_UnknownSourceType(t *testing.T) {
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "unknown123",
 		Name: "Unknown Format",
 	}
@@ -269,7 +281,7 @@ func TestNewAudiobookFilesMetadata_UnknownSourceType(t *testing.T) {
 	}
 
 	mockMetadata := &MockMetadataProvider{}
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -284,7 +296,7 @@ func TestNewAudiobookFilesMetadata_UnknownSourceType(t *testing.T) {
 func TestNewAudiobookFilesMetadata_EmptyFiles(t *testing.T) {
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "empty123",
 		Name: "Empty Audiobook",
 	}
@@ -292,7 +304,7 @@ func TestNewAudiobookFilesMetadata_EmptyFiles(t *testing.T) {
 	files := []common.MappedTorrentFile{}
 
 	mockMetadata := &MockMetadataProvider{}
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -304,7 +316,7 @@ func TestNewAudiobookFilesMetadata_EmptyFiles(t *testing.T) {
 func TestNewAudiobookFilesMetadata_MultipleM4BFiles(t *testing.T) {
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "multi123",
 		Name: "Multi M4B Audiobook",
 	}
@@ -339,7 +351,7 @@ func TestNewAudiobookFilesMetadata_MultipleM4BFiles(t *testing.T) {
 		},
 	}
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -353,7 +365,7 @@ func TestNewAudiobookFilesMetadata_MultipleM4BFiles(t *testing.T) {
 
 func TestAudiobookFilesMetadata_Hash(t *testing.T) {
 	abfm := &AudiobookFilesMetadata{
-		torrent: qbittorrent.Torrent{
+		torrent: downloadclient.Torrent{
 			Hash: "test-hash-123",
 			Name: "Test",
 		},
@@ -580,7 +592,7 @@ func analyzeSourceForTest(files []common.MappedTorrentFile) (source.SourceInfo,
 func TestNewAudiobookFilesMetadata_WithMixedNonAudioFiles(t *testing.T) {
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "mixed123",
 		Name: "Mixed Files",
 	}
@@ -619,7 +631,7 @@ func TestNewAudiobookFilesMetadata_WithMixedNonAudioFiles(t *testing.T) {
 		},
 	}
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -634,7 +646,7 @@ func TestNewAudiobookFilesMetadata_CustomMetadataFunc(t *testing.T) {
 	// This test demonstrates using a custom function for more complex metadata scenarios
 	ctx := context.Background()
 
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash: "custom456",
 		Name: "Custom Metadata Test",
 	}
@@ -664,7 +676,7 @@ func TestNewAudiobookFilesMetadata_CustomMetadataFunc(t *testing.T) {
 		return nil, errors.New("unexpected path")
 	}
 
-	mockQbit := &testutil.MockQbitClient{}
+	mockQbit := &testutil.MockDownloadClient{}
 
 	result, err := NewAudiobookFilesMetadata(ctx, mockQbit, mockMetadata, torrent, files)
 
@@ -6,41 +6,37 @@ import (
 	"fmt"
 	"log/slog"
 
-	"github.com/autobrr/go-qbittorrent"
 	"github.com/cappuccinotm/slogx"
 	"gorm.io/gorm"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/feedwatcher2"
-	"github.com/bobbyrward/stronghold/internal/importers/audiobooks"
-	"github.com/bobbyrward/stronghold/internal/importers/ebooks"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
 	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/bobbyrward/stronghold/internal/notifications"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 )
 
 // AuthorSubscriptionImporter handles importing torrents from the author-subscriptions category.
 // It looks up the AuthorSubscriptionItem to determine book type and destination library.
 type AuthorSubscriptionImporter struct {
-	db              *gorm.DB
-	qbitClient      qbit.QbitClient
-	audiobookSystem *audiobooks.AudiobookImporterSystem
-	ebookSystem     *ebooks.BookImporterSystem
+	db             *gorm.DB
+	downloadClient downloadclient.DownloadClient
+	router         *common.Router
 }
 
-// NewAuthorSubscriptionImporter creates a new AuthorSubscriptionImporter.
+// NewAuthorSubscriptionImporter creates a new AuthorSubscriptionImporter. The
+// router supplies the importer for each item's book type.
 func NewAuthorSubscriptionImporter(
 	db *gorm.DB,
-	qbitClient qbit.QbitClient,
-	audiobookSystem *audiobooks.AudiobookImporterSystem,
-	ebookSystem *ebooks.BookImporterSystem,
+	downloadClient downloadclient.DownloadClient,
+	router *common.Router,
 ) *AuthorSubscriptionImporter {
 	return &AuthorSubscriptionImporter{
-		db:              db,
-		qbitClient:      qbitClient,
-		audiobookSystem: audiobookSystem,
-		ebookSystem:     ebookSystem,
+		db:             db,
+		downloadClient: downloadClient,
+		router:         router,
 	}
 }
 
@@ -48,19 +44,25 @@ func NewAuthorSubscriptionImporter(
 func (asi *AuthorSubscriptionImporter) Run(ctx context.Context) error {
 	slog.InfoContext(ctx, "Running author subscription import process...")
 
-	torrents, err := qbit.GetUnimportedTorrentsByCategory(
+	summary := common.NewRunSummary("author-subscriptions")
+	defer summary.Log(ctx)
+
+	candidates, err := downloadclient.GetImportReadyTorrentsByCategory(
 		ctx,
-		asi.qbitClient,
+		asi.downloadClient,
 		feedwatcher2.AuthorSubscriptionCategory,
+		downloadclient.SeedGate{},
 	)
 	if err != nil {
 		return fmt.Errorf("failed to get unimported torrents for author-subscriptions: %w", err)
 	}
 
+	summary.Add(candidates)
+
 	slog.InfoContext(ctx, "Found unimported author subscription torrents",
-		slog.Int("count", len(torrents)))
+		slog.Int("count", len(candidates.Ready)))
 
-	for _, torrent := range torrents {
+	for _, torrent := range candidates.Ready {
 		err := asi.importTorrent(ctx, torrent)
 		if err != nil {
 			slog.ErrorContext(ctx, "Failed to import author subscription torrent",
@@ -71,11 +73,13 @@ func (asi *AuthorSubscriptionImporter) Run(ctx context.Context) error {
 		}
 	}
 
+	asi.router.Finish(ctx)
+
 	return nil
 }
 
 // importTorrent imports a single torrent from the author-subscriptions category.
-func (asi *AuthorSubscriptionImporter) importTorrent(ctx context.Context, torrent qbittorrent.Torrent) error {
+func (asi *AuthorSubscriptionImporter) importTorrent(ctx context.Context, torrent downloadclient.Torrent) error {
 	slog.InfoContext(ctx, "Processing author subscription torrent",
 		slog.String("name", torrent.Name),
 		slog.String("hash", torrent.Hash))
@@ -85,6 +89,7 @@ func (asi *AuthorSubscriptionImporter) importTorrent(ctx context.Context, torren
 	result := asi.db.
 		Preload("BookType").
 		Preload("AuthorSubscription").
+		Preload("AuthorSubscription.Scope").
 		Preload("AuthorSubscription.EbookLibrary").
 		Preload("AuthorSubscription.AudiobookLibrary").
 		Preload("AuthorSubscription.Notifier").
@@ -107,15 +112,16 @@ func (asi *AuthorSubscriptionImporter) importTorrent(ctx context.Context, torren
 		slog.String("book_type", item.BookType.Name),
 		slog.Uint64("subscription_id", uint64(item.AuthorSubscriptionID)))
 
-	// Determine the destination library based on book type
+	// The destination is whichever of the subscription's libraries holds this book type
 	var library *models.Library
-	switch item.BookType.Name {
-	case "audiobook":
-		library = &item.AuthorSubscription.AudiobookLibrary
-	case "ebook":
-		library = &item.AuthorSubscription.EbookLibrary
-	default:
-		return fmt.Errorf("unknown book type: %s", item.BookType.Name)
+	for _, candidate := range []*models.Library{&item.AuthorSubscription.EbookLibrary, &item.AuthorSubscription.AudiobookLibrary} {
+		if candidate.BookTypeID == item.BookTypeID {
+			library = candidate
+			break
+		}
+	}
+	if library == nil {
+		return fmt.Errorf("subscription %d has no %s library", item.AuthorSubscriptionID, item.BookType.Name)
 	}
 
 	slog.InfoContext(ctx, "Using library for import",
@@ -123,43 +129,68 @@ func (asi *AuthorSubscriptionImporter) importTorrent(ctx context.Context, torren
 		slog.String("library_path", library.Path),
 		slog.String("book_type", item.BookType.Name))
 
-	// Create config adapters for the import systems
-	importLibrary := &config.ImportLibrary{
-		Name: library.Name,
-		Path: library.Path,
-	}
-
 	// Get notifier name if set
 	notifierName := ""
 	if item.AuthorSubscription.Notifier != nil {
 		notifierName = item.AuthorSubscription.Notifier.Name
 	}
 
-	importType := config.ImportType{
-		Category:        feedwatcher2.AuthorSubscriptionCategory,
-		Library:         library.Name,
-		DiscordNotifier: notifierName,
+	importer, importType, err := asi.route(ctx, &item)
+	if err != nil {
+		return err
 	}
 
+	importType.Category = feedwatcher2.AuthorSubscriptionCategory
+	importType.Library = library.Name
+	importType.DiscordNotifier = notifierName
+
 	eventlog.Log(asi.db, eventlog.CategoryImport, eventlog.EventImportStarted, eventlog.SourceAuthorSubscriptionImporter,
 		eventlog.EntityTorrent, torrent.Hash,
 		fmt.Sprintf("Import started: %s (%s)", item.Title, item.BookType.Name),
 		map[string]string{"title": item.Title, "hash": torrent.Hash, "book_type": item.BookType.Name, "library": library.Name})
 
-	// Route to appropriate importer based on book type
-	switch item.BookType.Name {
-	case "audiobook":
-		asi.audiobookSystem.ImportTorrentWithLibrary(ctx, torrent, importType, importLibrary)
-	case "ebook":
-		asi.ebookSystem.ImportTorrent(ctx, torrent, importType, importLibrary)
-	}
+	importer.ImportTorrent(ctx, torrent, importType, common.ImportLibraryFromModel(*library))
 
 	return nil
 }
 
+// route returns the importer and import settings for an item. The import type
+// of the category holding the item's book type in the subscription's scope
+// supplies the settings, e.g. personal-audiobooks for a personal subscription.
+// Without one the item is imported with default settings.
+func (asi *AuthorSubscriptionImporter) route(ctx context.Context, item *models.AuthorSubscriptionItem) (common.Importer, config.ImportType, error) {
+	routes, err := asi.router.Routes(ctx, common.RouteFilter{
+		MediaType: item.BookType.Name,
+		Scope:     item.AuthorSubscription.Scope.Name,
+	})
+	if err != nil {
+		return nil, config.ImportType{}, err
+	}
+
+	for _, route := range routes {
+		// author-subscriptions is itself a category and is not a source of settings
+		if route.Category.Name == feedwatcher2.AuthorSubscriptionCategory {
+			continue
+		}
+
+		slog.InfoContext(ctx, "Using import settings for subscription scope",
+			slog.String("scope", item.AuthorSubscription.Scope.Name),
+			slog.String("category", route.Category.Name))
+
+		return route.Importer, route.ImportType, nil
+	}
+
+	importer, err := asi.router.Importer(item.BookType.Name)
+	if err != nil {
+		return nil, config.ImportType{}, err
+	}
+
+	return importer, config.ImportType{}, nil
+}
+
 // markForManualIntervention tags a torrent for manual handling and sends a notification.
-func (asi *AuthorSubscriptionImporter) markForManualIntervention(ctx context.Context, torrent qbittorrent.Torrent, notifierName string, reason string) error {
-	err := qbit.TagTorrent(ctx, asi.qbitClient, torrent, config.Config.Importers.ManualInterventionTag)
+func (asi *AuthorSubscriptionImporter) markForManualIntervention(ctx context.Context, torrent downloadclient.Torrent, notifierName string, reason string) error {
+	err := downloadclient.TagTorrent(ctx, asi.downloadClient, torrent, config.Config.Importers.ManualInterventionTag)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to add manual intervention tag",
 			slog.String("name", torrent.Name),
@@ -178,6 +209,8 @@ func (asi *AuthorSubscriptionImporter) markForManualIntervention(ctx context.Con
 		fmt.Sprintf("Manual intervention: %s: %s", torrent.Name, reason),
 		map[string]string{"name": torrent.Name, "hash": torrent.Hash, "reason": reason})
 
+	common.RecordManualIntervention(ctx, asi.db, torrent, eventlog.SourceAuthorSubscriptionImporter, reason)
+
 	// Send Discord notification if notifier is configured
 	asi.sendManualInterventionNotification(ctx, torrent, notifierName, reason)
 
@@ -185,7 +218,7 @@ func (asi *AuthorSubscriptionImporter) markForManualIntervention(ctx context.Con
 }
 
 // sendManualInterventionNotification sends a Discord notification about a torrent requiring manual intervention.
-func (asi *AuthorSubscriptionImporter) sendManualInterventionNotification(ctx context.Context, torrent qbittorrent.Torrent, notifierName string, reason string) {
+func (asi *AuthorSubscriptionImporter) sendManualInterventionNotification(ctx context.Context, torrent downloadclient.Torrent, notifierName string, reason string) {
 	if notifierName == "" {
 		return
 	}
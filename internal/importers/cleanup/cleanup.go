@@ -0,0 +1,264 @@
+// Package cleanup removes imported torrents from the download client once the retention
+// policy of their import type allows it.
+package cleanup
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// KeepReasonNotHardlinked is why a torrent due for removal was kept when its
+// files are not hardlinked into the library.
+const KeepReasonNotHardlinked = "not-hardlinked"
+
+// Removal is a torrent removed, or due for removal in a dry run.
+type Removal struct {
+	Torrent downloadclient.Torrent
+	Reason  string
+}
+
+type CleanupSystem struct {
+	downloadClient downloadclient.DownloadClient
+	config         config.ImportersConfig
+	db             *gorm.DB
+
+	// DryRun logs the torrents that would be removed without removing them
+	DryRun bool
+}
+
+func NewCleanupSystem(downloadClient downloadclient.DownloadClient, importersConfig config.ImportersConfig, db *gorm.DB) *CleanupSystem {
+	return &CleanupSystem{
+		downloadClient: downloadClient,
+		config:         importersConfig,
+		db:             db,
+	}
+}
+
+// RetentionPolicyForImportType returns the retention policy configured for an import type.
+func RetentionPolicyForImportType(importType config.ImportType) downloadclient.RetentionPolicy {
+	return downloadclient.RetentionPolicy{
+		RemoveAfterRatio:    importType.Retention.RemoveAfterRatio,
+		RemoveAfterSeedTime: time.Duration(importType.Retention.RemoveAfterSeedDays) * 24 * time.Hour,
+		PrivateMinSeedTime:  time.Duration(importType.Retention.PrivateMinSeedDays) * 24 * time.Hour,
+	}
+}
+
+// Run applies the retention policy of every import type.
+func (cs *CleanupSystem) Run(ctx context.Context) ([]Removal, error) {
+	slog.InfoContext(ctx, "Running torrent cleanup...", slog.Bool("dryRun", cs.DryRun))
+
+	importTypes, err := common.LoadImportTypes(ctx, cs.db, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var removals []Removal
+
+	for _, row := range importTypes {
+		importType := common.ImportTypeConfig(row)
+
+		removed, err := cs.ProcessImportType(ctx, importType)
+		removals = append(removals, removed...)
+		if err != nil {
+			return removals, fmt.Errorf("failed to clean up import type %s: %w", importType.Category, err)
+		}
+	}
+
+	slog.InfoContext(ctx, "Torrent cleanup finished", slog.Int("removed", len(removals)), slog.Bool("dryRun", cs.DryRun))
+
+	return removals, nil
+}
+
+// ProcessImportType removes the imported torrents of an import type that its
+// retention policy no longer keeps. A torrent that fails to be removed is
+// logged and left for the next run.
+func (cs *CleanupSystem) ProcessImportType(ctx context.Context, importType config.ImportType) ([]Removal, error) {
+	policy := RetentionPolicyForImportType(importType)
+	if !policy.Enabled() {
+		return nil, nil
+	}
+
+	torrents, err := downloadclient.GetImportedTorrentsByCategory(ctx, cs.downloadClient, importType.Category, cs.config.ImportedTag)
+	if err != nil {
+		return nil, err
+	}
+
+	var removals []Removal
+
+	for _, torrent := range torrents {
+		remove, reason := downloadclient.RetentionDecision(torrent, policy)
+
+		if remove && importType.Retention.RequireHardlink {
+			linked, err := cs.hardlinkedIntoLibrary(ctx, torrent)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to check torrent hardlinks",
+					slog.String("name", torrent.Name),
+					slog.String("hash", torrent.Hash),
+					slogx.Error(err))
+			}
+
+			if !linked {
+				remove, reason = false, KeepReasonNotHardlinked
+			}
+		}
+
+		if !remove {
+			slog.DebugContext(ctx, "Keeping imported torrent",
+				slog.String("name", torrent.Name),
+				slog.String("hash", torrent.Hash),
+				slog.String("reason", reason))
+			continue
+		}
+
+		if cs.DryRun {
+			slog.InfoContext(ctx, "Would remove imported torrent",
+				slog.String("name", torrent.Name),
+				slog.String("hash", torrent.Hash),
+				slog.String("reason", reason))
+			removals = append(removals, Removal{Torrent: torrent, Reason: reason})
+			continue
+		}
+
+		err := cs.downloadClient.RemoveTorrents(ctx, []string{torrent.Hash}, importType.Retention.DeleteFiles)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to remove imported torrent",
+				slog.String("name", torrent.Name),
+				slog.String("hash", torrent.Hash),
+				slogx.Error(err))
+			continue
+		}
+
+		slog.InfoContext(ctx, "Removed imported torrent",
+			slog.String("name", torrent.Name),
+			slog.String("hash", torrent.Hash),
+			slog.String("reason", reason),
+			slog.Bool("deleteFiles", importType.Retention.DeleteFiles))
+
+		eventlog.Log(cs.db, eventlog.CategoryDownload, eventlog.EventTorrentRemoved, eventlog.SourceTorrentCleanup,
+			eventlog.EntityTorrent, torrent.Hash,
+			fmt.Sprintf("Removed imported torrent: %s (%s)", torrent.Name, reason),
+			map[string]any{
+				"name":        torrent.Name,
+				"category":    torrent.Category,
+				"reason":      reason,
+				"ratio":       torrent.Ratio,
+				"seedingTime": torrent.SeedingTime,
+				"private":     torrent.Private,
+				"deleteFiles": importType.Retention.DeleteFiles,
+			})
+
+		removals = append(removals, Removal{Torrent: torrent, Reason: reason})
+	}
+
+	return removals, nil
+}
+
+// hardlinkedIntoLibrary reports whether a torrent's import was hardlinked. Every
+// torrent file that was placed in the library must be the same file as its
+// library copy, so a copy made after a failed link keeps the torrent, and at
+// least one file must have been placed. Library files the importer recorded as
+// rewritten after linking them, such as retagged audio, count as linked.
+func (cs *CleanupSystem) hardlinkedIntoLibrary(ctx context.Context, torrent downloadclient.Torrent) (bool, error) {
+	if cs.db == nil {
+		return false, nil
+	}
+
+	var items []models.ImportedItem
+	if err := cs.db.Where("torrent_hash = ?", torrent.Hash).Find(&items).Error; err != nil {
+		return false, fmt.Errorf("failed to load imported items: %w", err)
+	}
+
+	libraryFiles := make(map[string][]os.FileInfo)
+	rewritten := make(map[string]bool)
+
+	for _, item := range items {
+		rewrittenFiles, err := common.ImportedItemRewrittenFiles(item)
+		if err != nil {
+			return false, fmt.Errorf("failed to read imported item %s: %w", item.Path, err)
+		}
+
+		for _, name := range rewrittenFiles {
+			rewritten[name] = true
+		}
+
+		err = filepath.WalkDir(item.Path, func(filePath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.Type().IsRegular() {
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+
+				libraryFiles[d.Name()] = append(libraryFiles[d.Name()], info)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to read imported item %s: %w", item.Path, err)
+		}
+	}
+
+	if len(libraryFiles) == 0 {
+		return false, nil
+	}
+
+	torrentFiles, err := common.MapTorrentFilesToLocalPaths(ctx, cs.downloadClient, torrent)
+	if err != nil {
+		return false, err
+	}
+
+	linked := 0
+
+	for _, torrentFile := range torrentFiles {
+		copies, ok := libraryFiles[filepath.Base(torrentFile.BaseName)]
+		if !ok {
+			continue
+		}
+
+		if rewritten[filepath.Base(torrentFile.BaseName)] {
+			linked++
+			continue
+		}
+
+		info, err := os.Stat(torrentFile.LocalPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to stat torrent file: %w", err)
+		}
+
+		if !sameFileAsAny(info, copies) {
+			return false, nil
+		}
+
+		linked++
+	}
+
+	return linked > 0, nil
+}
+
+func sameFileAsAny(info os.FileInfo, others []os.FileInfo) bool {
+	for _, other := range others {
+		if os.SameFile(info, other) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,214 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/testutil"
+)
+
+func importedTorrent(hash string, ratio float64) downloadclient.Torrent {
+	return downloadclient.Torrent{
+		Hash:     hash,
+		Name:     hash,
+		Category: "audiobooks",
+		Tags:     []string{"imported"},
+		SavePath: "/remote/" + hash,
+		State:    downloadclient.StateStalledSeeding,
+		Progress: 1,
+		Ratio:    ratio,
+	}
+}
+
+func writeFile(t *testing.T, filePath string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	require.NoError(t, os.WriteFile(filePath, []byte("audio"), 0644))
+}
+
+// setupCleanupTest creates a download directory with one file per torrent and
+// imports "linked" by hardlink and "copied" by copy.
+func setupCleanupTest(t *testing.T) (*testutil.MockDownloadClient, config.ImportersConfig, *gorm.DB) {
+	t.Helper()
+
+	ctx := context.Background()
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	downloads := t.TempDir()
+	libraryDir := t.TempDir()
+
+	config.Config.Qbit = config.QbitConfig{
+		DownloadPath:      "/remote",
+		LocalDownloadPath: downloads,
+	}
+
+	torrents := []downloadclient.Torrent{
+		importedTorrent("linked", 3),
+		importedTorrent("copied", 3),
+		importedTorrent("seeding", 0.5),
+	}
+
+	private := importedTorrent("private", 5)
+	private.Private = true
+	private.SeedingTime = 24 * 60 * 60
+	torrents = append(torrents, private)
+
+	for _, torrent := range torrents {
+		writeFile(t, filepath.Join(downloads, torrent.Hash, "book.m4b"))
+	}
+
+	linkedDir := filepath.Join(libraryDir, "Linked Book")
+	require.NoError(t, os.MkdirAll(linkedDir, 0755))
+	require.NoError(t, os.Link(filepath.Join(downloads, "linked", "book.m4b"), filepath.Join(linkedDir, "book.m4b")))
+	writeFile(t, filepath.Join(linkedDir, "metadata.opf"))
+	common.RecordImportedItem(ctx, db, "linked", linkedDir, "Linked Book", "", nil)
+
+	copiedDir := filepath.Join(libraryDir, "Copied Book")
+	writeFile(t, filepath.Join(copiedDir, "book.m4b"))
+	common.RecordImportedItem(ctx, db, "copied", copiedDir, "Copied Book", "", nil)
+
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesFunc: func(ctx context.Context, hash string) ([]downloadclient.TorrentFile, error) {
+			return []downloadclient.TorrentFile{{Name: "book.m4b"}}, nil
+		},
+	}
+	mockClient.GetTorrentsReturn.Torrents = torrents
+
+	var bookType models.BookType
+	require.NoError(t, db.Where("name = ?", "audiobook").First(&bookType).Error)
+
+	library := models.Library{Name: "audiobooks", Path: libraryDir, BookTypeID: bookType.ID}
+	require.NoError(t, db.Create(&library).Error)
+
+	var category models.TorrentCategory
+	require.NoError(t, db.Where("name = ?", "audiobooks").First(&category).Error)
+
+	require.NoError(t, db.Create(&models.ImportType{
+		TorrentCategoryID:  category.ID,
+		LibraryID:          library.ID,
+		RemoveAfterRatio:   2,
+		PrivateMinSeedDays: 7,
+		RequireHardlink:    true,
+		DeleteFiles:        true,
+	}).Error)
+
+	importersConfig := config.ImportersConfig{ImportedTag: "imported"}
+
+	return mockClient, importersConfig, db
+}
+
+func TestCleanupSystem_Run(t *testing.T) {
+	ctx := context.Background()
+	mockClient, importersConfig, db := setupCleanupTest(t)
+
+	removals, err := NewCleanupSystem(mockClient, importersConfig, db).Run(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, removals, 1)
+	assert.Equal(t, "linked", removals[0].Torrent.Hash)
+	assert.Equal(t, "ratio", removals[0].Reason)
+
+	require.Len(t, mockClient.RemoveTorrentsCalls, 1)
+	assert.Equal(t, []string{"linked"}, mockClient.RemoveTorrentsCalls[0].Hashes)
+	assert.True(t, mockClient.RemoveTorrentsCalls[0].DeleteFiles)
+
+	var events []models.EventLog
+	require.NoError(t, db.Where("event_type = ?", eventlog.EventTorrentRemoved).Find(&events).Error)
+	require.Len(t, events, 1)
+	assert.Equal(t, "linked", events[0].EntityID)
+	assert.Equal(t, eventlog.SourceTorrentCleanup, events[0].Source)
+}
+
+func TestCleanupSystem_RewrittenFiles(t *testing.T) {
+	ctx := context.Background()
+	mockClient, importersConfig, db := setupCleanupTest(t)
+
+	// The copied import's file was replaced by tagging after it was linked
+	var item models.ImportedItem
+	require.NoError(t, db.Where("torrent_hash = ?", "copied").First(&item).Error)
+	common.SetImportedItemRewrittenFiles(ctx, db, item.Path, []string{"book.m4b"})
+
+	removals, err := NewCleanupSystem(mockClient, importersConfig, db).Run(ctx)
+	require.NoError(t, err)
+
+	removed := make([]string, 0, len(removals))
+	for _, removal := range removals {
+		removed = append(removed, removal.Torrent.Hash)
+	}
+	assert.ElementsMatch(t, []string{"linked", "copied"}, removed)
+}
+
+func TestCleanupSystem_WithoutHardlinkRequirement(t *testing.T) {
+	ctx := context.Background()
+	mockClient, importersConfig, db := setupCleanupTest(t)
+	require.NoError(t, db.Model(&models.ImportType{}).Where("1 = 1").Update("require_hardlink", false).Error)
+
+	removals, err := NewCleanupSystem(mockClient, importersConfig, db).Run(ctx)
+	require.NoError(t, err)
+
+	removed := make([]string, 0, len(removals))
+	for _, removal := range removals {
+		removed = append(removed, removal.Torrent.Hash)
+	}
+	assert.ElementsMatch(t, []string{"linked", "copied"}, removed)
+}
+
+func TestCleanupSystem_DryRun(t *testing.T) {
+	ctx := context.Background()
+	mockClient, importersConfig, db := setupCleanupTest(t)
+
+	cleanup := NewCleanupSystem(mockClient, importersConfig, db)
+	cleanup.DryRun = true
+
+	removals, err := cleanup.Run(ctx)
+	require.NoError(t, err)
+	assert.Len(t, removals, 1)
+	assert.Empty(t, mockClient.RemoveTorrentsCalls)
+
+	var count int64
+	require.NoError(t, db.Model(&models.EventLog{}).Where("event_type = ?", eventlog.EventTorrentRemoved).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestCleanupSystem_DeleteFailure(t *testing.T) {
+	ctx := context.Background()
+	mockClient, importersConfig, db := setupCleanupTest(t)
+	mockClient.RemoveTorrentsReturn = errors.New("connection refused")
+
+	removals, err := NewCleanupSystem(mockClient, importersConfig, db).Run(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, removals)
+	assert.Len(t, mockClient.RemoveTorrentsCalls, 1)
+
+	var count int64
+	require.NoError(t, db.Model(&models.EventLog{}).Where("event_type = ?", eventlog.EventTorrentRemoved).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestCleanupSystem_NoPolicy(t *testing.T) {
+	ctx := context.Background()
+	mockClient, importersConfig, db := setupCleanupTest(t)
+	require.NoError(t, db.Model(&models.ImportType{}).Where("1 = 1").Updates(map[string]any{
+		"remove_after_ratio":    0,
+		"private_min_seed_days": 0,
+	}).Error)
+
+	removals, err := NewCleanupSystem(mockClient, importersConfig, db).Run(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, removals)
+	assert.Empty(t, mockClient.GetTorrentsCalls)
+}
@@ -7,10 +7,8 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/autobrr/go-qbittorrent"
-
 	"github.com/bobbyrward/stronghold/internal/config"
-	"github.com/bobbyrward/stronghold/internal/qbit"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 )
 
 type MappedTorrentFile struct {
@@ -18,7 +16,7 @@ type MappedTorrentFile struct {
 	LocalPath string
 }
 
-func MapTorrentContentPathToLocalPath(torrent qbittorrent.Torrent, remoteDownloadPath string, localDownloadPath string) string {
+func MapTorrentContentPathToLocalPath(torrent downloadclient.Torrent, remoteDownloadPath string, localDownloadPath string) string {
 	return filepath.Join(
 		localDownloadPath,
 		strings.TrimPrefix(
@@ -38,22 +36,19 @@ func MapTorrentSavePathToLocalPath(savePath string, remoteDownloadPath string, l
 	)
 }
 
-func MapTorrentFilesToLocalPaths(ctx context.Context, qbit qbit.QbitClient, torrent qbittorrent.Torrent) ([]MappedTorrentFile, error) {
-	localSavePath := MapTorrentSavePathToLocalPath(
-		torrent.SavePath,
-		config.Config.Qbit.DownloadPath,
-		config.Config.Qbit.LocalDownloadPath,
-	)
+func MapTorrentFilesToLocalPaths(ctx context.Context, client downloadclient.DownloadClient, torrent downloadclient.Torrent) ([]MappedTorrentFile, error) {
+	remoteDownloadPath, localDownloadPath := config.Config.DownloadPaths()
+	localSavePath := MapTorrentSavePathToLocalPath(torrent.SavePath, remoteDownloadPath, localDownloadPath)
 
-	torrentFiles, err := qbit.GetFilesInformationCtx(ctx, torrent.Hash)
+	torrentFiles, err := client.GetFiles(ctx, torrent.Hash)
 	if err != nil {
 		slog.InfoContext(ctx, "failed to get torrent files", slog.String("name", torrent.Name), slog.Any("err", err))
 		return nil, fmt.Errorf("failed to get torrent files: %w", err)
 	}
 
-	files := make([]MappedTorrentFile, 0, len(*torrentFiles))
+	files := make([]MappedTorrentFile, 0, len(torrentFiles))
 
-	for _, torrentFile := range *torrentFiles {
+	for _, torrentFile := range torrentFiles {
 		files = append(files, MappedTorrentFile{
 			BaseName:  torrentFile.Name,
 			LocalPath: filepath.Join(localSavePath, torrentFile.Name),
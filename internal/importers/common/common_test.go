@@ -5,10 +5,10 @@ import (
 	"errors"
 	"testing"
 
-	"github.com/autobrr/go-qbittorrent"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/testutil"
 )
 
@@ -79,8 +79,8 @@ func TestMapTorrentSavePathToLocalPath(t *testing.T) {
 func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 	tests := []struct {
 		name          string
-		torrent       qbittorrent.Torrent
-		mockFiles     *qbittorrent.TorrentFiles
+		torrent       downloadclient.Torrent
+		mockFiles     []downloadclient.TorrentFile
 		mockErr       error
 		qbitConfig    config.QbitConfig
 		expectedFiles []MappedTorrentFile
@@ -88,12 +88,12 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 	}{
 		{
 			name: "Single file torrent",
-			torrent: qbittorrent.Torrent{
+			torrent: downloadclient.Torrent{
 				Hash:     "abc123",
 				Name:     "Test Book",
 				SavePath: "/data/books",
 			},
-			mockFiles: &qbittorrent.TorrentFiles{
+			mockFiles: []downloadclient.TorrentFile{
 				{Name: "Book.epub"},
 			},
 			mockErr: nil,
@@ -111,12 +111,12 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 		},
 		{
 			name: "Multiple files in torrent",
-			torrent: qbittorrent.Torrent{
+			torrent: downloadclient.Torrent{
 				Hash:     "def456",
 				Name:     "Audio Series",
 				SavePath: "/remote/audiobooks/series",
 			},
-			mockFiles: &qbittorrent.TorrentFiles{
+			mockFiles: []downloadclient.TorrentFile{
 				{Name: "book1.m4b"},
 				{Name: "book2.m4b"},
 				{Name: "book3.m4b"},
@@ -144,12 +144,12 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 		},
 		{
 			name: "Files with subdirectories in torrent",
-			torrent: qbittorrent.Torrent{
+			torrent: downloadclient.Torrent{
 				Hash:     "ghi789",
 				Name:     "Collection",
 				SavePath: "/data/downloads/collection",
 			},
-			mockFiles: &qbittorrent.TorrentFiles{
+			mockFiles: []downloadclient.TorrentFile{
 				{Name: "subfolder/file1.m4b"},
 				{Name: "subfolder/file2.mp3"},
 				{Name: "other/file3.m4b"},
@@ -177,12 +177,12 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 		},
 		{
 			name: "Torrent saved at remote base path",
-			torrent: qbittorrent.Torrent{
+			torrent: downloadclient.Torrent{
 				Hash:     "jkl012",
 				Name:     "Root Book",
 				SavePath: "/downloads",
 			},
-			mockFiles: &qbittorrent.TorrentFiles{
+			mockFiles: []downloadclient.TorrentFile{
 				{Name: "root-book.epub"},
 			},
 			mockErr: nil,
@@ -200,12 +200,12 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 		},
 		{
 			name: "Empty torrent (no files)",
-			torrent: qbittorrent.Torrent{
+			torrent: downloadclient.Torrent{
 				Hash:     "empty123",
 				Name:     "Empty Torrent",
 				SavePath: "/data/empty",
 			},
-			mockFiles: &qbittorrent.TorrentFiles{},
+			mockFiles: []downloadclient.TorrentFile{},
 			mockErr:   nil,
 			qbitConfig: config.QbitConfig{
 				DownloadPath:      "/data",
@@ -216,7 +216,7 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 		},
 		{
 			name: "Error getting files information",
-			torrent: qbittorrent.Torrent{
+			torrent: downloadclient.Torrent{
 				Hash:     "error123",
 				Name:     "Failed Torrent",
 				SavePath: "/data/failed",
@@ -232,12 +232,12 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 		},
 		{
 			name: "Deeply nested torrent path",
-			torrent: qbittorrent.Torrent{
+			torrent: downloadclient.Torrent{
 				Hash:     "nested456",
 				Name:     "Nested Book",
 				SavePath: "/mnt/storage/torrents/2024/fiction/mystery",
 			},
-			mockFiles: &qbittorrent.TorrentFiles{
+			mockFiles: []downloadclient.TorrentFile{
 				{Name: "book.m4b"},
 			},
 			mockErr: nil,
@@ -261,9 +261,9 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 			config.Config.Qbit = tt.qbitConfig
 
 			// Create mock client
-			mockClient := &testutil.MockQbitClient{
-				GetFilesInformationCtxReturn: struct {
-					Files *qbittorrent.TorrentFiles
+			mockClient := &testutil.MockDownloadClient{
+				GetFilesReturn: struct {
+					Files []downloadclient.TorrentFile
 					Err   error
 				}{
 					Files: tt.mockFiles,
@@ -286,15 +286,15 @@ func TestMapTorrentFilesToLocalPaths(t *testing.T) {
 			}
 
 			// Verify mock was called correctly
-			assert.Len(t, mockClient.GetFilesInformationCtxCalls, 1)
-			assert.Equal(t, tt.torrent.Hash, mockClient.GetFilesInformationCtxCalls[0])
+			assert.Len(t, mockClient.GetFilesCalls, 1)
+			assert.Equal(t, tt.torrent.Hash, mockClient.GetFilesCalls[0])
 		})
 	}
 }
 
 func TestMapTorrentFilesToLocalPaths_WithCustomMockFunction(t *testing.T) {
 	// This test demonstrates using a custom mock function for more complex scenarios
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash:     "custom123",
 		Name:     "Custom Test",
 		SavePath: "/remote/books/custom",
@@ -305,11 +305,11 @@ func TestMapTorrentFilesToLocalPaths_WithCustomMockFunction(t *testing.T) {
 		LocalDownloadPath: "/local",
 	}
 
-	mockClient := &testutil.MockQbitClient{}
-	mockClient.GetFilesInformationCtxFunc = func(ctx context.Context, hash string) (*qbittorrent.TorrentFiles, error) {
+	mockClient := &testutil.MockDownloadClient{}
+	mockClient.GetFilesFunc = func(ctx context.Context, hash string) ([]downloadclient.TorrentFile, error) {
 		// Custom logic - could do different things based on hash
 		if hash == "custom123" {
-			return &qbittorrent.TorrentFiles{
+			return []downloadclient.TorrentFile{
 				{Name: "custom-file.m4b"},
 			}, nil
 		}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+
+	"github.com/cappuccinotm/slogx"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// RecordImportedItem records a book directory or file placed in a library so
+// library verification can find it later. Importing to the same path again
+// replaces the previous record. bookMetadata is optional and stored as JSON.
+func RecordImportedItem(ctx context.Context, db *gorm.DB, torrentHash string, path string, title string, identifier string, bookMetadata any) {
+	if db == nil {
+		return
+	}
+
+	item := models.ImportedItem{
+		TorrentHash: torrentHash,
+		Title:       title,
+		Identifier:  identifier,
+	}
+
+	if bookMetadata != nil {
+		encoded, err := json.Marshal(bookMetadata)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to marshal imported item metadata", slog.String("path", path), slogx.Error(err))
+		} else {
+			metadata := string(encoded)
+			item.Metadata = &metadata
+		}
+	}
+
+	err := db.Where(models.ImportedItem{Path: filepath.Clean(path)}).
+		Assign(item).
+		FirstOrCreate(&models.ImportedItem{}).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record imported item", slog.String("path", path), slogx.Error(err))
+	}
+}
+
+// ImportedItemCalibreBookID returns the Calibre book ID recorded for the item
+// at path. ok is false when the item was not added to Calibre.
+func ImportedItemCalibreBookID(ctx context.Context, db *gorm.DB, path string) (int, bool) {
+	if db == nil {
+		return 0, false
+	}
+
+	var item models.ImportedItem
+	err := db.WithContext(ctx).Where("path = ? AND calibre_book_id IS NOT NULL", filepath.Clean(path)).First(&item).Error
+	if err != nil {
+		return 0, false
+	}
+
+	return *item.CalibreBookID, true
+}
+
+// SetImportedItemCalibreBookID records the Calibre book ID of the item at path.
+func SetImportedItemCalibreBookID(ctx context.Context, db *gorm.DB, path string, bookID int) {
+	if db == nil {
+		return
+	}
+
+	err := db.WithContext(ctx).Model(&models.ImportedItem{}).
+		Where("path = ?", filepath.Clean(path)).
+		Update("calibre_book_id", bookID).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record Calibre book id", slog.String("path", path), slogx.Error(err))
+	}
+}
+
+// SetImportedItemAudiobookshelfID records the Audiobookshelf item of the item at path.
+func SetImportedItemAudiobookshelfID(ctx context.Context, db *gorm.DB, path string, itemID string) {
+	if db == nil {
+		return
+	}
+
+	err := db.WithContext(ctx).Model(&models.ImportedItem{}).
+		Where("path = ?", filepath.Clean(path)).
+		Update("audiobookshelf_item_id", itemID).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record Audiobookshelf item id", slog.String("path", path), slogx.Error(err))
+	}
+}
+
+// SetImportedItemRewrittenFiles records the library files of the item at path
+// that the importer replaced after linking them, so torrent cleanup does not
+// mistake them for copies made after a failed hardlink.
+func SetImportedItemRewrittenFiles(ctx context.Context, db *gorm.DB, path string, files []string) {
+	if db == nil || len(files) == 0 {
+		return
+	}
+
+	encoded, err := json.Marshal(files)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal rewritten files", slog.String("path", path), slogx.Error(err))
+		return
+	}
+
+	err = db.WithContext(ctx).Model(&models.ImportedItem{}).
+		Where("path = ?", filepath.Clean(path)).
+		Update("rewritten_files", string(encoded)).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record rewritten files", slog.String("path", path), slogx.Error(err))
+	}
+}
+
+// ImportedItemRewrittenFiles returns the base names recorded by
+// SetImportedItemRewrittenFiles for item.
+func ImportedItemRewrittenFiles(item models.ImportedItem) ([]string, error) {
+	if item.RewrittenFiles == nil {
+		return nil, nil
+	}
+
+	var files []string
+	if err := json.Unmarshal([]byte(*item.RewrittenFiles), &files); err != nil {
+		return nil, fmt.Errorf("failed to decode rewritten files: %w", err)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,235 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// Import job statuses
+const (
+	ImportJobPending   = "pending"
+	ImportJobRetrying  = "retrying"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+)
+
+// ErrorClass says whether retrying a failed import might succeed.
+type ErrorClass string
+
+const (
+	ErrorClassTransient ErrorClass = "transient"
+	ErrorClassPermanent ErrorClass = "permanent"
+)
+
+const (
+	defaultMaxAttempts  = 5
+	defaultRetryBackoff = 5 * time.Minute
+	maxRetryBackoff     = 6 * time.Hour
+)
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as a failure that retrying will not fix, such as missing
+// tags or an ambiguous metadata match.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// ClassifyError returns the error class of err. Errors marked with Permanent are
+// permanent. Everything else (network, filesystem and upstream API failures) is
+// treated as transient and retried until the attempt limit is reached.
+func ClassifyError(err error) ErrorClass {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return ErrorClassPermanent
+	}
+
+	return ErrorClassTransient
+}
+
+// RetryPolicy controls how often and how quickly failed imports are retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// NewRetryPolicy creates a retry policy from the importer config, using defaults for unset values.
+func NewRetryPolicy(cfg config.ImportersConfig) RetryPolicy {
+	policy := RetryPolicy{
+		MaxAttempts: cfg.MaxAttempts,
+		Backoff:     time.Duration(cfg.RetryBackoffMinutes) * time.Minute,
+	}
+
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultMaxAttempts
+	}
+
+	if policy.Backoff <= 0 {
+		policy.Backoff = defaultRetryBackoff
+	}
+
+	return policy
+}
+
+// Delay returns how long to wait after the given number of failed attempts.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.Backoff
+
+	for i := 1; i < attempts && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryBackoff)
+}
+
+// StartImportJob loads or creates the import job for a torrent and counts a new attempt.
+// It returns ready=false when the job is still waiting for its next retry. A job that
+// already completed or failed is started over, since the torrent is only offered again
+// once its tags were cleared. Without a database no job is tracked and ready is true.
+func StartImportJob(ctx context.Context, db *gorm.DB, torrent downloadclient.Torrent) (*models.ImportJob, bool, error) {
+	if db == nil {
+		return nil, true, nil
+	}
+
+	var job models.ImportJob
+
+	err := db.Where(models.ImportJob{TorrentHash: torrent.Hash}).
+		Attrs(models.ImportJob{TorrentName: torrent.Name, Category: torrent.Category, Status: ImportJobPending}).
+		FirstOrCreate(&job).Error
+	if err != nil {
+		return nil, true, err
+	}
+
+	if job.NextAttemptAt != nil && time.Now().Before(*job.NextAttemptAt) {
+		return &job, false, nil
+	}
+
+	if job.Status == ImportJobCompleted || job.Status == ImportJobFailed {
+		job.Attempts = 0
+		job.LastError = ""
+		job.ErrorClass = ""
+		job.CompletedAt = nil
+	}
+
+	job.Attempts++
+	job.Status = ImportJobPending
+	job.NextAttemptAt = nil
+
+	err = db.Save(&job).Error
+	if err != nil {
+		return nil, true, err
+	}
+
+	slog.InfoContext(ctx, "Started import attempt",
+		slog.String("hash", job.TorrentHash),
+		slog.Int("attempt", job.Attempts))
+
+	return &job, true, nil
+}
+
+// FailImportJob records a failed attempt and schedules a retry when the error is
+// transient and attempts remain. It returns true when the torrent should be sent
+// to manual intervention instead. Without a job it always returns true.
+func FailImportJob(ctx context.Context, db *gorm.DB, job *models.ImportJob, importErr error, policy RetryPolicy) bool {
+	if db == nil || job == nil {
+		return true
+	}
+
+	errorClass := ClassifyError(importErr)
+
+	job.LastError = importErr.Error()
+	job.ErrorClass = string(errorClass)
+
+	giveUp := errorClass == ErrorClassPermanent || job.Attempts >= policy.MaxAttempts
+
+	if giveUp {
+		job.Status = ImportJobFailed
+		job.NextAttemptAt = nil
+	} else {
+		next := time.Now().Add(policy.Delay(job.Attempts))
+		job.Status = ImportJobRetrying
+		job.NextAttemptAt = &next
+	}
+
+	err := db.Save(job).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save import job", slog.String("hash", job.TorrentHash), slogx.Error(err))
+	}
+
+	return giveUp
+}
+
+// SetImportJobMetadata records the metadata chosen for the import.
+func SetImportJobMetadata(ctx context.Context, db *gorm.DB, job *models.ImportJob, chosen any) {
+	if db == nil || job == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(chosen)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to marshal import job metadata", slog.String("hash", job.TorrentHash), slogx.Error(err))
+		return
+	}
+
+	metadata := string(encoded)
+	job.Metadata = &metadata
+
+	err = db.Model(job).Update("metadata", job.Metadata).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save import job metadata", slog.String("hash", job.TorrentHash), slogx.Error(err))
+	}
+}
+
+// CompleteImportJob marks the import job as completed.
+func CompleteImportJob(ctx context.Context, db *gorm.DB, job *models.ImportJob) {
+	if db == nil || job == nil {
+		return
+	}
+
+	now := time.Now()
+	job.Status = ImportJobCompleted
+	job.CompletedAt = &now
+	job.NextAttemptAt = nil
+	job.LastError = ""
+	job.ErrorClass = ""
+
+	err := db.Save(job).Error
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save import job", slog.String("hash", job.TorrentHash), slogx.Error(err))
+	}
+
+	clearMatchCandidates(ctx, db, job.TorrentHash)
+}
+
+// clearMatchCandidates deletes the audiobook match candidates saved for a
+// torrent. They are only read while the match is unresolved.
+func clearMatchCandidates(ctx context.Context, db *gorm.DB, torrentHash string) {
+	err := db.WithContext(ctx).Where("torrent_hash = ?", torrentHash).Delete(&models.AudiobookMatchCandidate{}).Error
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to clear match candidates", slog.String("hash", torrentHash), slogx.Error(err))
+	}
+}
@@ -0,0 +1,129 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func TestClassifyError(t *testing.T) {
+	transient := errors.New("connection refused")
+	permanent := Permanent(errors.New("title tag not found"))
+
+	assert.Equal(t, ErrorClassTransient, ClassifyError(transient))
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(permanent))
+	assert.Equal(t, ErrorClassPermanent, ClassifyError(fmt.Errorf("lookup failed: %w", permanent)))
+	assert.Equal(t, "title tag not found", permanent.Error())
+	assert.Nil(t, Permanent(nil))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	policy := NewRetryPolicy(config.ImportersConfig{})
+	assert.Equal(t, defaultMaxAttempts, policy.MaxAttempts)
+	assert.Equal(t, defaultRetryBackoff, policy.Backoff)
+
+	policy = NewRetryPolicy(config.ImportersConfig{MaxAttempts: 3, RetryBackoffMinutes: 10})
+	assert.Equal(t, 3, policy.MaxAttempts)
+	assert.Equal(t, 10*time.Minute, policy.Delay(1))
+	assert.Equal(t, 20*time.Minute, policy.Delay(2))
+	assert.Equal(t, 40*time.Minute, policy.Delay(3))
+	assert.Equal(t, maxRetryBackoff, policy.Delay(20))
+}
+
+func TestImportJobLifecycle(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	torrent := downloadclient.Torrent{Hash: "abc123", Name: "Test Book", Category: "books"}
+	policy := RetryPolicy{MaxAttempts: 2, Backoff: time.Minute}
+
+	// First attempt fails transiently and is scheduled for retry
+	job, ready, err := StartImportJob(ctx, db, torrent)
+	require.NoError(t, err)
+	require.True(t, ready)
+	assert.Equal(t, 1, job.Attempts)
+
+	giveUp := FailImportJob(ctx, db, job, errors.New("stale NFS file handle"), policy)
+	assert.False(t, giveUp)
+	assert.Equal(t, ImportJobRetrying, job.Status)
+	assert.Equal(t, string(ErrorClassTransient), job.ErrorClass)
+	require.NotNil(t, job.NextAttemptAt)
+
+	// Not ready again until the backoff has passed
+	_, ready, err = StartImportJob(ctx, db, torrent)
+	require.NoError(t, err)
+	assert.False(t, ready)
+
+	require.NoError(t, db.Model(&models.ImportJob{}).Where("torrent_hash = ?", torrent.Hash).
+		Update("next_attempt_at", time.Now().Add(-time.Second)).Error)
+
+	// Second attempt fails transiently and runs out of attempts
+	job, ready, err = StartImportJob(ctx, db, torrent)
+	require.NoError(t, err)
+	require.True(t, ready)
+	assert.Equal(t, 2, job.Attempts)
+
+	giveUp = FailImportJob(ctx, db, job, errors.New("stale NFS file handle"), policy)
+	assert.True(t, giveUp)
+	assert.Equal(t, ImportJobFailed, job.Status)
+	assert.Equal(t, "stale NFS file handle", job.LastError)
+
+	// Clearing the manual tag offers the torrent again, which starts over
+	job, ready, err = StartImportJob(ctx, db, torrent)
+	require.NoError(t, err)
+	require.True(t, ready)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Empty(t, job.LastError)
+
+	require.NoError(t, db.Create(&models.AudiobookMatchCandidate{TorrentHash: torrent.Hash, Asin: "B002V1OF70", Rank: 1}).Error)
+
+	SetImportJobMetadata(ctx, db, job, map[string]string{"title": "Test Book"})
+	CompleteImportJob(ctx, db, job)
+
+	var candidates int64
+	require.NoError(t, db.Model(&models.AudiobookMatchCandidate{}).Count(&candidates).Error)
+	assert.Zero(t, candidates)
+
+	var stored models.ImportJob
+	require.NoError(t, db.Where("torrent_hash = ?", torrent.Hash).First(&stored).Error)
+	assert.Equal(t, ImportJobCompleted, stored.Status)
+	assert.NotNil(t, stored.CompletedAt)
+	require.NotNil(t, stored.Metadata)
+	assert.JSONEq(t, `{"title": "Test Book"}`, *stored.Metadata)
+}
+
+func TestFailImportJob_Permanent(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	job, _, err := StartImportJob(ctx, db, downloadclient.Torrent{Hash: "perm", Name: "Test Book"})
+	require.NoError(t, err)
+
+	giveUp := FailImportJob(ctx, db, job, Permanent(errors.New("no ebook files")), RetryPolicy{MaxAttempts: 5, Backoff: time.Minute})
+	assert.True(t, giveUp)
+	assert.Equal(t, string(ErrorClassPermanent), job.ErrorClass)
+	assert.Nil(t, job.NextAttemptAt)
+}
+
+func TestImportJob_WithoutDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	job, ready, err := StartImportJob(ctx, nil, downloadclient.Torrent{Hash: "nodb"})
+	require.NoError(t, err)
+	assert.True(t, ready)
+	assert.Nil(t, job)
+
+	assert.True(t, FailImportJob(ctx, nil, job, errors.New("boom"), RetryPolicy{MaxAttempts: 5}))
+}
@@ -0,0 +1,254 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func preloadImportType(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("TorrentCategory.Scope").
+		Preload("Library.BookType").
+		Preload("Notifier")
+}
+
+// LoadImportTypes returns the import types for categories of a media type, or
+// every import type when mediaType is empty.
+func LoadImportTypes(ctx context.Context, db *gorm.DB, mediaType string) ([]models.ImportType, error) {
+	query := preloadImportType(db.WithContext(ctx)).Order("id")
+
+	if mediaType != "" {
+		categories := db.Model(&models.TorrentCategory{}).Select("id").Where("media_type = ?", mediaType)
+		query = query.Where("torrent_category_id IN (?)", categories)
+	}
+
+	var importTypes []models.ImportType
+	if err := query.Find(&importTypes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load import types: %w", err)
+	}
+
+	return importTypes, nil
+}
+
+// FindImportType returns the import type for a category. ok is false when the
+// category has none.
+func FindImportType(ctx context.Context, db *gorm.DB, category string) (models.ImportType, bool, error) {
+	var importType models.ImportType
+
+	categories := db.Model(&models.TorrentCategory{}).Select("id").Where("name = ?", category)
+
+	err := preloadImportType(db.WithContext(ctx)).
+		Where("torrent_category_id IN (?)", categories).
+		First(&importType).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return importType, false, nil
+	}
+	if err != nil {
+		return importType, false, fmt.Errorf("failed to load import type for category %s: %w", category, err)
+	}
+
+	return importType, true, nil
+}
+
+// ImportTypeConfig adapts a database import type, with its relations loaded, to
+// the settings the importers take.
+func ImportTypeConfig(importType models.ImportType) config.ImportType {
+	cfg := config.ImportType{
+		Category:           importType.TorrentCategory.Name,
+		Library:            importType.Library.Name,
+		CalibreDesktopURL:  importType.CalibreDesktopURL,
+		CalibreWebURL:      importType.CalibreWebURL,
+		CalibreLibrary:     importType.CalibreLibrary,
+		MetadataProviders:  SplitMetadataProviders(importType.MetadataProviders),
+		MinSeedTimeMinutes: importType.MinSeedTimeMinutes,
+		MinSeedRatio:       importType.MinSeedRatio,
+		Retention: config.Retention{
+			RemoveAfterRatio:    importType.RemoveAfterRatio,
+			RemoveAfterSeedDays: importType.RemoveAfterSeedDays,
+			PrivateMinSeedDays:  importType.PrivateMinSeedDays,
+			RequireHardlink:     importType.RequireHardlink,
+			DeleteFiles:         importType.DeleteFiles,
+		},
+	}
+
+	if importType.Notifier != nil {
+		cfg.DiscordNotifier = importType.Notifier.Name
+	}
+
+	return cfg
+}
+
+// SplitMetadataProviders parses the comma-separated provider chain stored on
+// an import type.
+func SplitMetadataProviders(value string) []string {
+	var names []string
+
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// SplitFormats parses a comma-separated format list into lower-case
+// extensions without the leading dot.
+func SplitFormats(value string) []string {
+	var formats []string
+
+	for _, format := range strings.Split(value, ",") {
+		format = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(format)), ".")
+		if format != "" {
+			formats = append(formats, format)
+		}
+	}
+
+	return formats
+}
+
+// ConfigMigration counts what MigrateImportConfig changed.
+type ConfigMigration struct {
+	LibrariesCreated   int
+	ImportTypesCreated int
+	Warnings           []string // import types skipped or migrated without their notifier, with the reason
+}
+
+// MigrateImportConfig copies the libraries and import types from the YAML
+// importer config into the database. Libraries and import types that already
+// exist are left alone, so it is safe to run more than once.
+func MigrateImportConfig(ctx context.Context, db *gorm.DB, cfg config.ImportersConfig) (ConfigMigration, error) {
+	var result ConfigMigration
+
+	sections := []struct {
+		mediaType   string
+		libraries   []config.ImportLibrary
+		importTypes []config.ImportType
+	}{
+		{MediaTypeEbook, cfg.BookImporter.Libraries, cfg.BookImporter.ImportTypes},
+		{MediaTypeAudiobook, cfg.AudiobookImporter.Libraries, cfg.AudiobookImporter.ImportTypes},
+	}
+
+	for _, section := range sections {
+		var bookType models.BookType
+		if err := db.WithContext(ctx).Where("name = ?", section.mediaType).First(&bookType).Error; err != nil {
+			return result, fmt.Errorf("failed to find book type %s: %w", section.mediaType, err)
+		}
+
+		for _, library := range section.libraries {
+			row := models.Library{
+				Name:                    library.Name,
+				Path:                    library.Path,
+				BookTypeID:              bookType.ID,
+				PreferredFormats:        strings.Join(library.PreferredFormats, ","),
+				AudiobookshelfLibraryID: library.AudiobookshelfLibraryID,
+				AudiobookshelfPath:      library.AudiobookshelfPath,
+				WriteAudioTags:          library.WriteAudioTags,
+			}
+
+			res := db.WithContext(ctx).Where(models.Library{Name: library.Name}).Attrs(row).FirstOrCreate(&row)
+			if res.Error != nil {
+				return result, fmt.Errorf("failed to migrate library %s: %w", library.Name, res.Error)
+			}
+
+			result.LibrariesCreated += int(res.RowsAffected)
+		}
+
+		for _, importType := range section.importTypes {
+			created, warning, err := migrateImportType(ctx, db, section.mediaType, importType)
+			if err != nil {
+				return result, err
+			}
+
+			if warning != "" {
+				result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s", importType.Category, warning))
+			}
+			if created {
+				result.ImportTypesCreated++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// migrateImportType creates the database import type for a YAML import type.
+// The warning says why it was skipped, or that its notifier was dropped
+// because no notifier by that name exists in the database.
+func migrateImportType(ctx context.Context, db *gorm.DB, mediaType string, importType config.ImportType) (bool, string, error) {
+	db = db.WithContext(ctx)
+
+	var category models.TorrentCategory
+	err := db.Where("name = ?", importType.Category).First(&category).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, "unknown torrent category", nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to find torrent category %s: %w", importType.Category, err)
+	}
+
+	if category.MediaType != mediaType {
+		return false, fmt.Sprintf("category holds %s, not %s", category.MediaType, mediaType), nil
+	}
+
+	var existing int64
+	if err := db.Model(&models.ImportType{}).Where("torrent_category_id = ?", category.ID).Count(&existing).Error; err != nil {
+		return false, "", fmt.Errorf("failed to check import type %s: %w", importType.Category, err)
+	}
+	if existing > 0 {
+		return false, "", nil
+	}
+
+	var library models.Library
+	err = db.Where("name = ?", importType.Library).First(&library).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Sprintf("unknown library %q", importType.Library), nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to find library %s: %w", importType.Library, err)
+	}
+
+	row := models.ImportType{
+		TorrentCategoryID:   category.ID,
+		LibraryID:           library.ID,
+		CalibreDesktopURL:   importType.CalibreDesktopURL,
+		CalibreWebURL:       importType.CalibreWebURL,
+		CalibreLibrary:      importType.CalibreLibrary,
+		MetadataProviders:   strings.Join(importType.MetadataProviders, ","),
+		MinSeedTimeMinutes:  importType.MinSeedTimeMinutes,
+		MinSeedRatio:        importType.MinSeedRatio,
+		RemoveAfterRatio:    importType.Retention.RemoveAfterRatio,
+		RemoveAfterSeedDays: importType.Retention.RemoveAfterSeedDays,
+		PrivateMinSeedDays:  importType.Retention.PrivateMinSeedDays,
+		RequireHardlink:     importType.Retention.RequireHardlink,
+		DeleteFiles:         importType.Retention.DeleteFiles,
+	}
+
+	warning := ""
+
+	if importType.DiscordNotifier != "" {
+		var notifier models.Notifier
+		err := db.Where("name = ?", importType.DiscordNotifier).First(&notifier).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			warning = fmt.Sprintf("unknown notifier %q, migrated without notifications", importType.DiscordNotifier)
+		case err != nil:
+			return false, "", fmt.Errorf("failed to find notifier %s: %w", importType.DiscordNotifier, err)
+		default:
+			row.NotifierID = &notifier.ID
+		}
+	}
+
+	if err := db.Create(&row).Error; err != nil {
+		return false, "", fmt.Errorf("failed to create import type %s: %w", importType.Category, err)
+	}
+
+	return true, warning, nil
+}
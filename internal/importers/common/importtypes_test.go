@@ -0,0 +1,119 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func TestLoadImportTypes(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	createImportType(t, db, "audiobooks", createLibrary(t, db, "family-audiobooks", "audiobook"), nil)
+	createImportType(t, db, "books", createLibrary(t, db, "family-books", "ebook"), nil)
+
+	all, err := LoadImportTypes(ctx, db, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	audiobooks, err := LoadImportTypes(ctx, db, MediaTypeAudiobook)
+	require.NoError(t, err)
+	require.Len(t, audiobooks, 1)
+	assert.Equal(t, "audiobooks", audiobooks[0].TorrentCategory.Name)
+	assert.Equal(t, "audiobook", audiobooks[0].Library.BookType.Name)
+
+	_, ok, err := FindImportType(ctx, db, "kids-books")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSplitMetadataProviders(t *testing.T) {
+	assert.Equal(t, []string{"audible", "openlibrary"}, SplitMetadataProviders(" audible, ,openlibrary "))
+	assert.Nil(t, SplitMetadataProviders(""))
+}
+
+func TestSplitFormats(t *testing.T) {
+	assert.Equal(t, []string{"epub", "azw3"}, SplitFormats(" EPUB, ,.azw3 "))
+	assert.Nil(t, SplitFormats(""))
+}
+
+func TestMigrateImportConfig(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	var discord models.NotificationType
+	require.NoError(t, db.Where("name = ?", "discord").First(&discord).Error)
+	require.NoError(t, db.Create(&models.Notifier{Name: "books-notifier", NotificationTypeID: discord.ID}).Error)
+
+	// Already in the database, so only its import type is created
+	createLibrary(t, db, "family-books", "ebook")
+
+	cfg := config.ImportersConfig{
+		BookImporter: config.BookImporter{
+			Libraries: []config.ImportLibrary{{Name: "family-books", Path: "/books"}},
+			ImportTypes: []config.ImportType{
+				{Category: "books", Library: "family-books", DiscordNotifier: "books-notifier", CalibreWebURL: "https://calibre.example.com"},
+				{Category: "kids-books", Library: "family-books", DiscordNotifier: "missing-notifier"},
+				{Category: "audiobooks", Library: "family-books"},
+				{Category: "no-such-category", Library: "family-books"},
+			},
+		},
+		AudiobookImporter: config.AudiobookImporter{
+			Libraries: []config.ImportLibrary{{Name: "audiobooks", Path: "/audiobooks"}},
+			ImportTypes: []config.ImportType{
+				{
+					Category:          "audiobooks",
+					Library:           "audiobooks",
+					MetadataProviders: []string{"audible", "googlebooks"},
+					MinSeedRatio:      1,
+					Retention:         config.Retention{RemoveAfterRatio: 2, RequireHardlink: true},
+				},
+				{Category: "family-audiobooks", Library: "missing-library"},
+			},
+		},
+	}
+
+	result, err := MigrateImportConfig(ctx, db, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.LibrariesCreated)
+	assert.Equal(t, 3, result.ImportTypesCreated)
+	assert.Len(t, result.Warnings, 4)
+
+	importType, ok, err := FindImportType(ctx, db, "books")
+	require.NoError(t, err)
+	require.True(t, ok)
+	migrated := ImportTypeConfig(importType)
+	assert.Equal(t, "family-books", migrated.Library)
+	assert.Equal(t, "books-notifier", migrated.DiscordNotifier)
+	assert.Equal(t, "https://calibre.example.com", migrated.CalibreWebURL)
+
+	importType, ok, err = FindImportType(ctx, db, "kids-books")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Nil(t, importType.NotifierID)
+
+	importType, ok, err = FindImportType(ctx, db, "audiobooks")
+	require.NoError(t, err)
+	require.True(t, ok)
+	migrated = ImportTypeConfig(importType)
+	assert.Equal(t, "audiobooks", migrated.Library)
+	assert.Equal(t, []string{"audible", "googlebooks"}, migrated.MetadataProviders)
+	assert.Equal(t, 1.0, migrated.MinSeedRatio)
+	assert.Equal(t, config.Retention{RemoveAfterRatio: 2, RequireHardlink: true}, migrated.Retention)
+
+	// Running again changes nothing
+	result, err = MigrateImportConfig(ctx, db, cfg)
+	require.NoError(t, err)
+	assert.Zero(t, result.LibrariesCreated)
+	assert.Zero(t, result.ImportTypesCreated)
+}
@@ -0,0 +1,164 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// Manual intervention statuses
+const (
+	InterventionOpen     = "open"
+	InterventionResolved = "resolved"
+	InterventionIgnored  = "ignored"
+)
+
+// Manual intervention resolutions
+const (
+	ResolutionRetry  = "retry"
+	ResolutionImport = "import"
+	ResolutionIgnore = "ignore"
+	ResolutionDelete = "delete"
+)
+
+// RecordManualIntervention opens the manual intervention for a torrent, copying
+// the error class, attempts and chosen metadata from its import job if it has one.
+func RecordManualIntervention(ctx context.Context, db *gorm.DB, torrent downloadclient.Torrent, source string, reason string) {
+	if db == nil {
+		return
+	}
+
+	intervention := models.ManualIntervention{
+		TorrentHash: torrent.Hash,
+		TorrentName: torrent.Name,
+		Category:    torrent.Category,
+		Source:      source,
+		Reason:      reason,
+		Status:      InterventionOpen,
+	}
+
+	var job models.ImportJob
+	err := db.WithContext(ctx).Where("torrent_hash = ?", torrent.Hash).First(&job).Error
+	switch {
+	case err == nil:
+		intervention.ErrorClass = job.ErrorClass
+		intervention.Attempts = job.Attempts
+		intervention.Metadata = job.Metadata
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		slog.WarnContext(ctx, "Failed to load import job for manual intervention", slog.String("hash", torrent.Hash), slogx.Error(err))
+	}
+
+	var existing models.ManualIntervention
+	err = db.WithContext(ctx).Where("torrent_hash = ?", torrent.Hash).First(&existing).Error
+	switch {
+	case err == nil:
+		intervention.ID = existing.ID
+		intervention.CreatedAt = existing.CreatedAt
+		err = db.WithContext(ctx).Save(&intervention).Error
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err = db.WithContext(ctx).Create(&intervention).Error
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to record manual intervention", slog.String("hash", torrent.Hash), slogx.Error(err))
+	}
+}
+
+// LoadManualInterventions returns the manual interventions for the given
+// torrents, keyed by hash.
+func LoadManualInterventions(ctx context.Context, db *gorm.DB, hashes []string) (map[string]models.ManualIntervention, error) {
+	interventions := make(map[string]models.ManualIntervention, len(hashes))
+	if len(hashes) == 0 {
+		return interventions, nil
+	}
+
+	var rows []models.ManualIntervention
+	if err := db.WithContext(ctx).Where("torrent_hash IN ?", hashes).Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load manual interventions: %w", err)
+	}
+
+	for _, row := range rows {
+		interventions[row.TorrentHash] = row
+	}
+
+	return interventions, nil
+}
+
+// CloseManualIntervention marks the manual intervention for a torrent resolved,
+// or ignored for ResolutionIgnore. Torrents tagged before interventions were
+// recorded get a row, so that ignoring them sticks.
+func CloseManualIntervention(ctx context.Context, db *gorm.DB, torrent downloadclient.Torrent, resolution string) error {
+	if db == nil {
+		return nil
+	}
+
+	status := InterventionResolved
+	if resolution == ResolutionIgnore {
+		status = InterventionIgnored
+	}
+
+	now := time.Now()
+
+	result := db.WithContext(ctx).Model(&models.ManualIntervention{}).
+		Where("torrent_hash = ?", torrent.Hash).
+		Updates(map[string]any{"status": status, "resolution": resolution, "resolved_at": &now})
+	if result.Error != nil {
+		return fmt.Errorf("failed to close manual intervention: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		err := db.WithContext(ctx).Create(&models.ManualIntervention{
+			TorrentHash: torrent.Hash,
+			TorrentName: torrent.Name,
+			Category:    torrent.Category,
+			Status:      status,
+			Resolution:  resolution,
+			ResolvedAt:  &now,
+		}).Error
+		if err != nil {
+			return fmt.Errorf("failed to close manual intervention: %w", err)
+		}
+	}
+
+	clearMatchCandidates(ctx, db, torrent.Hash)
+
+	return nil
+}
+
+// RetryManualIntervention re-queues a torrent by removing its manual
+// intervention tag. The importer picks it up on its next run and starts a
+// fresh set of attempts.
+func RetryManualIntervention(ctx context.Context, db *gorm.DB, downloadClient downloadclient.DownloadClient, torrent downloadclient.Torrent) error {
+	err := downloadClient.RemoveTags(ctx, []string{torrent.Hash}, config.Config.Importers.ManualInterventionTag)
+	if err != nil {
+		return fmt.Errorf("failed to remove manual intervention tag: %w", err)
+	}
+
+	return CloseManualIntervention(ctx, db, torrent, ResolutionRetry)
+}
+
+// IgnoreManualIntervention hides a torrent from the manual intervention list
+// for good. It keeps its tag, so the importers still skip it.
+func IgnoreManualIntervention(ctx context.Context, db *gorm.DB, torrent downloadclient.Torrent) error {
+	return CloseManualIntervention(ctx, db, torrent, ResolutionIgnore)
+}
+
+// DeleteManualInterventionTorrent removes a torrent, and optionally its files,
+// from the download client.
+func DeleteManualInterventionTorrent(ctx context.Context, db *gorm.DB, downloadClient downloadclient.DownloadClient, torrent downloadclient.Torrent, deleteFiles bool) error {
+	err := downloadClient.RemoveTorrents(ctx, []string{torrent.Hash}, deleteFiles)
+	if err != nil {
+		return fmt.Errorf("failed to delete torrent: %w", err)
+	}
+
+	return CloseManualIntervention(ctx, db, torrent, ResolutionDelete)
+}
@@ -0,0 +1,132 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/testutil"
+)
+
+func TestRecordManualIntervention(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	torrent := downloadclient.Torrent{Hash: "abc", Name: "Dune", Category: "audiobooks"}
+
+	// The failed import job supplies the error class, attempts and metadata
+	job, _, err := StartImportJob(ctx, db, torrent)
+	require.NoError(t, err)
+	SetImportJobMetadata(ctx, db, job, map[string]string{"asin": "B002V1OF70"})
+	FailImportJob(ctx, db, job, Permanent(errors.New("ambiguous match")), RetryPolicy{MaxAttempts: 5})
+
+	RecordManualIntervention(ctx, db, torrent, "audiobook-importer", "Ambiguous match")
+
+	interventions, err := LoadManualInterventions(ctx, db, []string{"abc", "unknown"})
+	require.NoError(t, err)
+	require.Len(t, interventions, 1)
+
+	intervention := interventions["abc"]
+	assert.Equal(t, "Dune", intervention.TorrentName)
+	assert.Equal(t, "audiobook-importer", intervention.Source)
+	assert.Equal(t, "Ambiguous match", intervention.Reason)
+	assert.Equal(t, string(ErrorClassPermanent), intervention.ErrorClass)
+	assert.Equal(t, 1, intervention.Attempts)
+	require.NotNil(t, intervention.Metadata)
+	assert.JSONEq(t, `{"asin": "B002V1OF70"}`, *intervention.Metadata)
+	assert.Equal(t, InterventionOpen, intervention.Status)
+
+	// Resolving and failing again reopens the same row
+	require.NoError(t, IgnoreManualIntervention(ctx, db, torrent))
+	RecordManualIntervention(ctx, db, torrent, "audiobook-importer", "Still ambiguous")
+
+	var rows []models.ManualIntervention
+	require.NoError(t, db.Find(&rows).Error)
+	require.Len(t, rows, 1)
+	assert.Equal(t, intervention.ID, rows[0].ID)
+	assert.Equal(t, "Still ambiguous", rows[0].Reason)
+	assert.Equal(t, InterventionOpen, rows[0].Status)
+	assert.Empty(t, rows[0].Resolution)
+	assert.Nil(t, rows[0].ResolvedAt)
+}
+
+func TestResolveManualIntervention(t *testing.T) {
+	ctx := context.Background()
+	config.Config.Importers.ManualInterventionTag = "needs-manual"
+
+	torrent := downloadclient.Torrent{Hash: "abc", Name: "Dune", Category: "audiobooks"}
+
+	t.Run("retry", func(t *testing.T) {
+		db, err := models.ConnectTestDB()
+		require.NoError(t, err)
+		mockClient := &testutil.MockDownloadClient{}
+
+		RecordManualIntervention(ctx, db, torrent, "audiobook-importer", "Failed")
+		require.NoError(t, RetryManualIntervention(ctx, db, mockClient, torrent))
+
+		require.Len(t, mockClient.RemoveTagsCalls, 1)
+		assert.Equal(t, []string{"abc"}, mockClient.RemoveTagsCalls[0].Hashes)
+		assert.Equal(t, []string{"needs-manual"}, mockClient.RemoveTagsCalls[0].Tags)
+
+		var intervention models.ManualIntervention
+		require.NoError(t, db.Where("torrent_hash = ?", "abc").First(&intervention).Error)
+		assert.Equal(t, InterventionResolved, intervention.Status)
+		assert.Equal(t, ResolutionRetry, intervention.Resolution)
+		assert.NotNil(t, intervention.ResolvedAt)
+	})
+
+	t.Run("retry keeps the intervention open when the tag cannot be removed", func(t *testing.T) {
+		db, err := models.ConnectTestDB()
+		require.NoError(t, err)
+		mockClient := &testutil.MockDownloadClient{RemoveTagsReturn: errors.New("connection refused")}
+
+		RecordManualIntervention(ctx, db, torrent, "audiobook-importer", "Failed")
+		assert.Error(t, RetryManualIntervention(ctx, db, mockClient, torrent))
+
+		var intervention models.ManualIntervention
+		require.NoError(t, db.Where("torrent_hash = ?", "abc").First(&intervention).Error)
+		assert.Equal(t, InterventionOpen, intervention.Status)
+	})
+
+	t.Run("ignore without a recorded intervention", func(t *testing.T) {
+		db, err := models.ConnectTestDB()
+		require.NoError(t, err)
+		require.NoError(t, db.Create(&models.AudiobookMatchCandidate{TorrentHash: "abc", Asin: "B002V1OF70", Rank: 1}).Error)
+
+		require.NoError(t, IgnoreManualIntervention(ctx, db, torrent))
+
+		var candidates int64
+		require.NoError(t, db.Model(&models.AudiobookMatchCandidate{}).Count(&candidates).Error)
+		assert.Zero(t, candidates)
+
+		var intervention models.ManualIntervention
+		require.NoError(t, db.Where("torrent_hash = ?", "abc").First(&intervention).Error)
+		assert.Equal(t, "Dune", intervention.TorrentName)
+		assert.Equal(t, InterventionIgnored, intervention.Status)
+		assert.Equal(t, ResolutionIgnore, intervention.Resolution)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		db, err := models.ConnectTestDB()
+		require.NoError(t, err)
+		mockClient := &testutil.MockDownloadClient{}
+
+		RecordManualIntervention(ctx, db, torrent, "ebook-importer", "No ebook files found")
+		require.NoError(t, DeleteManualInterventionTorrent(ctx, db, mockClient, torrent, true))
+
+		require.Len(t, mockClient.RemoveTorrentsCalls, 1)
+		assert.Equal(t, []string{"abc"}, mockClient.RemoveTorrentsCalls[0].Hashes)
+		assert.True(t, mockClient.RemoveTorrentsCalls[0].DeleteFiles)
+
+		var intervention models.ManualIntervention
+		require.NoError(t, db.Where("torrent_hash = ?", "abc").First(&intervention).Error)
+		assert.Equal(t, ResolutionDelete, intervention.Resolution)
+	})
+}
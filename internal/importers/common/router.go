@@ -0,0 +1,223 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/cappuccinotm/slogx"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// Media types, matching TorrentCategory.MediaType and BookType.Name
+const (
+	MediaTypeAudiobook = "audiobook"
+	MediaTypeEbook     = "ebook"
+)
+
+// ErrNoImporter is returned when no importer handles a media type.
+var ErrNoImporter = errors.New("no importer for media type")
+
+// Importer imports single torrents of one media type into a library.
+type Importer interface {
+	// MediaType is the TorrentCategory.MediaType the importer handles
+	MediaType() string
+
+	// ImportTorrent imports a torrent into library. Failures are handled by the
+	// importer, which retries or marks the torrent for manual intervention.
+	ImportTorrent(ctx context.Context, torrent downloadclient.Torrent, importType config.ImportType, library *config.ImportLibrary)
+}
+
+// Finisher is implemented by importers that put off slow follow-up work, such
+// as waiting for a media server to scan an import, until a run's torrents have
+// been imported.
+type Finisher interface {
+	// Finish completes the work put off by the imports made so far.
+	Finish(ctx context.Context)
+}
+
+// Route is where a torrent in a category is imported.
+type Route struct {
+	Category   models.TorrentCategory
+	Importer   Importer
+	Library    models.Library
+	ImportType config.ImportType
+}
+
+// Router maps torrent categories to an importer by media type, and to a library
+// and import settings through the category's import type in the database.
+type Router struct {
+	db        *gorm.DB
+	importers map[string]Importer
+}
+
+// NewRouter creates a Router for the given importers.
+func NewRouter(db *gorm.DB, importers ...Importer) *Router {
+	router := &Router{
+		db:        db,
+		importers: make(map[string]Importer, len(importers)),
+	}
+
+	for _, importer := range importers {
+		router.importers[importer.MediaType()] = importer
+	}
+
+	return router
+}
+
+// Importer returns the importer for a media type.
+func (r *Router) Importer(mediaType string) (Importer, error) {
+	importer, ok := r.importers[mediaType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoImporter, mediaType)
+	}
+
+	return importer, nil
+}
+
+// RouteFilter selects categories by media type and scope. Empty fields match
+// every category.
+type RouteFilter struct {
+	MediaType string
+	Scope     string // SubscriptionScope name, e.g. "personal"
+}
+
+func (f RouteFilter) matches(category models.TorrentCategory) bool {
+	return (f.MediaType == "" || category.MediaType == f.MediaType) &&
+		(f.Scope == "" || category.Scope.Name == f.Scope)
+}
+
+// Route resolves the importer, library and import settings for a category.
+func (r *Router) Route(ctx context.Context, categoryName string) (Route, error) {
+	importType, ok, err := FindImportType(ctx, r.db, categoryName)
+	if err != nil {
+		return Route{}, err
+	}
+	if !ok {
+		return Route{}, fmt.Errorf("no import type for category %s", categoryName)
+	}
+
+	return r.route(importType)
+}
+
+// Routes returns the routes of the categories with an import type that match
+// filter, in import type order. Categories whose route cannot be resolved are
+// logged and skipped so one bad import type does not stop the others.
+func (r *Router) Routes(ctx context.Context, filter RouteFilter) ([]Route, error) {
+	importTypes, err := LoadImportTypes(ctx, r.db, filter.MediaType)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []Route
+
+	for _, importType := range importTypes {
+		if !filter.matches(importType.TorrentCategory) {
+			continue
+		}
+
+		route, err := r.route(importType)
+		if err != nil {
+			slog.WarnContext(ctx, "Skipping category without a usable route",
+				slog.String("category", importType.TorrentCategory.Name),
+				slogx.Error(err))
+			continue
+		}
+
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// ImportRoutes imports the ready torrents in each route's category through the
+// route's importer, and records the torrents that are not ready in summary.
+// The importers finish their put-off work once every route has been imported.
+func (r *Router) ImportRoutes(ctx context.Context, downloadClient downloadclient.DownloadClient, routes []Route, summary *RunSummary) error {
+	defer r.Finish(ctx)
+
+	for _, route := range routes {
+		slog.InfoContext(ctx, "Processing import type", slog.String("category", route.Category.Name))
+
+		candidates, err := downloadclient.GetImportReadyTorrentsByCategory(
+			ctx,
+			downloadClient,
+			route.Category.Name,
+			SeedGateForImportType(route.ImportType),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to get unimported torrents for category %s: %w", route.Category.Name, err)
+		}
+
+		summary.Add(candidates)
+
+		library := ImportLibraryFromModel(route.Library)
+
+		for _, torrent := range candidates.Ready {
+			slog.InfoContext(ctx, "Found unimported torrent", slog.String("name", torrent.Name))
+			route.Importer.ImportTorrent(ctx, torrent, route.ImportType, library)
+		}
+	}
+
+	return nil
+}
+
+// route resolves the route for an import type loaded with its relations.
+func (r *Router) route(importType models.ImportType) (Route, error) {
+	category := importType.TorrentCategory
+
+	importer, err := r.Importer(category.MediaType)
+	if err != nil {
+		return Route{}, err
+	}
+
+	if importType.Library.BookType.Name != category.MediaType {
+		return Route{}, fmt.Errorf("library %s holds %s, not %s", importType.Library.Name, importType.Library.BookType.Name, category.MediaType)
+	}
+
+	return Route{
+		Category:   category,
+		Importer:   importer,
+		Library:    importType.Library,
+		ImportType: ImportTypeConfig(importType),
+	}, nil
+}
+
+// ImportTorrent imports a torrent through the route for its category.
+func (r *Router) ImportTorrent(ctx context.Context, torrent downloadclient.Torrent) error {
+	route, err := r.Route(ctx, torrent.Category)
+	if err != nil {
+		return err
+	}
+
+	route.Importer.ImportTorrent(ctx, torrent, route.ImportType, ImportLibraryFromModel(route.Library))
+	r.Finish(ctx)
+
+	return nil
+}
+
+// Finish completes the work the router's importers put off, see Finisher.
+func (r *Router) Finish(ctx context.Context) {
+	for _, importer := range r.importers {
+		if finisher, ok := importer.(Finisher); ok {
+			finisher.Finish(ctx)
+		}
+	}
+}
+
+// ImportLibraryFromModel adapts a database library to the importer config type.
+func ImportLibraryFromModel(library models.Library) *config.ImportLibrary {
+	return &config.ImportLibrary{
+		Name:                    library.Name,
+		Path:                    library.Path,
+		PreferredFormats:        SplitFormats(library.PreferredFormats),
+		AudiobookshelfLibraryID: library.AudiobookshelfLibraryID,
+		AudiobookshelfPath:      library.AudiobookshelfPath,
+		WriteAudioTags:          library.WriteAudioTags,
+	}
+}
@@ -0,0 +1,175 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+type importCall struct {
+	torrent    downloadclient.Torrent
+	importType config.ImportType
+	library    config.ImportLibrary
+}
+
+// recordingImporter records the torrents it is asked to import.
+type recordingImporter struct {
+	mediaType string
+	calls     []importCall
+}
+
+func (r *recordingImporter) MediaType() string {
+	return r.mediaType
+}
+
+func (r *recordingImporter) ImportTorrent(ctx context.Context, torrent downloadclient.Torrent, importType config.ImportType, library *config.ImportLibrary) {
+	r.calls = append(r.calls, importCall{torrent: torrent, importType: importType, library: *library})
+}
+
+func createLibrary(t *testing.T, db *gorm.DB, name string, bookType string) models.Library {
+	t.Helper()
+
+	var bt models.BookType
+	require.NoError(t, db.Where("name = ?", bookType).First(&bt).Error)
+
+	library := models.Library{Name: name, Path: "/libraries/" + name, BookTypeID: bt.ID}
+	require.NoError(t, db.Create(&library).Error)
+
+	return library
+}
+
+func createImportType(t *testing.T, db *gorm.DB, category string, library models.Library, notifierID *uint) models.ImportType {
+	t.Helper()
+
+	var torrentCategory models.TorrentCategory
+	require.NoError(t, db.Where("name = ?", category).First(&torrentCategory).Error)
+
+	importType := models.ImportType{TorrentCategoryID: torrentCategory.ID, LibraryID: library.ID, NotifierID: notifierID}
+	require.NoError(t, db.Create(&importType).Error)
+
+	return importType
+}
+
+func TestRouter_Route(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	audiobookLibrary := createLibrary(t, db, "family-audiobooks", "audiobook")
+	ebookLibrary := createLibrary(t, db, "family-books", "ebook")
+
+	notifier := models.Notifier{Name: "books-notifier"}
+	require.NoError(t, db.Create(&notifier).Error)
+
+	createImportType(t, db, "audiobooks", audiobookLibrary, nil)
+	createImportType(t, db, "books", ebookLibrary, &notifier.ID)
+	createImportType(t, db, "personal-books", audiobookLibrary, nil)
+
+	audiobookImporter := &recordingImporter{mediaType: MediaTypeAudiobook}
+	ebookImporter := &recordingImporter{mediaType: MediaTypeEbook}
+	router := NewRouter(db, audiobookImporter, ebookImporter)
+
+	route, err := router.Route(ctx, "audiobooks")
+	require.NoError(t, err)
+	assert.Same(t, audiobookImporter, route.Importer)
+	assert.Equal(t, audiobookLibrary.ID, route.Library.ID)
+	assert.Equal(t, "family", route.Category.Scope.Name)
+	assert.Equal(t, "audiobooks", route.ImportType.Category)
+	assert.Equal(t, "family-audiobooks", route.ImportType.Library)
+	assert.Empty(t, route.ImportType.DiscordNotifier)
+
+	route, err = router.Route(ctx, "books")
+	require.NoError(t, err)
+	assert.Same(t, ebookImporter, route.Importer)
+	assert.Equal(t, ebookLibrary.ID, route.Library.ID)
+	assert.Equal(t, "books-notifier", route.ImportType.DiscordNotifier)
+
+	// The library must hold the category's media type
+	_, err = router.Route(ctx, "personal-books")
+	assert.ErrorContains(t, err, "holds audiobook, not ebook")
+
+	// No import type at all
+	_, err = router.Route(ctx, "kids-books")
+	assert.ErrorContains(t, err, "no import type for category kids-books")
+
+	_, err = router.Route(ctx, "does-not-exist")
+	assert.Error(t, err)
+}
+
+func TestRouter_ImportTorrent(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	createImportType(t, db, "audiobooks", createLibrary(t, db, "family-audiobooks", "audiobook"), nil)
+	createImportType(t, db, "books", createLibrary(t, db, "family-books", "ebook"), nil)
+
+	audiobookImporter := &recordingImporter{mediaType: MediaTypeAudiobook}
+	router := NewRouter(db, audiobookImporter)
+
+	torrent := downloadclient.Torrent{Hash: "abc", Name: "Dune", Category: "audiobooks"}
+	require.NoError(t, router.ImportTorrent(ctx, torrent))
+
+	require.Len(t, audiobookImporter.calls, 1)
+	assert.Equal(t, "abc", audiobookImporter.calls[0].torrent.Hash)
+	assert.Equal(t, config.ImportLibrary{Name: "family-audiobooks", Path: "/libraries/family-audiobooks"}, audiobookImporter.calls[0].library)
+
+	// No importer registered for ebooks
+	err = router.ImportTorrent(ctx, downloadclient.Torrent{Hash: "def", Category: "books"})
+	assert.True(t, errors.Is(err, ErrNoImporter))
+}
+
+func TestRouter_Routes(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	familyAudiobooks := createLibrary(t, db, "family-audiobooks", "audiobook")
+	personalAudiobooks := createLibrary(t, db, "personal-audiobooks", "audiobook")
+	familyBooks := createLibrary(t, db, "family-books", "ebook")
+
+	createImportType(t, db, "audiobooks", familyAudiobooks, nil)
+	createImportType(t, db, "personal-audiobooks", personalAudiobooks, nil)
+	createImportType(t, db, "books", familyBooks, nil)
+	// Misconfigured: an ebook category importing into an audiobook library
+	createImportType(t, db, "personal-books", personalAudiobooks, nil)
+
+	audiobookImporter := &recordingImporter{mediaType: MediaTypeAudiobook}
+	ebookImporter := &recordingImporter{mediaType: MediaTypeEbook}
+	router := NewRouter(db, audiobookImporter, ebookImporter)
+
+	categories := func(filter RouteFilter) []string {
+		routes, err := router.Routes(ctx, filter)
+		require.NoError(t, err)
+
+		names := make([]string, len(routes))
+		for i, route := range routes {
+			names[i] = route.Category.Name
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"audiobooks", "personal-audiobooks", "books"}, categories(RouteFilter{}))
+	assert.Equal(t, []string{"audiobooks", "personal-audiobooks"}, categories(RouteFilter{MediaType: MediaTypeAudiobook}))
+	assert.Equal(t, []string{"personal-audiobooks"}, categories(RouteFilter{MediaType: MediaTypeAudiobook, Scope: "personal"}))
+	assert.Equal(t, []string{"books"}, categories(RouteFilter{Scope: "family", MediaType: MediaTypeEbook}))
+	assert.Empty(t, categories(RouteFilter{Scope: "kids"}))
+
+	routes, err := router.Routes(ctx, RouteFilter{MediaType: MediaTypeAudiobook, Scope: "personal"})
+	require.NoError(t, err)
+	require.Len(t, routes, 1)
+	assert.Same(t, audiobookImporter, routes[0].Importer)
+	assert.Equal(t, personalAudiobooks.ID, routes[0].Library.ID)
+	assert.Equal(t, "personal-audiobooks", routes[0].ImportType.Library)
+}
@@ -0,0 +1,56 @@
+package common
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+)
+
+// SeedGateForImportType returns the seed gate configured for an import type.
+func SeedGateForImportType(importType config.ImportType) downloadclient.SeedGate {
+	return downloadclient.SeedGate{
+		MinSeedTime: time.Duration(importType.MinSeedTimeMinutes) * time.Minute,
+		MinRatio:    importType.MinSeedRatio,
+	}
+}
+
+// RunSummary collects the torrents an importer looked at during a run.
+type RunSummary struct {
+	Importer string
+	Ready    int
+	Skipped  []downloadclient.SkippedTorrent
+}
+
+// NewRunSummary creates an empty summary for the named importer.
+func NewRunSummary(importer string) *RunSummary {
+	return &RunSummary{Importer: importer}
+}
+
+// Add records the candidates found for one category.
+func (s *RunSummary) Add(candidates downloadclient.ImportCandidates) {
+	s.Ready += len(candidates.Ready)
+	s.Skipped = append(s.Skipped, candidates.Skipped...)
+}
+
+// Log writes the summary, listing each torrent that was skipped because it is
+// not ready for import yet.
+func (s *RunSummary) Log(ctx context.Context) {
+	slog.InfoContext(ctx, "Import run summary",
+		slog.String("importer", s.Importer),
+		slog.Int("ready", s.Ready),
+		slog.Int("skipped", len(s.Skipped)))
+
+	for _, skipped := range s.Skipped {
+		slog.InfoContext(ctx, "Skipped torrent not ready for import",
+			slog.String("importer", s.Importer),
+			slog.String("name", skipped.Torrent.Name),
+			slog.String("hash", skipped.Torrent.Hash),
+			slog.String("category", skipped.Torrent.Category),
+			slog.String("state", string(skipped.Torrent.State)),
+			slog.Float64("progress", skipped.Torrent.Progress),
+			slog.String("reason", skipped.Reason))
+	}
+}
@@ -2,72 +2,96 @@ package ebooks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 
-	"github.com/autobrr/go-qbittorrent"
 	"github.com/cappuccinotm/slogx"
 
 	"gorm.io/gorm"
 
+	"github.com/bobbyrward/stronghold/internal/calibre"
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/bobbyrward/stronghold/internal/notifications"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 )
 
 type BookImporterSystem struct {
-	qbitClient qbit.QbitClient
-	db         *gorm.DB
+	downloadClient downloadclient.DownloadClient
+	db             *gorm.DB
+	converter      *Converter
+
+	// newCalibreClient opens the Calibre library of an import type.
+	newCalibreClient func(location string) (calibre.Client, error)
 }
 
-func NewBookImporterSystem(qbitClient qbit.QbitClient, db *gorm.DB) *BookImporterSystem {
+func NewBookImporterSystem(downloadClient downloadclient.DownloadClient, db *gorm.DB) *BookImporterSystem {
 	return &BookImporterSystem{
-		qbitClient: qbitClient,
-		db:         db,
+		downloadClient: downloadClient,
+		db:             db,
+		converter:      NewConverter(config.Config.Importers.BookImporter.Converter),
+		newCalibreClient: func(location string) (calibre.Client, error) {
+			return calibre.NewClient(config.Config.Importers.BookImporter.Calibre, location)
+		},
 	}
 }
 
+// importedBook is a file the importer placed in the library.
+type importedBook struct {
+	Name          string
+	CalibreBookID int // 0 when not added to Calibre
+}
+
 func (bis *BookImporterSystem) Run(ctx context.Context) error {
 	slog.InfoContext(ctx, "Running book import process...")
 
-	for _, importType := range config.Config.Importers.BookImporter.ImportTypes {
-		slog.InfoContext(ctx, "Processing import type", slog.String("category", importType.Category))
+	summary := common.NewRunSummary("ebook")
+	defer summary.Log(ctx)
 
-		library, ok := config.FindLibraryByName(config.Config.Importers.BookImporter.Libraries, importType.Library)
-		if !ok {
-			return fmt.Errorf("unabled to find library: %s", importType.Library)
-		}
+	router := common.NewRouter(bis.db, bis)
 
-		err := bis.ProcessImportType(ctx, importType, library)
-		if err != nil {
-			return fmt.Errorf("failed to process import type %s: %w", importType.Category, err)
-		}
+	routes, err := router.Routes(ctx, common.RouteFilter{MediaType: common.MediaTypeEbook})
+	if err != nil {
+		return err
 	}
 
-	return nil
+	return router.ImportRoutes(ctx, bis.downloadClient, routes, summary)
+}
+
+// MediaType implements common.Importer.
+func (bis *BookImporterSystem) MediaType() string {
+	return common.MediaTypeEbook
 }
 
-func (bis *BookImporterSystem) ProcessImportType(ctx context.Context, importType config.ImportType, library *config.ImportLibrary) error {
-	torrents, err := qbit.GetUnimportedTorrentsByCategory(ctx, bis.qbitClient, importType.Category)
+// ImportTorrent copies the ebook files of a torrent into the library. Transient failures
+// are retried with backoff on later runs; permanent failures and exhausted retries are
+// marked for manual intervention.
+func (bis *BookImporterSystem) ImportTorrent(ctx context.Context, torrent downloadclient.Torrent, importType config.ImportType, library *config.ImportLibrary) {
+	job, ready, err := common.StartImportJob(ctx, bis.db, torrent)
 	if err != nil {
-		return fmt.Errorf("failed to get unimported torrents for category %s: %w", importType.Category, err)
+		slog.ErrorContext(ctx, "Failed to start import job, importing without retry tracking",
+			slog.String("name", torrent.Name),
+			slog.String("hash", torrent.Hash),
+			slogx.Error(err),
+		)
 	}
 
-	for _, torrent := range torrents {
-		slog.InfoContext(ctx, "Found unimported torrent", slog.String("name", torrent.Name))
-		bis.ImportTorrent(ctx, torrent, importType, library)
+	if !ready {
+		slog.InfoContext(ctx, "Import is waiting for its next retry",
+			slog.String("name", torrent.Name),
+			slog.String("hash", torrent.Hash),
+			slog.Any("nextAttemptAt", job.NextAttemptAt),
+		)
+		return
 	}
 
-	return nil
-}
-
-func (bis *BookImporterSystem) ImportTorrent(ctx context.Context, torrent qbittorrent.Torrent, importType config.ImportType, library *config.ImportLibrary) {
-	files, err := common.MapTorrentFilesToLocalPaths(ctx, bis.qbitClient, torrent)
+	files, err := common.MapTorrentFilesToLocalPaths(ctx, bis.downloadClient, torrent)
 	if err != nil {
 		slog.InfoContext(ctx, "Failed to map torrent files",
 			slog.String("name", torrent.Name),
@@ -75,19 +99,14 @@ func (bis *BookImporterSystem) ImportTorrent(ctx context.Context, torrent qbitto
 			slogx.Error(err),
 		)
 
-		bis.markForManualIntervention(ctx, torrent, importType.DiscordNotifier, "Failed to map torrent files: "+err.Error())
+		bis.failImport(ctx, torrent, importType, job, "Failed to map torrent files", err)
 		return
 	}
 
 	books := make([]common.MappedTorrentFile, 0, len(files))
 
 	for _, mappedFile := range files {
-		switch filepath.Ext(mappedFile.BaseName) {
-		case ".azw3":
-			fallthrough
-		case ".mobi":
-			fallthrough
-		case ".epub":
+		if IsEbookFile(mappedFile.BaseName) {
 			books = append(books, mappedFile)
 		}
 	}
@@ -95,45 +114,163 @@ func (bis *BookImporterSystem) ImportTorrent(ctx context.Context, torrent qbitto
 	if len(books) == 0 {
 		slog.InfoContext(ctx, "Unable to find epubs in torrent", slog.String("name", torrent.Name))
 
-		bis.markForManualIntervention(ctx, torrent, importType.DiscordNotifier, "No ebook files (.epub, .mobi, .azw3) found in torrent")
+		bis.failImport(ctx, torrent, importType, job, "No ebook files found", common.Permanent(errors.New("no .epub, .mobi or .azw3 files in torrent")))
 		return
 	}
 
 	slog.InfoContext(ctx, "Found epubs", slog.Int("count", len(books)))
 
-	for _, mappedFile := range books {
-		slog.InfoContext(ctx, "Copying file", slog.Any("mappedFile", mappedFile))
+	var calibreClient calibre.Client
+	if importType.CalibreLibrary != "" {
+		calibreClient, err = bis.newCalibreClient(importType.CalibreLibrary)
+		if err != nil {
+			bis.failImport(ctx, torrent, importType, job, "Invalid Calibre library", common.Permanent(err))
+			return
+		}
+	}
+
+	planned := planBooks(books, library.PreferredFormats, bis.converter != nil)
+	imported := make([]importedBook, 0, len(planned))
+	bookNames := make([]string, 0, len(planned))
+	calibreBookIDs := map[string]int{}
 
-		// Use only the base filename to flatten directory structure
-		destPath := filepath.Join(library.Path, filepath.Base(mappedFile.BaseName))
-		err = copyFile(mappedFile.LocalPath, destPath)
+	for _, book := range planned {
+		destName, err := bis.importBook(ctx, book, library)
 		if err != nil {
-			slog.InfoContext(ctx, "Unable to copy file", slog.Any("mappedFile", mappedFile), slog.String("name", torrent.Name), slog.Any("err", err))
+			slog.InfoContext(ctx, "Unable to copy file", slog.Any("mappedFile", book.Source), slog.String("name", torrent.Name), slog.Any("err", err))
 
-			bis.markForManualIntervention(ctx, torrent, importType.DiscordNotifier, "Failed to copy file: "+err.Error())
+			bis.failImport(ctx, torrent, importType, job, "Failed to copy file", err)
 			return
 		}
+
+		destPath := filepath.Join(library.Path, destName)
+		common.RecordImportedItem(ctx, bis.db, torrent.Hash, destPath, torrent.Name, "", nil)
+
+		result := importedBook{Name: destName}
+
+		if calibreClient != nil {
+			result.CalibreBookID, err = bis.addToCalibre(ctx, calibreClient, destPath)
+			if err != nil {
+				slog.InfoContext(ctx, "Unable to add book to Calibre", slog.String("path", destPath), slog.String("name", torrent.Name), slogx.Error(err))
+
+				bis.failImport(ctx, torrent, importType, job, "Failed to add book to Calibre", err)
+				return
+			}
+
+			if result.CalibreBookID != 0 {
+				calibreBookIDs[destName] = result.CalibreBookID
+			}
+		}
+
+		imported = append(imported, result)
+		bookNames = append(bookNames, destName)
 	}
 
-	err = qbit.TagTorrent(ctx, bis.qbitClient, torrent, config.Config.Importers.ImportedTag)
+	err = downloadclient.TagTorrent(ctx, bis.downloadClient, torrent, config.Config.Importers.ImportedTag)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to add imported tag", slog.String("name", torrent.Name), slog.Any("err", err))
 	}
 
-	bookNames := make([]string, len(books))
-	for i, b := range books {
-		bookNames[i] = b.BaseName
-	}
+	common.CompleteImportJob(ctx, bis.db, job)
+
 	eventlog.Log(bis.db, eventlog.CategoryImport, eventlog.EventImportCompleted, eventlog.SourceEbookImporter,
 		eventlog.EntityTorrent, torrent.Hash,
-		fmt.Sprintf("Imported ebook: %s (%d files)", torrent.Name, len(books)),
-		map[string]any{"name": torrent.Name, "hash": torrent.Hash, "books": bookNames, "library": library.Path, "category": importType.Category})
+		fmt.Sprintf("Imported ebook: %s (%d files)", torrent.Name, len(bookNames)),
+		map[string]any{"name": torrent.Name, "hash": torrent.Hash, "books": bookNames, "library": library.Path, "category": importType.Category, "calibreBookIds": calibreBookIDs})
+
+	bis.sendDiscordNotification(ctx, torrent, library, imported, importType)
+}
+
+// addToCalibre adds a library file to Calibre and returns its book ID. Files
+// added by an earlier attempt keep their recorded ID instead of being added
+// again. Duplicates Calibre already had are skipped and return 0.
+func (bis *BookImporterSystem) addToCalibre(ctx context.Context, client calibre.Client, path string) (int, error) {
+	if bookID, ok := common.ImportedItemCalibreBookID(ctx, bis.db, path); ok {
+		return bookID, nil
+	}
+
+	bookID, added, err := client.AddBook(ctx, path)
+	if err != nil {
+		return 0, err
+	}
+
+	if !added {
+		slog.InfoContext(ctx, "Calibre already has this book, skipped adding it", slog.String("path", path))
+		return 0, nil
+	}
+
+	slog.InfoContext(ctx, "Added book to Calibre", slog.String("path", path), slog.Int("bookID", bookID))
+	common.SetImportedItemCalibreBookID(ctx, bis.db, path, bookID)
+
+	return bookID, nil
+}
+
+// importBook writes a planned book to the library and returns its file name
+// there. A failed conversion falls back to copying the original format.
+func (bis *BookImporterSystem) importBook(ctx context.Context, book plannedBook, library *config.ImportLibrary) (string, error) {
+	if book.ConvertTo != "" {
+		destPath := filepath.Join(library.Path, book.DestName())
+		slog.InfoContext(ctx, "Converting file", slog.Any("mappedFile", book.Source), slog.String("dest", destPath))
+
+		err := bis.converter.Convert(ctx, book.Source.LocalPath, destPath)
+		if err == nil {
+			return book.DestName(), nil
+		}
+
+		slog.WarnContext(ctx, "Ebook conversion failed, importing the original format",
+			slog.Any("mappedFile", book.Source),
+			slog.String("format", book.ConvertTo),
+			slogx.Error(err),
+		)
+		book.ConvertTo = ""
+	}
+
+	slog.InfoContext(ctx, "Copying file", slog.Any("mappedFile", book.Source))
+
+	return book.DestName(), copyFile(book.Source.LocalPath, filepath.Join(library.Path, book.DestName()))
+}
+
+// IsEbookFile reports whether filePath is an ebook format the importer copies.
+func IsEbookFile(filePath string) bool {
+	return IsEbookFormat(ebookFormat(filePath))
+}
+
+// IsEbookFormat reports whether format, an extension without the dot, is one
+// the importer copies.
+func IsEbookFormat(format string) bool {
+	switch format {
+	case "azw3", "mobi", "epub":
+		return true
+	}
 
-	bis.sendDiscordNotification(ctx, torrent, library, books, importType)
+	return false
 }
 
-func (bis *BookImporterSystem) markForManualIntervention(ctx context.Context, torrent qbittorrent.Torrent, notifierName string, reason string) {
-	err := qbit.TagTorrent(ctx, bis.qbitClient, torrent, config.Config.Importers.ManualInterventionTag)
+// failImport records a failed import attempt. The torrent is marked for manual intervention
+// when the failure is permanent or out of retries, otherwise it is left for a later run.
+func (bis *BookImporterSystem) failImport(ctx context.Context, torrent downloadclient.Torrent, importType config.ImportType, job *models.ImportJob, reason string, err error) {
+	reason = reason + ": " + err.Error()
+
+	if common.FailImportJob(ctx, bis.db, job, err, common.NewRetryPolicy(config.Config.Importers)) {
+		bis.markForManualIntervention(ctx, torrent, importType.DiscordNotifier, reason)
+		return
+	}
+
+	slog.InfoContext(ctx, "Scheduled import retry",
+		slog.String("name", torrent.Name),
+		slog.String("hash", torrent.Hash),
+		slog.Int("attempt", job.Attempts),
+		slog.Any("nextAttemptAt", job.NextAttemptAt),
+	)
+
+	eventlog.Log(bis.db, eventlog.CategoryImport, eventlog.EventImportFailed, eventlog.SourceEbookImporter,
+		eventlog.EntityTorrent, torrent.Hash,
+		fmt.Sprintf("Import attempt %d failed, will retry: %s: %s", job.Attempts, torrent.Name, reason),
+		map[string]any{"name": torrent.Name, "hash": torrent.Hash, "reason": reason, "attempt": job.Attempts, "nextAttemptAt": job.NextAttemptAt})
+}
+
+func (bis *BookImporterSystem) markForManualIntervention(ctx context.Context, torrent downloadclient.Torrent, notifierName string, reason string) {
+	err := downloadclient.TagTorrent(ctx, bis.downloadClient, torrent, config.Config.Importers.ManualInterventionTag)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to add manual intervention tag",
 			slog.String("name", torrent.Name),
@@ -154,13 +291,15 @@ func (bis *BookImporterSystem) markForManualIntervention(ctx context.Context, to
 		fmt.Sprintf("Manual intervention: %s: %s", torrent.Name, reason),
 		map[string]string{"name": torrent.Name, "hash": torrent.Hash, "reason": reason})
 
+	common.RecordManualIntervention(ctx, bis.db, torrent, eventlog.SourceEbookImporter, reason)
+
 	// Send notification if notifier is configured
 	if notifierName != "" {
 		bis.sendManualInterventionNotification(ctx, torrent, notifierName, reason)
 	}
 }
 
-func (bis *BookImporterSystem) sendManualInterventionNotification(ctx context.Context, torrent qbittorrent.Torrent, notifierName string, reason string) {
+func (bis *BookImporterSystem) sendManualInterventionNotification(ctx context.Context, torrent downloadclient.Torrent, notifierName string, reason string) {
 	message := notifications.DiscordWebhookMessage{
 		Username: "Stronghold Book Importer",
 		Embeds: []notifications.DiscordEmbed{
@@ -192,51 +331,72 @@ func (bis *BookImporterSystem) sendManualInterventionNotification(ctx context.Co
 	}
 }
 
-func (bis *BookImporterSystem) sendDiscordNotification(ctx context.Context, torrent qbittorrent.Torrent, library *config.ImportLibrary, books []common.MappedTorrentFile, importType config.ImportType) {
+func (bis *BookImporterSystem) sendDiscordNotification(ctx context.Context, torrent downloadclient.Torrent, library *config.ImportLibrary, books []importedBook, importType config.ImportType) {
 	if importType.DiscordNotifier == "" {
 		return
 	}
 
+	message := importNotification(torrent, library, books, importType)
+
+	err := notifications.SendNotification(ctx, importType.DiscordNotifier, message)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send Discord notification", slog.String("torrent", torrent.Name), slog.Any("err", err))
+	}
+}
+
+// importNotification is the Discord message announcing imported books.
+func importNotification(torrent downloadclient.Torrent, library *config.ImportLibrary, books []importedBook, importType config.ImportType) notifications.DiscordWebhookMessage {
 	bookList := ""
 	for i, book := range books {
 		if i > 0 {
 			bookList += "\n"
 		}
-		bookList += "• " + book.BaseName
+
+		// Link each book to Calibre-Web when it was added to Calibre
+		if link := calibre.BookURL(importType.CalibreWebURL, book.CalibreBookID); link != "" {
+			bookList += fmt.Sprintf("• [%s](%s)", book.Name, link)
+		} else {
+			bookList += "• " + book.Name
+		}
 	}
 
-	message := notifications.DiscordWebhookMessage{
+	fields := []notifications.DiscordEmbedField{
+		{
+			Name:   "Books",
+			Value:  bookList,
+			Inline: false,
+		},
+		{
+			Name:   "Category",
+			Value:  importType.Category,
+			Inline: true,
+		},
+		{
+			Name:   "Destination",
+			Value:  library.Path,
+			Inline: true,
+		},
+	}
+
+	if importType.CalibreDesktopURL != "" {
+		fields = append(fields, notifications.DiscordEmbedField{
+			Name:   "Calibre",
+			Value:  importType.CalibreDesktopURL,
+			Inline: true,
+		})
+	}
+
+	return notifications.DiscordWebhookMessage{
 		Username: "Stronghold Book Importer",
 		Embeds: []notifications.DiscordEmbed{
 			{
 				Title:       "📚 New Book(s) Imported",
 				Description: fmt.Sprintf("Successfully imported %d book(s) from torrent **%s**", len(books), torrent.Name),
 				Color:       0x00ff00,
-				Fields: []notifications.DiscordEmbedField{
-					{
-						Name:   "Books",
-						Value:  bookList,
-						Inline: false,
-					},
-					{
-						Name:   "Category",
-						Value:  importType.Category,
-						Inline: true,
-					},
-					{
-						Name:   "Destination",
-						Value:  library.Path,
-						Inline: true,
-					},
-				},
+				Fields:      fields,
 			},
 		},
 	}
-
-	err := notifications.SendNotification(ctx, importType.DiscordNotifier, message)
-	if err != nil {
-		slog.ErrorContext(ctx, "Failed to send Discord notification", slog.String("torrent", torrent.Name), slog.Any("err", err))
-	}
 }
 
 func copyFile(sourcePath string, destPath string) error {
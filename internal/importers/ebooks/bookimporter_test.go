@@ -2,15 +2,18 @@ package ebooks
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
 
-	"github.com/autobrr/go-qbittorrent"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/bobbyrward/stronghold/internal/testutil"
 )
 
@@ -37,18 +40,18 @@ func TestImportTorrent_SingleFileInRoot(t *testing.T) {
 	config.Config.Importers.ManualInterventionTag = "manual"
 
 	// Mock qBittorrent client
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash:     "test123",
 		Name:     "Test Book",
 		SavePath: "/remote",
 	}
 
-	mockClient := &testutil.MockQbitClient{
-		GetFilesInformationCtxReturn: struct {
-			Files *qbittorrent.TorrentFiles
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
 			Err   error
 		}{
-			Files: &qbittorrent.TorrentFiles{
+			Files: []downloadclient.TorrentFile{
 				{Name: "book.epub"},
 			},
 		},
@@ -79,8 +82,8 @@ func TestImportTorrent_SingleFileInRoot(t *testing.T) {
 	assert.Equal(t, "test epub content", string(content))
 
 	// Verify torrent was tagged as imported
-	assert.Len(t, mockClient.AddTagsCtxCalls, 1)
-	assert.Equal(t, "imported", mockClient.AddTagsCtxCalls[0].Tags)
+	assert.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"imported"}, mockClient.AddTagsCalls[0].Tags)
 }
 
 func TestImportTorrent_SingleFileInNestedDirectory(t *testing.T) {
@@ -106,18 +109,18 @@ func TestImportTorrent_SingleFileInNestedDirectory(t *testing.T) {
 	config.Config.Importers.ManualInterventionTag = "manual"
 
 	// Mock qBittorrent client
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash:     "test456",
 		Name:     "Nested Book",
 		SavePath: "/remote",
 	}
 
-	mockClient := &testutil.MockQbitClient{
-		GetFilesInformationCtxReturn: struct {
-			Files *qbittorrent.TorrentFiles
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
 			Err   error
 		}{
-			Files: &qbittorrent.TorrentFiles{
+			Files: []downloadclient.TorrentFile{
 				{Name: "Author/Book Title/book.epub"},
 			},
 		},
@@ -155,8 +158,8 @@ func TestImportTorrent_SingleFileInNestedDirectory(t *testing.T) {
 	assert.Equal(t, "nested epub content", string(content))
 
 	// Verify torrent was tagged as imported
-	assert.Len(t, mockClient.AddTagsCtxCalls, 1)
-	assert.Equal(t, "imported", mockClient.AddTagsCtxCalls[0].Tags)
+	assert.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"imported"}, mockClient.AddTagsCalls[0].Tags)
 }
 
 func TestImportTorrent_MultipleFilesInNestedDirectories(t *testing.T) {
@@ -186,18 +189,18 @@ func TestImportTorrent_MultipleFilesInNestedDirectories(t *testing.T) {
 	config.Config.Importers.ManualInterventionTag = "manual"
 
 	// Mock qBittorrent client
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash:     "test789",
 		Name:     "Multiple Books",
 		SavePath: "/remote",
 	}
 
-	mockClient := &testutil.MockQbitClient{
-		GetFilesInformationCtxReturn: struct {
-			Files *qbittorrent.TorrentFiles
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
 			Err   error
 		}{
-			Files: &qbittorrent.TorrentFiles{
+			Files: []downloadclient.TorrentFile{
 				{Name: "Author1/Book1/book1.epub"},
 				{Name: "Author2/Series/Book2/book2.mobi"},
 			},
@@ -236,8 +239,8 @@ func TestImportTorrent_MultipleFilesInNestedDirectories(t *testing.T) {
 	}
 
 	// Verify torrent was tagged as imported
-	assert.Len(t, mockClient.AddTagsCtxCalls, 1)
-	assert.Equal(t, "imported", mockClient.AddTagsCtxCalls[0].Tags)
+	assert.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"imported"}, mockClient.AddTagsCalls[0].Tags)
 }
 
 func TestImportTorrent_MultipleFileFormats(t *testing.T) {
@@ -269,18 +272,18 @@ func TestImportTorrent_MultipleFileFormats(t *testing.T) {
 	config.Config.Importers.ManualInterventionTag = "manual"
 
 	// Mock qBittorrent client
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash:     "testformats",
 		Name:     "Multiple Formats",
 		SavePath: "/remote",
 	}
 
-	mockClient := &testutil.MockQbitClient{
-		GetFilesInformationCtxReturn: struct {
-			Files *qbittorrent.TorrentFiles
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
 			Err   error
 		}{
-			Files: &qbittorrent.TorrentFiles{
+			Files: []downloadclient.TorrentFile{
 				{Name: "book.epub"},
 				{Name: "book.mobi"},
 				{Name: "book.azw3"},
@@ -313,8 +316,8 @@ func TestImportTorrent_MultipleFileFormats(t *testing.T) {
 	assert.NoError(t, err, "azw3 should be imported")
 
 	// Verify torrent was tagged as imported
-	assert.Len(t, mockClient.AddTagsCtxCalls, 1)
-	assert.Equal(t, "imported", mockClient.AddTagsCtxCalls[0].Tags)
+	assert.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"imported"}, mockClient.AddTagsCalls[0].Tags)
 }
 
 func TestImportTorrent_IgnoreNonBookFiles(t *testing.T) {
@@ -346,18 +349,18 @@ func TestImportTorrent_IgnoreNonBookFiles(t *testing.T) {
 	config.Config.Importers.ManualInterventionTag = "manual"
 
 	// Mock qBittorrent client
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash:     "testfilter",
 		Name:     "Mixed Files",
 		SavePath: "/remote",
 	}
 
-	mockClient := &testutil.MockQbitClient{
-		GetFilesInformationCtxReturn: struct {
-			Files *qbittorrent.TorrentFiles
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
 			Err   error
 		}{
-			Files: &qbittorrent.TorrentFiles{
+			Files: []downloadclient.TorrentFile{
 				{Name: "book.epub"},
 				{Name: "readme.txt"},
 				{Name: "cover.jpg"},
@@ -390,8 +393,8 @@ func TestImportTorrent_IgnoreNonBookFiles(t *testing.T) {
 	assert.True(t, os.IsNotExist(err), "jpg file should not be imported")
 
 	// Verify torrent was tagged as imported
-	assert.Len(t, mockClient.AddTagsCtxCalls, 1)
-	assert.Equal(t, "imported", mockClient.AddTagsCtxCalls[0].Tags)
+	assert.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"imported"}, mockClient.AddTagsCalls[0].Tags)
 }
 
 func TestImportTorrent_NoBookFilesFound(t *testing.T) {
@@ -414,18 +417,18 @@ func TestImportTorrent_NoBookFilesFound(t *testing.T) {
 	config.Config.Importers.ManualInterventionTag = "manual"
 
 	// Mock qBittorrent client - only non-book files
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash:     "testnobooks",
 		Name:     "No Books",
 		SavePath: "/remote",
 	}
 
-	mockClient := &testutil.MockQbitClient{
-		GetFilesInformationCtxReturn: struct {
-			Files *qbittorrent.TorrentFiles
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
 			Err   error
 		}{
-			Files: &qbittorrent.TorrentFiles{
+			Files: []downloadclient.TorrentFile{
 				{Name: "readme.txt"},
 				{Name: "cover.jpg"},
 			},
@@ -452,8 +455,8 @@ func TestImportTorrent_NoBookFilesFound(t *testing.T) {
 	assert.Empty(t, entries, "No files should be imported")
 
 	// Verify torrent was tagged for manual intervention
-	assert.Len(t, mockClient.AddTagsCtxCalls, 1)
-	assert.Equal(t, "manual", mockClient.AddTagsCtxCalls[0].Tags)
+	assert.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"manual"}, mockClient.AddTagsCalls[0].Tags)
 }
 
 func TestImportTorrent_DeeplyNestedStructure(t *testing.T) {
@@ -481,18 +484,18 @@ func TestImportTorrent_DeeplyNestedStructure(t *testing.T) {
 	config.Config.Importers.ManualInterventionTag = "manual"
 
 	// Mock qBittorrent client
-	torrent := qbittorrent.Torrent{
+	torrent := downloadclient.Torrent{
 		Hash:     "testdeep",
 		Name:     "Deeply Nested",
 		SavePath: "/remote",
 	}
 
-	mockClient := &testutil.MockQbitClient{
-		GetFilesInformationCtxReturn: struct {
-			Files *qbittorrent.TorrentFiles
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
 			Err   error
 		}{
-			Files: &qbittorrent.TorrentFiles{
+			Files: []downloadclient.TorrentFile{
 				{Name: "Level1/Level2/Level3/Level4/Level5/deep-book.epub"},
 			},
 		},
@@ -529,6 +532,154 @@ func TestImportTorrent_DeeplyNestedStructure(t *testing.T) {
 	assert.Equal(t, "deeply nested content", string(content))
 
 	// Verify torrent was tagged as imported
-	assert.Len(t, mockClient.AddTagsCtxCalls, 1)
-	assert.Equal(t, "imported", mockClient.AddTagsCtxCalls[0].Tags)
+	assert.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"imported"}, mockClient.AddTagsCalls[0].Tags)
+}
+
+func TestImportTorrent_TransientFailureIsRetried(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	config.Config.Importers.ImportedTag = "imported"
+	config.Config.Importers.ManualInterventionTag = "manual"
+	config.Config.Importers.MaxAttempts = 2
+
+	torrent := downloadclient.Torrent{
+		Hash:     "testretry",
+		Name:     "Retry Book",
+		Category: "books",
+		SavePath: "/remote",
+	}
+
+	// qBittorrent is unreachable, which should be retried
+	mockClient := &testutil.MockDownloadClient{
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
+			Err   error
+		}{
+			Err: errors.New("connection refused"),
+		},
+	}
+
+	library := &config.ImportLibrary{Name: "test-library", Path: t.TempDir()}
+	importType := config.ImportType{Category: "books", Library: "test-library"}
+
+	importer := NewBookImporterSystem(mockClient, db)
+	importer.ImportTorrent(ctx, torrent, importType, library)
+
+	// Verify the torrent was not tagged and a retry is scheduled
+	assert.Empty(t, mockClient.AddTagsCalls)
+
+	var job models.ImportJob
+	require.NoError(t, db.Where("torrent_hash = ?", torrent.Hash).First(&job).Error)
+	assert.Equal(t, common.ImportJobRetrying, job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	require.NotNil(t, job.NextAttemptAt)
+
+	// A run before the retry is due leaves the torrent alone
+	importer.ImportTorrent(ctx, torrent, importType, library)
+	assert.Empty(t, mockClient.AddTagsCalls)
+
+	// Once the retry is due, the last attempt sends it to manual intervention
+	require.NoError(t, db.Model(&job).Update("next_attempt_at", nil).Error)
+
+	importer.ImportTorrent(ctx, torrent, importType, library)
+	require.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"manual"}, mockClient.AddTagsCalls[0].Tags)
+
+	require.NoError(t, db.Where("torrent_hash = ?", torrent.Hash).First(&job).Error)
+	assert.Equal(t, common.ImportJobFailed, job.Status)
+}
+
+func TestImportRoutes_SkipsIncompleteTorrents(t *testing.T) {
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	destDir := filepath.Join(tempDir, "dest")
+
+	require.NoError(t, os.MkdirAll(sourceDir, 0755))
+	require.NoError(t, os.MkdirAll(destDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "book.epub"), []byte("test epub content"), 0644))
+
+	config.Config.Qbit = config.QbitConfig{
+		DownloadPath:      "/remote",
+		LocalDownloadPath: sourceDir,
+	}
+	config.Config.Importers.ImportedTag = "imported"
+	config.Config.Importers.ManualInterventionTag = "manual"
+
+	completed := downloadclient.Torrent{
+		Hash:        "completed",
+		Name:        "Completed Book",
+		SavePath:    "/remote",
+		State:       downloadclient.StateStalledSeeding,
+		Progress:    1,
+		SeedingTime: 7200,
+	}
+	downloading := downloadclient.Torrent{
+		Hash:       "downloading",
+		Name:       "Downloading Book",
+		SavePath:   "/remote",
+		State:      downloadclient.StateDownloading,
+		Progress:   0.3,
+		AmountLeft: 4096,
+	}
+	justFinished := downloadclient.Torrent{
+		Hash:        "justfinished",
+		Name:        "Just Finished Book",
+		SavePath:    "/remote",
+		State:       downloadclient.StateSeeding,
+		Progress:    1,
+		SeedingTime: 60,
+	}
+
+	mockClient := &testutil.MockDownloadClient{
+		GetTorrentsReturn: struct {
+			Torrents []downloadclient.Torrent
+			Err      error
+		}{
+			Torrents: []downloadclient.Torrent{completed, downloading, justFinished},
+		},
+		GetFilesReturn: struct {
+			Files []downloadclient.TorrentFile
+			Err   error
+		}{
+			Files: []downloadclient.TorrentFile{
+				{Name: "book.epub"},
+			},
+		},
+	}
+
+	importer := NewBookImporterSystem(mockClient, nil)
+
+	route := common.Route{
+		Category: models.TorrentCategory{Name: "books", MediaType: common.MediaTypeEbook},
+		Importer: importer,
+		Library:  models.Library{Name: "test-library", Path: destDir},
+		ImportType: config.ImportType{
+			Category:           "books",
+			Library:            "test-library",
+			MinSeedTimeMinutes: 60,
+		},
+	}
+
+	summary := common.NewRunSummary("ebook")
+
+	router := common.NewRouter(nil, importer)
+	require.NoError(t, router.ImportRoutes(ctx, mockClient, []common.Route{route}, summary))
+
+	// Only the completed torrent that has seeded long enough is imported
+	require.Len(t, mockClient.AddTagsCalls, 1)
+	assert.Equal(t, []string{"completed"}, mockClient.AddTagsCalls[0].Hashes)
+	assert.Equal(t, []string{"imported"}, mockClient.AddTagsCalls[0].Tags)
+
+	assert.Equal(t, 1, summary.Ready)
+	require.Len(t, summary.Skipped, 2)
+	assert.Equal(t, "downloading", summary.Skipped[0].Torrent.Hash)
+	assert.Equal(t, downloadclient.SkipReasonDownloading, summary.Skipped[0].Reason)
+	assert.Equal(t, "justfinished", summary.Skipped[1].Torrent.Hash)
+	assert.Equal(t, downloadclient.SkipReasonSeeding, summary.Skipped[1].Reason)
 }
@@ -0,0 +1,151 @@
+package ebooks
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/calibre"
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/testutil"
+)
+
+// fakeCalibre hands out increasing book IDs and records the files it was given.
+type fakeCalibre struct {
+	added  []string
+	nextID int
+	err    error
+}
+
+func (f *fakeCalibre) AddBook(ctx context.Context, path string) (int, bool, error) {
+	if f.err != nil {
+		return 0, false, f.err
+	}
+
+	f.added = append(f.added, path)
+	f.nextID++
+	return f.nextID, true, nil
+}
+
+func TestImportTorrent_Calibre(t *testing.T) {
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	destDir := filepath.Join(tempDir, "dest")
+
+	require.NoError(t, os.MkdirAll(sourceDir, 0755))
+	for _, name := range []string{"dune.epub", "emma.epub"} {
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644))
+	}
+
+	config.Config.Qbit = config.QbitConfig{
+		DownloadPath:      "/remote",
+		LocalDownloadPath: sourceDir,
+	}
+	config.Config.Importers.ImportedTag = "imported"
+	config.Config.Importers.ManualInterventionTag = "manual"
+	config.Config.Importers.MaxAttempts = 5
+
+	torrent := downloadclient.Torrent{Hash: "calibre", Name: "Two Books", SavePath: "/remote"}
+	library := &config.ImportLibrary{Name: "test-library", Path: destDir}
+	importType := config.ImportType{Category: "books", Library: "test-library", CalibreLibrary: "/calibre/library"}
+
+	newImporter := func(t *testing.T, fake *fakeCalibre) (*BookImporterSystem, *testutil.MockDownloadClient) {
+		db, err := models.ConnectTestDB()
+		require.NoError(t, err)
+
+		mockClient := &testutil.MockDownloadClient{
+			GetFilesReturn: struct {
+				Files []downloadclient.TorrentFile
+				Err   error
+			}{
+				Files: []downloadclient.TorrentFile{{Name: "dune.epub"}, {Name: "emma.epub"}},
+			},
+		}
+
+		importer := NewBookImporterSystem(mockClient, db)
+		importer.newCalibreClient = func(location string) (calibre.Client, error) {
+			assert.Equal(t, "/calibre/library", location)
+			return fake, nil
+		}
+
+		return importer, mockClient
+	}
+
+	t.Run("records book ids", func(t *testing.T) {
+		fake := &fakeCalibre{}
+		importer, mockClient := newImporter(t, fake)
+
+		importer.ImportTorrent(ctx, torrent, importType, library)
+
+		assert.Equal(t, []string{filepath.Join(destDir, "dune.epub"), filepath.Join(destDir, "emma.epub")}, fake.added)
+		assert.Len(t, mockClient.AddTagsCalls, 1)
+
+		var items []models.ImportedItem
+		require.NoError(t, importer.db.Order("path").Find(&items).Error)
+		require.Len(t, items, 2)
+		require.NotNil(t, items[0].CalibreBookID)
+		assert.Equal(t, 1, *items[0].CalibreBookID)
+		require.NotNil(t, items[1].CalibreBookID)
+		assert.Equal(t, 2, *items[1].CalibreBookID)
+	})
+
+	t.Run("failure is retried without adding books twice", func(t *testing.T) {
+		fake := &fakeCalibre{}
+		importer, mockClient := newImporter(t, fake)
+
+		// An earlier attempt added dune but failed before recording emma
+		importer.ImportTorrent(ctx, torrent, importType, library)
+		require.NoError(t, importer.db.Model(&models.ImportedItem{}).
+			Where("path = ?", filepath.Join(destDir, "emma.epub")).
+			Update("calibre_book_id", nil).Error)
+		require.NoError(t, importer.db.Where("1 = 1").Delete(&models.ImportJob{}).Error)
+		fake.added = nil
+
+		importer.ImportTorrent(ctx, torrent, importType, library)
+		assert.Equal(t, []string{filepath.Join(destDir, "emma.epub")}, fake.added)
+		assert.Len(t, mockClient.AddTagsCalls, 2)
+	})
+
+	t.Run("calibre errors schedule a retry", func(t *testing.T) {
+		fake := &fakeCalibre{err: errors.New("connection refused")}
+		importer, mockClient := newImporter(t, fake)
+
+		importer.ImportTorrent(ctx, torrent, importType, library)
+
+		assert.Empty(t, mockClient.AddTagsCalls)
+
+		var job models.ImportJob
+		require.NoError(t, importer.db.Where("torrent_hash = ?", "calibre").First(&job).Error)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Contains(t, job.LastError, "connection refused")
+	})
+}
+
+func TestImportNotification_CalibreLinks(t *testing.T) {
+	torrent := downloadclient.Torrent{Hash: "abc", Name: "Two Books"}
+	library := &config.ImportLibrary{Name: "books", Path: "/books"}
+	importType := config.ImportType{
+		Category:          "books",
+		CalibreWebURL:     "https://calibre.example.com/",
+		CalibreDesktopURL: "https://calibre-desktop.example.com/",
+	}
+
+	message := importNotification(torrent, library, []importedBook{
+		{Name: "dune.epub", CalibreBookID: 12},
+		{Name: "emma.epub"},
+	}, importType)
+
+	require.Len(t, message.Embeds, 1)
+	fields := message.Embeds[0].Fields
+	assert.Equal(t, "• [dune.epub](https://calibre.example.com/book/12)\n• emma.epub", fields[0].Value)
+	assert.Equal(t, "https://calibre-desktop.example.com/", fields[len(fields)-1].Value)
+}
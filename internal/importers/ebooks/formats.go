@@ -0,0 +1,146 @@
+package ebooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+)
+
+const defaultConverterTimeout = 5 * time.Minute
+
+// plannedBook is one file the importer writes to the library: a copy of Source,
+// or Source converted to ConvertTo when that is set.
+type plannedBook struct {
+	Source    common.MappedTorrentFile
+	ConvertTo string
+}
+
+// DestName is the file name the book gets in the library. Only the base name
+// is used, which flattens the torrent's directory structure.
+func (pb plannedBook) DestName() string {
+	name := filepath.Base(pb.Source.BaseName)
+	if pb.ConvertTo == "" {
+		return name
+	}
+
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "." + pb.ConvertTo
+}
+
+// ebookFormat is the lower-case extension of an ebook file without the dot.
+func ebookFormat(filePath string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(filePath)), ".")
+}
+
+// planBooks picks the files to import. Without preferred formats every file is
+// copied. Otherwise files with the same name but different extensions are one
+// book, and only its best format is copied; formats missing from preferred
+// rank after the listed ones. When canConvert is set and a book lacks the first
+// preferred format, its best file is converted to that format instead.
+func planBooks(books []common.MappedTorrentFile, preferred []string, canConvert bool) []plannedBook {
+	planned := make([]plannedBook, 0, len(books))
+
+	if len(preferred) == 0 {
+		for _, book := range books {
+			planned = append(planned, plannedBook{Source: book})
+		}
+		return planned
+	}
+
+	rank := func(book common.MappedTorrentFile) int {
+		format := ebookFormat(book.BaseName)
+		for i, preferredFormat := range preferred {
+			if format == preferredFormat {
+				return i
+			}
+		}
+		return len(preferred)
+	}
+
+	// Group by path without extension, keeping the torrent's file order
+	var order []string
+	best := map[string]common.MappedTorrentFile{}
+
+	for _, book := range books {
+		key := strings.ToLower(strings.TrimSuffix(book.BaseName, filepath.Ext(book.BaseName)))
+
+		current, ok := best[key]
+		if !ok {
+			order = append(order, key)
+		}
+		if !ok || rank(book) < rank(current) {
+			best[key] = book
+		}
+	}
+
+	for _, key := range order {
+		book := plannedBook{Source: best[key]}
+		if canConvert && rank(book.Source) != 0 {
+			book.ConvertTo = preferred[0]
+		}
+		planned = append(planned, book)
+	}
+
+	return planned
+}
+
+// Converter runs the configured ebook conversion command.
+type Converter struct {
+	Command string
+	Args    []string
+	Timeout time.Duration
+}
+
+// NewConverter returns the converter for cfg, or nil when no command is
+// configured.
+func NewConverter(cfg config.EbookConverter) *Converter {
+	if cfg.Command == "" {
+		return nil
+	}
+
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = defaultConverterTimeout
+	}
+
+	return &Converter{
+		Command: cfg.Command,
+		Args:    cfg.Args,
+		Timeout: timeout,
+	}
+}
+
+// Convert writes inputPath converted to the format of outputPath's extension.
+// A partial output is removed when the command fails.
+func (c *Converter) Convert(ctx context.Context, inputPath string, outputPath string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+
+	args := append([]string{inputPath, outputPath}, c.Args...)
+	output, err := exec.CommandContext(ctx, c.Command, args...).CombinedOutput()
+	if err != nil {
+		_ = os.Remove(outputPath)
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("%s timed out after %s", c.Command, c.Timeout)
+		}
+		return fmt.Errorf("%s failed: %w: %s", c.Command, err, strings.TrimSpace(string(output)))
+	}
+
+	if _, err := os.Stat(outputPath); err != nil {
+		return fmt.Errorf("%s did not write %s: %w", c.Command, outputPath, err)
+	}
+
+	return nil
+}
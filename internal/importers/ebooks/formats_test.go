@@ -0,0 +1,187 @@
+package ebooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/testutil"
+)
+
+// writeStubConverter writes a shell script standing in for ebook-convert.
+func writeStubConverter(t *testing.T, script string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "ebook-convert")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755))
+	return path
+}
+
+func TestPlanBooks(t *testing.T) {
+	files := func(names ...string) []common.MappedTorrentFile {
+		mapped := make([]common.MappedTorrentFile, len(names))
+		for i, name := range names {
+			mapped[i] = common.MappedTorrentFile{BaseName: name, LocalPath: "/downloads/" + name}
+		}
+		return mapped
+	}
+
+	destNames := func(planned []plannedBook) []string {
+		names := make([]string, len(planned))
+		for i, book := range planned {
+			names[i] = book.DestName()
+		}
+		return names
+	}
+
+	books := files("Dune/Dune.mobi", "Dune/Dune.EPUB", "Dune/Dune.azw3", "Emma/Emma.mobi")
+
+	t.Run("no preference imports every format", func(t *testing.T) {
+		assert.Equal(t, []string{"Dune.mobi", "Dune.EPUB", "Dune.azw3", "Emma.mobi"}, destNames(planBooks(books, nil, true)))
+	})
+
+	t.Run("best format of each book", func(t *testing.T) {
+		planned := planBooks(books, []string{"epub", "azw3"}, false)
+		assert.Equal(t, []string{"Dune.EPUB", "Emma.mobi"}, destNames(planned))
+		assert.Empty(t, planned[1].ConvertTo)
+	})
+
+	t.Run("converts books missing the first preference", func(t *testing.T) {
+		planned := planBooks(books, []string{"azw3", "epub"}, true)
+		assert.Equal(t, []string{"Dune.azw3", "Emma.azw3"}, destNames(planned))
+		assert.Empty(t, planned[0].ConvertTo)
+		assert.Equal(t, "/downloads/Emma/Emma.mobi", planned[1].Source.LocalPath)
+	})
+
+	t.Run("same name in different directories are different books", func(t *testing.T) {
+		planned := planBooks(files("a/book.epub", "b/book.mobi"), []string{"epub"}, false)
+		assert.Len(t, planned, 2)
+	})
+}
+
+func TestConverter(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "book.mobi")
+	require.NoError(t, os.WriteFile(input, []byte("mobi content"), 0644))
+
+	t.Run("passes input, output and args", func(t *testing.T) {
+		command := writeStubConverter(t, `in="$1"; out="$2"; shift 2; { cat "$in"; echo " $*"; } > "$out"`)
+		converter := NewConverter(config.EbookConverter{Command: command, Args: []string{"--no-default-epub-cover"}})
+
+		output := filepath.Join(dir, "library", "book.epub")
+		require.NoError(t, converter.Convert(ctx, input, output))
+
+		content, err := os.ReadFile(output)
+		require.NoError(t, err)
+		assert.Equal(t, "mobi content --no-default-epub-cover\n", string(content))
+	})
+
+	t.Run("failure removes partial output", func(t *testing.T) {
+		command := writeStubConverter(t, `echo partial > "$2"; echo "unsupported input" >&2; exit 1`)
+		converter := NewConverter(config.EbookConverter{Command: command})
+
+		output := filepath.Join(dir, "failed.epub")
+		err := converter.Convert(ctx, input, output)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported input")
+		assert.NoFileExists(t, output)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		converter := &Converter{Command: writeStubConverter(t, "exec sleep 5"), Timeout: 50 * time.Millisecond}
+
+		err := converter.Convert(ctx, input, filepath.Join(dir, "slow.epub"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out")
+	})
+
+	t.Run("disabled without a command", func(t *testing.T) {
+		assert.Nil(t, NewConverter(config.EbookConverter{}))
+	})
+}
+
+func TestImportTorrent_PreferredFormats(t *testing.T) {
+	ctx := context.Background()
+
+	tempDir := t.TempDir()
+	sourceDir := filepath.Join(tempDir, "source")
+	destDir := filepath.Join(tempDir, "dest")
+
+	require.NoError(t, os.MkdirAll(sourceDir, 0755))
+	for _, name := range []string{"dune.epub", "dune.mobi", "emma.mobi"} {
+		require.NoError(t, os.WriteFile(filepath.Join(sourceDir, name), []byte(name), 0644))
+	}
+
+	config.Config.Qbit = config.QbitConfig{
+		DownloadPath:      "/remote",
+		LocalDownloadPath: sourceDir,
+	}
+	config.Config.Importers.ImportedTag = "imported"
+	config.Config.Importers.ManualInterventionTag = "manual"
+
+	torrent := downloadclient.Torrent{Hash: "formats", Name: "Two Books", SavePath: "/remote"}
+	library := &config.ImportLibrary{Name: "test-library", Path: destDir, PreferredFormats: []string{"epub", "mobi"}}
+	importType := config.ImportType{Category: "books", Library: "test-library"}
+
+	newMockClient := func() *testutil.MockDownloadClient {
+		return &testutil.MockDownloadClient{
+			GetFilesReturn: struct {
+				Files []downloadclient.TorrentFile
+				Err   error
+			}{
+				Files: []downloadclient.TorrentFile{{Name: "dune.epub"}, {Name: "dune.mobi"}, {Name: "emma.mobi"}},
+			},
+		}
+	}
+
+	t.Run("without a converter", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(destDir))
+		mockClient := newMockClient()
+
+		importer := NewBookImporterSystem(mockClient, nil)
+		importer.converter = nil
+		importer.ImportTorrent(ctx, torrent, importType, library)
+
+		assert.FileExists(t, filepath.Join(destDir, "dune.epub"))
+		assert.NoFileExists(t, filepath.Join(destDir, "dune.mobi"))
+		assert.FileExists(t, filepath.Join(destDir, "emma.mobi"))
+		assert.Len(t, mockClient.AddTagsCalls, 1)
+	})
+
+	t.Run("with a converter", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(destDir))
+		mockClient := newMockClient()
+
+		importer := NewBookImporterSystem(mockClient, nil)
+		importer.converter = NewConverter(config.EbookConverter{Command: writeStubConverter(t, `{ echo converted; cat "$1"; } > "$2"`)})
+		importer.ImportTorrent(ctx, torrent, importType, library)
+
+		content, err := os.ReadFile(filepath.Join(destDir, "emma.epub"))
+		require.NoError(t, err)
+		assert.Equal(t, "converted\nemma.mobi", string(content))
+		assert.NoFileExists(t, filepath.Join(destDir, "emma.mobi"))
+		assert.FileExists(t, filepath.Join(destDir, "dune.epub"))
+	})
+
+	t.Run("failed conversion imports the original", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(destDir))
+		mockClient := newMockClient()
+
+		importer := NewBookImporterSystem(mockClient, nil)
+		importer.converter = NewConverter(config.EbookConverter{Command: writeStubConverter(t, "exit 1")})
+		importer.ImportTorrent(ctx, torrent, importType, library)
+
+		assert.FileExists(t, filepath.Join(destDir, "emma.mobi"))
+		assert.NoFileExists(t, filepath.Join(destDir, "emma.epub"))
+		assert.Len(t, mockClient.AddTagsCalls, 1)
+	})
+}
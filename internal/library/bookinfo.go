@@ -0,0 +1,219 @@
+package library
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/source"
+)
+
+// Where a book's title and authors were read from
+const (
+	SourceOpf      = "opf"
+	SourceEpub     = "epub"
+	SourceTags     = "tags"
+	SourceFilename = "filename"
+)
+
+const epubContainerPath = "META-INF/container.xml"
+
+// bookInfo is what the scanner could read about a book on disk.
+type bookInfo struct {
+	Title   string
+	Authors []string
+	Source  string
+}
+
+type opfPackage struct {
+	Metadata struct {
+		Titles   []string     `xml:"http://purl.org/dc/elements/1.1/ title"`
+		Creators []opfCreator `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	} `xml:"metadata"`
+}
+
+type opfCreator struct {
+	Name string `xml:",chardata"`
+	Role string `xml:"http://www.idpf.org/2007/opf role,attr"`
+}
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+// parseOpf reads the title and authors from an OPF package document. Creators
+// without a role are treated as authors, as EPUB 3 moves roles out of the
+// creator element.
+func parseOpf(r io.Reader) (bookInfo, error) {
+	var pkg opfPackage
+	if err := xml.NewDecoder(r).Decode(&pkg); err != nil {
+		return bookInfo{}, fmt.Errorf("failed to parse opf: %w", err)
+	}
+
+	info := bookInfo{Source: SourceOpf}
+
+	for _, title := range pkg.Metadata.Titles {
+		if title = strings.TrimSpace(title); title != "" {
+			info.Title = title
+			break
+		}
+	}
+
+	for _, creator := range pkg.Metadata.Creators {
+		name := strings.TrimSpace(creator.Name)
+		if name != "" && (creator.Role == "" || creator.Role == "aut") {
+			info.Authors = append(info.Authors, name)
+		}
+	}
+
+	if info.Title == "" {
+		return info, errors.New("opf has no title")
+	}
+
+	return info, nil
+}
+
+func readOpfFile(opfPath string) (bookInfo, error) {
+	file, err := os.Open(opfPath)
+	if err != nil {
+		return bookInfo{}, err
+	}
+	defer func() { _ = file.Close() }()
+
+	return parseOpf(file)
+}
+
+// readEpubMetadata reads the package document of an EPUB.
+func readEpubMetadata(epubPath string) (bookInfo, error) {
+	archive, err := zip.OpenReader(epubPath)
+	if err != nil {
+		return bookInfo{}, fmt.Errorf("failed to open epub: %w", err)
+	}
+	defer func() { _ = archive.Close() }()
+
+	var container epubContainer
+	if err := decodeZipXML(&archive.Reader, epubContainerPath, &container); err != nil {
+		return bookInfo{}, err
+	}
+
+	if len(container.Rootfiles) == 0 {
+		return bookInfo{}, errors.New("epub container has no rootfile")
+	}
+
+	rootfile, err := archive.Open(container.Rootfiles[0].FullPath)
+	if err != nil {
+		return bookInfo{}, fmt.Errorf("failed to open epub package: %w", err)
+	}
+	defer func() { _ = rootfile.Close() }()
+
+	info, err := parseOpf(rootfile)
+	info.Source = SourceEpub
+
+	return info, err
+}
+
+func decodeZipXML(archive *zip.Reader, name string, out any) error {
+	file, err := archive.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := xml.NewDecoder(file).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// readAudiobookInfo reads an audiobook directory's metadata.opf, falling back to
+// the tags of its first audio file, then to the directory name.
+func readAudiobookInfo(ctx context.Context, tags metadata.MetadataProvider, bookPath string) bookInfo {
+	info, err := readOpfFile(filepath.Join(bookPath, opfFileName))
+	if err == nil {
+		return info
+	}
+
+	if tags != nil {
+		if audioFile, ok := firstAudioFile(bookPath); ok {
+			fileTags, err := tags.GetMetadata(ctx, audioFile)
+			if err == nil {
+				title, _ := fileTags.Title()
+				artist, _ := fileTags.Artist()
+
+				if title != "" {
+					return bookInfo{Title: title, Authors: splitPeople(artist), Source: SourceTags}
+				}
+			}
+		}
+	}
+
+	return bookInfo{Title: titleFromName(filepath.Base(bookPath)), Source: SourceFilename}
+}
+
+// readEbookInfo reads an ebook's embedded metadata, falling back to the file name.
+func readEbookInfo(bookPath string) bookInfo {
+	if strings.EqualFold(filepath.Ext(bookPath), ".epub") {
+		info, err := readEpubMetadata(bookPath)
+		if err == nil {
+			return info
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(bookPath), filepath.Ext(bookPath))
+
+	return bookInfo{Title: titleFromName(name), Source: SourceFilename}
+}
+
+func firstAudioFile(dirPath string) (string, bool) {
+	var files []string
+
+	_ = filepath.WalkDir(dirPath, func(filePath string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && source.IsAudioFile(filePath) {
+			files = append(files, filePath)
+		}
+		return nil
+	})
+
+	if len(files) == 0 {
+		return "", false
+	}
+
+	return slices.Min(files), true
+}
+
+// titleFromName strips any " - Series - Book N" suffix the audiobook importer
+// adds to directory names.
+func titleFromName(name string) string {
+	if before, _, found := strings.Cut(name, " - "); found {
+		name = before
+	}
+
+	return strings.TrimSpace(name)
+}
+
+// splitPeople splits a tag value holding several names.
+func splitPeople(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '&' || r == ';' || r == '/'
+	})
+
+	people := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if name := strings.TrimSpace(field); name != "" {
+			people = append(people, name)
+		}
+	}
+
+	return people
+}
@@ -0,0 +1,356 @@
+package library
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+const holdingAuthorSeparator = "; "
+
+var parenthetical = regexp.MustCompile(`[\(\[][^\)\]]*[\)\]]`)
+
+// ScanResult counts what a scan of one library found.
+type ScanResult struct {
+	LibraryID uint
+	Library   string
+	Found     int // books on disk
+	Read      int // books whose files were read because they are new or changed
+	Matched   int // books linked to a catalog Book
+	Satisfied int // acquisition targets newly marked satisfied
+	Removed   int // holdings deleted because the book is gone
+}
+
+// Scanner finds the books already in the libraries, links them to the catalog
+// and marks their acquisition targets satisfied.
+type Scanner struct {
+	db   *gorm.DB
+	tags metadata.MetadataProvider
+
+	// Full re-reads every book instead of only new and changed ones
+	Full bool
+}
+
+// NewScanner creates a Scanner. tags reads audio file tags for audiobooks without
+// a metadata.opf and may be nil to skip them.
+func NewScanner(db *gorm.DB, tags metadata.MetadataProvider) *Scanner {
+	return &Scanner{
+		db:   db,
+		tags: tags,
+	}
+}
+
+// ScanLibraries scans every library in the database.
+func (s *Scanner) ScanLibraries(ctx context.Context) ([]ScanResult, error) {
+	var libraries []models.Library
+	if err := s.db.Preload("BookType").Order("name").Find(&libraries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load libraries: %w", err)
+	}
+
+	results := make([]ScanResult, 0, len(libraries))
+
+	for _, library := range libraries {
+		result, err := s.ScanLibrary(ctx, library)
+		if err != nil {
+			return results, fmt.Errorf("failed to scan library %s: %w", library.Name, err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// ScanLibrary scans one library. The library's BookType must be loaded. Books
+// whose fingerprint is unchanged since the last scan are not read again, but
+// unmatched ones are matched again in case the catalog has grown.
+func (s *Scanner) ScanLibrary(ctx context.Context, library models.Library) (ScanResult, error) {
+	result := ScanResult{LibraryID: library.ID, Library: library.Name}
+	audiobookLibrary := library.BookType.Name == "audiobook"
+
+	slog.InfoContext(ctx, "Scanning library",
+		slog.String("library", library.Name),
+		slog.String("path", library.Path),
+		slog.Bool("full", s.Full))
+
+	var onDisk []string
+	var err error
+	if audiobookLibrary {
+		onDisk, err = findAudiobookDirectories(library.Path, nil)
+	} else {
+		onDisk, err = findEbookFiles(library.Path)
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to walk library: %w", err)
+	}
+
+	result.Found = len(onDisk)
+
+	var holdings []models.LibraryHolding
+	if err := s.db.Where("library_id = ?", library.ID).Find(&holdings).Error; err != nil {
+		return result, fmt.Errorf("failed to load holdings: %w", err)
+	}
+
+	existing := make(map[string]models.LibraryHolding, len(holdings))
+	for _, holding := range holdings {
+		existing[holding.Path] = holding
+	}
+
+	matcher, err := newCatalogMatcher(s.db)
+	if err != nil {
+		return result, err
+	}
+
+	for _, bookPath := range onDisk {
+		holding, ok := existing[bookPath]
+		delete(existing, bookPath)
+
+		fingerprint, err := fingerprintBook(bookPath)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to fingerprint book", slog.String("path", bookPath), slog.Any("err", err))
+			continue
+		}
+
+		if !ok || s.Full || holding.Fingerprint != fingerprint {
+			var info bookInfo
+			if audiobookLibrary {
+				info = readAudiobookInfo(ctx, s.tags, bookPath)
+			} else {
+				info = readEbookInfo(bookPath)
+			}
+
+			holding.LibraryID = library.ID
+			holding.Path = bookPath
+			holding.Title = info.Title
+			holding.Authors = strings.Join(info.Authors, holdingAuthorSeparator)
+			holding.Source = info.Source
+			holding.Fingerprint = fingerprint
+			holding.BookID = nil
+
+			result.Read++
+		}
+
+		if holding.BookID == nil {
+			book, err := matcher.match(holding.Title, holdingAuthors(holding))
+			if err != nil {
+				return result, err
+			}
+
+			if book != nil {
+				holding.BookID = &book.ID
+			}
+		}
+
+		holding.ScannedAt = time.Now()
+
+		if err := s.db.Save(&holding).Error; err != nil {
+			return result, fmt.Errorf("failed to save holding %s: %w", bookPath, err)
+		}
+
+		if holding.BookID == nil {
+			continue
+		}
+
+		result.Matched++
+
+		satisfied, err := satisfyTarget(s.db, *holding.BookID, library.BookTypeID)
+		if err != nil {
+			return result, err
+		}
+
+		result.Satisfied += satisfied
+	}
+
+	// Whatever was not seen on disk is gone
+	for _, holding := range existing {
+		if err := s.db.Delete(&holding).Error; err != nil {
+			return result, fmt.Errorf("failed to delete holding %s: %w", holding.Path, err)
+		}
+
+		result.Removed++
+	}
+
+	slog.InfoContext(ctx, "Scanned library",
+		slog.String("library", library.Name),
+		slog.Int("found", result.Found),
+		slog.Int("read", result.Read),
+		slog.Int("matched", result.Matched),
+		slog.Int("satisfied", result.Satisfied),
+		slog.Int("removed", result.Removed))
+
+	return result, nil
+}
+
+func holdingAuthors(holding models.LibraryHolding) []string {
+	if holding.Authors == "" {
+		return nil
+	}
+
+	return strings.Split(holding.Authors, holdingAuthorSeparator)
+}
+
+// satisfyTarget marks the unsatisfied acquisition target for a book and media
+// type satisfied. Books that are not wanted have no target and are left alone.
+func satisfyTarget(db *gorm.DB, bookID uint, bookTypeID uint) (int, error) {
+	res := db.Model(&models.AcquisitionTarget{}).
+		Where("book_id = ? AND book_type_id = ? AND satisfied = ?", bookID, bookTypeID, false).
+		Update("satisfied", true)
+	if res.Error != nil {
+		return 0, fmt.Errorf("failed to satisfy acquisition target for book %d: %w", bookID, res.Error)
+	}
+
+	return int(res.RowsAffected), nil
+}
+
+// fingerprintBook summarizes the size and modification time of a book's files.
+// For an audiobook directory this covers the directory itself, which changes when
+// files are added or removed, its metadata.opf and its first audio file.
+func fingerprintBook(bookPath string) (string, error) {
+	info, err := os.Stat(bookPath)
+	if err != nil {
+		return "", err
+	}
+
+	parts := []string{stamp(info)}
+
+	if info.IsDir() {
+		related := []string{filepath.Join(bookPath, opfFileName)}
+		if audioFile, ok := firstAudioFile(bookPath); ok {
+			related = append(related, audioFile)
+		}
+
+		for _, relatedPath := range related {
+			if relatedInfo, err := os.Stat(relatedPath); err == nil {
+				parts = append(parts, stamp(relatedInfo))
+			}
+		}
+	}
+
+	return strings.Join(parts, ","), nil
+}
+
+func stamp(info os.FileInfo) string {
+	return fmt.Sprintf("%d:%d", info.Size(), info.ModTime().UnixNano())
+}
+
+// catalogMatcher links titles and author names to catalog Books.
+type catalogMatcher struct {
+	db          *gorm.DB
+	authorIDs   map[string]uint
+	authorBooks map[uint][]models.Book
+}
+
+// newCatalogMatcher loads every author name and alias.
+func newCatalogMatcher(db *gorm.DB) (*catalogMatcher, error) {
+	matcher := &catalogMatcher{
+		db:          db,
+		authorIDs:   make(map[string]uint),
+		authorBooks: make(map[uint][]models.Book),
+	}
+
+	var authors []models.Author
+	if err := db.Find(&authors).Error; err != nil {
+		return nil, fmt.Errorf("failed to load authors: %w", err)
+	}
+
+	for _, author := range authors {
+		matcher.authorIDs[normalizeName(author.Name)] = author.ID
+	}
+
+	var aliases []models.AuthorAlias
+	if err := db.Find(&aliases).Error; err != nil {
+		return nil, fmt.Errorf("failed to load author aliases: %w", err)
+	}
+
+	for _, alias := range aliases {
+		matcher.authorIDs[normalizeName(alias.Name)] = alias.AuthorID
+	}
+
+	return matcher, nil
+}
+
+// match returns the catalog Book by one of the authors with the same title, or
+// nil when there is none.
+func (m *catalogMatcher) match(title string, authors []string) (*models.Book, error) {
+	wanted := normalizeTitle(title)
+	if wanted == "" {
+		return nil, nil
+	}
+
+	for _, name := range authors {
+		authorID, ok := m.authorIDs[normalizeName(name)]
+		if !ok {
+			continue
+		}
+
+		books, err := m.booksByAuthor(authorID)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range books {
+			if normalizeTitle(books[i].Title) == wanted {
+				return &books[i], nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *catalogMatcher) booksByAuthor(authorID uint) ([]models.Book, error) {
+	if books, ok := m.authorBooks[authorID]; ok {
+		return books, nil
+	}
+
+	var books []models.Book
+	err := m.db.
+		Joins("JOIN book_authors ON book_authors.book_id = books.id").
+		Where("book_authors.author_id = ?", authorID).
+		Find(&books).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load books for author %d: %w", authorID, err)
+	}
+
+	m.authorBooks[authorID] = books
+
+	return books, nil
+}
+
+// normalizeName keeps only the letters and digits of a name, so "J.R.R. Tolkien"
+// and "J. R. R. Tolkien" compare equal.
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// normalizeTitle drops subtitles and parenthesized qualifiers like "(Unabridged)"
+// and keeps only the words of a title.
+func normalizeTitle(title string) string {
+	title = parenthetical.ReplaceAllString(title, " ")
+
+	if before, _, found := strings.Cut(title, ":"); found {
+		title = before
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,249 @@
+package library
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+const testOpf = `<?xml version='1.0' encoding='utf-8'?>
+<ns0:package xmlns:dc='http://purl.org/dc/elements/1.1/' xmlns:ns0='http://www.idpf.org/2007/opf' version='2.0'>
+  <ns0:metadata xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:opf="http://www.idpf.org/2007/opf">
+	<dc:title>%TITLE%</dc:title>
+	<dc:creator opf:role="aut">%AUTHOR%</dc:creator>
+	<dc:creator opf:role="nrt">Some Narrator</dc:creator>
+  </ns0:metadata>
+</ns0:package>
+`
+
+const testEpubContainer = `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+const testEpubPackage = `<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>%TITLE%</dc:title>
+    <dc:creator id="creator">%AUTHOR%</dc:creator>
+  </metadata>
+</package>
+`
+
+func fillTemplate(template, title, author string) string {
+	return strings.NewReplacer("%TITLE%", title, "%AUTHOR%", author).Replace(template)
+}
+
+func writeTestEpub(t *testing.T, epubPath, title, author string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(epubPath), 0755))
+
+	file, err := os.Create(epubPath)
+	require.NoError(t, err)
+
+	archive := zip.NewWriter(file)
+
+	for name, content := range map[string]string{
+		epubContainerPath:   testEpubContainer,
+		"OEBPS/content.opf": fillTemplate(testEpubPackage, title, author),
+	} {
+		w, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, archive.Close())
+	require.NoError(t, file.Close())
+}
+
+// stubTags returns the same title and artist for every file
+type stubTags struct {
+	title  string
+	artist string
+	calls  int
+}
+
+func (s *stubTags) GetMetadata(ctx context.Context, path string) (metadata.MetadataTags, error) {
+	s.calls++
+	return s, nil
+}
+
+func (s *stubTags) Title() (string, bool)          { return s.title, s.title != "" }
+func (s *stubTags) Artist() (string, bool)         { return s.artist, s.artist != "" }
+func (s *stubTags) AudibleASIN() (string, bool)    { return "", false }
+func (s *stubTags) Narrator() (string, bool)       { return "", false }
+func (s *stubTags) SeriesPosition() (string, bool) { return "", false }
+
+func createTestBook(t *testing.T, db *gorm.DB, title string, author *models.Author, wantedBookType string) models.Book {
+	t.Helper()
+
+	book := models.Book{Title: title, Authors: []models.Author{*author}}
+	require.NoError(t, db.Create(&book).Error)
+
+	if wantedBookType != "" {
+		var bt models.BookType
+		require.NoError(t, db.Where("name = ?", wantedBookType).First(&bt).Error)
+		require.NoError(t, db.Create(&models.AcquisitionTarget{BookID: book.ID, BookTypeID: bt.ID}).Error)
+	}
+
+	return book
+}
+
+func targetSatisfied(t *testing.T, db *gorm.DB, bookID uint) bool {
+	t.Helper()
+
+	var target models.AcquisitionTarget
+	require.NoError(t, db.Where("book_id = ?", bookID).First(&target).Error)
+	return target.Satisfied
+}
+
+func TestParseOpf(t *testing.T) {
+	info, err := parseOpf(strings.NewReader(fillTemplate(testOpf, "Dune", "Frank Herbert")))
+	require.NoError(t, err)
+	assert.Equal(t, "Dune", info.Title)
+	assert.Equal(t, []string{"Frank Herbert"}, info.Authors)
+
+	_, err = parseOpf(strings.NewReader("not xml"))
+	assert.Error(t, err)
+}
+
+func TestNormalizeTitle(t *testing.T) {
+	assert.Equal(t, "the way of kings", normalizeTitle("The Way of Kings (Unabridged)"))
+	assert.Equal(t, "dune", normalizeTitle("Dune: Deluxe Edition"))
+	assert.Equal(t, normalizeName("J.R.R. Tolkien"), normalizeName("J. R. R. Tolkien"))
+}
+
+func TestScanLibrary_Audiobooks(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	library := createTestLibrary(t, db, "audiobooks", "audiobook")
+
+	herbert := models.Author{Name: "Frank Herbert"}
+	require.NoError(t, db.Create(&herbert).Error)
+	weir := models.Author{Name: "Andrew Weir"}
+	require.NoError(t, db.Create(&weir).Error)
+	require.NoError(t, db.Create(&models.AuthorAlias{AuthorID: weir.ID, Name: "Andy Weir"}).Error)
+
+	dune := createTestBook(t, db, "Dune", &herbert, "audiobook")
+	hailMary := createTestBook(t, db, "Project Hail Mary", &weir, "audiobook")
+	messiah := createTestBook(t, db, "Dune Messiah", &herbert, "audiobook")
+
+	// Matched through its metadata.opf
+	duneDir := filepath.Join(library.Path, "Dune")
+	writeTestFile(t, filepath.Join(duneDir, "book.m4b"))
+	require.NoError(t, os.WriteFile(filepath.Join(duneDir, opfFileName), []byte(fillTemplate(testOpf, "Dune", "Frank Herbert")), 0644))
+
+	// Matched through audio tags and an author alias
+	hailMaryDir := filepath.Join(library.Path, "Project Hail Mary")
+	writeTestFile(t, filepath.Join(hailMaryDir, "part1.mp3"))
+
+	// Not in the catalog
+	strayDir := filepath.Join(library.Path, "Stray Book")
+	writeTestFile(t, filepath.Join(strayDir, "book.m4b"))
+	require.NoError(t, os.WriteFile(filepath.Join(strayDir, opfFileName), []byte(fillTemplate(testOpf, "Stray Book", "Nobody")), 0644))
+
+	tags := &stubTags{title: "Project Hail Mary (Unabridged)", artist: "Andy Weir"}
+	scanner := NewScanner(db, tags)
+
+	result, err := scanner.ScanLibrary(ctx, library)
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Found)
+	assert.Equal(t, 3, result.Read)
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 2, result.Satisfied)
+	assert.Equal(t, 1, tags.calls)
+
+	assert.True(t, targetSatisfied(t, db, dune.ID))
+	assert.True(t, targetSatisfied(t, db, hailMary.ID))
+	assert.False(t, targetSatisfied(t, db, messiah.ID))
+
+	var holding models.LibraryHolding
+	require.NoError(t, db.Where("path = ?", hailMaryDir).First(&holding).Error)
+	assert.Equal(t, library.ID, holding.LibraryID)
+	assert.Equal(t, SourceTags, holding.Source)
+	require.NotNil(t, holding.BookID)
+	assert.Equal(t, hailMary.ID, *holding.BookID)
+
+	// Unchanged books are not read again, and a book added to the catalog
+	// since the last scan is matched from the stored holding
+	nobody := models.Author{Name: "Nobody"}
+	require.NoError(t, db.Create(&nobody).Error)
+	stray := createTestBook(t, db, "Stray Book", &nobody, "")
+
+	result, err = scanner.ScanLibrary(ctx, library)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Read)
+	assert.Equal(t, 3, result.Matched)
+	assert.Equal(t, 0, result.Satisfied)
+	assert.Equal(t, 1, tags.calls)
+
+	holding = models.LibraryHolding{}
+	require.NoError(t, db.Where("path = ?", strayDir).First(&holding).Error)
+	require.NotNil(t, holding.BookID)
+	assert.Equal(t, stray.ID, *holding.BookID)
+
+	// Changed books are read again and deleted books are forgotten
+	future := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(duneDir, opfFileName), future, future))
+	require.NoError(t, os.RemoveAll(hailMaryDir))
+
+	result, err = scanner.ScanLibrary(ctx, library)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Found)
+	assert.Equal(t, 1, result.Read)
+	assert.Equal(t, 1, result.Removed)
+
+	// A full scan reads everything
+	scanner.Full = true
+
+	result, err = scanner.ScanLibrary(ctx, library)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Read)
+}
+
+func TestScanLibraries_Ebooks(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	library := createTestLibrary(t, db, "books", "ebook")
+
+	sanderson := models.Author{Name: "Brandon Sanderson"}
+	require.NoError(t, db.Create(&sanderson).Error)
+	book := createTestBook(t, db, "The Way of Kings", &sanderson, "ebook")
+
+	writeTestEpub(t, filepath.Join(library.Path, "kings.epub"), "The Way of Kings", "Brandon Sanderson")
+	writeTestFile(t, filepath.Join(library.Path, "Unknown Title - Someone.mobi"))
+
+	results, err := NewScanner(db, nil).ScanLibraries(ctx)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, 2, results[0].Found)
+	assert.Equal(t, 1, results[0].Matched)
+	assert.Equal(t, 1, results[0].Satisfied)
+	assert.True(t, targetSatisfied(t, db, book.ID))
+
+	var holding models.LibraryHolding
+	require.NoError(t, db.Where("path = ?", filepath.Join(library.Path, "Unknown Title - Someone.mobi")).First(&holding).Error)
+	assert.Equal(t, SourceFilename, holding.Source)
+	assert.Equal(t, "Unknown Title", holding.Title)
+	assert.Nil(t, holding.BookID)
+}
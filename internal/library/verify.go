@@ -0,0 +1,352 @@
+// Package library inspects the book libraries on disk. It sits above models and
+// the importers so the same checks are reusable by the doctor CLI and scheduled jobs.
+package library
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/source"
+	"github.com/bobbyrward/stronghold/internal/importers/ebooks"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+const opfFileName = "metadata.opf"
+
+// IssueKind identifies a kind of library problem.
+type IssueKind string
+
+const (
+	// IssueMissing is a recorded import that is no longer on disk
+	IssueMissing IssueKind = "missing"
+	// IssueMissingOpf is an audiobook directory without a metadata.opf
+	IssueMissingOpf IssueKind = "missing-opf"
+	// IssueDuplicate is a book imported to more than one path
+	IssueDuplicate IssueKind = "duplicate"
+	// IssueOrphan is a book on disk with no import record
+	IssueOrphan IssueKind = "orphan"
+)
+
+// Issue is a single problem found in a library.
+type Issue struct {
+	Kind     IssueKind
+	Path     string
+	Detail   string
+	Repaired bool
+}
+
+// Report is the result of verifying one library.
+type Report struct {
+	LibraryID uint
+	Library   string
+	Path      string
+	Books     int
+	Issues    []Issue
+}
+
+// VerifyOptions selects the repairs to make while verifying.
+type VerifyOptions struct {
+	// RegenerateOpf rewrites missing metadata.opf files from the recorded metadata
+	RegenerateOpf bool
+	// ForgetMissing deletes the import records of books no longer on disk
+	ForgetMissing bool
+	// AdoptOrphans records books found on disk that have no import record
+	AdoptOrphans bool
+}
+
+// VerifyLibraries verifies every library in the database.
+func VerifyLibraries(ctx context.Context, db *gorm.DB, opts VerifyOptions) ([]Report, error) {
+	var libraries []models.Library
+	if err := db.Preload("BookType").Order("name").Find(&libraries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load libraries: %w", err)
+	}
+
+	reports := make([]Report, 0, len(libraries))
+
+	for _, library := range libraries {
+		report, err := VerifyLibrary(ctx, db, library, opts)
+		if err != nil {
+			return reports, fmt.Errorf("failed to verify library %s: %w", library.Name, err)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+// VerifyLibrary walks a library and cross-references the books on disk with
+// the recorded imports. The library's BookType must be loaded.
+func VerifyLibrary(ctx context.Context, db *gorm.DB, library models.Library, opts VerifyOptions) (Report, error) {
+	report := Report{LibraryID: library.ID, Library: library.Name, Path: library.Path}
+	audiobookLibrary := library.BookType.Name == "audiobook"
+
+	slog.InfoContext(ctx, "Verifying library",
+		slog.String("library", library.Name),
+		slog.String("path", library.Path),
+		slog.String("bookType", library.BookType.Name))
+
+	items, err := recordedItems(db, library.Path)
+	if err != nil {
+		return report, err
+	}
+
+	recorded := make(map[string]models.ImportedItem, len(items))
+	for _, item := range items {
+		recorded[item.Path] = item
+	}
+
+	var onDisk []string
+	if audiobookLibrary {
+		onDisk, err = findAudiobookDirectories(library.Path, recorded)
+	} else {
+		onDisk, err = findEbookFiles(library.Path)
+	}
+	if err != nil {
+		return report, fmt.Errorf("failed to walk library: %w", err)
+	}
+
+	report.Books = len(onDisk)
+
+	present := make(map[string]bool, len(onDisk))
+	for _, bookPath := range onDisk {
+		present[bookPath] = true
+	}
+
+	// Recorded imports that are gone
+	for _, item := range items {
+		if present[item.Path] {
+			continue
+		}
+
+		issue := Issue{Kind: IssueMissing, Path: item.Path, Detail: item.Title}
+
+		if opts.ForgetMissing {
+			if err := db.Delete(&item).Error; err != nil {
+				issue.Detail = fmt.Sprintf("%s (failed to forget: %v)", item.Title, err)
+			} else {
+				issue.Repaired = true
+			}
+		}
+
+		report.Issues = append(report.Issues, issue)
+	}
+
+	// Books on disk without a record, and audiobooks without an OPF
+	for _, bookPath := range onDisk {
+		item, ok := recorded[bookPath]
+
+		if !ok {
+			issue := Issue{Kind: IssueOrphan, Path: bookPath}
+
+			if opts.AdoptOrphans {
+				item = models.ImportedItem{Path: bookPath, Title: filepath.Base(bookPath)}
+				if err := db.Create(&item).Error; err != nil {
+					issue.Detail = fmt.Sprintf("failed to adopt: %v", err)
+				} else {
+					issue.Repaired = true
+				}
+			}
+
+			report.Issues = append(report.Issues, issue)
+		}
+
+		if audiobookLibrary && !fileExists(filepath.Join(bookPath, opfFileName)) {
+			issue := Issue{Kind: IssueMissingOpf, Path: bookPath}
+
+			if opts.RegenerateOpf {
+				err := regenerateOpf(item, bookPath)
+				if err != nil {
+					issue.Detail = fmt.Sprintf("failed to regenerate: %v", err)
+				} else {
+					issue.Repaired = true
+				}
+			}
+
+			report.Issues = append(report.Issues, issue)
+		}
+	}
+
+	report.Issues = append(report.Issues, findDuplicates(items, present)...)
+
+	for _, issue := range report.Issues {
+		slog.InfoContext(ctx, "Library issue found",
+			slog.String("library", library.Name),
+			slog.String("kind", string(issue.Kind)),
+			slog.String("path", issue.Path),
+			slog.String("detail", issue.Detail),
+			slog.Bool("repaired", issue.Repaired))
+	}
+
+	return report, nil
+}
+
+// recordedItems returns the imported items inside libraryPath.
+func recordedItems(db *gorm.DB, libraryPath string) ([]models.ImportedItem, error) {
+	var items []models.ImportedItem
+	if err := db.Order("path").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to load imported items: %w", err)
+	}
+
+	prefix := filepath.Clean(libraryPath) + string(filepath.Separator)
+	inLibrary := make([]models.ImportedItem, 0, len(items))
+
+	for _, item := range items {
+		if strings.HasPrefix(item.Path, prefix) {
+			inLibrary = append(inLibrary, item)
+		}
+	}
+
+	return inLibrary, nil
+}
+
+// findAudiobookDirectories returns the book directories in an audiobook library.
+// A book directory is a recorded import, a directory with a metadata.opf, or a
+// directory directly containing audio files. Subdirectories of a book, such as
+// per-disc folders, are part of that book.
+func findAudiobookDirectories(libraryPath string, recorded map[string]models.ImportedItem) ([]string, error) {
+	var books []string
+
+	root := filepath.Clean(libraryPath)
+
+	err := filepath.WalkDir(root, func(dirPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() || dirPath == root {
+			return nil
+		}
+
+		entries, err := os.ReadDir(dirPath)
+		if err != nil {
+			return err
+		}
+
+		_, isRecorded := recorded[dirPath]
+
+		if isRecorded || containsBook(entries) {
+			books = append(books, dirPath)
+			return filepath.SkipDir
+		}
+
+		return nil
+	})
+
+	return books, err
+}
+
+func containsBook(entries []fs.DirEntry) bool {
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		if entry.Name() == opfFileName || source.IsAudioFile(entry.Name()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findEbookFiles returns the ebook files in an ebook library.
+func findEbookFiles(libraryPath string) ([]string, error) {
+	var books []string
+
+	err := filepath.WalkDir(filepath.Clean(libraryPath), func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() && ebooks.IsEbookFile(filePath) {
+			books = append(books, filePath)
+		}
+
+		return nil
+	})
+
+	return books, err
+}
+
+// findDuplicates reports books that were imported to more than one path that
+// still exists, matched by identifier or by title. Items from the same torrent
+// are one import, such as an ebook in several formats, so only the first of
+// them is grouped.
+func findDuplicates(items []models.ImportedItem, present map[string]bool) []Issue {
+	groups := make(map[string][]string)
+	torrents := make(map[string]bool)
+	var keys []string
+
+	for _, item := range items {
+		if !present[item.Path] {
+			continue
+		}
+
+		key := "title:" + strings.ToLower(strings.TrimSpace(item.Title))
+		if item.Identifier != "" {
+			key = "id:" + item.Identifier
+		}
+
+		if item.TorrentHash != "" {
+			torrentKey := key + "|" + item.TorrentHash
+			if torrents[torrentKey] {
+				continue
+			}
+			torrents[torrentKey] = true
+		}
+
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], item.Path)
+	}
+
+	var issues []Issue
+
+	for _, key := range keys {
+		paths := groups[key]
+		if len(paths) < 2 {
+			continue
+		}
+
+		for _, duplicatePath := range paths[1:] {
+			issues = append(issues, Issue{
+				Kind:   IssueDuplicate,
+				Path:   duplicatePath,
+				Detail: fmt.Sprintf("same book as %s", paths[0]),
+			})
+		}
+	}
+
+	return issues
+}
+
+// regenerateOpf writes metadata.opf from the metadata recorded at import time.
+func regenerateOpf(item models.ImportedItem, bookPath string) error {
+	if item.Metadata == nil {
+		return errors.New("no recorded metadata")
+	}
+
+	var bookMetadata metadata.BookMetadata
+	if err := json.Unmarshal([]byte(*item.Metadata), &bookMetadata); err != nil {
+		return fmt.Errorf("failed to unmarshal recorded metadata: %w", err)
+	}
+
+	return bookMetadata.WriteOpf(filepath.Join(bookPath, opfFileName))
+}
+
+func fileExists(filePath string) bool {
+	_, err := os.Stat(filePath)
+	return err == nil
+}
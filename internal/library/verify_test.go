@@ -0,0 +1,200 @@
+package library
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func createTestLibrary(t *testing.T, db *gorm.DB, name string, bookType string) models.Library {
+	t.Helper()
+
+	var bt models.BookType
+	require.NoError(t, db.Where("name = ?", bookType).First(&bt).Error)
+
+	library := models.Library{Name: name, Path: t.TempDir(), BookTypeID: bt.ID}
+	require.NoError(t, db.Create(&library).Error)
+	require.NoError(t, db.Preload("BookType").First(&library, library.ID).Error)
+
+	return library
+}
+
+func writeTestFile(t *testing.T, filePath string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+	require.NoError(t, os.WriteFile(filePath, []byte("test"), 0644))
+}
+
+func issuesByKind(report Report) map[IssueKind][]Issue {
+	byKind := make(map[IssueKind][]Issue)
+	for _, issue := range report.Issues {
+		byKind[issue.Kind] = append(byKind[issue.Kind], issue)
+	}
+	return byKind
+}
+
+func TestVerifyLibrary_Audiobooks(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	library := createTestLibrary(t, db, "audiobooks", "audiobook")
+
+	healthy := filepath.Join(library.Path, "Andy Weir", "Project Hail Mary")
+	writeTestFile(t, filepath.Join(healthy, "book.m4b"))
+	writeTestFile(t, filepath.Join(healthy, opfFileName))
+	common.RecordImportedItem(ctx, db, "hash1", healthy, "Project Hail Mary", "B08G9PRS1K", nil)
+
+	// Recorded, but the OPF was deleted
+	noOpf := filepath.Join(library.Path, "Frank Herbert", "Dune")
+	writeTestFile(t, filepath.Join(noOpf, "Disc 1", "part1.mp3"))
+	writeTestFile(t, filepath.Join(noOpf, "Disc 2", "part2.mp3"))
+	common.RecordImportedItem(ctx, db, "hash2", noOpf, "Dune", "B002V1OF70", metadata.BookMetadata{Asin: "B002V1OF70", Title: "Dune"})
+
+	// Same ASIN imported a second time under another directory
+	duplicate := filepath.Join(library.Path, "Frank Herbert", "Dune (2)")
+	writeTestFile(t, filepath.Join(duplicate, "book.m4b"))
+	writeTestFile(t, filepath.Join(duplicate, opfFileName))
+	common.RecordImportedItem(ctx, db, "hash3", duplicate, "Dune", "B002V1OF70", nil)
+
+	// Recorded, but deleted from disk
+	missing := filepath.Join(library.Path, "Gone", "Deleted Book")
+	common.RecordImportedItem(ctx, db, "hash4", missing, "Deleted Book", "B000000000", nil)
+
+	// On disk, but never recorded
+	orphan := filepath.Join(library.Path, "Unknown", "Stray Book")
+	writeTestFile(t, filepath.Join(orphan, "book.mp3"))
+
+	report, err := VerifyLibrary(ctx, db, library, VerifyOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Books)
+
+	byKind := issuesByKind(report)
+	require.Len(t, byKind[IssueMissing], 1)
+	assert.Equal(t, missing, byKind[IssueMissing][0].Path)
+
+	require.Len(t, byKind[IssueOrphan], 1)
+	assert.Equal(t, orphan, byKind[IssueOrphan][0].Path)
+
+	require.Len(t, byKind[IssueMissingOpf], 2)
+	assert.ElementsMatch(t, []string{noOpf, orphan}, []string{byKind[IssueMissingOpf][0].Path, byKind[IssueMissingOpf][1].Path})
+
+	require.Len(t, byKind[IssueDuplicate], 1)
+	assert.Equal(t, duplicate, byKind[IssueDuplicate][0].Path)
+	assert.Contains(t, byKind[IssueDuplicate][0].Detail, noOpf)
+
+	for _, issue := range report.Issues {
+		assert.False(t, issue.Repaired)
+	}
+	assert.NoFileExists(t, filepath.Join(noOpf, opfFileName))
+}
+
+func TestVerifyLibrary_Repairs(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	library := createTestLibrary(t, db, "audiobooks", "audiobook")
+
+	noOpf := filepath.Join(library.Path, "Frank Herbert", "Dune")
+	writeTestFile(t, filepath.Join(noOpf, "book.m4b"))
+	common.RecordImportedItem(ctx, db, "hash1", noOpf, "Dune", "B002V1OF70", metadata.BookMetadata{Asin: "B002V1OF70", Title: "Dune"})
+
+	missing := filepath.Join(library.Path, "Gone", "Deleted Book")
+	common.RecordImportedItem(ctx, db, "hash2", missing, "Deleted Book", "", nil)
+
+	orphan := filepath.Join(library.Path, "Unknown", "Stray Book")
+	writeTestFile(t, filepath.Join(orphan, "book.mp3"))
+	writeTestFile(t, filepath.Join(orphan, opfFileName))
+
+	opts := VerifyOptions{RegenerateOpf: true, ForgetMissing: true, AdoptOrphans: true}
+
+	report, err := VerifyLibrary(ctx, db, library, opts)
+	require.NoError(t, err)
+	require.Len(t, report.Issues, 3)
+
+	for _, issue := range report.Issues {
+		assert.True(t, issue.Repaired, "issue %s at %s should be repaired", issue.Kind, issue.Path)
+	}
+
+	opf, err := os.ReadFile(filepath.Join(noOpf, opfFileName))
+	require.NoError(t, err)
+	assert.Contains(t, string(opf), "<dc:title>Dune</dc:title>")
+
+	var count int64
+	require.NoError(t, db.Model(&models.ImportedItem{}).Where("path = ?", missing).Count(&count).Error)
+	assert.Zero(t, count)
+
+	require.NoError(t, db.Model(&models.ImportedItem{}).Where("path = ?", orphan).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+
+	// A second run finds nothing left to fix
+	report, err = VerifyLibrary(ctx, db, library, opts)
+	require.NoError(t, err)
+	assert.Empty(t, report.Issues)
+}
+
+func TestVerifyLibraries_Ebooks(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	library := createTestLibrary(t, db, "books", "ebook")
+
+	imported := filepath.Join(library.Path, "book.epub")
+	writeTestFile(t, imported)
+	common.RecordImportedItem(ctx, db, "hash1", imported, "Book", "", nil)
+
+	writeTestFile(t, filepath.Join(library.Path, "stray.mobi"))
+	writeTestFile(t, filepath.Join(library.Path, "notes.txt"))
+
+	reports, err := VerifyLibraries(ctx, db, VerifyOptions{})
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+
+	report := reports[0]
+	assert.Equal(t, library.ID, report.LibraryID)
+	assert.Equal(t, 2, report.Books)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueOrphan, report.Issues[0].Kind)
+	assert.Equal(t, filepath.Join(library.Path, "stray.mobi"), report.Issues[0].Path)
+}
+
+func TestVerifyLibrary_EbookFormats(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	library := createTestLibrary(t, db, "books", "ebook")
+
+	// One torrent imported in two formats, both recorded under the torrent name
+	for _, name := range []string{"dune.epub", "dune.mobi"} {
+		imported := filepath.Join(library.Path, name)
+		writeTestFile(t, imported)
+		common.RecordImportedItem(ctx, db, "hash1", imported, "Dune", "", nil)
+	}
+
+	// The same book from another torrent is still a duplicate
+	again := filepath.Join(library.Path, "second-dune.epub")
+	writeTestFile(t, again)
+	common.RecordImportedItem(ctx, db, "hash2", again, "Dune", "", nil)
+
+	report, err := VerifyLibrary(ctx, db, library, VerifyOptions{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Books)
+	require.Len(t, report.Issues, 1)
+	assert.Equal(t, IssueDuplicate, report.Issues[0].Kind)
+	assert.Equal(t, again, report.Issues[0].Path)
+}
@@ -13,6 +13,17 @@ type BookSearchCredential struct {
 	IPAddress   string
 	ASN         string
 	LastRefresh time.Time
+
+	// LastError and LastFailureAt are from the last failed refresh or rejected
+	// token. ConsecutiveFailures is reset by a successful refresh.
+	LastError           string
+	LastFailureAt       *time.Time
+	ConsecutiveFailures int `gorm:"not null;default:0"`
+
+	// The IP address and ASN the site saw before they last changed
+	PreviousIPAddress string
+	PreviousASN       string
+	IPChangedAt       *time.Time
 }
 
 // GetBookSearchCredential retrieves the current credential from database
@@ -26,7 +37,9 @@ func GetBookSearchCredential(db *gorm.DB) (*BookSearchCredential, error) {
 	return &credential, nil
 }
 
-// UpsertBookSearchCredential creates or updates the credential
+// UpsertBookSearchCredential creates or updates the credential after a
+// successful refresh, clearing its failures and keeping the previous IP
+// address and ASN when they change
 func UpsertBookSearchCredential(db *gorm.DB, apiKey, ipAddress, asn string) error {
 	var credential BookSearchCredential
 	result := db.First(&credential)
@@ -46,11 +59,44 @@ func UpsertBookSearchCredential(db *gorm.DB, apiKey, ipAddress, asn string) erro
 		return db.Create(&credential).Error
 	}
 
+	updates := map[string]any{
+		"api_key":              apiKey,
+		"ip_address":           ipAddress,
+		"asn":                  asn,
+		"last_refresh":         time.Now(),
+		"last_error":           "",
+		"consecutive_failures": 0,
+	}
+
+	if credential.IPAddress != "" && ipAddress != "" && (credential.IPAddress != ipAddress || credential.ASN != asn) {
+		updates["previous_ip_address"] = credential.IPAddress
+		updates["previous_asn"] = credential.ASN
+		updates["ip_changed_at"] = time.Now()
+	}
+
 	// Update existing record
-	return db.Model(&credential).Updates(map[string]any{
-		"api_key":      apiKey,
-		"ip_address":   ipAddress,
-		"asn":          asn,
-		"last_refresh": time.Now(),
+	return db.Model(&credential).Updates(updates).Error
+}
+
+// RecordBookSearchCredentialFailure records a failed refresh or rejected token
+// and returns the number of consecutive failures
+func RecordBookSearchCredentialFailure(db *gorm.DB, message string) (int, error) {
+	credential, err := GetBookSearchCredential(db)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	failures := credential.ConsecutiveFailures + 1
+
+	err = db.Model(credential).Updates(map[string]any{
+		"last_error":           message,
+		"last_failure_at":      now,
+		"consecutive_failures": failures,
 	}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return failures, nil
 }
@@ -143,10 +143,16 @@ func AutoMigrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to drop removed filter tables: %w", err)
 	}
 
+	// Search results are kept in SearchHistory now
+	err = db.Migrator().DropTable("search_response_items")
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to drop removed search results table", slog.Any("err", err))
+		return fmt.Errorf("failed to drop removed search results table: %w", err)
+	}
+
 	err = db.AutoMigrate(
 		// Existing models
 		&FeedItem{},
-		&SearchResponseItem{},
 		&NotificationType{},
 		&Notifier{},
 		&Feed{},
@@ -156,14 +162,27 @@ func AutoMigrate(db *gorm.DB) error {
 		&BookType{},
 		&Library{},
 		&TorrentCategory{},
+		&ImportType{},
 		&Author{},
 		&AuthorAlias{},
 		&AuthorSubscription{},
 		&AuthorSubscriptionItem{},
+		&RejectedRelease{},
 		// Catalog spine
 		&Book{},
 		&AcquisitionTarget{},
 		&EventLog{},
+		// Importers
+		&AudiobookMatchCandidate{},
+		&ImportJob{},
+		&ManualIntervention{},
+		&ImportedItem{},
+		&LibraryHolding{},
+		// Download monitoring
+		&TorrentHealth{},
+		// Book search
+		&SearchCacheEntry{},
+		&SearchHistory{},
 	)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to auto-migrate database", slog.Any("err", err))
@@ -37,22 +37,6 @@ type FeedItem struct {
 	CreatedAt   time.Time
 }
 
-type SearchResponseItem struct {
-	ID           uint   `gorm:"primaryKey"`
-	Title        string `gorm:"notnull"`
-	Authors      string `gorm:"notnull"`
-	MainCategory int    `gorm:"notnull"`
-	Category     string `gorm:"notnull"`
-	DlHash       string `gorm:"notnull"`
-	FileTypes    string `gorm:"notnull"`
-	TorrentID    uint   `gorm:"notnull"`
-	Language     string `gorm:"notnull"`
-	Series       string `gorm:"notnull"`
-	Narrators    string `gorm:"notnull"`
-	Size         string `gorm:"notnull"`
-	Tags         string `gorm:"notnull"`
-}
-
 type NotificationType struct {
 	gorm.Model
 	Name string `gorm:"not null;uniqueIndex"`
@@ -91,6 +75,21 @@ type Library struct {
 	Path       string   `gorm:"not null;uniqueIndex"`
 	BookTypeID uint     `gorm:"not null"`
 	BookType   BookType `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+
+	// PreferredFormats is a comma-separated ebook format order, best first.
+	// When a torrent has several formats of a book only the best is imported;
+	// empty imports every format.
+	PreferredFormats string `gorm:"not null;default:''"`
+
+	// AudiobookshelfLibraryID maps an audiobook library to the Audiobookshelf
+	// library scanned after imports, and AudiobookshelfPath is this library's
+	// folder as Audiobookshelf sees it (defaults to Path).
+	AudiobookshelfLibraryID string `gorm:"not null;default:''"`
+	AudiobookshelfPath      string `gorm:"not null;default:''"`
+
+	// WriteAudioTags rewrites the tags of imported audiobook files from the
+	// selected metadata.
+	WriteAudioTags bool `gorm:"not null;default:false"`
 }
 
 // TorrentCategory (updated - replaces existing model)
@@ -102,6 +101,34 @@ type TorrentCategory struct {
 	MediaType string `gorm:"not null"` // "audiobook" or "ebook"
 }
 
+// ImportType says how the importers handle torrents in a category: the library
+// they go to, who is notified and the per-category import options.
+type ImportType struct {
+	CommonFields
+	TorrentCategoryID uint            `gorm:"not null;uniqueIndex"` // one import type per category
+	TorrentCategory   TorrentCategory `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	LibraryID         uint            `gorm:"not null"`
+	Library           Library         `gorm:"constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	NotifierID        *uint
+	Notifier          *Notifier `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+
+	CalibreDesktopURL string
+	CalibreWebURL     string
+	CalibreLibrary    string // local library path or content server URL, ebooks only
+	MetadataProviders string // comma-separated fallback chain, audiobooks only
+
+	// Seed gate before import
+	MinSeedTimeMinutes int     `gorm:"not null;default:0"`
+	MinSeedRatio       float64 `gorm:"not null;default:0"`
+
+	// Retention after import
+	RemoveAfterRatio    float64 `gorm:"not null;default:0"`
+	RemoveAfterSeedDays int     `gorm:"not null;default:0"`
+	PrivateMinSeedDays  int     `gorm:"not null;default:0"`
+	RequireHardlink     bool    `gorm:"not null;default:false"`
+	DeleteFiles         bool    `gorm:"not null;default:false"`
+}
+
 // Author represents a writer of books
 type Author struct {
 	CommonFields
@@ -167,4 +194,122 @@ type AuthorSubscriptionItem struct {
 	BooksearchID         string             `gorm:"not null;uniqueIndex"` // torrent ID extracted from feed GUID URL
 	Title                string             `gorm:"not null"`
 	DownloadedAt         time.Time          `gorm:"not null"`
+	DownloadLink         string             `gorm:"not null;default:''"` // .torrent URL or magnet link from the feed, for re-adding
+	Status               string             `gorm:"not null;default:''"` // set by reconciliation: ok, imported, missing, errored, stalled, recategorized
+	StatusCheckedAt      *time.Time
+}
+
+// RejectedRelease is a feed release that failed the release checks. It stops
+// the feed watcher fetching and rejecting the same release on every poll.
+// Rows expire after feedWatcher2.rejectedReleaseDays, or can be deleted, so
+// the release is checked again.
+type RejectedRelease struct {
+	CommonFields
+	BooksearchID string `gorm:"not null;uniqueIndex"` // torrent ID extracted from feed GUID URL
+	TorrentHash  string `gorm:"not null;index"`
+	Title        string `gorm:"not null"`
+	Reason       string `gorm:"not null"`
+}
+
+// TorrentHealth follows an incomplete torrent between health checks, so a
+// stall can be timed across runs. The row is dropped once the torrent
+// finishes or leaves the client.
+type TorrentHealth struct {
+	CommonFields
+	TorrentHash  string     `gorm:"not null;uniqueIndex"`
+	TorrentName  string     `gorm:"not null"`
+	Category     string     `gorm:"not null"`
+	Progress     float64    `gorm:"not null;default:0"` // progress at the last check
+	StalledSince *time.Time // nil while the torrent is making progress
+	Reason       string     `gorm:"not null;default:''"` // why the torrent is stalled
+	FlaggedAt    *time.Time // when the stall was reported
+}
+
+// AudiobookMatchCandidate is a scored metadata match for a torrent that could not
+// be auto-selected. Candidates are replaced on every such lookup and ranked from 1
+// (best) so the audiobook wizard can preselect the top match. They are deleted
+// once the import completes or its manual intervention is closed.
+type AudiobookMatchCandidate struct {
+	CommonFields
+	TorrentHash string  `gorm:"not null;index"`
+	Asin        string  `gorm:"not null"`
+	Rank        int     `gorm:"not null"`
+	Score       float64 `gorm:"not null"`
+	Summary     string  `gorm:"not null"`
+	Metadata    string  `gorm:"type:jsonb"` // serialized metadata.BookMetadata
+}
+
+// ImportJob tracks import attempts for a torrent so transient failures can be
+// retried with backoff instead of going straight to manual intervention.
+type ImportJob struct {
+	CommonFields
+	TorrentHash   string     `gorm:"not null;uniqueIndex"`
+	TorrentName   string     `gorm:"not null"`
+	Category      string     `gorm:"not null"`
+	Status        string     `gorm:"not null;index"` // pending, retrying, completed, failed
+	Attempts      int        `gorm:"not null;default:0"`
+	LastError     string     `gorm:"not null;default:''"`
+	ErrorClass    string     `gorm:"not null;default:''"` // transient, permanent
+	NextAttemptAt *time.Time `gorm:"index"`
+	CompletedAt   *time.Time
+	Metadata      *string `gorm:"type:jsonb"` // metadata chosen for the import, serialized; nil until chosen
+}
+
+// ManualIntervention records why a torrent was tagged for manual intervention
+// and how it was resolved. There is one row per torrent; a torrent sent back
+// to manual intervention reopens it.
+type ManualIntervention struct {
+	CommonFields
+	TorrentHash string  `gorm:"not null;uniqueIndex"`
+	TorrentName string  `gorm:"not null"`
+	Category    string  `gorm:"not null"`
+	Source      string  `gorm:"not null"` // importer that gave up, an eventlog source
+	Reason      string  `gorm:"not null"`
+	ErrorClass  string  `gorm:"not null;default:''"` // transient, permanent; empty when no import attempt failed
+	Attempts    int     `gorm:"not null;default:0"`
+	Metadata    *string `gorm:"type:jsonb"`          // metadata chosen by the failed import, from its ImportJob
+	Status      string  `gorm:"not null;index"`      // open, resolved, ignored
+	Resolution  string  `gorm:"not null;default:''"` // retry, import, ignore, delete
+	ResolvedAt  *time.Time
+}
+
+// ImportedItem is a book directory or file an importer placed in a library.
+// Library verification cross-references these rows against what is on disk.
+type ImportedItem struct {
+	CommonFields
+	TorrentHash string  `gorm:"not null;index"` // empty for items adopted by library verification
+	Path        string  `gorm:"not null;uniqueIndex"`
+	Title       string  `gorm:"not null"`
+	Identifier  string  `gorm:"not null;default:'';index"` // ASIN for audiobooks, empty when unknown
+	Metadata    *string `gorm:"type:jsonb"`                 // serialized metadata.BookMetadata for audiobooks, used to regenerate the OPF
+
+	// CalibreBookID is the ID of the book in the import type's Calibre library,
+	// for ebooks added to one.
+	CalibreBookID *int
+
+	// AudiobookshelfItemID is the Audiobookshelf library item of an audiobook,
+	// once its scan has picked it up.
+	AudiobookshelfItemID string `gorm:"not null;default:''"`
+
+	// RewrittenFiles is a JSON list of the base names of library files the
+	// importer replaced after linking them, such as audio files whose tags were
+	// rewritten. They no longer share an inode with the torrent's files.
+	RewrittenFiles *string `gorm:"type:jsonb"`
+}
+
+// LibraryHolding is a book found on disk by the library scanner, linked to its
+// catalog Book when one matches. Fingerprint changes when the files do, so
+// re-scans only re-read books that changed.
+type LibraryHolding struct {
+	CommonFields
+	LibraryID   uint    `gorm:"not null;index"`
+	Library     Library `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Path        string  `gorm:"not null;uniqueIndex"`
+	Title       string  `gorm:"not null"`
+	Authors     string  `gorm:"not null;default:''"` // as read from the files, separated by "; "
+	Source      string  `gorm:"not null"`            // opf, epub, tags or filename
+	Fingerprint string  `gorm:"not null"`
+	BookID      *uint   `gorm:"index"`
+	Book        *Book   `gorm:"constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	ScannedAt   time.Time
 }
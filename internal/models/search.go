@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// SearchCacheEntry is a cached book search response, keyed by a hash of the
+// normalised search request
+type SearchCacheEntry struct {
+	ID        uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+	CacheKey  string    `gorm:"not null;uniqueIndex"`
+	Response  string    `gorm:"type:jsonb;not null"` // booksearch.SearchResponse
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+// SearchHistory records a book search: who ran it, from where, and what it found
+type SearchHistory struct {
+	ID          uint      `gorm:"primaryKey"`
+	CreatedAt   time.Time `gorm:"not null;index"`
+	Source      string    `gorm:"not null;index"` // discord-bot, api, cli
+	RequestedBy string    // Discord user ID, client IP or local user
+	Location    string    // Discord channel ID
+	Query       string    `gorm:"not null"`
+	Parameters  string    `gorm:"type:jsonb"` // booksearch.SearchParameters
+	ResultCount int       `gorm:"not null"`
+	TotalFound  int       `gorm:"not null"`
+	Cached      bool      `gorm:"not null;default:false"`
+	Results     string    `gorm:"type:jsonb"` // []booksearch.SearchResponseItem
+}
@@ -1,12 +0,0 @@
-package qbit
-
-import (
-	"github.com/autobrr/go-qbittorrent"
-)
-
-type (
-	Torrent              = qbittorrent.Torrent
-	TorrentFiles         = qbittorrent.TorrentFiles
-	TorrentFilterOptions = qbittorrent.TorrentFilterOptions
-	TorrentState         = qbittorrent.TorrentState
-)
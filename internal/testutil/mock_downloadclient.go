@@ -0,0 +1,169 @@
+package testutil
+
+import (
+	"context"
+
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+)
+
+// MockDownloadClient is a reusable mock implementation of downloadclient.DownloadClient for testing
+type MockDownloadClient struct {
+	// GetTorrents mocking
+	GetTorrentsFunc   func(ctx context.Context, filter downloadclient.TorrentFilter) ([]downloadclient.Torrent, error)
+	GetTorrentsCalls  []downloadclient.TorrentFilter
+	GetTorrentsReturn struct {
+		Torrents []downloadclient.Torrent
+		Err      error
+	}
+
+	// AddTags mocking
+	AddTagsFunc   func(ctx context.Context, hashes []string, tags ...string) error
+	AddTagsCalls  []TagsCall
+	AddTagsReturn error
+
+	// RemoveTags mocking
+	RemoveTagsFunc   func(ctx context.Context, hashes []string, tags ...string) error
+	RemoveTagsCalls  []TagsCall
+	RemoveTagsReturn error
+
+	// SetTags mocking
+	SetTagsFunc   func(ctx context.Context, hashes []string, tags ...string) error
+	SetTagsCalls  []TagsCall
+	SetTagsReturn error
+
+	// GetFiles mocking
+	GetFilesFunc   func(ctx context.Context, hash string) ([]downloadclient.TorrentFile, error)
+	GetFilesCalls  []string
+	GetFilesReturn struct {
+		Files []downloadclient.TorrentFile
+		Err   error
+	}
+
+	// AddTorrentFromURL and AddTorrentFromBytes mocking
+	AddTorrentFunc   func(ctx context.Context, call AddTorrentCall) error
+	AddTorrentCalls  []AddTorrentCall
+	AddTorrentReturn error
+
+	// SetCategory mocking
+	SetCategoryFunc   func(ctx context.Context, hashes []string, category string) error
+	SetCategoryCalls  []SetCategoryCall
+	SetCategoryReturn error
+
+	// RemoveTorrents mocking
+	RemoveTorrentsFunc   func(ctx context.Context, hashes []string, deleteFiles bool) error
+	RemoveTorrentsCalls  []RemoveTorrentsCall
+	RemoveTorrentsReturn error
+}
+
+// Compile-time check that MockDownloadClient implements DownloadClient interface.
+var _ downloadclient.DownloadClient = (*MockDownloadClient)(nil)
+
+type TagsCall struct {
+	Hashes []string
+	Tags   []string
+}
+
+// AddTorrentCall records an added torrent. URL is empty when it was added from Data.
+type AddTorrentCall struct {
+	URL     string
+	Data    []byte
+	Options downloadclient.AddOptions
+}
+
+type SetCategoryCall struct {
+	Hashes   []string
+	Category string
+}
+
+type RemoveTorrentsCall struct {
+	Hashes      []string
+	DeleteFiles bool
+}
+
+func (m *MockDownloadClient) GetTorrents(ctx context.Context, filter downloadclient.TorrentFilter) ([]downloadclient.Torrent, error) {
+	m.GetTorrentsCalls = append(m.GetTorrentsCalls, filter)
+
+	if m.GetTorrentsFunc != nil {
+		return m.GetTorrentsFunc(ctx, filter)
+	}
+
+	return m.GetTorrentsReturn.Torrents, m.GetTorrentsReturn.Err
+}
+
+func (m *MockDownloadClient) AddTags(ctx context.Context, hashes []string, tags ...string) error {
+	m.AddTagsCalls = append(m.AddTagsCalls, TagsCall{Hashes: hashes, Tags: tags})
+
+	if m.AddTagsFunc != nil {
+		return m.AddTagsFunc(ctx, hashes, tags...)
+	}
+
+	return m.AddTagsReturn
+}
+
+func (m *MockDownloadClient) RemoveTags(ctx context.Context, hashes []string, tags ...string) error {
+	m.RemoveTagsCalls = append(m.RemoveTagsCalls, TagsCall{Hashes: hashes, Tags: tags})
+
+	if m.RemoveTagsFunc != nil {
+		return m.RemoveTagsFunc(ctx, hashes, tags...)
+	}
+
+	return m.RemoveTagsReturn
+}
+
+func (m *MockDownloadClient) SetTags(ctx context.Context, hashes []string, tags ...string) error {
+	m.SetTagsCalls = append(m.SetTagsCalls, TagsCall{Hashes: hashes, Tags: tags})
+
+	if m.SetTagsFunc != nil {
+		return m.SetTagsFunc(ctx, hashes, tags...)
+	}
+
+	return m.SetTagsReturn
+}
+
+func (m *MockDownloadClient) GetFiles(ctx context.Context, hash string) ([]downloadclient.TorrentFile, error) {
+	m.GetFilesCalls = append(m.GetFilesCalls, hash)
+
+	if m.GetFilesFunc != nil {
+		return m.GetFilesFunc(ctx, hash)
+	}
+
+	return m.GetFilesReturn.Files, m.GetFilesReturn.Err
+}
+
+func (m *MockDownloadClient) addTorrent(ctx context.Context, call AddTorrentCall) error {
+	m.AddTorrentCalls = append(m.AddTorrentCalls, call)
+
+	if m.AddTorrentFunc != nil {
+		return m.AddTorrentFunc(ctx, call)
+	}
+
+	return m.AddTorrentReturn
+}
+
+func (m *MockDownloadClient) AddTorrentFromURL(ctx context.Context, url string, options downloadclient.AddOptions) error {
+	return m.addTorrent(ctx, AddTorrentCall{URL: url, Options: options})
+}
+
+func (m *MockDownloadClient) AddTorrentFromBytes(ctx context.Context, data []byte, options downloadclient.AddOptions) error {
+	return m.addTorrent(ctx, AddTorrentCall{Data: data, Options: options})
+}
+
+func (m *MockDownloadClient) SetCategory(ctx context.Context, hashes []string, category string) error {
+	m.SetCategoryCalls = append(m.SetCategoryCalls, SetCategoryCall{Hashes: hashes, Category: category})
+
+	if m.SetCategoryFunc != nil {
+		return m.SetCategoryFunc(ctx, hashes, category)
+	}
+
+	return m.SetCategoryReturn
+}
+
+func (m *MockDownloadClient) RemoveTorrents(ctx context.Context, hashes []string, deleteFiles bool) error {
+	m.RemoveTorrentsCalls = append(m.RemoveTorrentsCalls, RemoveTorrentsCall{Hashes: hashes, DeleteFiles: deleteFiles})
+
+	if m.RemoveTorrentsFunc != nil {
+		return m.RemoveTorrentsFunc(ctx, hashes, deleteFiles)
+	}
+
+	return m.RemoveTorrentsReturn
+}
@@ -0,0 +1,500 @@
+// Package torrenthealth watches incomplete torrents and flags the ones that
+// stopped making progress, such as dead releases without seeders.
+package torrenthealth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/booksearch"
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/feedwatcher2"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/notifications"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
+)
+
+// Actions taken on a flagged torrent
+const (
+	ActionNone     = ""
+	ActionRemoved  = "removed"
+	ActionReplaced = "replaced"
+)
+
+// alternativeSearchResults is how many search results are considered when
+// looking for another release.
+const alternativeSearchResults = 25
+
+// ReleaseSearcher searches the book search site. BookSearchService implements it.
+type ReleaseSearcher interface {
+	Search(ctx context.Context, db *gorm.DB, params *booksearch.SearchParameters) (*booksearch.SearchResponse, error)
+}
+
+// Flag is a torrent flagged as stalled, and what the monitor did about it.
+type Flag struct {
+	Torrent    downloadclient.Torrent
+	Reason     string
+	StalledFor time.Duration
+	Action     string
+
+	// Alternative is the title of the release that replaced the torrent
+	Alternative string
+}
+
+type Monitor struct {
+	db                *gorm.DB
+	downloadClient    downloadclient.DownloadClient
+	torrentDownloader *torrentutil.TorrentDownloader
+	searcher          ReleaseSearcher
+	config            config.TorrentHealthConfig
+}
+
+func NewMonitor(db *gorm.DB, downloadClient downloadclient.DownloadClient, searcher ReleaseSearcher, httpProxy, httpsProxy string, healthConfig config.TorrentHealthConfig) *Monitor {
+	return &Monitor{
+		db:                db,
+		downloadClient:    downloadClient,
+		torrentDownloader: torrentutil.NewTorrentDownloader(httpProxy, httpsProxy),
+		searcher:          searcher,
+		config:            healthConfig,
+	}
+}
+
+// Run checks every monitored incomplete torrent once and returns the ones
+// newly flagged as stalled. It is meant to run periodically; stalls are timed
+// from the first run that saw no progress.
+func (m *Monitor) Run(ctx context.Context) ([]Flag, error) {
+	if m.config.StalledAfterHours <= 0 {
+		slog.InfoContext(ctx, "Torrent health monitor disabled, stalledAfterHours is not set")
+		return nil, nil
+	}
+
+	slog.InfoContext(ctx, "Checking torrent health...",
+		slog.Any("categories", m.config.Categories),
+		slog.Int("stalledAfterHours", m.config.StalledAfterHours))
+
+	torrents, err := m.monitoredTorrents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []models.TorrentHealth
+	if err := m.db.Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load torrent health: %w", err)
+	}
+
+	health := make(map[string]models.TorrentHealth, len(rows))
+	for _, row := range rows {
+		health[row.TorrentHash] = row
+	}
+
+	now := time.Now()
+	stalledAfter := time.Duration(m.config.StalledAfterHours) * time.Hour
+	seen := make(map[string]bool)
+	var flags []Flag
+
+	for _, torrent := range torrents {
+		if !isIncomplete(torrent) {
+			continue
+		}
+		seen[torrent.Hash] = true
+
+		row, ok := health[torrent.Hash]
+		if !ok {
+			row = models.TorrentHealth{TorrentHash: torrent.Hash}
+		}
+
+		progressed := ok && torrent.Progress > row.Progress
+		if isStalled(torrent) && !progressed {
+			if row.StalledSince == nil {
+				row.StalledSince = &now
+			}
+			row.Reason = stallReason(torrent)
+		} else {
+			row.StalledSince = nil
+			row.FlaggedAt = nil
+			row.Reason = ""
+		}
+
+		row.TorrentName = torrent.Name
+		row.Category = torrent.Category
+		row.Progress = torrent.Progress
+
+		if row.StalledSince != nil && row.FlaggedAt == nil && now.Sub(*row.StalledSince) >= stalledAfter {
+			flag := m.flag(ctx, torrent, row.Reason, now.Sub(*row.StalledSince))
+			flags = append(flags, flag)
+
+			if flag.Action != ActionNone {
+				// The torrent is gone; its row is dropped below
+				delete(seen, torrent.Hash)
+				continue
+			}
+
+			row.FlaggedAt = &now
+		}
+
+		if err := m.db.Save(&row).Error; err != nil {
+			slog.ErrorContext(ctx, "Failed to save torrent health",
+				slog.String("hash", torrent.Hash),
+				slogx.Error(err))
+		}
+	}
+
+	// Forget torrents that finished, were paused or left the client
+	for _, row := range rows {
+		if !seen[row.TorrentHash] {
+			if err := m.db.Delete(&row).Error; err != nil {
+				slog.ErrorContext(ctx, "Failed to delete torrent health",
+					slog.String("hash", row.TorrentHash),
+					slogx.Error(err))
+			}
+		}
+	}
+
+	slog.InfoContext(ctx, "Torrent health check finished",
+		slog.Int("monitored", len(seen)),
+		slog.Int("flagged", len(flags)))
+
+	return flags, nil
+}
+
+// monitoredTorrents returns the torrents in the configured categories, or
+// every torrent when none are configured.
+func (m *Monitor) monitoredTorrents(ctx context.Context) ([]downloadclient.Torrent, error) {
+	if len(m.config.Categories) == 0 {
+		torrents, err := m.downloadClient.GetTorrents(ctx, downloadclient.TorrentFilter{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get torrents: %w", err)
+		}
+		return torrents, nil
+	}
+
+	var torrents []downloadclient.Torrent
+	for _, category := range m.config.Categories {
+		inCategory, err := m.downloadClient.GetTorrents(ctx, downloadclient.TorrentFilter{Category: category})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get torrents in category %s: %w", category, err)
+		}
+		torrents = append(torrents, inCategory...)
+	}
+
+	return torrents, nil
+}
+
+// isIncomplete reports whether a torrent is still meant to be downloading.
+// Paused torrents were stopped on purpose and are not monitored.
+func isIncomplete(torrent downloadclient.Torrent) bool {
+	switch torrent.State {
+	case downloadclient.StateMetadata, downloadclient.StateDownloading, downloadclient.StateStalledDownloading:
+		return true
+	}
+
+	return false
+}
+
+// isStalled reports whether a torrent is downloading nothing right now.
+func isStalled(torrent downloadclient.Torrent) bool {
+	switch torrent.State {
+	case downloadclient.StateMetadata, downloadclient.StateStalledDownloading:
+		return true
+	case downloadclient.StateDownloading:
+		return torrent.DownloadSpeed == 0
+	}
+
+	return false
+}
+
+// stallReason explains a stall from the torrent's peers.
+func stallReason(torrent downloadclient.Torrent) string {
+	switch {
+	case torrent.State == downloadclient.StateMetadata:
+		return "waiting for metadata"
+	case torrent.Availability >= 0 && torrent.Availability < 1:
+		return fmt.Sprintf("only %.0f%% of the torrent is available from peers", torrent.Availability*100)
+	case torrent.Seeds == 0:
+		return "no seeders"
+	}
+
+	return "no download progress"
+}
+
+// flag reports a stalled torrent and, if configured, removes it and looks
+// for another release.
+func (m *Monitor) flag(ctx context.Context, torrent downloadclient.Torrent, reason string, stalledFor time.Duration) Flag {
+	flag := Flag{Torrent: torrent, Reason: reason, StalledFor: stalledFor}
+
+	slog.WarnContext(ctx, "Torrent stalled",
+		slog.String("name", torrent.Name),
+		slog.String("hash", torrent.Hash),
+		slog.String("reason", reason),
+		slog.Duration("stalledFor", stalledFor))
+
+	eventlog.Log(m.db, eventlog.CategoryDownload, eventlog.EventTorrentStalled, eventlog.SourceTorrentHealth,
+		eventlog.EntityTorrent, torrent.Hash,
+		fmt.Sprintf("Stalled: %s (%s)", torrent.Name, reason),
+		map[string]any{
+			"name":         torrent.Name,
+			"category":     torrent.Category,
+			"reason":       reason,
+			"progress":     torrent.Progress,
+			"availability": torrent.Availability,
+			"seeds":        torrent.Seeds,
+			"stalledHours": int(stalledFor.Hours()),
+		})
+
+	item, err := m.subscriptionItem(torrent.Hash)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to look up subscription item",
+			slog.String("hash", torrent.Hash),
+			slogx.Error(err))
+	}
+
+	if m.config.RemoveStalled {
+		m.remove(ctx, &flag, item)
+	}
+
+	m.notify(ctx, flag, item)
+
+	return flag
+}
+
+// subscriptionItem returns the author subscription item that downloaded a
+// torrent, or nil if the torrent was added some other way.
+func (m *Monitor) subscriptionItem(hash string) (*models.AuthorSubscriptionItem, error) {
+	var item models.AuthorSubscriptionItem
+	err := m.db.
+		Preload("AuthorSubscription.Author").
+		Preload("AuthorSubscription.Notifier").
+		Preload("BookType").
+		Where("torrent_hash = ?", hash).
+		First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+// remove removes a flagged torrent with its partial data. A subscription
+// item is replaced by an alternative release when one is found, and deleted
+// otherwise so the next feed hit can download the book again.
+func (m *Monitor) remove(ctx context.Context, flag *Flag, item *models.AuthorSubscriptionItem) {
+	torrent := flag.Torrent
+
+	err := m.downloadClient.RemoveTorrents(ctx, []string{torrent.Hash}, true)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to remove stalled torrent",
+			slog.String("name", torrent.Name),
+			slog.String("hash", torrent.Hash),
+			slogx.Error(err))
+		return
+	}
+
+	flag.Action = ActionRemoved
+
+	slog.InfoContext(ctx, "Removed stalled torrent",
+		slog.String("name", torrent.Name),
+		slog.String("hash", torrent.Hash))
+
+	eventlog.Log(m.db, eventlog.CategoryDownload, eventlog.EventTorrentRemoved, eventlog.SourceTorrentHealth,
+		eventlog.EntityTorrent, torrent.Hash,
+		fmt.Sprintf("Removed stalled torrent: %s (%s)", torrent.Name, flag.Reason),
+		map[string]any{"name": torrent.Name, "category": torrent.Category, "reason": flag.Reason, "deleteFiles": true})
+
+	if item == nil {
+		return
+	}
+
+	if m.config.SearchAlternative {
+		replacement, err := m.replace(ctx, item)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to download an alternative release",
+				slog.String("title", item.Title),
+				slogx.Error(err))
+		} else if replacement != nil {
+			flag.Action = ActionReplaced
+			flag.Alternative = replacement.Title
+			return
+		}
+	}
+
+	if err := m.db.Delete(item).Error; err != nil {
+		slog.ErrorContext(ctx, "Failed to delete subscription item",
+			slog.String("title", item.Title),
+			slogx.Error(err))
+	}
+}
+
+// replace downloads the best seeded other release of an item's book and
+// swaps the item for it. It returns nil when no release qualifies, and an
+// error when the item could not be swapped, even if the release was added.
+func (m *Monitor) replace(ctx context.Context, item *models.AuthorSubscriptionItem) (*models.AuthorSubscriptionItem, error) {
+	if m.searcher == nil {
+		return nil, nil
+	}
+
+	response, err := m.searcher.Search(ctx, m.db, &booksearch.SearchParameters{
+		Query:      item.Title,
+		MaxResults: alternativeSearchResults,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search for an alternative release: %w", err)
+	}
+
+	mainCategory := booksearch.MainCategoryEbooks
+	if item.BookType.Name == feedwatcher2.MediaTypeAudiobook {
+		mainCategory = booksearch.MainCategoryAudiobooks
+	}
+
+	var best *booksearch.SearchResponseItem
+	for i := range response.Data {
+		result := &response.Data[i]
+		if result.MainCategory != mainCategory || result.Seeders == 0 ||
+			!strings.EqualFold(strings.TrimSpace(result.Title), strings.TrimSpace(item.Title)) {
+			continue
+		}
+
+		// The stalled release itself, or one already downloaded
+		var count int64
+		err := m.db.Model(&models.AuthorSubscriptionItem{}).
+			Where("booksearch_id = ?", strconv.Itoa(result.ID)).
+			Count(&count).Error
+		if err != nil {
+			return nil, fmt.Errorf("failed to check for existing item: %w", err)
+		}
+		if count > 0 {
+			continue
+		}
+
+		if best == nil || result.Seeders > best.Seeders {
+			best = result
+		}
+	}
+
+	if best == nil {
+		slog.InfoContext(ctx, "No alternative release found", slog.String("title", item.Title))
+		return nil, nil
+	}
+
+	link := best.DownloadTorrentURL()
+	hash, err := m.torrentDownloader.DownloadAndAdd(ctx, m.downloadClient, link, downloadclient.AddOptions{
+		Category: feedwatcher2.AuthorSubscriptionCategory,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	replacement := models.AuthorSubscriptionItem{
+		AuthorSubscriptionID: item.AuthorSubscriptionID,
+		BookTypeID:           item.BookTypeID,
+		TorrentHash:          hash,
+		BooksearchID:         strconv.Itoa(best.ID),
+		Title:                best.Title,
+		DownloadedAt:         time.Now(),
+		DownloadLink:         link,
+	}
+
+	slog.InfoContext(ctx, "Downloaded alternative release",
+		slog.String("title", best.Title),
+		slog.String("hash", hash),
+		slog.Int("seeders", best.Seeders))
+
+	// The torrent is in the client whether or not the item can be swapped
+	eventlog.Log(m.db, eventlog.CategoryDownload, eventlog.EventTorrentAdded, eventlog.SourceTorrentHealth,
+		eventlog.EntityTorrent, hash,
+		fmt.Sprintf("Alternative release: %s", best.Title),
+		map[string]any{
+			"title":         best.Title,
+			"hash":          hash,
+			"booksearch_id": replacement.BooksearchID,
+			"replaces_hash": item.TorrentHash,
+			"seeders":       best.Seeders,
+		})
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(item).Error; err != nil {
+			return err
+		}
+		return tx.Create(&replacement).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to replace subscription item with %s: %w", hash, err)
+	}
+
+	return &replacement, nil
+}
+
+// notify sends a stalled torrent through its subscription's Notifier, or the
+// configured notifier for other torrents.
+func (m *Monitor) notify(ctx context.Context, flag Flag, item *models.AuthorSubscriptionItem) {
+	message := createStalledNotificationPayload(flag, item)
+
+	var err error
+	if item != nil {
+		err = feedwatcher2.SendNotificationViaNotifier(ctx, m.db, item.AuthorSubscription.Notifier, message)
+	} else {
+		err = notifications.SendNotification(ctx, m.config.Notifier, message)
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to send stalled torrent notification",
+			slog.String("hash", flag.Torrent.Hash),
+			slogx.Error(err))
+	}
+}
+
+func createStalledNotificationPayload(flag Flag, item *models.AuthorSubscriptionItem) notifications.DiscordWebhookMessage {
+	var embed notifications.DiscordEmbed
+
+	embed.Author.Name = "Torrent Health"
+	embed.Title = flag.Torrent.Name
+	embed.Description = "Torrent Stalled"
+	embed.Color = 15158332
+	embed.Timestamp = time.Now().UTC().Format(time.RFC3339)
+
+	addField := func(name string, value string, inline bool) {
+		if value == "" {
+			return
+		}
+		embed.Fields = append(embed.Fields, notifications.DiscordEmbedField{
+			Name:   name,
+			Value:  value,
+			Inline: inline,
+		})
+	}
+
+	addField("Reason", flag.Reason, false)
+	addField("Progress", fmt.Sprintf("%.1f%%", flag.Torrent.Progress*100), true)
+	addField("Stalled For", flag.StalledFor.Truncate(time.Hour).String(), true)
+	addField("Seeds", strconv.FormatInt(flag.Torrent.Seeds, 10), true)
+	addField("Category", flag.Torrent.Category, true)
+
+	if item != nil {
+		addField("Subscribed Author", item.AuthorSubscription.Author.Name, true)
+	}
+
+	switch flag.Action {
+	case ActionRemoved:
+		addField("Action", "Removed from the download client", false)
+	case ActionReplaced:
+		addField("Action", fmt.Sprintf("Removed and replaced by %s", flag.Alternative), false)
+	}
+
+	return notifications.DiscordWebhookMessage{
+		Username: "Stronghold",
+		Content:  "",
+		Embeds:   []notifications.DiscordEmbed{embed},
+	}
+}
@@ -0,0 +1,426 @@
+package torrenthealth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackpal/bencode-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/booksearch"
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/feedwatcher2"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/notifications"
+	"github.com/bobbyrward/stronghold/internal/testutil"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
+)
+
+const stalledHash = "1111111111111111111111111111111111111111"
+
+type fakeSearcher struct {
+	response booksearch.SearchResponse
+	queries  []string
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, db *gorm.DB, params *booksearch.SearchParameters) (*booksearch.SearchResponse, error) {
+	f.queries = append(f.queries, params.Query)
+	return &f.response, nil
+}
+
+func createTestMonitor(db *gorm.DB, client downloadclient.DownloadClient, searcher ReleaseSearcher, healthConfig config.TorrentHealthConfig) *Monitor {
+	return &Monitor{
+		db:                db,
+		downloadClient:    client,
+		torrentDownloader: torrentutil.NewTestTorrentDownloader(),
+		searcher:          searcher,
+		config:            healthConfig,
+	}
+}
+
+func stalledTorrent() downloadclient.Torrent {
+	return downloadclient.Torrent{
+		Hash:         stalledHash,
+		Name:         "Mistborn",
+		Category:     feedwatcher2.AuthorSubscriptionCategory,
+		State:        downloadclient.StateStalledDownloading,
+		Progress:     0.1,
+		AmountLeft:   900,
+		Availability: 0.4,
+	}
+}
+
+// stallSince records a torrent as stalled since the given time, as an earlier run would.
+func stallSince(t *testing.T, db *gorm.DB, torrent downloadclient.Torrent, since time.Time) {
+	t.Helper()
+
+	require.NoError(t, db.Create(&models.TorrentHealth{
+		TorrentHash:  torrent.Hash,
+		TorrentName:  torrent.Name,
+		Category:     torrent.Category,
+		Progress:     torrent.Progress,
+		StalledSince: &since,
+	}).Error)
+}
+
+// createSubscriptionItem creates an audiobook subscription item for stalledHash
+// that notifies the returned server's messages.
+func createSubscriptionItem(t *testing.T, db *gorm.DB) (models.AuthorSubscriptionItem, *[]notifications.DiscordWebhookMessage) {
+	t.Helper()
+
+	var messages []notifications.DiscordWebhookMessage
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message notifications.DiscordWebhookMessage
+		_ = json.NewDecoder(r.Body).Decode(&message)
+		messages = append(messages, message)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	notifier := models.Notifier{Name: "test-notifier", URL: server.URL}
+	require.NoError(t, db.Create(&notifier).Error)
+
+	var scope models.SubscriptionScope
+	require.NoError(t, db.FirstOrCreate(&scope, models.SubscriptionScope{Name: "personal"}).Error)
+
+	author := models.Author{Name: "Brandon Sanderson"}
+	require.NoError(t, db.Create(&author).Error)
+
+	subscription := models.AuthorSubscription{AuthorID: author.ID, ScopeID: scope.ID, NotifierID: &notifier.ID}
+	require.NoError(t, db.Create(&subscription).Error)
+
+	var bookType models.BookType
+	require.NoError(t, db.Where("name = ?", feedwatcher2.MediaTypeAudiobook).First(&bookType).Error)
+
+	item := models.AuthorSubscriptionItem{
+		AuthorSubscriptionID: subscription.ID,
+		BookTypeID:           bookType.ID,
+		TorrentHash:          stalledHash,
+		BooksearchID:         "1001",
+		Title:                "Mistborn",
+		DownloadedAt:         time.Now(),
+	}
+	require.NoError(t, db.Create(&item).Error)
+
+	return item, &messages
+}
+
+func TestMonitor_StartsTrackingStall(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	client := &testutil.MockDownloadClient{}
+	client.GetTorrentsReturn.Torrents = []downloadclient.Torrent{stalledTorrent()}
+
+	flags, err := createTestMonitor(db, client, nil, config.TorrentHealthConfig{StalledAfterHours: 24}).Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+
+	var row models.TorrentHealth
+	require.NoError(t, db.Where("torrent_hash = ?", stalledHash).First(&row).Error)
+	assert.NotNil(t, row.StalledSince)
+	assert.Nil(t, row.FlaggedAt)
+	assert.Equal(t, "only 40% of the torrent is available from peers", row.Reason)
+}
+
+func TestMonitor_ProgressResetsStall(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	torrent := stalledTorrent()
+	stallSince(t, db, torrent, time.Now().Add(-48*time.Hour))
+
+	// Slow but moving
+	torrent.Progress = 0.2
+
+	client := &testutil.MockDownloadClient{}
+	client.GetTorrentsReturn.Torrents = []downloadclient.Torrent{torrent}
+
+	flags, err := createTestMonitor(db, client, nil, config.TorrentHealthConfig{StalledAfterHours: 24}).Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+
+	var row models.TorrentHealth
+	require.NoError(t, db.Where("torrent_hash = ?", stalledHash).First(&row).Error)
+	assert.Nil(t, row.StalledSince)
+	assert.Equal(t, 0.2, row.Progress)
+}
+
+func TestMonitor_FlagsAndNotifies(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	item, messages := createSubscriptionItem(t, db)
+	torrent := stalledTorrent()
+	stallSince(t, db, torrent, time.Now().Add(-48*time.Hour))
+
+	client := &testutil.MockDownloadClient{}
+	client.GetTorrentsReturn.Torrents = []downloadclient.Torrent{torrent}
+
+	monitor := createTestMonitor(db, client, nil, config.TorrentHealthConfig{
+		Categories:        []string{feedwatcher2.AuthorSubscriptionCategory},
+		StalledAfterHours: 24,
+	})
+
+	flags, err := monitor.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, ActionNone, flags[0].Action)
+	assert.GreaterOrEqual(t, flags[0].StalledFor, 48*time.Hour)
+	assert.Equal(t, feedwatcher2.AuthorSubscriptionCategory, client.GetTorrentsCalls[0].Category)
+
+	require.Len(t, *messages, 1)
+	assert.Equal(t, "Torrent Stalled", (*messages)[0].Embeds[0].Description)
+	assert.Equal(t, "Mistborn", (*messages)[0].Embeds[0].Title)
+
+	// The torrent and its item are kept
+	assert.Empty(t, client.RemoveTorrentsCalls)
+	require.NoError(t, db.First(&models.AuthorSubscriptionItem{}, item.ID).Error)
+
+	var events []models.EventLog
+	require.NoError(t, db.Where("event_type = ?", eventlog.EventTorrentStalled).Find(&events).Error)
+	assert.Len(t, events, 1)
+
+	// A flagged torrent is reported once
+	flags, err = monitor.Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+	assert.Len(t, *messages, 1)
+}
+
+func TestMonitor_ForgetsFinishedTorrents(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	torrent := stalledTorrent()
+	stallSince(t, db, torrent, time.Now().Add(-time.Hour))
+
+	torrent.State = downloadclient.StateSeeding
+	torrent.Progress = 1
+	torrent.AmountLeft = 0
+
+	client := &testutil.MockDownloadClient{}
+	client.GetTorrentsReturn.Torrents = []downloadclient.Torrent{torrent}
+
+	_, err = createTestMonitor(db, client, nil, config.TorrentHealthConfig{StalledAfterHours: 24}).Run(context.Background())
+	require.NoError(t, err)
+
+	var count int64
+	require.NoError(t, db.Model(&models.TorrentHealth{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestMonitor_RemoveReleasesItem(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	item, messages := createSubscriptionItem(t, db)
+	torrent := stalledTorrent()
+	stallSince(t, db, torrent, time.Now().Add(-48*time.Hour))
+
+	client := &testutil.MockDownloadClient{}
+	client.GetTorrentsReturn.Torrents = []downloadclient.Torrent{torrent}
+
+	searcher := &fakeSearcher{}
+
+	flags, err := createTestMonitor(db, client, searcher, config.TorrentHealthConfig{
+		StalledAfterHours: 24,
+		RemoveStalled:     true,
+		SearchAlternative: true,
+	}).Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, ActionRemoved, flags[0].Action)
+
+	require.Len(t, client.RemoveTorrentsCalls, 1)
+	assert.Equal(t, []string{stalledHash}, client.RemoveTorrentsCalls[0].Hashes)
+	assert.True(t, client.RemoveTorrentsCalls[0].DeleteFiles)
+	assert.Equal(t, []string{"Mistborn"}, searcher.queries)
+
+	// Without an alternative the item is released for the next feed hit
+	err = db.First(&models.AuthorSubscriptionItem{}, item.ID).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	var count int64
+	require.NoError(t, db.Model(&models.TorrentHealth{}).Count(&count).Error)
+	assert.Zero(t, count)
+
+	require.Len(t, *messages, 1)
+	assert.Equal(t, "Removed from the download client", (*messages)[0].Embeds[0].Fields[len((*messages)[0].Embeds[0].Fields)-1].Value)
+}
+
+// serveAlternative serves a torrent for the alternative release dl-2002 as the
+// book search site and returns its bytes and the site's URL.
+func serveAlternative(t *testing.T) ([]byte, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, bencode.Marshal(&buf, map[string]any{
+		"info": map[string]any{
+			"name":         "Mistborn.m4b",
+			"piece length": 262144,
+			"pieces":       "12345678901234567890",
+			"length":       1024,
+		},
+	}))
+
+	torrentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tor/download.php/dl-2002", r.URL.Path)
+		_, _ = w.Write(buf.Bytes())
+	}))
+	t.Cleanup(torrentServer.Close)
+
+	originalBaseURL := config.Config.BookSearch.BaseURL
+	config.Config.BookSearch.BaseURL = torrentServer.URL
+	t.Cleanup(func() { config.Config.BookSearch.BaseURL = originalBaseURL })
+
+	return buf.Bytes(), torrentServer.URL
+}
+
+func TestMonitor_ReplacesWithAlternative(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	torrentData, siteURL := serveAlternative(t)
+	alternative, err := torrentutil.ParseMetainfo(torrentData)
+	require.NoError(t, err)
+
+	item, messages := createSubscriptionItem(t, db)
+	torrent := stalledTorrent()
+	stallSince(t, db, torrent, time.Now().Add(-48*time.Hour))
+
+	client := &testutil.MockDownloadClient{
+		GetTorrentsFunc: func(ctx context.Context, filter downloadclient.TorrentFilter) ([]downloadclient.Torrent, error) {
+			if len(filter.Hashes) > 0 {
+				return nil, nil
+			}
+			return []downloadclient.Torrent{torrent}, nil
+		},
+	}
+
+	searcher := &fakeSearcher{response: booksearch.SearchResponse{Data: []booksearch.SearchResponseItem{
+		// The stalled release itself
+		{ID: 1001, Title: "Mistborn", MainCategory: booksearch.MainCategoryAudiobooks, Seeders: 50, DlHash: "dl-1001"},
+		// The ebook
+		{ID: 2000, Title: "Mistborn", MainCategory: booksearch.MainCategoryEbooks, Seeders: 40, DlHash: "dl-2000"},
+		// Another book
+		{ID: 2001, Title: "Mistborn: Secret History", MainCategory: booksearch.MainCategoryAudiobooks, Seeders: 30, DlHash: "dl-2001"},
+		{ID: 2002, Title: "mistborn", MainCategory: booksearch.MainCategoryAudiobooks, Seeders: 12, DlHash: "dl-2002"},
+		{ID: 2003, Title: "Mistborn", MainCategory: booksearch.MainCategoryAudiobooks, Seeders: 3, DlHash: "dl-2003"},
+		{ID: 2004, Title: "Mistborn", MainCategory: booksearch.MainCategoryAudiobooks, Seeders: 0, DlHash: "dl-2004"},
+	}}}
+
+	flags, err := createTestMonitor(db, client, searcher, config.TorrentHealthConfig{
+		StalledAfterHours: 24,
+		RemoveStalled:     true,
+		SearchAlternative: true,
+	}).Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, ActionReplaced, flags[0].Action)
+	assert.Equal(t, "mistborn", flags[0].Alternative)
+
+	require.Len(t, client.AddTorrentCalls, 1)
+	assert.Equal(t, torrentData, client.AddTorrentCalls[0].Data)
+	assert.Equal(t, feedwatcher2.AuthorSubscriptionCategory, client.AddTorrentCalls[0].Options.Category)
+
+	// The item now tracks the alternative
+	err = db.First(&models.AuthorSubscriptionItem{}, item.ID).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	var replacement models.AuthorSubscriptionItem
+	require.NoError(t, db.Where("booksearch_id = ?", "2002").First(&replacement).Error)
+	assert.Equal(t, alternative.Hash(), replacement.TorrentHash)
+	assert.Equal(t, item.AuthorSubscriptionID, replacement.AuthorSubscriptionID)
+	assert.Equal(t, siteURL+"/tor/download.php/dl-2002", replacement.DownloadLink)
+
+	require.Len(t, *messages, 1)
+}
+
+func TestMonitor_ReplaceFailureReleasesItem(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	serveAlternative(t)
+
+	item, messages := createSubscriptionItem(t, db)
+	torrent := stalledTorrent()
+	stallSince(t, db, torrent, time.Now().Add(-48*time.Hour))
+
+	// Saving the replacement item fails
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("fail_subscription_items", func(tx *gorm.DB) {
+		if tx.Statement.Table == "author_subscription_items" {
+			_ = tx.AddError(errors.New("database is locked"))
+		}
+	}))
+
+	client := &testutil.MockDownloadClient{
+		GetTorrentsFunc: func(ctx context.Context, filter downloadclient.TorrentFilter) ([]downloadclient.Torrent, error) {
+			if len(filter.Hashes) > 0 {
+				return nil, nil
+			}
+			return []downloadclient.Torrent{torrent}, nil
+		},
+	}
+
+	searcher := &fakeSearcher{response: booksearch.SearchResponse{Data: []booksearch.SearchResponseItem{
+		{ID: 2002, Title: "Mistborn", MainCategory: booksearch.MainCategoryAudiobooks, Seeders: 12, DlHash: "dl-2002"},
+	}}}
+
+	flags, err := createTestMonitor(db, client, searcher, config.TorrentHealthConfig{
+		StalledAfterHours: 24,
+		RemoveStalled:     true,
+		SearchAlternative: true,
+	}).Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, ActionRemoved, flags[0].Action)
+	assert.Empty(t, flags[0].Alternative)
+	require.Len(t, client.AddTorrentCalls, 1)
+
+	// The stale item is released instead of being kept for the removed torrent
+	err = db.First(&models.AuthorSubscriptionItem{}, item.ID).Error
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	require.Len(t, *messages, 1)
+	assert.Equal(t, "Removed from the download client", (*messages)[0].Embeds[0].Fields[len((*messages)[0].Embeds[0].Fields)-1].Value)
+}
+
+func TestMonitor_Disabled(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	client := &testutil.MockDownloadClient{}
+
+	flags, err := createTestMonitor(db, client, nil, config.TorrentHealthConfig{}).Run(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+	assert.Empty(t, client.GetTorrentsCalls)
+}
+
+func TestStallReason(t *testing.T) {
+	torrent := stalledTorrent()
+	assert.Equal(t, "only 40% of the torrent is available from peers", stallReason(torrent))
+
+	torrent.Availability = 1.5
+	assert.Equal(t, "no seeders", stallReason(torrent))
+
+	torrent.Seeds = 2
+	assert.Equal(t, "no download progress", stallReason(torrent))
+
+	torrent.Availability = -1
+	assert.Equal(t, "no download progress", stallReason(torrent))
+
+	torrent.State = downloadclient.StateMetadata
+	assert.Equal(t, "waiting for metadata", stallReason(torrent))
+}
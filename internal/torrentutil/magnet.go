@@ -0,0 +1,148 @@
+package torrentutil
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// sha256MultihashPrefix starts a btmh value: the SHA-256 multihash code and
+// digest length.
+const sha256MultihashPrefix = "1220"
+
+// Magnet is a parsed magnet link.
+type Magnet struct {
+	URI string
+
+	// Name is the display name (dn), if given
+	Name     string
+	Trackers []string
+
+	// InfoHash is the v1 info hash from urn:btih, InfoHashV2 the v2 info hash
+	// from urn:btmh. Hybrid links have both. Both are lower-case hex.
+	InfoHash   string
+	InfoHashV2 string
+}
+
+// Hash returns the hash download clients identify the torrent by, as
+// Metainfo.Hash does.
+func (m *Magnet) Hash() string {
+	return clientHash(m.InfoHash, m.InfoHashV2)
+}
+
+// IsMagnetURI reports whether link is a magnet link rather than a .torrent URL.
+func IsMagnetURI(link string) bool {
+	return strings.HasPrefix(strings.ToLower(strings.TrimSpace(link)), "magnet:")
+}
+
+// ParseMagnetURI parses a magnet link. Its v1 hash may be hex or base32.
+func ParseMagnetURI(uri string) (*Magnet, error) {
+	uri = strings.TrimSpace(uri)
+	if !IsMagnetURI(uri) {
+		return nil, fmt.Errorf("not a magnet link: %q", uri)
+	}
+
+	_, rawQuery, _ := strings.Cut(uri, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse magnet link: %w", err)
+	}
+
+	magnet := &Magnet{
+		URI:  uri,
+		Name: query.Get("dn"),
+	}
+
+	// Sorted keys keep numbered parameters in order
+	keys := slices.Sorted(maps.Keys(query))
+
+	for _, key := range keys {
+		values := query[key]
+
+		switch {
+		// Links may number repeated parameters: xt.1, xt.2, tr.1
+		case key == "xt" || strings.HasPrefix(key, "xt."):
+			for _, value := range values {
+				err := magnet.parseExactTopic(value)
+				if err != nil {
+					return nil, err
+				}
+			}
+		case key == "tr" || strings.HasPrefix(key, "tr."):
+			for _, value := range values {
+				if value != "" && !slices.Contains(magnet.Trackers, value) {
+					magnet.Trackers = append(magnet.Trackers, value)
+				}
+			}
+		}
+	}
+
+	if magnet.InfoHash == "" && magnet.InfoHashV2 == "" {
+		return nil, fmt.Errorf("magnet link has no BitTorrent info hash")
+	}
+
+	return magnet, nil
+}
+
+// parseExactTopic reads an xt value. Topics other than BitTorrent info hashes are ignored.
+func (m *Magnet) parseExactTopic(topic string) error {
+	lower := strings.ToLower(topic)
+
+	if strings.HasPrefix(lower, "urn:btih:") {
+		hash, err := decodeBtih(topic[len("urn:btih:"):])
+		if err != nil {
+			return err
+		}
+
+		m.InfoHash = hash
+		return nil
+	}
+
+	if multihash, ok := strings.CutPrefix(lower, "urn:btmh:"); ok {
+		digest, ok := strings.CutPrefix(multihash, sha256MultihashPrefix)
+		if !ok || len(digest) != 64 {
+			return fmt.Errorf("unsupported magnet link btmh hash %q", multihash)
+		}
+		if _, err := hex.DecodeString(digest); err != nil {
+			return fmt.Errorf("invalid magnet link btmh hash %q", multihash)
+		}
+
+		m.InfoHashV2 = digest
+		return nil
+	}
+
+	return nil
+}
+
+// decodeBtih decodes a v1 info hash given as 40 hex or 32 base32 characters.
+func decodeBtih(hash string) (string, error) {
+	switch len(hash) {
+	case 40:
+		if _, err := hex.DecodeString(hash); err != nil {
+			return "", fmt.Errorf("invalid magnet link btih hash %q", hash)
+		}
+		return strings.ToLower(hash), nil
+	case 32:
+		decoded, err := base32.StdEncoding.DecodeString(strings.ToUpper(hash))
+		if err != nil {
+			return "", fmt.Errorf("invalid magnet link btih hash %q", hash)
+		}
+		return hex.EncodeToString(decoded), nil
+	}
+
+	return "", fmt.Errorf("invalid magnet link btih hash %q", hash)
+}
+
+// clientHash is the hash download clients identify a torrent by: the v1 info
+// hash, or the v2 info hash truncated to 40 characters for v2-only torrents.
+func clientHash(infoHash, infoHashV2 string) string {
+	if infoHash != "" {
+		return infoHash
+	}
+
+	return infoHashV2[:40]
+}
@@ -0,0 +1,122 @@
+package torrentutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testV1Hash = "c12fe1c06bba254a9dc9f519b335aa7c1367a88a"
+	testV2Hash = "d8dd32ac93357c368556af3ac1d95c9d76bd0dff6fa9833ecdac3d53134efabb"
+)
+
+func TestIsMagnetURI(t *testing.T) {
+	assert.True(t, IsMagnetURI("magnet:?xt=urn:btih:"+testV1Hash))
+	assert.True(t, IsMagnetURI("  MAGNET:?xt=urn:btih:"+testV1Hash))
+	assert.False(t, IsMagnetURI("https://example.com/dune.torrent"))
+	assert.False(t, IsMagnetURI(""))
+}
+
+func TestParseMagnetURI(t *testing.T) {
+	tests := []struct {
+		name       string
+		uri        string
+		infoHash   string
+		infoHashV2 string
+		hash       string
+	}{
+		{
+			name:     "v1 hex",
+			uri:      "magnet:?xt=urn:btih:" + testV1Hash,
+			infoHash: testV1Hash,
+			hash:     testV1Hash,
+		},
+		{
+			name:     "v1 upper-case hex",
+			uri:      "magnet:?xt=urn:btih:C12FE1C06BBA254A9DC9F519B335AA7C1367A88A",
+			infoHash: testV1Hash,
+			hash:     testV1Hash,
+		},
+		{
+			name:     "v1 base32",
+			uri:      "magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK",
+			infoHash: testV1Hash,
+			hash:     testV1Hash,
+		},
+		{
+			name:     "v1 lower-case base32",
+			uri:      "magnet:?xt=urn:btih:yex6dqdlxisuvhoj6um3gnnkpqjwpkek",
+			infoHash: testV1Hash,
+			hash:     testV1Hash,
+		},
+		{
+			name:       "v2 only",
+			uri:        "magnet:?xt=urn:btmh:1220" + testV2Hash,
+			infoHashV2: testV2Hash,
+			hash:       testV2Hash[:40],
+		},
+		{
+			name:       "hybrid",
+			uri:        "magnet:?xt=urn:btih:" + testV1Hash + "&xt=urn:btmh:1220" + testV2Hash,
+			infoHash:   testV1Hash,
+			infoHashV2: testV2Hash,
+			hash:       testV1Hash,
+		},
+		{
+			name:       "numbered topics",
+			uri:        "magnet:?xt.1=urn:btih:" + testV1Hash + "&xt.2=urn:btmh:1220" + testV2Hash,
+			infoHash:   testV1Hash,
+			infoHashV2: testV2Hash,
+			hash:       testV1Hash,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			magnet, err := ParseMagnetURI(tt.uri)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.uri, magnet.URI)
+			assert.Equal(t, tt.infoHash, magnet.InfoHash)
+			assert.Equal(t, tt.infoHashV2, magnet.InfoHashV2)
+			assert.Equal(t, tt.hash, magnet.Hash())
+		})
+	}
+}
+
+func TestParseMagnetURI_NameAndTrackers(t *testing.T) {
+	magnet, err := ParseMagnetURI("magnet:?xt=urn:btih:" + testV1Hash +
+		"&dn=Dune%20-%20Frank%20Herbert" +
+		"&tr=http%3A%2F%2Fa.example.com%2Fannounce" +
+		"&tr=udp%3A%2F%2Fb.example.com%3A6969" +
+		"&tr=http%3A%2F%2Fa.example.com%2Fannounce")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Dune - Frank Herbert", magnet.Name)
+	assert.Equal(t, []string{"http://a.example.com/announce", "udp://b.example.com:6969"}, magnet.Trackers)
+}
+
+func TestParseMagnetURI_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		err  string
+	}{
+		{name: "not a magnet link", uri: "https://example.com/dune.torrent", err: "not a magnet link"},
+		{name: "no info hash", uri: "magnet:?dn=Dune", err: "no BitTorrent info hash"},
+		{name: "other topic only", uri: "magnet:?xt=urn:sha1:YNCKHTQCWBTRNJIV4WNAE52SJUQCZO5C", err: "no BitTorrent info hash"},
+		{name: "short btih", uri: "magnet:?xt=urn:btih:c12fe1c0", err: "invalid magnet link btih hash"},
+		{name: "bad hex btih", uri: "magnet:?xt=urn:btih:zz2fe1c06bba254a9dc9f519b335aa7c1367a88a", err: "invalid magnet link btih hash"},
+		{name: "bad base32 btih", uri: "magnet:?xt=urn:btih:1111111111111111111111111111111!", err: "invalid magnet link btih hash"},
+		{name: "non sha256 btmh", uri: "magnet:?xt=urn:btmh:1114" + testV1Hash, err: "unsupported magnet link btmh hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseMagnetURI(tt.uri)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package torrentutil
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jackpal/bencode-go"
+)
+
+// Metainfo is the parsed contents of a .torrent file.
+type Metainfo struct {
+	Name string
+
+	// Size is the total size of Files in bytes
+	Size    int64
+	Files   []MetainfoFile
+	Private bool
+
+	// Trackers are the announce URLs, in tier order without duplicates
+	Trackers []string
+
+	// InfoHash is the v1 (SHA-1) info hash, empty for v2-only torrents.
+	// InfoHashV2 is the v2 (SHA-256) info hash, empty for v1-only torrents.
+	// Hybrid torrents have both.
+	InfoHash   string
+	InfoHashV2 string
+}
+
+// MetainfoFile is a file in a torrent. Path is relative to the save path and
+// starts with the torrent's name for multi-file torrents, as download clients
+// report it.
+type MetainfoFile struct {
+	Path string
+	Size int64
+}
+
+// Hash returns the hash download clients identify the torrent by: the v1 info
+// hash, or the truncated v2 info hash for v2-only torrents.
+func (m *Metainfo) Hash() string {
+	return clientHash(m.InfoHash, m.InfoHashV2)
+}
+
+// IsV2 reports whether the torrent has v2 metadata, alone or as a hybrid.
+func (m *Metainfo) IsV2() bool {
+	return m.InfoHashV2 != ""
+}
+
+// ParseMetainfo parses raw torrent file bytes. Padding files of hybrid
+// torrents are left out of Files.
+func ParseMetainfo(torrentData []byte) (*Metainfo, error) {
+	// Use bencode.Decode to parse into a generic map (Unmarshal with struct doesn't work for nested dicts)
+	decoded, err := bencode.Decode(bytes.NewReader(torrentData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse torrent file: %w", err)
+	}
+
+	torrent, ok := decoded.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("torrent file is not a dictionary")
+	}
+
+	info, ok := torrent["info"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("torrent file missing info dictionary")
+	}
+
+	// Re-encode the info dictionary to get the exact bytes for hashing
+	var infoBuf bytes.Buffer
+	err = bencode.Marshal(&infoBuf, info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-encode info dictionary: %w", err)
+	}
+
+	metainfo := &Metainfo{
+		Name:     bencodeString(info["name"]),
+		Private:  bencodeInt(info["private"]) == 1,
+		Trackers: parseTrackers(torrent),
+	}
+
+	isV2 := bencodeInt(info["meta version"]) == 2
+	_, hasPieces := info["pieces"]
+
+	if hasPieces || !isV2 {
+		sum := sha1.Sum(infoBuf.Bytes())
+		metainfo.InfoHash = hex.EncodeToString(sum[:])
+	}
+
+	if isV2 {
+		sum := sha256.Sum256(infoBuf.Bytes())
+		metainfo.InfoHashV2 = hex.EncodeToString(sum[:])
+	}
+
+	var singleFile bool
+	if isV2 {
+		fileTree, ok := info["file tree"].(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("v2 torrent file missing file tree")
+		}
+
+		// A single-file v2 torrent's tree holds just the file, under the torrent's name
+		if node, ok := fileTree[metainfo.Name].(map[string]any); ok && len(fileTree) == 1 {
+			_, singleFile = node[""]
+		}
+
+		metainfo.Files = parseFileTree(fileTree, nil)
+	} else {
+		_, hasFiles := info["files"]
+		singleFile = !hasFiles
+
+		metainfo.Files, err = parseFileList(info)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Multi-file torrents download into a folder named after the torrent
+	if !singleFile {
+		for i := range metainfo.Files {
+			metainfo.Files[i].Path = path.Join(metainfo.Name, metainfo.Files[i].Path)
+		}
+	}
+
+	for _, file := range metainfo.Files {
+		metainfo.Size += file.Size
+	}
+
+	return metainfo, nil
+}
+
+// parseFileList reads the v1 file list, or the single file of a single-file torrent.
+func parseFileList(info map[string]any) ([]MetainfoFile, error) {
+	files, ok := info["files"].([]any)
+	if !ok {
+		if _, ok := info["length"]; !ok {
+			return nil, fmt.Errorf("torrent file has neither files nor length")
+		}
+
+		return []MetainfoFile{{Path: bencodeString(info["name"]), Size: bencodeInt(info["length"])}}, nil
+	}
+
+	result := make([]MetainfoFile, 0, len(files))
+	for _, f := range files {
+		file, ok := f.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("torrent file has an invalid file entry")
+		}
+
+		if strings.Contains(bencodeString(file["attr"]), "p") {
+			continue
+		}
+
+		segments := make([]string, 0)
+		if pathList, ok := file["path"].([]any); ok {
+			for _, segment := range pathList {
+				segments = append(segments, bencodeString(segment))
+			}
+		}
+
+		result = append(result, MetainfoFile{Path: path.Join(segments...), Size: bencodeInt(file["length"])})
+	}
+
+	return result, nil
+}
+
+// parseFileTree flattens a v2 file tree. A file is a dictionary holding an
+// empty key whose value describes it.
+func parseFileTree(tree map[string]any, parents []string) []MetainfoFile {
+	names := make([]string, 0, len(tree))
+	for name := range tree {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]MetainfoFile, 0)
+	for _, name := range names {
+		node, ok := tree[name].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		segments := append(slices.Clone(parents), name)
+
+		if details, ok := node[""].(map[string]any); ok {
+			files = append(files, MetainfoFile{Path: path.Join(segments...), Size: bencodeInt(details["length"])})
+			continue
+		}
+
+		files = append(files, parseFileTree(node, segments)...)
+	}
+
+	return files
+}
+
+// parseTrackers reads announce-list, falling back to announce.
+func parseTrackers(torrent map[string]any) []string {
+	trackers := make([]string, 0)
+	add := func(tracker string) {
+		if tracker != "" && !slices.Contains(trackers, tracker) {
+			trackers = append(trackers, tracker)
+		}
+	}
+
+	if tiers, ok := torrent["announce-list"].([]any); ok {
+		for _, tier := range tiers {
+			if urls, ok := tier.([]any); ok {
+				for _, url := range urls {
+					add(bencodeString(url))
+				}
+			}
+		}
+	}
+
+	add(bencodeString(torrent["announce"]))
+
+	return trackers
+}
+
+func bencodeString(value any) string {
+	s, _ := value.(string)
+	return s
+}
+
+func bencodeInt(value any) int64 {
+	i, _ := value.(int64)
+	return i
+}
@@ -0,0 +1,208 @@
+package torrentutil
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/jackpal/bencode-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeTorrent bencodes a torrent and returns it with the SHA-1 and SHA-256
+// hashes of its info dictionary, if it has one.
+func encodeTorrent(t *testing.T, torrent map[string]any) ([]byte, string, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	require.NoError(t, bencode.Marshal(&buf, torrent))
+
+	if _, ok := torrent["info"]; !ok {
+		return buf.Bytes(), "", ""
+	}
+
+	var infoBuf bytes.Buffer
+	require.NoError(t, bencode.Marshal(&infoBuf, torrent["info"]))
+
+	sha1Sum := sha1.Sum(infoBuf.Bytes())
+	sha256Sum := sha256.Sum256(infoBuf.Bytes())
+
+	return buf.Bytes(), hex.EncodeToString(sha1Sum[:]), hex.EncodeToString(sha256Sum[:])
+}
+
+func TestParseMetainfo_SingleFile(t *testing.T) {
+	data, v1Hash, _ := encodeTorrent(t, map[string]any{
+		"announce": "http://tracker.example.com/announce",
+		"info": map[string]any{
+			"name":         "Dune.m4b",
+			"piece length": 262144,
+			"pieces":       "12345678901234567890",
+			"length":       1024,
+		},
+	})
+
+	metainfo, err := ParseMetainfo(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Dune.m4b", metainfo.Name)
+	assert.Equal(t, int64(1024), metainfo.Size)
+	assert.Equal(t, []MetainfoFile{{Path: "Dune.m4b", Size: 1024}}, metainfo.Files)
+	assert.False(t, metainfo.Private)
+	assert.Equal(t, []string{"http://tracker.example.com/announce"}, metainfo.Trackers)
+	assert.Equal(t, v1Hash, metainfo.InfoHash)
+	assert.Empty(t, metainfo.InfoHashV2)
+	assert.False(t, metainfo.IsV2())
+	assert.Equal(t, v1Hash, metainfo.Hash())
+}
+
+func TestParseMetainfo_MultiFile(t *testing.T) {
+	data, _, _ := encodeTorrent(t, map[string]any{
+		"announce": "http://a.example.com/announce",
+		"announce-list": []any{
+			[]any{"http://a.example.com/announce", "http://b.example.com/announce"},
+			[]any{"udp://c.example.com:6969"},
+		},
+		"info": map[string]any{
+			"name":         "Dune",
+			"piece length": 262144,
+			"pieces":       "12345678901234567890",
+			"private":      1,
+			"files": []any{
+				map[string]any{"length": 1000, "path": []any{"Part 1.mp3"}},
+				map[string]any{"length": 24, "path": []any{".pad", "24"}, "attr": "p"},
+				map[string]any{"length": 2000, "path": []any{"Extras", "cover.jpg"}},
+			},
+		},
+	})
+
+	metainfo, err := ParseMetainfo(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Dune", metainfo.Name)
+	assert.Equal(t, int64(3000), metainfo.Size)
+	assert.Equal(t, []MetainfoFile{
+		{Path: "Dune/Part 1.mp3", Size: 1000},
+		{Path: "Dune/Extras/cover.jpg", Size: 2000},
+	}, metainfo.Files)
+	assert.True(t, metainfo.Private)
+	assert.Equal(t, []string{
+		"http://a.example.com/announce",
+		"http://b.example.com/announce",
+		"udp://c.example.com:6969",
+	}, metainfo.Trackers)
+}
+
+func TestParseMetainfo_V2Only(t *testing.T) {
+	data, _, v2Hash := encodeTorrent(t, map[string]any{
+		"info": map[string]any{
+			"name":         "Emma",
+			"piece length": 262144,
+			"meta version": 2,
+			"file tree": map[string]any{
+				"Emma.epub": map[string]any{"": map[string]any{"length": 512, "pieces root": "x"}},
+				"Art": map[string]any{
+					"cover.jpg": map[string]any{"": map[string]any{"length": 256}},
+				},
+			},
+		},
+	})
+
+	metainfo, err := ParseMetainfo(data)
+	require.NoError(t, err)
+
+	assert.Empty(t, metainfo.InfoHash)
+	assert.Equal(t, v2Hash, metainfo.InfoHashV2)
+	assert.True(t, metainfo.IsV2())
+	assert.Equal(t, v2Hash[:40], metainfo.Hash())
+	assert.Equal(t, []MetainfoFile{
+		{Path: "Emma/Art/cover.jpg", Size: 256},
+		{Path: "Emma/Emma.epub", Size: 512},
+	}, metainfo.Files)
+	assert.Equal(t, int64(768), metainfo.Size)
+	assert.Empty(t, metainfo.Trackers)
+}
+
+func TestParseMetainfo_V2SingleFile(t *testing.T) {
+	data, _, _ := encodeTorrent(t, map[string]any{
+		"info": map[string]any{
+			"name":         "Emma.epub",
+			"piece length": 262144,
+			"meta version": 2,
+			"file tree": map[string]any{
+				"Emma.epub": map[string]any{"": map[string]any{"length": 512}},
+			},
+		},
+	})
+
+	metainfo, err := ParseMetainfo(data)
+	require.NoError(t, err)
+	assert.Equal(t, []MetainfoFile{{Path: "Emma.epub", Size: 512}}, metainfo.Files)
+}
+
+func TestParseMetainfo_Hybrid(t *testing.T) {
+	data, v1Hash, v2Hash := encodeTorrent(t, map[string]any{
+		"info": map[string]any{
+			"name":         "Emma.epub",
+			"piece length": 262144,
+			"pieces":       "12345678901234567890",
+			"length":       512,
+			"meta version": 2,
+			"file tree": map[string]any{
+				"Emma.epub": map[string]any{"": map[string]any{"length": 512}},
+			},
+		},
+	})
+
+	metainfo, err := ParseMetainfo(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, v1Hash, metainfo.InfoHash)
+	assert.Equal(t, v2Hash, metainfo.InfoHashV2)
+	assert.True(t, metainfo.IsV2())
+
+	// Clients identify hybrid torrents by their v1 hash
+	assert.Equal(t, v1Hash, metainfo.Hash())
+
+	hash, err := ExtractInfoHash(data)
+	require.NoError(t, err)
+	assert.Equal(t, v1Hash, hash)
+}
+
+func TestParseMetainfo_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		torrent map[string]any
+		err     string
+	}{
+		{
+			name:    "missing info",
+			torrent: map[string]any{"announce": "http://tracker.example.com/announce"},
+			err:     "missing info dictionary",
+		},
+		{
+			name:    "v2 without file tree",
+			torrent: map[string]any{"info": map[string]any{"name": "x", "meta version": 2}},
+			err:     "missing file tree",
+		},
+		{
+			name:    "no files",
+			torrent: map[string]any{"info": map[string]any{"name": "x", "pieces": "12345678901234567890"}},
+			err:     "neither files nor length",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _, _ := encodeTorrent(t, tt.torrent)
+
+			_, err := ParseMetainfo(data)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+
+	_, err := ParseMetainfo([]byte("not a torrent"))
+	assert.ErrorContains(t, err, "failed to parse torrent file")
+}
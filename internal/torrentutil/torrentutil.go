@@ -1,19 +1,20 @@
 package torrentutil
 
 import (
-	"bytes"
 	"context"
-	"crypto/sha1"
-	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
 
-	"github.com/jackpal/bencode-go"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 )
 
+// ErrAddTorrent is wrapped by DownloadAndAdd when the download client rejects the torrent.
+var ErrAddTorrent = errors.New("failed to add torrent to download client")
+
 // TorrentDownloader downloads torrent files and extracts info hashes.
 type TorrentDownloader struct {
 	httpClient *http.Client
@@ -27,8 +28,13 @@ func NewTestTorrentDownloader() *TorrentDownloader {
 }
 
 // NewTorrentDownloader creates a new TorrentDownloader with HTTP/HTTPS proxy support.
-// Both httpProxy and httpsProxy are required and should be hostname:port (e.g., "myhost:8080").
+// Both httpProxy and httpsProxy should be hostname:port (e.g., "myhost:8080"). When both
+// are empty no proxy is used.
 func NewTorrentDownloader(httpProxy, httpsProxy string) *TorrentDownloader {
+	if httpProxy == "" && httpsProxy == "" {
+		return NewTestTorrentDownloader()
+	}
+
 	return &TorrentDownloader{
 		httpClient: &http.Client{
 			Transport: &http.Transport{
@@ -43,9 +49,91 @@ func NewTorrentDownloader(httpProxy, httpsProxy string) *TorrentDownloader {
 	}
 }
 
+// Release is a torrent ready to hand to a download client: a downloaded .torrent
+// file, or a magnet link the client resolves itself.
+type Release struct {
+	Hash string
+
+	// Data and Metainfo are the .torrent file, nil for magnet links
+	Data     []byte
+	Metainfo *Metainfo
+
+	// Magnet is the parsed magnet link, nil for .torrent files
+	Magnet *Magnet
+}
+
+// AddTo adds the release to the download client, uploading the .torrent file
+// or passing on the magnet link.
+func (r *Release) AddTo(ctx context.Context, client downloadclient.DownloadClient, options downloadclient.AddOptions) error {
+	if r.Magnet != nil {
+		return client.AddTorrentFromURL(ctx, r.Magnet.URI, options)
+	}
+
+	return client.AddTorrentFromBytes(ctx, r.Data, options)
+}
+
+// Fetch downloads the torrent file at link, or parses link when it is a magnet link.
+func (td *TorrentDownloader) Fetch(ctx context.Context, link string) (*Release, error) {
+	if IsMagnetURI(link) {
+		magnet, err := ParseMagnetURI(link)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Release{Hash: magnet.Hash(), Magnet: magnet}, nil
+	}
+
+	data, metainfo, err := td.Download(ctx, link)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Release{Hash: metainfo.Hash(), Data: data, Metainfo: metainfo}, nil
+}
+
 // DownloadAndHash downloads a torrent file from the given URL and returns its info hash.
-// The info hash is the SHA1 hash of the bencoded "info" dictionary.
+// The info hash is the SHA1 hash of the bencoded "info" dictionary. Magnet links are
+// parsed instead of downloaded.
 func (td *TorrentDownloader) DownloadAndHash(ctx context.Context, torrentURL string) (string, error) {
+	release, err := td.Fetch(ctx, torrentURL)
+	if err != nil {
+		return "", err
+	}
+
+	return release.Hash, nil
+}
+
+// DownloadAndAdd downloads a torrent file from the given URL and uploads it to the download
+// client, so the returned info hash is that of the torrent the client received. The client
+// never fetches the URL itself. Magnet links are handed to the client as they are. A torrent
+// the client already has is not added again.
+func (td *TorrentDownloader) DownloadAndAdd(ctx context.Context, client downloadclient.DownloadClient, torrentURL string, options downloadclient.AddOptions) (string, error) {
+	release, err := td.Fetch(ctx, torrentURL)
+	if err != nil {
+		return "", err
+	}
+
+	existing, err := client.GetTorrents(ctx, downloadclient.TorrentFilter{Hashes: []string{release.Hash}})
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAddTorrent, err)
+	}
+
+	if len(existing) > 0 {
+		slog.InfoContext(ctx, "Torrent already in download client, not adding it again",
+			slog.String("hash", release.Hash))
+		return release.Hash, nil
+	}
+
+	err = release.AddTo(ctx, client, options)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrAddTorrent, err)
+	}
+
+	return release.Hash, nil
+}
+
+// Download downloads a torrent file from the given URL and returns its contents and parsed metainfo.
+func (td *TorrentDownloader) Download(ctx context.Context, torrentURL string) ([]byte, *Metainfo, error) {
 	slog.DebugContext(ctx, "Downloading torrent", slog.String("url", torrentURL))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, torrentURL, nil)
@@ -53,7 +141,7 @@ func (td *TorrentDownloader) DownloadAndHash(ctx context.Context, torrentURL str
 		slog.ErrorContext(ctx, "Failed to create HTTP request",
 			slog.String("url", torrentURL),
 			slog.Any("error", err))
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	resp, err := td.httpClient.Do(req)
@@ -61,7 +149,7 @@ func (td *TorrentDownloader) DownloadAndHash(ctx context.Context, torrentURL str
 		slog.ErrorContext(ctx, "Failed to download torrent",
 			slog.String("url", torrentURL),
 			slog.Any("error", err))
-		return "", fmt.Errorf("failed to download torrent: %w", err)
+		return nil, nil, fmt.Errorf("failed to download torrent: %w", err)
 	}
 	defer func() {
 		// Drain body before closing for connection reuse
@@ -73,7 +161,7 @@ func (td *TorrentDownloader) DownloadAndHash(ctx context.Context, torrentURL str
 		slog.ErrorContext(ctx, "Unexpected HTTP status",
 			slog.String("url", torrentURL),
 			slog.Int("status", resp.StatusCode))
-		return "", fmt.Errorf("unexpected HTTP status: %d", resp.StatusCode)
+		return nil, nil, fmt.Errorf("unexpected HTTP status: %d", resp.StatusCode)
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -81,57 +169,33 @@ func (td *TorrentDownloader) DownloadAndHash(ctx context.Context, torrentURL str
 		slog.ErrorContext(ctx, "Failed to read response body",
 			slog.String("url", torrentURL),
 			slog.Any("error", err))
-		return "", fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	slog.DebugContext(ctx, "Downloaded torrent", slog.Int("bytes", len(body)))
 
-	hash, err := ExtractInfoHash(body)
+	metainfo, err := ParseMetainfo(body)
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to extract info hash",
 			slog.String("url", torrentURL),
 			slog.Any("error", err))
-		return "", err
+		return nil, nil, err
 	}
 
 	slog.DebugContext(ctx, "Extracted info hash",
 		slog.String("url", torrentURL),
-		slog.String("hash", hash))
+		slog.String("hash", metainfo.Hash()))
 
-	return hash, nil
+	return body, metainfo, nil
 }
 
-// ExtractInfoHash extracts the info hash from raw torrent file bytes.
+// ExtractInfoHash extracts the info hash download clients identify the torrent by
+// from raw torrent file bytes. See Metainfo.Hash.
 func ExtractInfoHash(torrentData []byte) (string, error) {
-	reader := bytes.NewReader(torrentData)
-
-	// Use bencode.Decode to parse into a generic map (Unmarshal with struct doesn't work for nested dicts)
-	decoded, err := bencode.Decode(reader)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse torrent file: %w", err)
-	}
-
-	torrent, ok := decoded.(map[string]any)
-	if !ok {
-		return "", fmt.Errorf("torrent file is not a dictionary")
-	}
-
-	info, ok := torrent["info"]
-	if !ok || info == nil {
-		return "", fmt.Errorf("torrent file missing info dictionary")
-	}
-
-	// Re-encode the info dictionary to get the exact bytes for hashing
-	var infoBuf bytes.Buffer
-	err = bencode.Marshal(&infoBuf, info)
+	metainfo, err := ParseMetainfo(torrentData)
 	if err != nil {
-		return "", fmt.Errorf("failed to re-encode info dictionary: %w", err)
+		return "", err
 	}
 
-	// SHA1 hash the bencoded info dictionary
-	hasher := sha1.New()
-	hasher.Write(infoBuf.Bytes())
-	hash := hasher.Sum(nil)
-
-	return hex.EncodeToString(hash), nil
+	return metainfo.Hash(), nil
 }
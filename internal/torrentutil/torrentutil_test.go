@@ -3,6 +3,7 @@ package torrentutil
 import (
 	"bytes"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -10,6 +11,9 @@ import (
 	"github.com/jackpal/bencode-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/testutil"
 )
 
 // createTestTorrent creates a valid torrent file bytes for testing.
@@ -61,6 +65,136 @@ func TestDownloadAndHash(t *testing.T) {
 	assert.Regexp(t, "^[a-f0-9]{40}$", hash)
 }
 
+func TestNewTorrentDownloader_NoProxy(t *testing.T) {
+	td := NewTorrentDownloader("", "")
+	assert.Nil(t, td.httpClient.Transport)
+}
+
+func TestDownload(t *testing.T) {
+	torrentData := createTestTorrent(t, "test-file.txt")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(torrentData)
+	}))
+	defer server.Close()
+
+	td := createTestDownloader()
+	data, metainfo, err := td.Download(context.Background(), server.URL+"/test.torrent")
+	require.NoError(t, err)
+
+	expectedHash, err := ExtractInfoHash(torrentData)
+	require.NoError(t, err)
+	assert.Equal(t, torrentData, data)
+	assert.Equal(t, expectedHash, metainfo.Hash())
+	assert.Equal(t, "test-file.txt", metainfo.Name)
+}
+
+func TestDownloadAndAdd(t *testing.T) {
+	torrentData := createTestTorrent(t, "test-file.txt")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(torrentData)
+	}))
+	defer server.Close()
+
+	mockClient := &testutil.MockDownloadClient{}
+	td := createTestDownloader()
+
+	hash, err := td.DownloadAndAdd(context.Background(), mockClient, server.URL+"/test.torrent", downloadclient.AddOptions{Category: "books"})
+	require.NoError(t, err)
+
+	expectedHash, err := ExtractInfoHash(torrentData)
+	require.NoError(t, err)
+	assert.Equal(t, expectedHash, hash)
+
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Empty(t, mockClient.AddTorrentCalls[0].URL)
+	assert.Equal(t, torrentData, mockClient.AddTorrentCalls[0].Data)
+	assert.Equal(t, "books", mockClient.AddTorrentCalls[0].Options.Category)
+}
+
+func TestFetch_Magnet(t *testing.T) {
+	td := createTestDownloader()
+
+	release, err := td.Fetch(context.Background(), "magnet:?xt=urn:btih:"+testV1Hash)
+	require.NoError(t, err)
+
+	assert.Equal(t, testV1Hash, release.Hash)
+	require.NotNil(t, release.Magnet)
+	assert.Nil(t, release.Data)
+	assert.Nil(t, release.Metainfo)
+}
+
+func TestDownloadAndAdd_Magnet(t *testing.T) {
+	mockClient := &testutil.MockDownloadClient{}
+	td := createTestDownloader()
+	magnetURI := "magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK&dn=Dune"
+
+	hash, err := td.DownloadAndAdd(context.Background(), mockClient, magnetURI, downloadclient.AddOptions{Category: "books"})
+	require.NoError(t, err)
+	assert.Equal(t, testV1Hash, hash)
+
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Equal(t, magnetURI, mockClient.AddTorrentCalls[0].URL)
+	assert.Nil(t, mockClient.AddTorrentCalls[0].Data)
+	assert.Equal(t, "books", mockClient.AddTorrentCalls[0].Options.Category)
+}
+
+func TestDownloadAndAdd_AlreadyInClient(t *testing.T) {
+	mockClient := &testutil.MockDownloadClient{}
+	mockClient.GetTorrentsReturn.Torrents = []downloadclient.Torrent{{Hash: testV1Hash}}
+	td := createTestDownloader()
+
+	hash, err := td.DownloadAndAdd(context.Background(), mockClient, "magnet:?xt=urn:btih:"+testV1Hash, downloadclient.AddOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, testV1Hash, hash)
+
+	require.Len(t, mockClient.GetTorrentsCalls, 1)
+	assert.Equal(t, []string{testV1Hash}, mockClient.GetTorrentsCalls[0].Hashes)
+	assert.Empty(t, mockClient.AddTorrentCalls)
+}
+
+func TestDownloadAndAdd_InvalidMagnet(t *testing.T) {
+	mockClient := &testutil.MockDownloadClient{}
+	td := createTestDownloader()
+
+	_, err := td.DownloadAndAdd(context.Background(), mockClient, "magnet:?dn=Dune", downloadclient.AddOptions{})
+	assert.ErrorContains(t, err, "no BitTorrent info hash")
+	assert.NotErrorIs(t, err, ErrAddTorrent)
+	assert.Empty(t, mockClient.AddTorrentCalls)
+}
+
+func TestDownloadAndAdd_DownloadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	mockClient := &testutil.MockDownloadClient{}
+	td := createTestDownloader()
+
+	_, err := td.DownloadAndAdd(context.Background(), mockClient, server.URL+"/test.torrent", downloadclient.AddOptions{})
+	assert.ErrorContains(t, err, "unexpected HTTP status: 404")
+	assert.NotErrorIs(t, err, ErrAddTorrent)
+	assert.Empty(t, mockClient.AddTorrentCalls)
+}
+
+func TestDownloadAndAdd_ClientError(t *testing.T) {
+	torrentData := createTestTorrent(t, "test-file.txt")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(torrentData)
+	}))
+	defer server.Close()
+
+	mockClient := &testutil.MockDownloadClient{AddTorrentReturn: errors.New("connection refused")}
+	td := createTestDownloader()
+
+	_, err := td.DownloadAndAdd(context.Background(), mockClient, server.URL+"/test.torrent", downloadclient.AddOptions{})
+	assert.ErrorIs(t, err, ErrAddTorrent)
+	assert.ErrorContains(t, err, "connection refused")
+}
+
 func TestDownloadAndHash_HTTPError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusNotFound)
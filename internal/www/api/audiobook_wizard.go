@@ -1,16 +1,20 @@
 package api
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
 
 	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/audible"
 	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/providers"
 	"github.com/bobbyrward/stronghold/internal/importers/common"
-	"github.com/bobbyrward/stronghold/internal/qbit"
+	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/labstack/echo/v5"
 	"gorm.io/gorm"
 )
@@ -26,6 +30,14 @@ type TorrentImportInfo struct {
 	Author           string `json:"author,omitempty"`
 	SuggestedLibrary string `json:"suggested_library,omitempty"`
 	LocalPath        string `json:"local_path"`
+
+	Candidates []MatchCandidateResponse `json:"candidates,omitempty"`
+}
+
+// MatchCandidateResponse is a scored metadata candidate saved when a torrent matched several editions
+type MatchCandidateResponse struct {
+	Score    float64               `json:"score"`
+	Metadata metadata.BookMetadata `json:"metadata"`
 }
 
 // SearchASINRequest contains the request body for ASIN search
@@ -66,30 +78,31 @@ func GetTorrentInfo(db *gorm.DB) echo.HandlerFunc {
 
 		slog.InfoContext(ctx, "Getting torrent import info", slog.String("hash", hash))
 
-		// Create qBittorrent client
-		qbitClient, err := qbit.CreateClient()
+		// Create download client
+		downloadClient, err := downloadclient.SharedClient()
 		if err != nil {
-			return InternalError(c, ctx, "failed to create qBittorrent client", err)
+			return InternalError(c, ctx, "failed to create download client", err)
 		}
 
-		// Get torrent by hash
-		torrents, err := qbitClient.GetTorrentsCtx(ctx, qbit.TorrentFilterOptions{Hashes: []string{hash}})
-		if err != nil || len(torrents) != 1 {
+		torrent, found, err := getTorrent(ctx, downloadClient, hash)
+		if err != nil {
+			return downloadClientError(c, ctx, "failed to get torrent from download client", err)
+		}
+		if !found {
 			return GenericNotFound(c, ctx, "torrent not found")
 		}
 
-		torrent := torrents[0]
-
-		// Create metadata provider and audible client
+		// Create metadata provider and metadata sources
 		metadataProvider := metadata.NewFFProbeMetadataProvider()
-		audibleClient := audible.NewAudibleApiClient()
+		metadataSources := providers.NewDefaultRegistry(config.Config.Hardcover.ApiToken)
 
 		// Create importer system
 		importer, err := audiobooks.NewAudiobookImporterSystem(
-			qbitClient,
+			downloadClient,
 			config.Config.Importers,
 			metadataProvider,
-			audibleClient,
+			metadata.NewFFProbeMediaProbe(),
+			metadataSources,
 			db,
 		)
 		if err != nil {
@@ -100,12 +113,13 @@ func GetTorrentInfo(db *gorm.DB) echo.HandlerFunc {
 		bookMetadata, err := importer.ExtractTorrentMetadata(ctx, torrent)
 
 		// Create response with available information
+		downloadPath, localDownloadPath := config.Config.DownloadPaths()
 		response := TorrentImportInfo{
 			Hash:      torrent.Hash,
 			Name:      torrent.Name,
 			Category:  torrent.Category,
-			Tags:      torrent.Tags,
-			LocalPath: common.MapTorrentContentPathToLocalPath(torrent, config.Config.Qbit.DownloadPath, config.Config.Qbit.LocalDownloadPath),
+			Tags:      strings.Join(torrent.Tags, ", "),
+			LocalPath: common.MapTorrentContentPathToLocalPath(torrent, downloadPath, localDownloadPath),
 		}
 
 		// If metadata extraction succeeded, include it
@@ -117,12 +131,19 @@ func GetTorrentInfo(db *gorm.DB) echo.HandlerFunc {
 			}
 		}
 
+		// Include ranked candidates when the match was ambiguous
+		candidates, err := audiobooks.LoadCandidates(db, torrent.Hash)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to load match candidates", slog.String("hash", hash), slog.Any("error", err))
+		}
+		response.Candidates = toMatchCandidateResponses(candidates)
+
 		// Determine suggested library based on torrent category
-		for _, importType := range config.Config.Importers.AudiobookImporter.ImportTypes {
-			if importType.Category == torrent.Category {
-				response.SuggestedLibrary = importType.Library
-				break
-			}
+		importType, ok, err := common.FindImportType(ctx, db, torrent.Category)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to load import type", slog.String("category", torrent.Category), slog.Any("error", err))
+		} else if ok {
+			response.SuggestedLibrary = importType.Library.Name
 		}
 
 		slog.InfoContext(ctx, "Successfully retrieved torrent info",
@@ -133,6 +154,34 @@ func GetTorrentInfo(db *gorm.DB) echo.HandlerFunc {
 	}
 }
 
+// GetTorrentCandidates returns the ranked metadata candidates saved for a torrent
+func GetTorrentCandidates(db *gorm.DB) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		ctx := c.Request().Context()
+		hash := c.Param("hash")
+
+		candidates, err := audiobooks.LoadCandidates(db, hash)
+		if err != nil {
+			return InternalError(c, ctx, "failed to load match candidates", err)
+		}
+
+		return c.JSON(http.StatusOK, toMatchCandidateResponses(candidates))
+	}
+}
+
+func toMatchCandidateResponses(candidates []audiobooks.ScoredCandidate) []MatchCandidateResponse {
+	responses := make([]MatchCandidateResponse, 0, len(candidates))
+
+	for _, candidate := range candidates {
+		responses = append(responses, MatchCandidateResponse{
+			Score:    candidate.Score,
+			Metadata: candidate.Metadata,
+		})
+	}
+
+	return responses
+}
+
 // SearchASIN searches for audiobooks by title and author, returning ASINs and metadata
 func SearchASIN(db *gorm.DB) echo.HandlerFunc {
 	return func(c *echo.Context) error {
@@ -256,7 +305,20 @@ func GetLibraries(db *gorm.DB) echo.HandlerFunc {
 
 		slog.InfoContext(ctx, "Getting audiobook libraries")
 
-		libraries := config.Config.Importers.AudiobookImporter.Libraries
+		var rows []models.Library
+		err := db.WithContext(ctx).
+			Joins("BookType").
+			Where("\"BookType\".\"name\" = ?", common.MediaTypeAudiobook).
+			Order("libraries.name").
+			Find(&rows).Error
+		if err != nil {
+			return InternalError(c, ctx, "failed to load libraries", err)
+		}
+
+		libraries := make([]config.ImportLibrary, 0, len(rows))
+		for _, row := range rows {
+			libraries = append(libraries, *common.ImportLibraryFromModel(row))
+		}
 
 		slog.InfoContext(ctx, "Successfully retrieved libraries",
 			slog.Int("count", len(libraries)))
@@ -284,76 +346,32 @@ func ExecuteImport(db *gorm.DB) echo.HandlerFunc {
 			slog.String("library", req.LibraryName),
 			slog.String("title", req.Metadata.Title))
 
-		// Create qBittorrent client
-		qbitClient, err := qbit.CreateClient()
+		// Create download client
+		downloadClient, err := downloadclient.SharedClient()
 		if err != nil {
-			return InternalError(c, ctx, "failed to create qBittorrent client", err)
+			return InternalError(c, ctx, "failed to create download client", err)
 		}
 
-		// Get torrent by hash
-		torrents, err := qbitClient.GetTorrentsCtx(ctx, qbit.TorrentFilterOptions{Hashes: []string{req.Hash}})
-		if err != nil || len(torrents) != 1 {
+		torrent, found, err := getTorrent(ctx, downloadClient, req.Hash)
+		if err != nil {
+			return downloadClientError(c, ctx, "failed to get torrent from download client", err)
+		}
+		if !found {
 			return GenericNotFound(c, ctx, "torrent not found")
 		}
 
-		torrent := torrents[0]
-
 		// Find the library
-		library, ok := config.FindLibraryByName(config.Config.Importers.AudiobookImporter.Libraries, req.LibraryName)
-		if !ok {
+		var libraryRow models.Library
+		err = db.WithContext(ctx).Preload("BookType").Where("name = ?", req.LibraryName).First(&libraryRow).Error
+		if err != nil || libraryRow.BookType.Name != common.MediaTypeAudiobook {
 			return BadRequest(c, ctx, "library not found")
 		}
 
-		// Create metadata provider and audible client
-		metadataProvider := metadata.NewFFProbeMetadataProvider()
-		audibleClient := audible.NewAudibleApiClient()
-
-		// Create importer system
-		importer, err := audiobooks.NewAudiobookImporterSystem(
-			qbitClient,
-			config.Config.Importers,
-			metadataProvider,
-			audibleClient,
-			db,
-		)
-		if err != nil {
-			return InternalError(c, ctx, "failed to create audiobook importer", err)
-		}
-
-		// Get local path
-		localPath := common.MapTorrentContentPathToLocalPath(torrent, config.Config.Qbit.DownloadPath, config.Config.Qbit.LocalDownloadPath)
-
-		// Execute the import
-		destinationPath, err := importer.ExecuteImport(ctx, torrent, req.Metadata, library, localPath)
+		destinationPath, err := importAudiobook(ctx, db, downloadClient, torrent, req.Metadata, libraryRow)
 		if err != nil {
 			return InternalError(c, ctx, "failed to execute import", err)
 		}
 
-		// Add imported tag
-		err = qbitClient.AddTagsCtx(ctx, []string{req.Hash}, config.Config.Importers.ImportedTag)
-		if err != nil {
-			slog.WarnContext(ctx, "Failed to add imported tag",
-				slog.String("hash", req.Hash),
-				slog.Any("error", err))
-		}
-
-		// Remove manual_intervention tag
-		err = qbitClient.RemoveTagsCtx(ctx, []string{req.Hash}, config.Config.Importers.ManualInterventionTag)
-		if err != nil {
-			slog.WarnContext(ctx, "Failed to remove manual_intervention tag",
-				slog.String("hash", req.Hash),
-				slog.Any("error", err))
-		}
-
-		// Find import type by category to get notification settings
-		for _, importType := range config.Config.Importers.AudiobookImporter.ImportTypes {
-			if importType.Category == torrent.Category {
-				// Send notification if configured
-				importer.SendDiscordNotification(ctx, req.Metadata, importType)
-				break
-			}
-		}
-
 		response := ExecuteImportResponse{
 			Success:         true,
 			DestinationPath: destinationPath,
@@ -368,6 +386,80 @@ func ExecuteImport(db *gorm.DB) echo.HandlerFunc {
 	}
 }
 
+// importAudiobook imports a torrent into library with the given metadata, tags it
+// as imported and resolves its manual intervention.
+func importAudiobook(ctx context.Context, db *gorm.DB, downloadClient downloadclient.DownloadClient, torrent downloadclient.Torrent, bookMetadata metadata.BookMetadata, libraryRow models.Library) (string, error) {
+	library := common.ImportLibraryFromModel(libraryRow)
+
+	// Create metadata provider and metadata sources
+	metadataProvider := metadata.NewFFProbeMetadataProvider()
+	metadataSources := providers.NewDefaultRegistry(config.Config.Hardcover.ApiToken)
+
+	// Create importer system
+	importer, err := audiobooks.NewAudiobookImporterSystem(
+		downloadClient,
+		config.Config.Importers,
+		metadataProvider,
+		metadata.NewFFProbeMediaProbe(),
+		metadataSources,
+		db,
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create audiobook importer: %w", err)
+	}
+
+	// Get local path
+	downloadPath, localDownloadPath := config.Config.DownloadPaths()
+	localPath := common.MapTorrentContentPathToLocalPath(torrent, downloadPath, localDownloadPath)
+
+	// Execute the import
+	destinationPath, err := importer.ExecuteImport(ctx, torrent, bookMetadata, library, localPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Add imported tag
+	err = downloadClient.AddTags(ctx, []string{torrent.Hash}, config.Config.Importers.ImportedTag)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to add imported tag",
+			slog.String("hash", torrent.Hash),
+			slog.Any("error", err))
+	}
+
+	// Remove manual_intervention tag
+	err = downloadClient.RemoveTags(ctx, []string{torrent.Hash}, config.Config.Importers.ManualInterventionTag)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to remove manual_intervention tag",
+			slog.String("hash", torrent.Hash),
+			slog.Any("error", err))
+	}
+
+	err = common.CloseManualIntervention(ctx, db, torrent, common.ResolutionImport)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to resolve manual intervention",
+			slog.String("hash", torrent.Hash),
+			slog.Any("error", err))
+	}
+
+	// Find import type by category to get notification settings
+	importType, ok, err := common.FindImportType(ctx, db, torrent.Category)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to load import type", slog.String("category", torrent.Category), slog.Any("error", err))
+		ok = false
+	}
+
+	// Waiting for Audiobookshelf to scan the book can take a while, so the
+	// response does not wait for it
+	go func(ctx context.Context) {
+		itemURL := importer.LinkAudiobookshelfItem(ctx, library, destinationPath, bookMetadata)
+		if ok {
+			importer.SendDiscordNotification(ctx, bookMetadata, common.ImportTypeConfig(importType), itemURL)
+		}
+	}(context.WithoutCancel(ctx))
+
+	return destinationPath, nil
+}
+
 // sanitizeName sanitizes a directory name by replacing invalid characters
 func sanitizeName(name string) string {
 	return strings.ReplaceAll(name, "/", "-")
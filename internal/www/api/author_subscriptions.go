@@ -274,13 +274,15 @@ func DeleteAuthorSubscription(db *gorm.DB) echo.HandlerFunc {
 
 // AuthorSubscriptionItemResponse is the response body for subscription items
 type AuthorSubscriptionItemResponse struct {
-	ID                   uint      `json:"id"`
-	AuthorSubscriptionID uint      `json:"author_subscription_id"`
-	TorrentHash          string    `json:"torrent_hash"`
-	BooksearchID         string    `json:"booksearch_id"`
-	TorrentUrl           string    `json:"torrent_url"`
-	Title                string    `json:"title"`
-	DownloadedAt         time.Time `json:"downloaded_at"`
+	ID                   uint       `json:"id"`
+	AuthorSubscriptionID uint       `json:"author_subscription_id"`
+	TorrentHash          string     `json:"torrent_hash"`
+	BooksearchID         string     `json:"booksearch_id"`
+	TorrentUrl           string     `json:"torrent_url"`
+	Title                string     `json:"title"`
+	DownloadedAt         time.Time  `json:"downloaded_at"`
+	Status               string     `json:"status"`
+	StatusCheckedAt      *time.Time `json:"status_checked_at"`
 }
 
 // itemToResponse converts an AuthorSubscriptionItem model to a response
@@ -293,6 +295,8 @@ func itemToResponse(item models.AuthorSubscriptionItem, torrentUrlPrefix string)
 		TorrentUrl:           torrentUrlPrefix + item.BooksearchID,
 		Title:                item.Title,
 		DownloadedAt:         item.DownloadedAt,
+		Status:               item.Status,
+		StatusCheckedAt:      item.StatusCheckedAt,
 	}
 }
 
@@ -1,12 +1,15 @@
 package api
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
-	"github.com/bobbyrward/stronghold/internal/qbit"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
 	"github.com/labstack/echo/v5"
 	"gorm.io/gorm"
 )
@@ -19,7 +22,8 @@ type BookTorrentDLRequest struct {
 }
 
 // DownloadBookTorrent handles requests to download a book torrent using the provided category and torrent information.
-func DownloadBookTorrent(db *gorm.DB, qbitClient *qbit.QbitClient) echo.HandlerFunc {
+// The torrent file is downloaded here and uploaded to the download client, so the returned hash is the one the client received.
+func DownloadBookTorrent(db *gorm.DB, downloadClient *downloadclient.DownloadClient, torrentDownloader *torrentutil.TorrentDownloader) echo.HandlerFunc {
 	return func(c *echo.Context) error {
 		ctx := c.Request().Context()
 
@@ -32,42 +36,51 @@ func DownloadBookTorrent(db *gorm.DB, qbitClient *qbit.QbitClient) echo.HandlerF
 			return BadRequest(c, ctx, "invalid request body")
 		}
 
-		if qbitClient == nil {
-			client, err := qbit.CreateClient()
+		if torrentutil.IsMagnetURI(req.TorrentURL) {
+			if _, err := torrentutil.ParseMagnetURI(req.TorrentURL); err != nil {
+				return BadRequest(c, ctx, "invalid magnet link")
+			}
+		}
+
+		if downloadClient == nil {
+			client, err := resolveDownloadClient(nil)
 			if err != nil {
-				return InternalError(c, ctx, "failed to create qBittorrent client", err)
+				return InternalError(c, ctx, "failed to create download client", err)
 			}
 
-			qbitClient = &client
+			downloadClient = &client
 		}
 
-		slog.InfoContext(ctx, "Adding book torrent to qbit",
+		if torrentDownloader == nil {
+			torrentDownloader = torrentutil.NewTorrentDownloader(config.Config.BookSearch.HttpProxy, config.Config.BookSearch.HttpsProxy)
+		}
+
+		slog.InfoContext(ctx, "Adding book torrent to download client",
 			slog.String("category", req.Category),
 			slog.Any("torrent_id", req.TorrentID),
 		)
 
-		_, err := (*qbitClient).AddTorrentFromUrlCtx(
-			ctx,
-			req.TorrentURL,
-			map[string]string{
-				"autoTMM":  "true",
-				"category": req.Category,
-			},
-		)
+		hash, err := torrentDownloader.DownloadAndAdd(ctx, *downloadClient, req.TorrentURL, downloadclient.AddOptions{
+			Category: req.Category,
+		})
+		if errors.Is(err, torrentutil.ErrAddTorrent) {
+			return InternalError(c, ctx, "failed to add torrent to download client", err)
+		}
 		if err != nil {
-			return InternalError(c, ctx, "failed to add torrent to qbit", err)
+			return InternalError(c, ctx, "failed to download torrent", err)
 		}
 
-		slog.InfoContext(ctx, "Successfully added book torrent to qbit",
+		slog.InfoContext(ctx, "Successfully added book torrent to download client",
 			slog.String("category", req.Category),
 			slog.String("torrent_id", req.TorrentID),
+			slog.String("hash", hash),
 		)
 
 		eventlog.Log(db, eventlog.CategoryDownload, eventlog.EventTorrentAdded, eventlog.SourceAPI,
-			eventlog.EntityTorrent, req.TorrentID,
+			eventlog.EntityTorrent, hash,
 			fmt.Sprintf("Downloaded via API: torrent %s (%s)", req.TorrentID, req.Category),
-			map[string]string{"category": req.Category, "torrent_id": req.TorrentID})
+			map[string]string{"category": req.Category, "torrent_id": req.TorrentID, "hash": hash})
 
-		return c.JSON(http.StatusOK, map[string]string{})
+		return c.JSON(http.StatusOK, map[string]string{"hash": hash})
 	}
 }
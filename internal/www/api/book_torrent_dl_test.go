@@ -8,13 +8,40 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
 	"github.com/bobbyrward/stronghold/internal/models"
-	"github.com/bobbyrward/stronghold/internal/qbit"
 	"github.com/bobbyrward/stronghold/internal/testutil"
+	"github.com/bobbyrward/stronghold/internal/torrentutil"
+	"github.com/jackpal/bencode-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newTestTorrentServer serves a valid torrent file at every path
+func newTestTorrentServer(t *testing.T) ([]byte, *httptest.Server) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	err := bencode.Marshal(&buf, map[string]any{
+		"info": map[string]any{
+			"name":         "book.epub",
+			"piece length": 262144,
+			"pieces":       "12345678901234567890",
+			"length":       1024,
+		},
+	})
+	require.NoError(t, err)
+
+	torrentData := buf.Bytes()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-bittorrent")
+		_, _ = w.Write(torrentData)
+	}))
+	t.Cleanup(server.Close)
+
+	return torrentData, server
+}
+
 // TestDownloadBookTorrent_Success tests successful torrent download
 func TestDownloadBookTorrent_Success(t *testing.T) {
 	db, err := models.ConnectTestDB()
@@ -22,16 +49,61 @@ func TestDownloadBookTorrent_Success(t *testing.T) {
 
 	e := SetupTestServerWithDB(db)
 
-	mockClient := &testutil.MockQbitClient{}
-	var qbitClient qbit.QbitClient = mockClient
+	torrentData, torrentServer := newTestTorrentServer(t)
+
+	mockClient := &testutil.MockDownloadClient{}
+	var downloadClient downloadclient.DownloadClient = mockClient
 
 	// Register the route with mock client
-	e.POST("/api/book-download", DownloadBookTorrent(db, &qbitClient))
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
 
 	req := BookTorrentDLRequest{
 		Category:   "audiobooks",
 		TorrentID:  "12345",
-		TorrentURL: "https://example.com/torrent/12345",
+		TorrentURL: torrentServer.URL + "/torrent/12345",
+	}
+
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/book-download", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httpReq)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Verify the downloaded bytes were uploaded with correct parameters
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	call := mockClient.AddTorrentCalls[0]
+	assert.Empty(t, call.URL)
+	assert.Equal(t, torrentData, call.Data)
+	assert.Equal(t, "audiobooks", call.Options.Category)
+
+	// Verify response carries the hash of the uploaded torrent
+	expectedHash, err := torrentutil.ExtractInfoHash(torrentData)
+	require.NoError(t, err)
+
+	var response map[string]string
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedHash, response["hash"])
+}
+
+// TestDownloadBookTorrent_MagnetLink tests that magnet links are handed to the download client
+func TestDownloadBookTorrent_MagnetLink(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	e := SetupTestServerWithDB(db)
+
+	mockClient := &testutil.MockDownloadClient{}
+	var downloadClient downloadclient.DownloadClient = mockClient
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
+
+	magnetURI := "magnet:?xt=urn:btih:YEX6DQDLXISUVHOJ6UM3GNNKPQJWPKEK&dn=Dune"
+	req := BookTorrentDLRequest{
+		Category:   "audiobooks",
+		TorrentID:  "12345",
+		TorrentURL: magnetURI,
 	}
 
 	body, _ := json.Marshal(req)
@@ -42,17 +114,80 @@ func TestDownloadBookTorrent_Success(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, rec.Code)
 
-	// Verify the mock was called with correct parameters
-	require.Len(t, mockClient.AddTorrentFromUrlCtxCalls, 1)
-	call := mockClient.AddTorrentFromUrlCtxCalls[0]
-	assert.Equal(t, "https://example.com/torrent/12345", call.URL)
-	assert.Equal(t, "true", call.Options["autoTMM"])
-	assert.Equal(t, "audiobooks", call.Options["category"])
+	require.Len(t, mockClient.AddTorrentCalls, 1)
+	assert.Equal(t, magnetURI, mockClient.AddTorrentCalls[0].URL)
+	assert.Equal(t, "audiobooks", mockClient.AddTorrentCalls[0].Options.Category)
 
-	// Verify response
 	var response map[string]string
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
 	assert.NoError(t, err)
+	assert.Equal(t, "c12fe1c06bba254a9dc9f519b335aa7c1367a88a", response["hash"])
+}
+
+// TestDownloadBookTorrent_InvalidMagnetLink tests validation of magnet links
+func TestDownloadBookTorrent_InvalidMagnetLink(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	e := SetupTestServerWithDB(db)
+
+	mockClient := &testutil.MockDownloadClient{}
+	var downloadClient downloadclient.DownloadClient = mockClient
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
+
+	req := BookTorrentDLRequest{
+		Category:   "audiobooks",
+		TorrentID:  "12345",
+		TorrentURL: "magnet:?dn=Dune",
+	}
+
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/book-download", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, mockClient.AddTorrentCalls, 0)
+}
+
+// TestDownloadBookTorrent_DownloadError tests handling of a torrent that cannot be downloaded
+func TestDownloadBookTorrent_DownloadError(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	e := SetupTestServerWithDB(db)
+
+	torrentServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer torrentServer.Close()
+
+	mockClient := &testutil.MockDownloadClient{}
+	var downloadClient downloadclient.DownloadClient = mockClient
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
+
+	req := BookTorrentDLRequest{
+		Category:   "audiobooks",
+		TorrentID:  "12345",
+		TorrentURL: torrentServer.URL + "/torrent/12345",
+	}
+
+	body, _ := json.Marshal(req)
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/book-download", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httpReq)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	// Verify mock was not called
+	assert.Len(t, mockClient.AddTorrentCalls, 0)
+
+	var response map[string]string
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "failed to download torrent", response["error"])
 }
 
 // TestDownloadBookTorrent_MissingCategory tests validation error for missing category
@@ -62,9 +197,9 @@ func TestDownloadBookTorrent_MissingCategory(t *testing.T) {
 
 	e := SetupTestServerWithDB(db)
 
-	mockClient := &testutil.MockQbitClient{}
-	var qbitClient qbit.QbitClient = mockClient
-	e.POST("/api/book-download", DownloadBookTorrent(db, &qbitClient))
+	mockClient := &testutil.MockDownloadClient{}
+	var downloadClient downloadclient.DownloadClient = mockClient
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
 
 	req := BookTorrentDLRequest{
 		// Category missing
@@ -81,7 +216,7 @@ func TestDownloadBookTorrent_MissingCategory(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	// Verify mock was not called
-	assert.Len(t, mockClient.AddTorrentFromUrlCtxCalls, 0)
+	assert.Len(t, mockClient.AddTorrentCalls, 0)
 }
 
 // TestDownloadBookTorrent_MissingTorrentID tests validation error for missing torrent_id
@@ -91,9 +226,9 @@ func TestDownloadBookTorrent_MissingTorrentID(t *testing.T) {
 
 	e := SetupTestServerWithDB(db)
 
-	mockClient := &testutil.MockQbitClient{}
-	var qbitClient qbit.QbitClient = mockClient
-	e.POST("/api/book-download", DownloadBookTorrent(db, &qbitClient))
+	mockClient := &testutil.MockDownloadClient{}
+	var downloadClient downloadclient.DownloadClient = mockClient
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
 
 	req := BookTorrentDLRequest{
 		Category: "audiobooks",
@@ -110,7 +245,7 @@ func TestDownloadBookTorrent_MissingTorrentID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	// Verify mock was not called
-	assert.Len(t, mockClient.AddTorrentFromUrlCtxCalls, 0)
+	assert.Len(t, mockClient.AddTorrentCalls, 0)
 }
 
 // TestDownloadBookTorrent_MissingTorrentURL tests validation error for missing torrent_url
@@ -120,9 +255,9 @@ func TestDownloadBookTorrent_MissingTorrentURL(t *testing.T) {
 
 	e := SetupTestServerWithDB(db)
 
-	mockClient := &testutil.MockQbitClient{}
-	var qbitClient qbit.QbitClient = mockClient
-	e.POST("/api/book-download", DownloadBookTorrent(db, &qbitClient))
+	mockClient := &testutil.MockDownloadClient{}
+	var downloadClient downloadclient.DownloadClient = mockClient
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
 
 	req := BookTorrentDLRequest{
 		Category:  "audiobooks",
@@ -139,7 +274,7 @@ func TestDownloadBookTorrent_MissingTorrentURL(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	// Verify mock was not called
-	assert.Len(t, mockClient.AddTorrentFromUrlCtxCalls, 0)
+	assert.Len(t, mockClient.AddTorrentCalls, 0)
 }
 
 // TestDownloadBookTorrent_InvalidJSON tests handling of invalid JSON
@@ -149,9 +284,9 @@ func TestDownloadBookTorrent_InvalidJSON(t *testing.T) {
 
 	e := SetupTestServerWithDB(db)
 
-	mockClient := &testutil.MockQbitClient{}
-	var qbitClient qbit.QbitClient = mockClient
-	e.POST("/api/book-download", DownloadBookTorrent(db, &qbitClient))
+	mockClient := &testutil.MockDownloadClient{}
+	var downloadClient downloadclient.DownloadClient = mockClient
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
 
 	httpReq := httptest.NewRequest(http.MethodPost, "/api/book-download", bytes.NewReader([]byte("invalid json")))
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -161,7 +296,7 @@ func TestDownloadBookTorrent_InvalidJSON(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 
 	// Verify mock was not called
-	assert.Len(t, mockClient.AddTorrentFromUrlCtxCalls, 0)
+	assert.Len(t, mockClient.AddTorrentCalls, 0)
 }
 
 // TestDownloadBookTorrent_QbitClientError tests handling of qBittorrent client errors
@@ -171,16 +306,18 @@ func TestDownloadBookTorrent_QbitClientError(t *testing.T) {
 
 	e := SetupTestServerWithDB(db)
 
-	mockClient := &testutil.MockQbitClient{
-		AddTorrentFromUrlCtxReturn: errors.New("qbittorrent connection failed"),
+	_, torrentServer := newTestTorrentServer(t)
+
+	mockClient := &testutil.MockDownloadClient{
+		AddTorrentReturn: errors.New("qbittorrent connection failed"),
 	}
-	var qbitClient qbit.QbitClient = mockClient
-	e.POST("/api/book-download", DownloadBookTorrent(db, &qbitClient))
+	var downloadClient downloadclient.DownloadClient = mockClient
+	e.POST("/api/book-download", DownloadBookTorrent(db, &downloadClient, torrentutil.NewTestTorrentDownloader()))
 
 	req := BookTorrentDLRequest{
 		Category:   "audiobooks",
 		TorrentID:  "12345",
-		TorrentURL: "https://example.com/torrent/12345",
+		TorrentURL: torrentServer.URL + "/torrent/12345",
 	}
 
 	body, _ := json.Marshal(req)
@@ -192,13 +329,13 @@ func TestDownloadBookTorrent_QbitClientError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 
 	// Verify mock was called
-	require.Len(t, mockClient.AddTorrentFromUrlCtxCalls, 1)
+	require.Len(t, mockClient.AddTorrentCalls, 1)
 
 	// Verify error response
 	var response map[string]string
 	err = json.Unmarshal(rec.Body.Bytes(), &response)
 	assert.NoError(t, err)
-	assert.Equal(t, "failed to add torrent to qbit", response["error"])
+	assert.Equal(t, "failed to add torrent to download client", response["error"])
 }
 
 // TestDownloadBookTorrent_NilQbitClient tests that handler creates client when nil
@@ -211,7 +348,7 @@ func TestDownloadBookTorrent_NilQbitClient(t *testing.T) {
 	e := SetupTestServerWithDB(db)
 
 	// Pass nil client - handler should create one
-	e.POST("/api/book-download", DownloadBookTorrent(db, nil))
+	e.POST("/api/book-download", DownloadBookTorrent(db, nil, nil))
 
 	req := BookTorrentDLRequest{
 		Category:   "audiobooks",
@@ -0,0 +1,271 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/booksearch"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// BookSearcher searches the book tracker
+type BookSearcher interface {
+	Search(ctx context.Context, db *gorm.DB, params *booksearch.SearchParameters) (*booksearch.SearchResponse, error)
+}
+
+// BookSearchResultResponse is a single book search result
+type BookSearchResultResponse struct {
+	TorrentID    int    `json:"torrent_id"`
+	TorrentURL   string `json:"torrent_url"`
+	Title        string `json:"title"`
+	Authors      string `json:"authors"`
+	Series       string `json:"series"`
+	Narrators    string `json:"narrators"`
+	Category     string `json:"category"`
+	MainCategory int    `json:"main_category"`
+	Language     string `json:"language"`
+	FileTypes    string `json:"file_types"`
+	Size         string `json:"size"`
+	Tags         string `json:"tags"`
+	Seeders      int    `json:"seeders"`
+	Leechers     int    `json:"leechers"`
+	Added        string `json:"added"`
+}
+
+// PaginatedBookSearchResponse is a page of book search results. Total is the
+// number of matches across all pages.
+type PaginatedBookSearchResponse struct {
+	Items   []BookSearchResultResponse `json:"items"`
+	Total   int                        `json:"total"`
+	Page    int                        `json:"page"`
+	PerPage int                        `json:"per_page"`
+	HasMore bool                       `json:"has_more"`
+	Cached  bool                       `json:"cached"`
+}
+
+// parseIntList parses a comma separated list of integers
+func parseIntList(value string) ([]int, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var values []int
+	for _, part := range strings.Split(value, ",") {
+		v, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	return values, nil
+}
+
+// parseStringList parses a comma separated list, dropping empty entries
+func parseStringList(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+
+	return values
+}
+
+// SearchBooks handles GET /booksearch and records the search in the search
+// history. A nil searcher uses the book search service.
+func SearchBooks(db *gorm.DB, searcher BookSearcher) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		ctx := c.Request().Context()
+
+		if searcher == nil {
+			searcher = booksearch.NewBookSearchService()
+		}
+
+		query := c.QueryParam("q")
+		if query == "" {
+			return BadRequest(c, ctx, "Query parameter 'q' is required")
+		}
+
+		page, _ := strconv.Atoi(c.QueryParam("page"))
+		if page < 1 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(c.QueryParam("per_page"))
+		if perPage < 1 {
+			perPage = 25
+		}
+		if perPage > 100 {
+			perPage = 100
+		}
+
+		params := booksearch.SearchParameters{
+			Query:      query,
+			MaxResults: perPage,
+			Offset:     (page - 1) * perPage,
+			Formats:    parseStringList(c.QueryParam("filetype")),
+			StartDate:  c.QueryParam("from"),
+			EndDate:    c.QueryParam("to"),
+			SearchType: c.QueryParam("search_type"),
+			SortType:   c.QueryParam("sort"),
+			SkipCache:  c.QueryParam("no_cache") == "true",
+		}
+
+		var err error
+		if params.Languages, err = parseIntList(c.QueryParam("language")); err != nil {
+			return BadRequest(c, ctx, "Invalid language")
+		}
+		if params.Categories, err = parseIntList(c.QueryParam("category")); err != nil {
+			return BadRequest(c, ctx, "Invalid category")
+		}
+		for _, value := range parseStringList(c.QueryParam("type")) {
+			mainCategory, err := booksearch.ParseMainCategory(value)
+			if err != nil {
+				return BadRequest(c, ctx, err.Error())
+			}
+			params.MainCategories = append(params.MainCategories, mainCategory)
+		}
+
+		if err := params.Validate(); err != nil {
+			return BadRequest(c, ctx, err.Error())
+		}
+
+		slog.InfoContext(ctx, "Searching books", slog.String("query", query), slog.Int("page", page))
+
+		result, err := searcher.Search(ctx, db, &params)
+		if err != nil {
+			return InternalError(c, ctx, "Failed to search books", err)
+		}
+
+		requester := booksearch.Requester{Source: booksearch.HistorySourceAPI, RequestedBy: c.RealIP()}
+		if _, err := booksearch.RecordSearch(db, requester, &params, result); err != nil {
+			slog.ErrorContext(ctx, "Failed to record search history", slog.Any("error", err))
+		}
+
+		response := PaginatedBookSearchResponse{
+			Items:   make([]BookSearchResultResponse, len(result.Data)),
+			Total:   result.TotalFound,
+			Page:    page,
+			PerPage: perPage,
+			HasMore: result.HasMore(),
+			Cached:  result.Cached,
+		}
+
+		for i, item := range result.Data {
+			response.Items[i] = BookSearchResultResponse{
+				TorrentID:    item.ID,
+				TorrentURL:   item.DownloadTorrentURL(),
+				Title:        item.Title,
+				Authors:      item.AuthorNames(),
+				Series:       item.SeriesNames(),
+				Narrators:    item.NarratorNames(),
+				Category:     item.CategoryName,
+				MainCategory: item.MainCategory,
+				Language:     item.LanguageCode,
+				FileTypes:    item.FileTypes,
+				Size:         item.Size,
+				Tags:         item.Tags,
+				Seeders:      item.Seeders,
+				Leechers:     item.Leechers,
+				Added:        item.Added,
+			}
+		}
+
+		slog.InfoContext(ctx, "Book search completed", slog.Int("results", len(response.Items)), slog.Int("total", response.Total))
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+// SearchHistoryResponse is a recorded book search
+type SearchHistoryResponse struct {
+	ID          uint      `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Source      string    `json:"source"`
+	RequestedBy string    `json:"requested_by"`
+	Location    string    `json:"location"`
+	Query       string    `json:"query"`
+	ResultCount int       `json:"result_count"`
+	TotalFound  int       `json:"total_found"`
+	Cached      bool      `json:"cached"`
+}
+
+// PaginatedSearchHistoryResponse is a page of the search history, newest first
+type PaginatedSearchHistoryResponse struct {
+	Items   []SearchHistoryResponse `json:"items"`
+	Total   int64                   `json:"total"`
+	Page    int                     `json:"page"`
+	PerPage int                     `json:"per_page"`
+}
+
+// ListSearchHistory handles GET /booksearch/history
+func ListSearchHistory(db *gorm.DB) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		ctx := c.Request().Context()
+		slog.InfoContext(ctx, "Listing search history")
+
+		page, _ := strconv.Atoi(c.QueryParam("page"))
+		if page < 1 {
+			page = 1
+		}
+		perPage, _ := strconv.Atoi(c.QueryParam("per_page"))
+		if perPage < 1 {
+			perPage = 50
+		}
+		if perPage > 200 {
+			perPage = 200
+		}
+
+		query := db.Model(&models.SearchHistory{})
+		if source := c.QueryParam("source"); source != "" {
+			query = query.Where("source = ?", source)
+		}
+		if requestedBy := c.QueryParam("requested_by"); requestedBy != "" {
+			query = query.Where("requested_by = ?", requestedBy)
+		}
+		if q := c.QueryParam("q"); q != "" {
+			query = query.Where("LOWER(query) LIKE ?", "%"+strings.ToLower(EscapeLikePattern(q))+"%")
+		}
+
+		var total int64
+		if err := query.Count(&total).Error; err != nil {
+			return InternalError(c, ctx, "Failed to count search history", err)
+		}
+
+		var history []models.SearchHistory
+		err := query.Order("created_at DESC, id DESC").Limit(perPage).Offset((page - 1) * perPage).Find(&history).Error
+		if err != nil {
+			return InternalError(c, ctx, "Failed to list search history", err)
+		}
+
+		response := PaginatedSearchHistoryResponse{
+			Items:   make([]SearchHistoryResponse, len(history)),
+			Total:   total,
+			Page:    page,
+			PerPage: perPage,
+		}
+
+		for i, h := range history {
+			response.Items[i] = SearchHistoryResponse{
+				ID:          h.ID,
+				CreatedAt:   h.CreatedAt,
+				Source:      h.Source,
+				RequestedBy: h.RequestedBy,
+				Location:    h.Location,
+				Query:       h.Query,
+				ResultCount: h.ResultCount,
+				TotalFound:  h.TotalFound,
+				Cached:      h.Cached,
+			}
+		}
+
+		return c.JSON(http.StatusOK, response)
+	}
+}
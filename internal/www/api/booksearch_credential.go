@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/booksearch"
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+// TokenRefresher refreshes the book search token
+type TokenRefresher interface {
+	RefreshToken(ctx context.Context, db *gorm.DB) error
+}
+
+// BookSearchCredentialResponse reports the health of the book search token.
+// The token itself is never returned.
+type BookSearchCredentialResponse struct {
+	Healthy             bool       `json:"healthy"`
+	LastRefresh         *time.Time `json:"last_refresh"`
+	RefreshDueAt        *time.Time `json:"refresh_due_at"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	IPAddress           string     `json:"ip_address"`
+	ASN                 string     `json:"asn"`
+	PreviousIPAddress   string     `json:"previous_ip_address,omitempty"`
+	PreviousASN         string     `json:"previous_asn,omitempty"`
+	IPChangedAt         *time.Time `json:"ip_changed_at"`
+}
+
+func credentialToResponse(credential *models.BookSearchCredential) BookSearchCredentialResponse {
+	response := BookSearchCredentialResponse{
+		Healthy:             credential.ConsecutiveFailures == 0,
+		LastError:           credential.LastError,
+		LastFailureAt:       credential.LastFailureAt,
+		ConsecutiveFailures: credential.ConsecutiveFailures,
+		IPAddress:           credential.IPAddress,
+		ASN:                 credential.ASN,
+		PreviousIPAddress:   credential.PreviousIPAddress,
+		PreviousASN:         credential.PreviousASN,
+		IPChangedAt:         credential.IPChangedAt,
+	}
+
+	if !credential.LastRefresh.IsZero() {
+		lastRefresh := credential.LastRefresh
+		response.LastRefresh = &lastRefresh
+
+		if intervalHours := config.Config.BookSearch.TokenRefresh.IntervalHours; intervalHours > 0 {
+			refreshDueAt := lastRefresh.Add(time.Duration(intervalHours) * time.Hour)
+			response.RefreshDueAt = &refreshDueAt
+		}
+	}
+
+	return response
+}
+
+// GetBookSearchCredential handles GET /booksearch/credential
+func GetBookSearchCredential(db *gorm.DB) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		ctx := c.Request().Context()
+
+		credential, err := models.GetBookSearchCredential(db)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return GenericNotFound(c, ctx, "book search credential not configured")
+		}
+		if err != nil {
+			return InternalError(c, ctx, "Failed to get book search credential", err)
+		}
+
+		return c.JSON(http.StatusOK, credentialToResponse(credential))
+	}
+}
+
+// RefreshBookSearchCredential handles POST /booksearch/credential/refresh. A
+// nil refresher uses the book search service.
+func RefreshBookSearchCredential(db *gorm.DB, refresher TokenRefresher) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		ctx := c.Request().Context()
+
+		if refresher == nil {
+			refresher = booksearch.NewBookSearchService()
+		}
+
+		if _, err := models.GetBookSearchCredential(db); errors.Is(err, gorm.ErrRecordNotFound) {
+			return GenericNotFound(c, ctx, "book search credential not configured")
+		}
+
+		slog.InfoContext(ctx, "Refreshing book search token")
+
+		if err := refresher.RefreshToken(ctx, db); err != nil {
+			return InternalError(c, ctx, "Failed to refresh book search token", err)
+		}
+
+		credential, err := models.GetBookSearchCredential(db)
+		if err != nil {
+			return InternalError(c, ctx, "Failed to get book search credential", err)
+		}
+
+		return c.JSON(http.StatusOK, credentialToResponse(credential))
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+type fakeTokenRefresher struct {
+	err   error
+	calls int
+}
+
+func (f *fakeTokenRefresher) RefreshToken(ctx context.Context, db *gorm.DB) error {
+	f.calls++
+	if f.err != nil {
+		_, _ = models.RecordBookSearchCredentialFailure(db, f.err.Error())
+		return f.err
+	}
+	return models.UpsertBookSearchCredential(db, "refreshed", "198.51.100.7", "64500")
+}
+
+func TestBookSearchCredential(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	refresher := &fakeTokenRefresher{}
+
+	e := SetupTestServerWithDB(db)
+	e.POST("/api/test/booksearch/credential/refresh", RefreshBookSearchCredential(db, refresher))
+
+	do := func(method, path string) (*httptest.ResponseRecorder, BookSearchCredentialResponse) {
+		req := httptest.NewRequest(method, path, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		var response BookSearchCredentialResponse
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		}
+		return rec, response
+	}
+
+	rec, _ := do(http.MethodGet, "/api/booksearch/credential")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec, _ = do(http.MethodPost, "/api/test/booksearch/credential/refresh")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Zero(t, refresher.calls)
+
+	require.NoError(t, models.UpsertBookSearchCredential(db, "token", "192.0.2.1", "64500"))
+
+	rec, response := do(http.MethodGet, "/api/booksearch/credential")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, response.Healthy)
+	assert.Equal(t, "192.0.2.1", response.IPAddress)
+	assert.NotNil(t, response.LastRefresh)
+	assert.NotContains(t, rec.Body.String(), "token")
+
+	rec, response = do(http.MethodPost, "/api/test/booksearch/credential/refresh")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 1, refresher.calls)
+	assert.Equal(t, "198.51.100.7", response.IPAddress)
+	assert.Equal(t, "192.0.2.1", response.PreviousIPAddress)
+	assert.NotNil(t, response.IPChangedAt)
+
+	refresher.err = errors.New("unable to refresh token: 403 Forbidden")
+
+	rec, _ = do(http.MethodPost, "/api/test/booksearch/credential/refresh")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec, response = do(http.MethodGet, "/api/booksearch/credential")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.False(t, response.Healthy)
+	assert.Equal(t, 1, response.ConsecutiveFailures)
+	assert.Equal(t, "unable to refresh token: 403 Forbidden", response.LastError)
+	assert.NotNil(t, response.LastFailureAt)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/booksearch"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+type fakeBookSearcher struct {
+	params   []booksearch.SearchParameters
+	response booksearch.SearchResponse
+}
+
+func (f *fakeBookSearcher) Search(ctx context.Context, db *gorm.DB, params *booksearch.SearchParameters) (*booksearch.SearchResponse, error) {
+	f.params = append(f.params, *params)
+	response := f.response
+	response.Offset = params.Offset
+	return &response, nil
+}
+
+func TestSearchBooks(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	searcher := &fakeBookSearcher{response: booksearch.SearchResponse{
+		TotalFound: 45,
+		NextOffset: 40,
+		Data: []booksearch.SearchResponseItem{{
+			ID:           1234,
+			Title:        "Dune",
+			Authors:      map[string]string{"1": "Frank Herbert"},
+			CategoryName: "Audiobooks - Science Fiction",
+			MainCategory: booksearch.MainCategoryAudiobooks,
+			FileTypes:    "m4b",
+			Seeders:      12,
+			DlHash:       "abc",
+		}},
+	}}
+
+	e := SetupTestServerWithDB(db)
+	e.GET("/api/test/booksearch", SearchBooks(db, searcher))
+
+	search := func(query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/test/booksearch"+query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("filters and paging", func(t *testing.T) {
+		rec := search("?q=dune&page=3&per_page=20&language=1,36&type=audiobooks&category=49&filetype=m4b,mp3&from=2024-01-01&to=2024-12-31&search_type=all&sort=seedersDesc")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		require.Len(t, searcher.params, 1)
+		params := searcher.params[0]
+		assert.Equal(t, "dune", params.Query)
+		assert.Equal(t, 20, params.MaxResults)
+		assert.Equal(t, 40, params.Offset)
+		assert.Equal(t, []int{1, 36}, params.Languages)
+		assert.Equal(t, []int{booksearch.MainCategoryAudiobooks}, params.MainCategories)
+		assert.Equal(t, []int{49}, params.Categories)
+		assert.Equal(t, []string{"m4b", "mp3"}, params.Formats)
+		assert.Equal(t, "2024-01-01", params.StartDate)
+		assert.Equal(t, "2024-12-31", params.EndDate)
+		assert.Equal(t, "all", params.SearchType)
+		assert.Equal(t, "seedersDesc", params.SortType)
+
+		var response PaginatedBookSearchResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, 45, response.Total)
+		assert.Equal(t, 3, response.Page)
+		assert.Equal(t, 20, response.PerPage)
+		assert.True(t, response.HasMore)
+
+		require.Len(t, response.Items, 1)
+		assert.Equal(t, 1234, response.Items[0].TorrentID)
+		assert.Equal(t, "Frank Herbert", response.Items[0].Authors)
+		assert.Equal(t, 12, response.Items[0].Seeders)
+		assert.Contains(t, response.Items[0].TorrentURL, "/tor/download.php/abc")
+	})
+
+	t.Run("records history", func(t *testing.T) {
+		searcher.params = nil
+
+		rec := search("?q=frank+herbert&no_cache=true")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		require.Len(t, searcher.params, 1)
+		assert.True(t, searcher.params[0].SkipCache)
+
+		var history models.SearchHistory
+		require.NoError(t, db.Where("query = ?", "frank herbert").First(&history).Error)
+		assert.Equal(t, booksearch.HistorySourceAPI, history.Source)
+		assert.NotEmpty(t, history.RequestedBy)
+		assert.Equal(t, 1, history.ResultCount)
+		assert.Equal(t, 45, history.TotalFound)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		searcher.params = nil
+
+		for _, query := range []string{
+			"",
+			"?q=dune&language=english",
+			"?q=dune&type=comics",
+			"?q=dune&from=yesterday",
+			"?q=dune&sort=newest",
+		} {
+			rec := search(query)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+
+		assert.Empty(t, searcher.params)
+	})
+}
+
+func TestListSearchHistory(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	for _, h := range []booksearch.Requester{
+		{Source: booksearch.HistorySourceDiscordBot, RequestedBy: "user-1", Location: "channel-1"},
+		{Source: booksearch.HistorySourceAPI, RequestedBy: "192.0.2.1"},
+		{Source: booksearch.HistorySourceCLI, RequestedBy: "bobby"},
+	} {
+		_, err := booksearch.RecordSearch(db, h, &booksearch.SearchParameters{Query: "dune " + h.Source}, &booksearch.SearchResponse{})
+		require.NoError(t, err)
+	}
+
+	e := SetupTestServerWithDB(db)
+	e.GET("/api/test/booksearch/history", ListSearchHistory(db))
+
+	list := func(query string) PaginatedSearchHistoryResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/test/booksearch/history"+query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var response PaginatedSearchHistoryResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+
+	response := list("")
+	assert.Equal(t, int64(3), response.Total)
+	require.Len(t, response.Items, 3)
+	assert.Equal(t, booksearch.HistorySourceCLI, response.Items[0].Source)
+
+	response = list("?source=" + booksearch.HistorySourceDiscordBot)
+	require.Len(t, response.Items, 1)
+	assert.Equal(t, "user-1", response.Items[0].RequestedBy)
+	assert.Equal(t, "channel-1", response.Items[0].Location)
+
+	response = list("?requested_by=bobby")
+	require.Len(t, response.Items, 1)
+	assert.Equal(t, booksearch.HistorySourceCLI, response.Items[0].Source)
+
+	response = list("?q=DUNE&per_page=2&page=2")
+	assert.Equal(t, int64(3), response.Total)
+	require.Len(t, response.Items, 1)
+	assert.Equal(t, booksearch.HistorySourceDiscordBot, response.Items[0].Source)
+}
@@ -25,6 +25,13 @@ func logEventIfSupported[Model any, Request any, Response any](handler ModelHand
 	}
 }
 
+// responseCommitted reports whether a handler method already wrote the
+// response, as RequestToModel and UpdateModel do when rejecting a request.
+func responseCommitted(c *echo.Context) bool {
+	resp, err := echo.UnwrapResponse(c.Response())
+	return err == nil && resp.Committed
+}
+
 type ModelHandler[Model any, Request any, Response any] interface {
 	ModelToResponse(*echo.Context, context.Context, *gorm.DB, Model) Response
 	RequestToModel(*echo.Context, context.Context, *gorm.DB, Request) (Model, error)
@@ -97,7 +104,7 @@ func genericCreateHandler[Model any, Request any, Response any](
 		slog.InfoContext(ctx, "Creating row", slog.Any("request", req), slog.String("type", typeName))
 
 		row, err := handler.RequestToModel(c, ctx, db, req)
-		if err != nil {
+		if err != nil || responseCommitted(c) {
 			return err
 		}
 
@@ -196,7 +203,7 @@ func genericUpdateHandler[Model any, Request any, Response any](
 		}
 
 		err = handler.UpdateModel(c, ctx, db, &row, req)
-		if err != nil {
+		if err != nil || responseCommitted(c) {
 			return err
 		}
 
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/cappuccinotm/slogx"
+	"github.com/labstack/echo/v5"
+
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+)
+
+// DownloadClientHealthResponse is the response body for the download client health check
+type DownloadClientHealthResponse struct {
+	Healthy             bool       `json:"healthy"`
+	Error               string     `json:"error,omitempty"`
+	BreakerState        string     `json:"breaker_state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastFailureAt       *time.Time `json:"last_failure_at"`
+	OpenedAt            *time.Time `json:"opened_at"`
+	RetryAt             *time.Time `json:"retry_at"`
+}
+
+// GetDownloadClientHealth checks that the download client answers and reports
+// its circuit breaker. It responds 503 when the client is unreachable or the
+// breaker is open. A nil downloadClient uses the shared client.
+func GetDownloadClientHealth(downloadClient *downloadclient.ResilientClient) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		ctx := c.Request().Context()
+
+		client := downloadClient
+		if client == nil {
+			var err error
+			client, err = downloadclient.SharedClient()
+			if err != nil {
+				return InternalError(c, ctx, "failed to create download client", err)
+			}
+		}
+
+		response := DownloadClientHealthResponse{Healthy: true}
+
+		if err := client.Ping(ctx); err != nil {
+			slog.WarnContext(ctx, "Download client health check failed", slogx.Error(err))
+			response.Healthy = false
+			response.Error = err.Error()
+		}
+
+		health := client.Health()
+		response.BreakerState = health.State
+		response.ConsecutiveFailures = health.ConsecutiveFailures
+		response.LastError = health.LastError
+		response.LastFailureAt = health.LastFailureAt
+		response.OpenedAt = health.OpenedAt
+		response.RetryAt = health.RetryAt
+
+		status := http.StatusOK
+		if !response.Healthy {
+			status = http.StatusServiceUnavailable
+		}
+
+		return c.JSON(status, response)
+	}
+}
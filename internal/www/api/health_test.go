@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/testutil"
+)
+
+func getDownloadClientHealth(t *testing.T, client *downloadclient.ResilientClient) (int, DownloadClientHealthResponse) {
+	t.Helper()
+
+	e := echo.New()
+	e.GET("/api/test/health/download-client", GetDownloadClientHealth(client))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/test/health/download-client", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var response DownloadClientHealthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+
+	return rec.Code, response
+}
+
+func TestGetDownloadClientHealth(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		mockClient := &testutil.MockDownloadClient{}
+		client := downloadclient.NewResilientClient(mockClient, config.DownloadClientConfig{})
+
+		code, response := getDownloadClientHealth(t, client)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, response.Healthy)
+		assert.Equal(t, downloadclient.BreakerClosed, response.BreakerState)
+		assert.Zero(t, response.ConsecutiveFailures)
+		assert.Len(t, mockClient.GetTorrentsCalls, 1)
+	})
+
+	t.Run("breaker open", func(t *testing.T) {
+		mockClient := &testutil.MockDownloadClient{}
+		mockClient.GetTorrentsReturn.Err = &downloadclient.StatusError{StatusCode: http.StatusBadGateway, Message: "bad gateway"}
+		client := downloadclient.NewResilientClient(mockClient, config.DownloadClientConfig{BreakerFailures: 1})
+
+		code, response := getDownloadClientHealth(t, client)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.False(t, response.Healthy)
+		assert.Equal(t, "bad gateway", response.Error)
+		assert.Equal(t, downloadclient.BreakerOpen, response.BreakerState)
+		assert.Equal(t, 1, response.ConsecutiveFailures)
+		assert.NotNil(t, response.RetryAt)
+
+		// The open breaker answers without calling the client
+		code, response = getDownloadClientHealth(t, client)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.Equal(t, downloadclient.ErrCircuitOpen.Error(), response.Error)
+		assert.Len(t, mockClient.GetTorrentsCalls, 1)
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		mockClient := &testutil.MockDownloadClient{}
+		mockClient.GetTorrentsReturn.Err = errors.New("bad credentials")
+		client := downloadclient.NewResilientClient(mockClient, config.DownloadClientConfig{BreakerFailures: 1})
+
+		code, response := getDownloadClientHealth(t, client)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+		assert.False(t, response.Healthy)
+		assert.Equal(t, downloadclient.BreakerClosed, response.BreakerState)
+	})
+}
@@ -0,0 +1,238 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/calibre"
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/providers"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+type ImportTypeRequest struct {
+	CategoryName        string   `json:"category_name" validate:"required"`
+	LibraryName         string   `json:"library_name" validate:"required"`
+	NotifierName        *string  `json:"notifier_name"`
+	CalibreDesktopURL   string   `json:"calibre_desktop_url"`
+	CalibreWebURL       string   `json:"calibre_web_url"`
+	CalibreLibrary      string   `json:"calibre_library"`
+	MetadataProviders   []string `json:"metadata_providers"`
+	MinSeedTimeMinutes  int      `json:"min_seed_time_minutes" validate:"min=0"`
+	MinSeedRatio        float64  `json:"min_seed_ratio" validate:"min=0"`
+	RemoveAfterRatio    float64  `json:"remove_after_ratio" validate:"min=0"`
+	RemoveAfterSeedDays int      `json:"remove_after_seed_days" validate:"min=0"`
+	PrivateMinSeedDays  int      `json:"private_min_seed_days" validate:"min=0"`
+	RequireHardlink     bool     `json:"require_hardlink"`
+	DeleteFiles         bool     `json:"delete_files"`
+}
+
+type ImportTypeResponse struct {
+	ID                  uint     `json:"id"`
+	CategoryID          uint     `json:"category_id"`
+	CategoryName        string   `json:"category_name"`
+	MediaType           string   `json:"media_type"`
+	LibraryID           uint     `json:"library_id"`
+	LibraryName         string   `json:"library_name"`
+	NotifierID          *uint    `json:"notifier_id"`
+	NotifierName        *string  `json:"notifier_name"`
+	CalibreDesktopURL   string   `json:"calibre_desktop_url"`
+	CalibreWebURL       string   `json:"calibre_web_url"`
+	CalibreLibrary      string   `json:"calibre_library"`
+	MetadataProviders   []string `json:"metadata_providers"`
+	MinSeedTimeMinutes  int      `json:"min_seed_time_minutes"`
+	MinSeedRatio        float64  `json:"min_seed_ratio"`
+	RemoveAfterRatio    float64  `json:"remove_after_ratio"`
+	RemoveAfterSeedDays int      `json:"remove_after_seed_days"`
+	PrivateMinSeedDays  int      `json:"private_min_seed_days"`
+	RequireHardlink     bool     `json:"require_hardlink"`
+	DeleteFiles         bool     `json:"delete_files"`
+}
+
+type ImportTypeHandler struct{}
+
+func (h ImportTypeHandler) ModelToResponse(c *echo.Context, ctx context.Context, db *gorm.DB, row models.ImportType) ImportTypeResponse {
+	response := ImportTypeResponse{
+		ID:                  row.ID,
+		CategoryID:          row.TorrentCategoryID,
+		CategoryName:        row.TorrentCategory.Name,
+		MediaType:           row.TorrentCategory.MediaType,
+		LibraryID:           row.LibraryID,
+		LibraryName:         row.Library.Name,
+		NotifierID:          row.NotifierID,
+		CalibreDesktopURL:   row.CalibreDesktopURL,
+		CalibreWebURL:       row.CalibreWebURL,
+		CalibreLibrary:      row.CalibreLibrary,
+		MetadataProviders:   common.SplitMetadataProviders(row.MetadataProviders),
+		MinSeedTimeMinutes:  row.MinSeedTimeMinutes,
+		MinSeedRatio:        row.MinSeedRatio,
+		RemoveAfterRatio:    row.RemoveAfterRatio,
+		RemoveAfterSeedDays: row.RemoveAfterSeedDays,
+		PrivateMinSeedDays:  row.PrivateMinSeedDays,
+		RequireHardlink:     row.RequireHardlink,
+		DeleteFiles:         row.DeleteFiles,
+	}
+
+	if response.MetadataProviders == nil {
+		response.MetadataProviders = []string{}
+	}
+
+	if row.Notifier != nil {
+		response.NotifierName = &row.Notifier.Name
+	}
+
+	return response
+}
+
+// resolveImportType looks up the names in a request and validates them into row.
+func (h ImportTypeHandler) resolveImportType(c *echo.Context, ctx context.Context, db *gorm.DB, row *models.ImportType, req ImportTypeRequest) error {
+	var category models.TorrentCategory
+	if err := LookupByName(db, ctx, &category, req.CategoryName, "Torrent category"); err != nil {
+		return BadRequest(c, ctx, "Invalid category_name: "+req.CategoryName)
+	}
+
+	var library models.Library
+	if err := LookupByName(db.Preload("BookType"), ctx, &library, req.LibraryName, "Library"); err != nil {
+		return BadRequest(c, ctx, "Invalid library_name: "+req.LibraryName)
+	}
+
+	if library.BookType.Name != category.MediaType {
+		return BadRequest(c, ctx, fmt.Sprintf("Library %s holds %s, but category %s is %s", library.Name, library.BookType.Name, category.Name, category.MediaType))
+	}
+
+	var notifierID *uint
+	if req.NotifierName != nil && *req.NotifierName != "" {
+		var notifier models.Notifier
+		if err := LookupByName(db, ctx, &notifier, *req.NotifierName, "Notifier"); err != nil {
+			return BadRequest(c, ctx, "Invalid notifier_name: "+*req.NotifierName)
+		}
+		notifierID = &notifier.ID
+	}
+
+	if len(req.MetadataProviders) > 0 {
+		if category.MediaType != common.MediaTypeAudiobook {
+			return BadRequest(c, ctx, "metadata_providers only apply to audiobook categories")
+		}
+
+		registry := providers.NewDefaultRegistry(config.Config.Hardcover.ApiToken)
+		if _, err := registry.Chain(req.MetadataProviders, nil); err != nil {
+			return BadRequest(c, ctx, err.Error())
+		}
+	}
+
+	if req.CalibreLibrary != "" {
+		if category.MediaType != common.MediaTypeEbook {
+			return BadRequest(c, ctx, "calibre_library only applies to ebook categories")
+		}
+
+		if calibre.IsServerURL(req.CalibreLibrary) {
+			if _, err := calibre.ParseServerURL(req.CalibreLibrary); err != nil {
+				return BadRequest(c, ctx, err.Error())
+			}
+		}
+	}
+
+	row.TorrentCategoryID = category.ID
+	row.LibraryID = library.ID
+	row.NotifierID = notifierID
+	row.CalibreDesktopURL = req.CalibreDesktopURL
+	row.CalibreWebURL = req.CalibreWebURL
+	row.CalibreLibrary = req.CalibreLibrary
+	row.MetadataProviders = strings.Join(req.MetadataProviders, ",")
+	row.MinSeedTimeMinutes = req.MinSeedTimeMinutes
+	row.MinSeedRatio = req.MinSeedRatio
+	row.RemoveAfterRatio = req.RemoveAfterRatio
+	row.RemoveAfterSeedDays = req.RemoveAfterSeedDays
+	row.PrivateMinSeedDays = req.PrivateMinSeedDays
+	row.RequireHardlink = req.RequireHardlink
+	row.DeleteFiles = req.DeleteFiles
+	return nil
+}
+
+func (h ImportTypeHandler) RequestToModel(c *echo.Context, ctx context.Context, db *gorm.DB, req ImportTypeRequest) (models.ImportType, error) {
+	var row models.ImportType
+	if err := h.resolveImportType(c, ctx, db, &row, req); err != nil {
+		return models.ImportType{}, err
+	}
+
+	var existing int64
+	if err := db.WithContext(ctx).Model(&models.ImportType{}).Where("torrent_category_id = ?", row.TorrentCategoryID).Count(&existing).Error; err != nil {
+		return models.ImportType{}, InternalError(c, ctx, "Failed to check existing import types", err)
+	}
+	if existing > 0 {
+		return models.ImportType{}, BadRequest(c, ctx, "Category already has an import type: "+req.CategoryName)
+	}
+
+	return row, nil
+}
+
+func (h ImportTypeHandler) UpdateModel(c *echo.Context, ctx context.Context, db *gorm.DB, row *models.ImportType, req ImportTypeRequest) error {
+	categoryID := row.TorrentCategoryID
+
+	if err := h.resolveImportType(c, ctx, db, row, req); err != nil {
+		return err
+	}
+
+	if row.TorrentCategoryID != categoryID {
+		var existing int64
+		if err := db.WithContext(ctx).Model(&models.ImportType{}).Where("torrent_category_id = ?", row.TorrentCategoryID).Count(&existing).Error; err != nil {
+			return InternalError(c, ctx, "Failed to check existing import types", err)
+		}
+		if existing > 0 {
+			return BadRequest(c, ctx, "Category already has an import type: "+req.CategoryName)
+		}
+	}
+
+	return nil
+}
+
+func (h ImportTypeHandler) ParseQuery(c *echo.Context, ctx context.Context, db *gorm.DB) (*gorm.DB, error) {
+	// Optional: filter by library_id query param
+	return ApplyUintFilter(c, ctx, db, "library_id", "library_id")
+}
+
+func (h ImportTypeHandler) PreloadRelations(c *echo.Context, ctx context.Context, db *gorm.DB) (*gorm.DB, error) {
+	return db.Preload("TorrentCategory").Preload("Library").Preload("Notifier"), nil
+}
+
+func (h ImportTypeHandler) IDFromModel(row models.ImportType) uint {
+	return row.ID
+}
+
+func (h ImportTypeHandler) LogEvent(db *gorm.DB, eventType string, row models.ImportType) {
+	eventlog.Log(db, eventlog.CategoryMutation, eventlog.EntityImportType+"."+eventType, eventlog.SourceAPI,
+		eventlog.EntityImportType, fmt.Sprintf("%d", row.ID),
+		fmt.Sprintf("Import type %s: %d", eventType, row.ID),
+		map[string]any{"id": row.ID, "category_id": row.TorrentCategoryID, "library_id": row.LibraryID})
+}
+
+// ListImportTypes returns all import types
+func ListImportTypes(db *gorm.DB) echo.HandlerFunc {
+	return genericListHandler[models.ImportType, ImportTypeRequest, ImportTypeResponse](db, ImportTypeHandler{})
+}
+
+// CreateImportType creates a new import type
+func CreateImportType(db *gorm.DB) echo.HandlerFunc {
+	return genericCreateHandler[models.ImportType, ImportTypeRequest, ImportTypeResponse](db, ImportTypeHandler{})
+}
+
+// GetImportType returns a single import type by ID
+func GetImportType(db *gorm.DB) echo.HandlerFunc {
+	return genericGetHandler[models.ImportType, ImportTypeRequest, ImportTypeResponse](db, ImportTypeHandler{})
+}
+
+// UpdateImportType updates an existing import type
+func UpdateImportType(db *gorm.DB) echo.HandlerFunc {
+	return genericUpdateHandler[models.ImportType, ImportTypeRequest, ImportTypeResponse](db, ImportTypeHandler{})
+}
+
+// DeleteImportType deletes an import type
+func DeleteImportType(db *gorm.DB) echo.HandlerFunc {
+	return genericDeleteHandlerWithEventLog[models.ImportType, ImportTypeRequest, ImportTypeResponse](db, ImportTypeHandler{})
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func sendImportType(t *testing.T, e *echo.Echo, method, path string, req ImportTypeRequest) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest(method, path, bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httpReq)
+
+	return rec
+}
+
+func TestImportTypes_CRUD(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+	e := SetupTestServerWithDB(db)
+
+	ebookLib, audiobookLib := createTestLibraries(t, e, "import-types")
+
+	var discord models.NotificationType
+	require.NoError(t, db.Where("name = ?", "discord").First(&discord).Error)
+	require.NoError(t, db.Create(&models.Notifier{Name: "family-books", NotificationTypeID: discord.ID}).Error)
+
+	notifierName := "family-books"
+
+	var created ImportTypeResponse
+
+	t.Run("Create", func(t *testing.T) {
+		rec := sendImportType(t, e, http.MethodPost, "/api/import-types", ImportTypeRequest{
+			CategoryName:      "audiobooks",
+			LibraryName:       audiobookLib.Name,
+			NotifierName:      &notifierName,
+			MetadataProviders: []string{"audible", "openlibrary"},
+			MinSeedRatio:      1.5,
+			RemoveAfterRatio:  2,
+			RequireHardlink:   true,
+		})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		assert.Equal(t, "audiobooks", created.CategoryName)
+		assert.Equal(t, "audiobook", created.MediaType)
+		assert.Equal(t, audiobookLib.ID, created.LibraryID)
+		assert.Equal(t, audiobookLib.Name, created.LibraryName)
+		require.NotNil(t, created.NotifierName)
+		assert.Equal(t, "family-books", *created.NotifierName)
+		assert.Equal(t, []string{"audible", "openlibrary"}, created.MetadataProviders)
+		assert.Equal(t, 1.5, created.MinSeedRatio)
+		assert.True(t, created.RequireHardlink)
+	})
+
+	t.Run("Create rejects a second import type for the category", func(t *testing.T) {
+		rec := sendImportType(t, e, http.MethodPost, "/api/import-types", ImportTypeRequest{
+			CategoryName: "audiobooks",
+			LibraryName:  audiobookLib.Name,
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Create rejects a library of the wrong book type", func(t *testing.T) {
+		rec := sendImportType(t, e, http.MethodPost, "/api/import-types", ImportTypeRequest{
+			CategoryName: "books",
+			LibraryName:  audiobookLib.Name,
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Create rejects unknown names", func(t *testing.T) {
+		unknown := "nope"
+
+		for _, req := range []ImportTypeRequest{
+			{CategoryName: "nope", LibraryName: ebookLib.Name},
+			{CategoryName: "books", LibraryName: "nope"},
+			{CategoryName: "books", LibraryName: ebookLib.Name, NotifierName: &unknown},
+			{CategoryName: "family-audiobooks", LibraryName: audiobookLib.Name, MetadataProviders: []string{"goodreads"}},
+			{CategoryName: "family-audiobooks", LibraryName: audiobookLib.Name, CalibreLibrary: "/calibre"},
+			{CategoryName: "books", LibraryName: ebookLib.Name, CalibreLibrary: "http://"},
+		} {
+			rec := sendImportType(t, e, http.MethodPost, "/api/import-types", req)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, "%+v", req)
+		}
+
+		var count int64
+		require.NoError(t, db.Model(&models.ImportType{}).Count(&count).Error)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("Create with a Calibre library", func(t *testing.T) {
+		rec := sendImportType(t, e, http.MethodPost, "/api/import-types", ImportTypeRequest{
+			CategoryName:   "books",
+			LibraryName:    ebookLib.Name,
+			CalibreLibrary: "http://calibre:8080/#Books",
+			CalibreWebURL:  "https://calibre.example.com",
+		})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var calibreType ImportTypeResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &calibreType))
+		assert.Equal(t, "http://calibre:8080/#Books", calibreType.CalibreLibrary)
+
+		require.NoError(t, db.Delete(&models.ImportType{}, calibreType.ID).Error)
+	})
+
+	t.Run("List and Get", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/import-types", nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var list []ImportTypeResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		require.Len(t, list, 1)
+		assert.Equal(t, created.ID, list[0].ID)
+
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/import-types/%d", created.ID), nil)
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		rec := sendImportType(t, e, http.MethodPut, fmt.Sprintf("/api/import-types/%d", created.ID), ImportTypeRequest{
+			CategoryName:        "audiobooks",
+			LibraryName:         audiobookLib.Name,
+			RemoveAfterSeedDays: 30,
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var updated ImportTypeResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+		assert.Nil(t, updated.NotifierName)
+		assert.Empty(t, updated.MetadataProviders)
+		assert.Equal(t, 30, updated.RemoveAfterSeedDays)
+		assert.False(t, updated.RequireHardlink)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/import-types/%d", created.ID), nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/import-types/%d", created.ID), nil)
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
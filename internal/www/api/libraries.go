@@ -3,11 +3,14 @@ package api
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/labstack/echo/v5"
 	"gorm.io/gorm"
 
 	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/importers/ebooks"
 	"github.com/bobbyrward/stronghold/internal/models"
 )
 
@@ -15,39 +18,55 @@ type LibraryRequest struct {
 	Name         string `json:"name" validate:"required"`
 	Path         string `json:"path" validate:"required"`
 	BookTypeName string `json:"book_type_name" validate:"required"`
+
+	// PreferredFormats is the ebook format order, best first. Only ebook
+	// libraries may set it.
+	PreferredFormats []string `json:"preferred_formats"`
+
+	// AudiobookshelfLibraryID and AudiobookshelfPath map an audiobook library
+	// to Audiobookshelf. The path defaults to Path.
+	AudiobookshelfLibraryID string `json:"audiobookshelf_library_id"`
+	AudiobookshelfPath      string `json:"audiobookshelf_path"`
+
+	// WriteAudioTags rewrites the tags of imported audiobook files. Only
+	// audiobook libraries may set it.
+	WriteAudioTags bool `json:"write_audio_tags"`
 }
 
 type LibraryResponse struct {
-	ID           uint   `json:"id"`
-	Name         string `json:"name"`
-	Path         string `json:"path"`
-	BookTypeID   uint   `json:"book_type_id"`
-	BookTypeName string `json:"book_type_name"`
+	ID                      uint     `json:"id"`
+	Name                    string   `json:"name"`
+	Path                    string   `json:"path"`
+	BookTypeID              uint     `json:"book_type_id"`
+	BookTypeName            string   `json:"book_type_name"`
+	PreferredFormats        []string `json:"preferred_formats"`
+	AudiobookshelfLibraryID string   `json:"audiobookshelf_library_id"`
+	AudiobookshelfPath      string   `json:"audiobookshelf_path"`
+	WriteAudioTags          bool     `json:"write_audio_tags"`
 }
 
 type LibraryHandler struct{}
 
 func (h LibraryHandler) ModelToResponse(c *echo.Context, ctx context.Context, db *gorm.DB, row models.Library) LibraryResponse {
+	// Never null, so clients can treat it as a list
+	formats := append([]string{}, common.SplitFormats(row.PreferredFormats)...)
+
 	return LibraryResponse{
-		ID:           row.ID,
-		Name:         row.Name,
-		Path:         row.Path,
-		BookTypeID:   row.BookTypeID,
-		BookTypeName: row.BookType.Name,
+		ID:                      row.ID,
+		Name:                    row.Name,
+		Path:                    row.Path,
+		BookTypeID:              row.BookTypeID,
+		BookTypeName:            row.BookType.Name,
+		PreferredFormats:        formats,
+		AudiobookshelfLibraryID: row.AudiobookshelfLibraryID,
+		AudiobookshelfPath:      row.AudiobookshelfPath,
+		WriteAudioTags:          row.WriteAudioTags,
 	}
 }
 
 func (h LibraryHandler) RequestToModel(c *echo.Context, ctx context.Context, db *gorm.DB, req LibraryRequest) (models.Library, error) {
-	var bookType models.BookType
-	if err := LookupByName(db, ctx, &bookType, req.BookTypeName, "Book type"); err != nil {
-		return models.Library{}, BadRequest(c, ctx, "Invalid book_type_name: "+req.BookTypeName)
-	}
-
-	return models.Library{
-		Name:       req.Name,
-		Path:       req.Path,
-		BookTypeID: bookType.ID,
-	}, nil
+	var row models.Library
+	return row, h.UpdateModel(c, ctx, db, &row, req)
 }
 
 func (h LibraryHandler) UpdateModel(c *echo.Context, ctx context.Context, db *gorm.DB, row *models.Library, req LibraryRequest) error {
@@ -56,9 +75,33 @@ func (h LibraryHandler) UpdateModel(c *echo.Context, ctx context.Context, db *go
 		return BadRequest(c, ctx, "Invalid book_type_name: "+req.BookTypeName)
 	}
 
+	formats := common.SplitFormats(strings.Join(req.PreferredFormats, ","))
+	if len(formats) > 0 && bookType.Name != common.MediaTypeEbook {
+		return BadRequest(c, ctx, "preferred_formats is only supported for ebook libraries")
+	}
+	for _, format := range formats {
+		if !ebooks.IsEbookFormat(format) {
+			return BadRequest(c, ctx, "Unsupported ebook format: "+format)
+		}
+	}
+
+	if (req.AudiobookshelfLibraryID != "" || req.AudiobookshelfPath != "") && bookType.Name != common.MediaTypeAudiobook {
+		return BadRequest(c, ctx, "Audiobookshelf settings are only supported for audiobook libraries")
+	}
+	if req.AudiobookshelfPath != "" && req.AudiobookshelfLibraryID == "" {
+		return BadRequest(c, ctx, "audiobookshelf_path requires audiobookshelf_library_id")
+	}
+	if req.WriteAudioTags && bookType.Name != common.MediaTypeAudiobook {
+		return BadRequest(c, ctx, "write_audio_tags is only supported for audiobook libraries")
+	}
+
 	row.Name = req.Name
 	row.Path = req.Path
 	row.BookTypeID = bookType.ID
+	row.PreferredFormats = strings.Join(formats, ",")
+	row.AudiobookshelfLibraryID = req.AudiobookshelfLibraryID
+	row.AudiobookshelfPath = req.AudiobookshelfPath
+	row.WriteAudioTags = req.WriteAudioTags
 	return nil
 }
 
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bobbyrward/stronghold/internal/models"
+)
+
+func sendLibrary(t *testing.T, e *echo.Echo, method, path string, req LibraryRequest) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest(method, path, bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httpReq)
+
+	return rec
+}
+
+func TestLibraries_PreferredFormats(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+	e := SetupTestServerWithDB(db)
+
+	var created LibraryResponse
+
+	t.Run("Create normalizes formats", func(t *testing.T) {
+		rec := sendLibrary(t, e, http.MethodPost, "/api/libraries", LibraryRequest{
+			Name:             "books",
+			Path:             "/books",
+			BookTypeName:     "ebook",
+			PreferredFormats: []string{" EPUB", ".azw3", ""},
+		})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		assert.Equal(t, []string{"epub", "azw3"}, created.PreferredFormats)
+
+		var row models.Library
+		require.NoError(t, db.First(&row, created.ID).Error)
+		assert.Equal(t, "epub,azw3", row.PreferredFormats)
+	})
+
+	t.Run("Update clears formats", func(t *testing.T) {
+		rec := sendLibrary(t, e, http.MethodPut, fmt.Sprintf("/api/libraries/%d", created.ID), LibraryRequest{
+			Name:         "books",
+			Path:         "/books",
+			BookTypeName: "ebook",
+		})
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var updated LibraryResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &updated))
+		assert.NotNil(t, updated.PreferredFormats)
+		assert.Empty(t, updated.PreferredFormats)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		rec := sendLibrary(t, e, http.MethodPost, "/api/libraries", LibraryRequest{
+			Name:             "pdfs",
+			Path:             "/pdfs",
+			BookTypeName:     "ebook",
+			PreferredFormats: []string{"pdf"},
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Audiobook library", func(t *testing.T) {
+		rec := sendLibrary(t, e, http.MethodPost, "/api/libraries", LibraryRequest{
+			Name:             "audiobooks",
+			Path:             "/audiobooks",
+			BookTypeName:     "audiobook",
+			PreferredFormats: []string{"epub"},
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		var count int64
+		require.NoError(t, db.Model(&models.Library{}).Where("name = ?", "audiobooks").Count(&count).Error)
+		assert.Zero(t, count)
+	})
+}
+
+func TestLibraries_Audiobookshelf(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+	e := SetupTestServerWithDB(db)
+
+	t.Run("Create audiobook library", func(t *testing.T) {
+		rec := sendLibrary(t, e, http.MethodPost, "/api/libraries", LibraryRequest{
+			Name:                    "audiobooks",
+			Path:                    "/data/audiobooks",
+			BookTypeName:            "audiobook",
+			AudiobookshelfLibraryID: "lib1",
+			AudiobookshelfPath:      "/audiobooks",
+		})
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var created LibraryResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+		assert.Equal(t, "lib1", created.AudiobookshelfLibraryID)
+		assert.Equal(t, "/audiobooks", created.AudiobookshelfPath)
+	})
+
+	t.Run("Path without library id", func(t *testing.T) {
+		rec := sendLibrary(t, e, http.MethodPost, "/api/libraries", LibraryRequest{
+			Name:               "more-audiobooks",
+			Path:               "/data/more",
+			BookTypeName:       "audiobook",
+			AudiobookshelfPath: "/more",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Ebook library", func(t *testing.T) {
+		rec := sendLibrary(t, e, http.MethodPost, "/api/libraries", LibraryRequest{
+			Name:                    "books",
+			Path:                    "/books",
+			BookTypeName:            "ebook",
+			AudiobookshelfLibraryID: "lib1",
+		})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func TestLibraries_WriteAudioTags(t *testing.T) {
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+	e := SetupTestServerWithDB(db)
+
+	rec := sendLibrary(t, e, http.MethodPost, "/api/libraries", LibraryRequest{
+		Name:           "audiobooks",
+		Path:           "/audiobooks",
+		BookTypeName:   "audiobook",
+		WriteAudioTags: true,
+	})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var created LibraryResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.True(t, created.WriteAudioTags)
+
+	var row models.Library
+	require.NoError(t, db.First(&row, created.ID).Error)
+	assert.True(t, row.WriteAudioTags)
+
+	rec = sendLibrary(t, e, http.MethodPost, "/api/libraries", LibraryRequest{
+		Name:           "books",
+		Path:           "/books",
+		BookTypeName:   "ebook",
+		WriteAudioTags: true,
+	})
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -32,6 +32,13 @@ func RegisterRoutes(e *echo.Group, db *gorm.DB, hc hardcover.Client) {
 	e.PUT("/libraries/:id", UpdateLibrary(db))
 	e.DELETE("/libraries/:id", DeleteLibrary(db))
 
+	// Import Types
+	e.GET("/import-types", ListImportTypes(db))
+	e.POST("/import-types", CreateImportType(db))
+	e.GET("/import-types/:id", GetImportType(db))
+	e.PUT("/import-types/:id", UpdateImportType(db))
+	e.DELETE("/import-types/:id", DeleteImportType(db))
+
 	// Notifiers
 	e.GET("/notifiers", ListNotifiers(db))
 	e.POST("/notifiers", CreateNotifier(db))
@@ -48,12 +55,14 @@ func RegisterRoutes(e *echo.Group, db *gorm.DB, hc hardcover.Client) {
 
 	// Torrents
 	e.GET("/torrents/unimported", ListUnimportedTorrents(db))
-	e.GET("/torrents/manual", ListManualInterventionTorrents(db))
+	e.GET("/torrents/manual", ListManualInterventionTorrents(db, nil))
+	e.POST("/torrents/:hash/resolve", ResolveManualIntervention(db, nil))
 	e.POST("/torrents/:hash/category", SetTorrentCategory(db))
 	e.POST("/torrents/:hash/tags", SetTorrentTags(db))
 
 	// Audiobook Wizard
 	e.GET("/audiobook-wizard/torrent/:hash/info", GetTorrentInfo(db))
+	e.GET("/audiobook-wizard/torrent/:hash/candidates", GetTorrentCandidates(db))
 	e.POST("/audiobook-wizard/search-asin", SearchASIN(db))
 	e.GET("/audiobook-wizard/asin/:asin/metadata", GetASINMetadata(db))
 	e.POST("/audiobook-wizard/preview-directory", PreviewDirectory(db))
@@ -61,7 +70,7 @@ func RegisterRoutes(e *echo.Group, db *gorm.DB, hc hardcover.Client) {
 	e.POST("/audiobook-wizard/execute-import", ExecuteImport(db))
 
 	// Downloads
-	e.POST("/book-torrent-dl", DownloadBookTorrent(db, nil))
+	e.POST("/book-torrent-dl", DownloadBookTorrent(db, nil, nil))
 
 	// Authors
 	e.GET("/authors", ListAuthors(db, hc))
@@ -89,10 +98,19 @@ func RegisterRoutes(e *echo.Group, db *gorm.DB, hc hardcover.Client) {
 	// Hardcover
 	e.GET("/hardcover/authors/search", SearchHardcoverAuthors(hc))
 
+	// Book Search
+	e.GET("/booksearch", SearchBooks(db, nil))
+	e.GET("/booksearch/history", ListSearchHistory(db))
+	e.GET("/booksearch/credential", GetBookSearchCredential(db))
+	e.POST("/booksearch/credential/refresh", RefreshBookSearchCredential(db, nil))
+
 	// Event Logs (read-only, paginated)
 	e.GET("/event-logs", ListEventLogs(db))
 	e.GET("/event-logs/:id", GetEventLog(db))
 
+	// Health checks
+	e.GET("/health/download-client", GetDownloadClientHealth(nil))
+
 	// Version info
 	e.GET("/version", GetVersion())
 }
@@ -1,23 +1,67 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/bobbyrward/stronghold/internal/config"
-	"github.com/bobbyrward/stronghold/internal/qbit"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks"
+	"github.com/bobbyrward/stronghold/internal/importers/audiobooks/metadata"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
 	"github.com/labstack/echo/v5"
 	"gorm.io/gorm"
 )
 
 type TorrentResponse struct {
-	Hash     string            `json:"hash"`
-	Name     string            `json:"name"`
-	Category string            `json:"category"`
-	State    qbit.TorrentState `json:"state"`
-	Tags     string            `json:"tags"`
+	Hash     string                      `json:"hash"`
+	Name     string                      `json:"name"`
+	Category string                      `json:"category"`
+	State    downloadclient.TorrentState `json:"state"`
+	Tags     string                      `json:"tags"`
+}
+
+// ManualInterventionResponse is why a torrent needs manual intervention and how
+// it was resolved.
+type ManualInterventionResponse struct {
+	Source     string          `json:"source"`
+	Reason     string          `json:"reason"`
+	ErrorClass string          `json:"error_class"`
+	Attempts   int             `json:"attempts"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	Status     string          `json:"status"`
+	Resolution string          `json:"resolution"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	ResolvedAt *time.Time      `json:"resolved_at"`
+}
+
+// ManualInterventionTorrentResponse is a torrent tagged for manual intervention.
+// Intervention is nil for torrents tagged before interventions were recorded.
+type ManualInterventionTorrentResponse struct {
+	TorrentResponse
+	Intervention *ManualInterventionResponse `json:"intervention"`
+	Candidates   []MatchCandidateResponse    `json:"candidates"`
+}
+
+// ResolveManualInterventionRequest resolves a manual intervention. Importing
+// takes either the full metadata or the ASIN of a saved match candidate, and
+// defaults to the library of the category's import type.
+type ResolveManualInterventionRequest struct {
+	Action      string                 `json:"action" validate:"required,oneof=retry import ignore delete"`
+	DeleteFiles bool                   `json:"delete_files"`
+	ASIN        string                 `json:"asin"`
+	Metadata    *metadata.BookMetadata `json:"metadata"`
+	LibraryName string                 `json:"library_name"`
 }
 
 type TorrentChangeCategoryRequest struct {
@@ -28,18 +72,18 @@ type TorrentChangeTagsRequest struct {
 	Tags string `json:"tags" validate:"required"`
 }
 
-func responseFromTorrent(torrent qbit.Torrent) TorrentResponse {
+func responseFromTorrent(torrent downloadclient.Torrent) TorrentResponse {
 	return TorrentResponse{
 		Hash:     torrent.Hash,
 		Name:     torrent.Name,
 		Category: torrent.Category,
 		State:    torrent.State,
-		Tags:     torrent.Tags,
+		Tags:     strings.Join(torrent.Tags, ", "),
 	}
 }
 
-func sortTorrents(torrents []qbit.Torrent) {
-	slices.SortStableFunc(torrents, func(a, b qbit.Torrent) int {
+func sortTorrents(torrents []downloadclient.Torrent) {
+	slices.SortStableFunc(torrents, func(a, b downloadclient.Torrent) int {
 		cmp := strings.Compare(string(a.State), string(b.State))
 
 		switch cmp {
@@ -60,14 +104,14 @@ func ListUnimportedTorrents(
 
 		slog.InfoContext(ctx, "Listing unimported torrents")
 
-		qbitClient, err := qbit.CreateClient()
+		downloadClient, err := downloadclient.SharedClient()
 		if err != nil {
-			return InternalError(c, ctx, "failed to create qBittorrent client", err)
+			return InternalError(c, ctx, "failed to create download client", err)
 		}
 
-		torrents, err := qbit.GetUnimportedTorrents(ctx, qbitClient)
+		torrents, err := downloadclient.GetUnimportedTorrents(ctx, downloadClient)
 		if err != nil {
-			return InternalError(c, ctx, "failed to get torrents from qBittorrent", err)
+			return InternalError(c, ctx, "failed to get torrents from download client", err)
 		}
 
 		responses := make([]TorrentResponse, len(torrents))
@@ -82,40 +126,260 @@ func ListUnimportedTorrents(
 	}
 }
 
+// ListManualInterventionTorrents returns the torrents tagged for manual intervention
+// with the recorded reason and, for audiobooks, the saved match candidates. Ignored
+// torrents are left out unless include_ignored=true.
 func ListManualInterventionTorrents(
 	db *gorm.DB,
+	downloadClient *downloadclient.DownloadClient,
 ) echo.HandlerFunc {
 	return func(c *echo.Context) error {
 		ctx := c.Request().Context()
 
 		slog.InfoContext(ctx, "Listing manual intervention torrents")
 
-		qbitClient, err := qbit.CreateClient()
+		client, err := resolveDownloadClient(downloadClient)
 		if err != nil {
-			return InternalError(c, ctx, "failed to create qBittorrent client", err)
+			return InternalError(c, ctx, "failed to create download client", err)
 		}
 
-		torrents, err := qbit.GetManualInterventionTorrents(
+		torrents, err := downloadclient.GetManualInterventionTorrents(
 			ctx,
-			qbitClient,
+			client,
 			config.Config.Importers.ManualInterventionTag,
 		)
 		if err != nil {
-			return InternalError(c, ctx, "failed to get torrents from qBittorrent", err)
+			return InternalError(c, ctx, "failed to get torrents from download client", err)
 		}
 
-		responses := make([]TorrentResponse, len(torrents))
+		hashes := make([]string, len(torrents))
+		for i, t := range torrents {
+			hashes[i] = t.Hash
+		}
+
+		interventions, err := common.LoadManualInterventions(ctx, db, hashes)
+		if err != nil {
+			return InternalError(c, ctx, "failed to load manual interventions", err)
+		}
+
+		includeIgnored := c.QueryParam("include_ignored") == "true"
 
 		sortTorrents(torrents)
 
-		for i, t := range torrents {
-			responses[i] = responseFromTorrent(t)
+		responses := make([]ManualInterventionTorrentResponse, 0, len(torrents))
+
+		for _, t := range torrents {
+			response := ManualInterventionTorrentResponse{
+				TorrentResponse: responseFromTorrent(t),
+				Candidates:      []MatchCandidateResponse{},
+			}
+
+			if intervention, ok := interventions[t.Hash]; ok {
+				if intervention.Status == common.InterventionIgnored && !includeIgnored {
+					continue
+				}
+
+				response.Intervention = responseFromIntervention(intervention)
+			}
+
+			candidates, err := audiobooks.LoadCandidates(db, t.Hash)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to load match candidates", slog.String("hash", t.Hash), slog.Any("error", err))
+			} else {
+				response.Candidates = toMatchCandidateResponses(candidates)
+			}
+
+			responses = append(responses, response)
 		}
 
 		return c.JSON(http.StatusOK, responses)
 	}
 }
 
+// ResolveManualIntervention resolves a torrent tagged for manual intervention by
+// re-queueing it, importing it with chosen metadata, ignoring it or deleting it.
+func ResolveManualIntervention(
+	db *gorm.DB,
+	downloadClient *downloadclient.DownloadClient,
+) echo.HandlerFunc {
+	return func(c *echo.Context) error {
+		ctx := c.Request().Context()
+
+		hash := c.Param("hash")
+
+		var req ResolveManualInterventionRequest
+		if err := BindRequest(c, ctx, &req); err != nil {
+			return BadRequest(c, ctx, "Invalid request body")
+		}
+
+		if err := ValidateRequest(c, ctx, &req); err != nil {
+			return BadRequest(c, ctx, "Invalid request body")
+		}
+
+		client, err := resolveDownloadClient(downloadClient)
+		if err != nil {
+			return InternalError(c, ctx, "failed to create download client", err)
+		}
+
+		torrent, found, err := getTorrent(ctx, client, hash)
+		if err != nil {
+			return downloadClientError(c, ctx, "failed to get torrent from download client", err)
+		}
+		if !found {
+			return GenericNotFound(c, ctx, "torrent not found")
+		}
+
+		slog.InfoContext(ctx, "Resolving manual intervention",
+			slog.String("hash", hash),
+			slog.String("action", req.Action))
+
+		var details map[string]any
+
+		switch req.Action {
+		case common.ResolutionRetry:
+			err = common.RetryManualIntervention(ctx, db, client, torrent)
+		case common.ResolutionIgnore:
+			err = common.IgnoreManualIntervention(ctx, db, torrent)
+		case common.ResolutionDelete:
+			err = common.DeleteManualInterventionTorrent(ctx, db, client, torrent, req.DeleteFiles)
+			details = map[string]any{"deleteFiles": req.DeleteFiles}
+		case common.ResolutionImport:
+			bookMetadata, libraryRow, message := resolveImportTarget(ctx, db, torrent, req)
+			if message != "" {
+				return BadRequest(c, ctx, message)
+			}
+
+			var destinationPath string
+			destinationPath, err = importAudiobook(ctx, db, client, torrent, bookMetadata, libraryRow)
+			details = map[string]any{"asin": bookMetadata.Asin, "title": bookMetadata.Title, "destination": destinationPath}
+		}
+		if err != nil {
+			return InternalError(c, ctx, "failed to resolve manual intervention", err)
+		}
+
+		if details == nil {
+			details = map[string]any{}
+		}
+		details["name"] = torrent.Name
+		details["action"] = req.Action
+
+		eventlog.Log(db, eventlog.CategoryImport, eventlog.EventImportManualResolved, eventlog.SourceAPI,
+			eventlog.EntityTorrent, torrent.Hash,
+			fmt.Sprintf("Manual intervention resolved (%s): %s", req.Action, torrent.Name),
+			details)
+
+		return c.NoContent(http.StatusNoContent)
+	}
+}
+
+// resolveImportTarget picks the metadata and library for importing a torrent
+// from manual intervention. message is set when the request cannot be imported.
+func resolveImportTarget(ctx context.Context, db *gorm.DB, torrent downloadclient.Torrent, req ResolveManualInterventionRequest) (metadata.BookMetadata, models.Library, string) {
+	var category models.TorrentCategory
+	if err := db.WithContext(ctx).Where("name = ?", torrent.Category).First(&category).Error; err != nil || category.MediaType != common.MediaTypeAudiobook {
+		return metadata.BookMetadata{}, models.Library{}, "import with chosen metadata is only supported for audiobooks"
+	}
+
+	var bookMetadata metadata.BookMetadata
+
+	switch {
+	case req.Metadata != nil:
+		bookMetadata = *req.Metadata
+	case req.ASIN != "":
+		candidates, err := audiobooks.LoadCandidates(db, torrent.Hash)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to load match candidates", slog.String("hash", torrent.Hash), slog.Any("error", err))
+		}
+
+		found := false
+		for _, candidate := range candidates {
+			if candidate.Metadata.Asin == req.ASIN {
+				bookMetadata, found = candidate.Metadata, true
+				break
+			}
+		}
+
+		if !found {
+			return metadata.BookMetadata{}, models.Library{}, "no match candidate with asin " + req.ASIN
+		}
+	default:
+		return metadata.BookMetadata{}, models.Library{}, "metadata or asin is required to import"
+	}
+
+	libraryName := req.LibraryName
+	if libraryName == "" {
+		importType, ok, err := common.FindImportType(ctx, db, torrent.Category)
+		if err != nil || !ok {
+			return metadata.BookMetadata{}, models.Library{}, "library_name is required, category has no import type"
+		}
+
+		libraryName = importType.Library.Name
+	}
+
+	var libraryRow models.Library
+	err := db.WithContext(ctx).Preload("BookType").Where("name = ?", libraryName).First(&libraryRow).Error
+	if err != nil || libraryRow.BookType.Name != common.MediaTypeAudiobook {
+		return metadata.BookMetadata{}, models.Library{}, "library not found"
+	}
+
+	return bookMetadata, libraryRow, ""
+}
+
+func responseFromIntervention(intervention models.ManualIntervention) *ManualInterventionResponse {
+	response := &ManualInterventionResponse{
+		Source:     intervention.Source,
+		Reason:     intervention.Reason,
+		ErrorClass: intervention.ErrorClass,
+		Attempts:   intervention.Attempts,
+		Status:     intervention.Status,
+		Resolution: intervention.Resolution,
+		CreatedAt:  intervention.CreatedAt,
+		UpdatedAt:  intervention.UpdatedAt,
+		ResolvedAt: intervention.ResolvedAt,
+	}
+
+	if intervention.Metadata != nil {
+		response.Metadata = json.RawMessage(*intervention.Metadata)
+	}
+
+	return response
+}
+
+// resolveDownloadClient returns downloadClient, or the shared client when it is nil.
+func resolveDownloadClient(downloadClient *downloadclient.DownloadClient) (downloadclient.DownloadClient, error) {
+	if downloadClient != nil {
+		return *downloadClient, nil
+	}
+
+	return downloadclient.SharedClient()
+}
+
+// downloadClientError returns a 502 response when the download client could
+// not be reached and a 500 response for its other failures.
+func downloadClientError(c *echo.Context, ctx context.Context, message string, err error) error {
+	if downloadclient.IsTransient(err) || errors.Is(err, downloadclient.ErrCircuitOpen) {
+		slog.ErrorContext(ctx, message, slog.Any("error", err))
+		return c.JSON(http.StatusBadGateway, map[string]string{"error": message})
+	}
+
+	return InternalError(c, ctx, message, err)
+}
+
+// getTorrent returns the torrent with hash. found is false when the download
+// client has no such torrent; err is only set when the client failed.
+func getTorrent(ctx context.Context, client downloadclient.DownloadClient, hash string) (downloadclient.Torrent, bool, error) {
+	torrents, err := client.GetTorrents(ctx, downloadclient.TorrentFilter{Hashes: []string{hash}})
+	if err != nil {
+		return downloadclient.Torrent{}, false, err
+	}
+
+	if len(torrents) != 1 {
+		return downloadclient.Torrent{}, false, nil
+	}
+
+	return torrents[0], true, nil
+}
+
 func SetTorrentCategory(
 	db *gorm.DB,
 ) echo.HandlerFunc {
@@ -133,17 +397,20 @@ func SetTorrentCategory(
 			return BadRequest(c, ctx, "Invalid request body")
 		}
 
-		qbitClient, err := qbit.CreateClient()
+		downloadClient, err := downloadclient.SharedClient()
 		if err != nil {
-			return InternalError(c, ctx, "failed to create qBittorrent client", err)
+			return InternalError(c, ctx, "failed to create download client", err)
 		}
 
-		torrents, err := qbitClient.GetTorrentsCtx(ctx, qbit.TorrentFilterOptions{Hashes: []string{hash}})
-		if len(torrents) != 1 || err != nil {
+		_, found, err := getTorrent(ctx, downloadClient, hash)
+		if err != nil {
+			return downloadClientError(c, ctx, "failed to get torrent from download client", err)
+		}
+		if !found {
 			return GenericNotFound(c, ctx, "torrent not found")
 		}
 
-		err = qbitClient.SetCategoryCtx(ctx, []string{hash}, req.Category)
+		err = downloadClient.SetCategory(ctx, []string{hash}, req.Category)
 		if err != nil {
 			return InternalError(c, ctx, "failed to set torrent category", err)
 		}
@@ -169,17 +436,20 @@ func SetTorrentTags(
 			return BadRequest(c, ctx, "Invalid request body")
 		}
 
-		qbitClient, err := qbit.CreateClient()
+		downloadClient, err := downloadclient.SharedClient()
 		if err != nil {
-			return InternalError(c, ctx, "failed to create qBittorrent client", err)
+			return InternalError(c, ctx, "failed to create download client", err)
 		}
 
-		torrents, err := qbitClient.GetTorrentsCtx(ctx, qbit.TorrentFilterOptions{Hashes: []string{hash}})
-		if len(torrents) != 1 || err != nil {
+		_, found, err := getTorrent(ctx, downloadClient, hash)
+		if err != nil {
+			return downloadClientError(c, ctx, "failed to get torrent from download client", err)
+		}
+		if !found {
 			return GenericNotFound(c, ctx, "torrent not found")
 		}
 
-		err = qbitClient.SetTags(ctx, []string{hash}, req.Tags)
+		err = downloadClient.SetTags(ctx, []string{hash}, downloadclient.ParseTagList(req.Tags)...)
 		if err != nil {
 			return InternalError(c, ctx, "failed to set torrent tags", err)
 		}
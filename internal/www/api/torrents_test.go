@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/bobbyrward/stronghold/internal/config"
+	"github.com/bobbyrward/stronghold/internal/downloadclient"
+	"github.com/bobbyrward/stronghold/internal/eventlog"
+	"github.com/bobbyrward/stronghold/internal/importers/common"
+	"github.com/bobbyrward/stronghold/internal/models"
+	"github.com/bobbyrward/stronghold/internal/testutil"
+)
+
+func setupManualInterventionTest(t *testing.T, torrents ...downloadclient.Torrent) (*echo.Echo, *testutil.MockDownloadClient, *gorm.DB) {
+	t.Helper()
+
+	db, err := models.ConnectTestDB()
+	require.NoError(t, err)
+
+	config.Config.Importers.ManualInterventionTag = "needs-manual"
+
+	mockClient := &testutil.MockDownloadClient{}
+	mockClient.GetTorrentsReturn.Torrents = torrents
+	var downloadClient downloadclient.DownloadClient = mockClient
+
+	e := SetupTestServerWithDB(db)
+	e.GET("/api/test/torrents/manual", ListManualInterventionTorrents(db, &downloadClient))
+	e.POST("/api/test/torrents/:hash/resolve", ResolveManualIntervention(db, &downloadClient))
+
+	return e, mockClient, db
+}
+
+func postResolve(t *testing.T, e *echo.Echo, hash string, req ResolveManualInterventionRequest) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	httpReq := httptest.NewRequest(http.MethodPost, "/api/test/torrents/"+hash+"/resolve", bytes.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httpReq)
+
+	return rec
+}
+
+func TestListManualInterventionTorrents(t *testing.T) {
+	ctx := context.Background()
+
+	failed := downloadclient.Torrent{Hash: "failed", Name: "Dune", Category: "audiobooks", Tags: []string{"needs-manual"}}
+	ignored := downloadclient.Torrent{Hash: "ignored", Name: "Emma", Category: "books", Tags: []string{"needs-manual"}}
+	legacy := downloadclient.Torrent{Hash: "legacy", Name: "Old", Category: "books", Tags: []string{"needs-manual"}}
+	untagged := downloadclient.Torrent{Hash: "untagged", Name: "Other", Category: "books"}
+
+	e, _, db := setupManualInterventionTest(t, failed, ignored, legacy, untagged)
+
+	common.RecordManualIntervention(ctx, db, failed, eventlog.SourceAudiobookImporter, "Multiple candidates found")
+	require.NoError(t, common.IgnoreManualIntervention(ctx, db, ignored))
+	require.NoError(t, db.Create(&models.AudiobookMatchCandidate{
+		TorrentHash: "failed", Asin: "B002V1OF70", Rank: 1, Score: 0.9, Summary: "Dune",
+		Metadata: `{"asin": "B002V1OF70", "title": "Dune"}`,
+	}).Error)
+
+	list := func(query string) []ManualInterventionTorrentResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/test/torrents/manual"+query, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var responses []ManualInterventionTorrentResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &responses))
+		return responses
+	}
+
+	responses := list("")
+	require.Len(t, responses, 2)
+
+	byHash := map[string]ManualInterventionTorrentResponse{}
+	for _, response := range responses {
+		byHash[response.Hash] = response
+	}
+
+	require.NotNil(t, byHash["failed"].Intervention)
+	assert.Equal(t, "Multiple candidates found", byHash["failed"].Intervention.Reason)
+	assert.Equal(t, eventlog.SourceAudiobookImporter, byHash["failed"].Intervention.Source)
+	assert.Equal(t, common.InterventionOpen, byHash["failed"].Intervention.Status)
+	require.Len(t, byHash["failed"].Candidates, 1)
+	assert.Equal(t, "B002V1OF70", byHash["failed"].Candidates[0].Metadata.Asin)
+
+	assert.Nil(t, byHash["legacy"].Intervention)
+	assert.Empty(t, byHash["legacy"].Candidates)
+
+	assert.Len(t, list("?include_ignored=true"), 3)
+}
+
+func TestResolveManualIntervention(t *testing.T) {
+	ctx := context.Background()
+	torrent := downloadclient.Torrent{Hash: "abc", Name: "Dune", Category: "audiobooks", Tags: []string{"needs-manual"}}
+
+	t.Run("retry", func(t *testing.T) {
+		e, mockClient, db := setupManualInterventionTest(t, torrent)
+		common.RecordManualIntervention(ctx, db, torrent, eventlog.SourceAudiobookImporter, "Failed")
+
+		rec := postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "retry"})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		require.Len(t, mockClient.RemoveTagsCalls, 1)
+		assert.Equal(t, []string{"needs-manual"}, mockClient.RemoveTagsCalls[0].Tags)
+
+		var intervention models.ManualIntervention
+		require.NoError(t, db.Where("torrent_hash = ?", "abc").First(&intervention).Error)
+		assert.Equal(t, common.ResolutionRetry, intervention.Resolution)
+
+		var events []models.EventLog
+		require.NoError(t, db.Where("event_type = ?", eventlog.EventImportManualResolved).Find(&events).Error)
+		assert.Len(t, events, 1)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		e, mockClient, _ := setupManualInterventionTest(t, torrent)
+
+		rec := postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "delete", DeleteFiles: true})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+
+		require.Len(t, mockClient.RemoveTorrentsCalls, 1)
+		assert.True(t, mockClient.RemoveTorrentsCalls[0].DeleteFiles)
+	})
+
+	t.Run("ignore", func(t *testing.T) {
+		e, mockClient, db := setupManualInterventionTest(t, torrent)
+
+		rec := postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "ignore"})
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		assert.Empty(t, mockClient.RemoveTagsCalls)
+
+		var intervention models.ManualIntervention
+		require.NoError(t, db.Where("torrent_hash = ?", "abc").First(&intervention).Error)
+		assert.Equal(t, common.InterventionIgnored, intervention.Status)
+	})
+
+	t.Run("import needs metadata or a known candidate", func(t *testing.T) {
+		e, _, _ := setupManualInterventionTest(t, torrent)
+
+		rec := postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "import"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+		rec = postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "import", ASIN: "B000000000"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("import is only for audiobooks", func(t *testing.T) {
+		ebook := downloadclient.Torrent{Hash: "def", Name: "Emma", Category: "books", Tags: []string{"needs-manual"}}
+		e, _, _ := setupManualInterventionTest(t, ebook)
+
+		rec := postResolve(t, e, "def", ResolveManualInterventionRequest{Action: "import", ASIN: "B002V1OF70"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unknown action", func(t *testing.T) {
+		e, _, _ := setupManualInterventionTest(t, torrent)
+
+		rec := postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "explode"})
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unknown torrent", func(t *testing.T) {
+		e, _, _ := setupManualInterventionTest(t)
+
+		rec := postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "retry"})
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("download client unreachable", func(t *testing.T) {
+		e, mockClient, _ := setupManualInterventionTest(t, torrent)
+		mockClient.GetTorrentsReturn.Err = downloadclient.ErrCircuitOpen
+
+		rec := postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "retry"})
+		assert.Equal(t, http.StatusBadGateway, rec.Code)
+	})
+
+	t.Run("download client error", func(t *testing.T) {
+		e, mockClient, _ := setupManualInterventionTest(t, torrent)
+		mockClient.GetTorrentsReturn.Err = &downloadclient.StatusError{StatusCode: http.StatusForbidden, Message: "forbidden"}
+
+		rec := postResolve(t, e, "abc", ResolveManualInterventionRequest{Action: "retry"})
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/labstack/echo/v5"
 	"github.com/labstack/echo/v5/middleware"
 
+	"github.com/bobbyrward/stronghold/internal/booksearch"
 	"github.com/bobbyrward/stronghold/internal/config"
 	"github.com/bobbyrward/stronghold/internal/eventlog"
 	"github.com/bobbyrward/stronghold/internal/hardcover"
@@ -43,6 +44,9 @@ func Run() error {
 	// Clean up old event logs
 	eventlog.Cleanup(ctx, db, 90)
 
+	// Clean up expired search cache entries and old search history
+	booksearch.Cleanup(ctx, db, config.Config.BookSearch.Cache.HistoryRetentionDays)
+
 	echoServer := echo.New()
 
 	// Add slog middleware for request logging
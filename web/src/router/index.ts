@@ -30,6 +30,11 @@ const routes = [
     name: 'libraries',
     component: () => import('@/views/LibrariesView.vue')
   },
+  {
+    path: '/import-types',
+    name: 'import-types',
+    component: () => import('@/views/ImportTypesView.vue')
+  },
   {
     path: '/notifiers',
     name: 'notifiers',
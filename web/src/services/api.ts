@@ -5,14 +5,19 @@ import type {
     BookType,
     Library,
     LibraryRequest,
+    ImportType,
+    ImportTypeRequest,
     Feed,
     FeedRequest,
     Notifier,
     NotifierRequest,
     Torrent,
+    ManualInterventionTorrent,
+    ResolveManualInterventionRequest,
     // Audiobook Wizard types
     BookMetadata,
     TorrentImportInfo,
+    MatchCandidate,
     SearchASINRequest,
     PreviewDirectoryRequest,
     PreviewDirectoryResponse,
@@ -29,6 +34,9 @@ import type {
     HardcoverAuthorSearchResult,
     PaginatedEventLogResponse,
     EventLog,
+    PaginatedBookSearchResponse,
+    PaginatedSearchHistoryResponse,
+    BookSearchCredential,
     VersionInfo
 } from '@/types/api'
 
@@ -100,6 +108,24 @@ export const api = {
             request<void>(`/libraries/${id}`, { method: 'DELETE' })
     },
 
+    // Import types
+    importTypes: {
+        list: () => request<ImportType[]>('/import-types'),
+        get: (id: number) => request<ImportType>(`/import-types/${id}`),
+        create: (data: ImportTypeRequest) =>
+            request<ImportType>('/import-types', {
+                method: 'POST',
+                body: JSON.stringify(data)
+            }),
+        update: (id: number, data: ImportTypeRequest) =>
+            request<ImportType>(`/import-types/${id}`, {
+                method: 'PUT',
+                body: JSON.stringify(data)
+            }),
+        delete: (id: number) =>
+            request<void>(`/import-types/${id}`, { method: 'DELETE' })
+    },
+
     // Hardcover (external search)
     hardcover: {
         searchAuthors: (query: string) =>
@@ -206,7 +232,12 @@ export const api = {
     // Torrents
     torrents: {
         unimported: () => request<Torrent[]>('/torrents/unimported'),
-        manualIntervention: () => request<Torrent[]>('/torrents/manual'),
+        manualIntervention: (includeIgnored = false) =>
+            request<ManualInterventionTorrent[]>(`/torrents/manual${includeIgnored ? '?include_ignored=true' : ''}`),
+        resolve: (hash: string, data: ResolveManualInterventionRequest) => request<void>(`/torrents/${hash}/resolve`, {
+            method: 'POST',
+            body: JSON.stringify(data)
+        }),
         changeCategory: (hash: string, category: string) => request<void>(`/torrents/${hash}/category`, {
             method: 'POST',
             body: JSON.stringify({ category: category })
@@ -222,6 +253,9 @@ export const api = {
         getTorrentInfo: (hash: string) =>
             request<TorrentImportInfo>(`/audiobook-wizard/torrent/${hash}/info`),
 
+        getCandidates: (hash: string) =>
+            request<MatchCandidate[]>(`/audiobook-wizard/torrent/${hash}/candidates`),
+
         searchAsin: (data: SearchASINRequest) =>
             request<BookMetadata[]>('/audiobook-wizard/search-asin', {
                 method: 'POST',
@@ -256,6 +290,21 @@ export const api = {
         get: (id: number) => request<EventLog>(`/event-logs/${id}`)
     },
 
+    // Book Search (paginated)
+    bookSearch: {
+        search: (params: Record<string, string>) => {
+            const query = new URLSearchParams(params).toString()
+            return request<PaginatedBookSearchResponse>(`/booksearch?${query}`)
+        },
+        history: (params: Record<string, string>) => {
+            const query = new URLSearchParams(params).toString()
+            return request<PaginatedSearchHistoryResponse>(`/booksearch/history?${query}`)
+        },
+        getCredential: () => request<BookSearchCredential>('/booksearch/credential'),
+        refreshCredential: () =>
+            request<BookSearchCredential>('/booksearch/credential/refresh', { method: 'POST' })
+    },
+
     // Version info
     version: {
         get: () => request<VersionInfo>('/version')
@@ -28,12 +28,59 @@ export interface Library {
     path: string
     book_type_id: number
     book_type_name: string
+    preferred_formats: string[]
+    audiobookshelf_library_id: string
+    audiobookshelf_path: string
+    write_audio_tags: boolean
 }
 
 export interface LibraryRequest {
     name: string
     path: string
     book_type_name: string
+    preferred_formats?: string[]
+    audiobookshelf_library_id?: string
+    audiobookshelf_path?: string
+    write_audio_tags?: boolean
+}
+
+export interface ImportType {
+    id: number
+    category_id: number
+    category_name: string
+    media_type: string
+    library_id: number
+    library_name: string
+    notifier_id: number | null
+    notifier_name: string | null
+    calibre_desktop_url: string
+    calibre_web_url: string
+    calibre_library: string
+    metadata_providers: string[]
+    min_seed_time_minutes: number
+    min_seed_ratio: number
+    remove_after_ratio: number
+    remove_after_seed_days: number
+    private_min_seed_days: number
+    require_hardlink: boolean
+    delete_files: boolean
+}
+
+export interface ImportTypeRequest {
+    category_name: string
+    library_name: string
+    notifier_name: string | null
+    calibre_desktop_url: string
+    calibre_web_url: string
+    calibre_library: string
+    metadata_providers: string[]
+    min_seed_time_minutes: number
+    min_seed_ratio: number
+    remove_after_ratio: number
+    remove_after_seed_days: number
+    private_min_seed_days: number
+    require_hardlink: boolean
+    delete_files: boolean
 }
 
 // Hardcover search
@@ -78,6 +125,8 @@ export interface AuthorSubscriptionItem {
     torrent_url: string
     title: string
     downloaded_at: string
+    status: string
+    status_checked_at: string | null
 }
 
 // Main resource types
@@ -134,6 +183,32 @@ export interface Torrent {
     tags: string
 }
 
+export interface ManualIntervention {
+    source: string
+    reason: string
+    error_class: string
+    attempts: number
+    metadata?: Record<string, unknown>
+    status: 'open' | 'resolved' | 'ignored'
+    resolution: string
+    created_at: string
+    updated_at: string
+    resolved_at: string | null
+}
+
+export interface ManualInterventionTorrent extends Torrent {
+    intervention: ManualIntervention | null
+    candidates: MatchCandidate[]
+}
+
+export interface ResolveManualInterventionRequest {
+    action: 'retry' | 'import' | 'ignore' | 'delete'
+    delete_files?: boolean
+    asin?: string
+    metadata?: BookMetadata
+    library_name?: string
+}
+
 export interface TorrentChangeCategoryRequest {
     category: string
 }
@@ -195,6 +270,12 @@ export interface TorrentImportInfo {
     author?: string
     suggested_library?: string
     local_path: string
+    candidates?: MatchCandidate[]
+}
+
+export interface MatchCandidate {
+    score: number
+    metadata: BookMetadata
 }
 
 export interface SearchASINRequest {
@@ -250,6 +331,66 @@ export interface PaginatedEventLogResponse {
     facets: EventLogFacets
 }
 
+export interface BookSearchResult {
+    torrent_id: number
+    torrent_url: string
+    title: string
+    authors: string
+    series: string
+    narrators: string
+    category: string
+    main_category: number
+    language: string
+    file_types: string
+    size: string
+    tags: string
+    seeders: number
+    leechers: number
+    added: string
+}
+
+export interface PaginatedBookSearchResponse {
+    items: BookSearchResult[]
+    total: number
+    page: number
+    per_page: number
+    has_more: boolean
+    cached: boolean
+}
+
+export interface SearchHistory {
+    id: number
+    created_at: string
+    source: string
+    requested_by: string
+    location: string
+    query: string
+    result_count: number
+    total_found: number
+    cached: boolean
+}
+
+export interface PaginatedSearchHistoryResponse {
+    items: SearchHistory[]
+    total: number
+    page: number
+    per_page: number
+}
+
+export interface BookSearchCredential {
+    healthy: boolean
+    last_refresh: string | null
+    refresh_due_at: string | null
+    last_error?: string
+    last_failure_at: string | null
+    consecutive_failures: number
+    ip_address: string
+    asn: string
+    previous_ip_address?: string
+    previous_asn?: string
+    ip_changed_at: string | null
+}
+
 export interface VersionInfo {
     version: string
     git_commit: string